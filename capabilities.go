@@ -0,0 +1,151 @@
+package hwp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Feature names a kind of document content the parser may extract.
+type Feature string
+
+const (
+	FeatureText           Feature = "text"
+	FeatureTables         Feature = "tables"
+	FeatureNestedTables   Feature = "nested-tables"
+	FeatureImages         Feature = "images"
+	FeatureFootnotes      Feature = "footnotes"
+	FeatureHeadersFooters Feature = "headers-footers"
+	FeatureStyles         Feature = "styles"
+	FeatureHyperlinks     Feature = "hyperlinks"
+	FeatureEquations      Feature = "equations"
+	FeatureTrackChanges   Feature = "track-changes"
+	FeatureMultiSection   Feature = "multi-section"
+	FeatureEncryption     Feature = "encryption"
+	FeatureEmbeddedDocs   Feature = "embedded-documents"
+	FeatureForms          Feature = "forms"
+	FeatureMedia          Feature = "media"
+	FeatureHiddenText     Feature = "hidden-text"
+	FeatureCharts         Feature = "charts"
+	FeatureComments       Feature = "comments"
+)
+
+// Support describes how completely a feature is extracted.
+type Support int
+
+const (
+	// Unsupported content is dropped from the output.
+	Unsupported Support = iota
+	// Partial content is extracted with losses described in Capability.Note.
+	Partial
+	// Supported content is extracted completely.
+	Supported
+)
+
+func (s Support) String() string {
+	switch s {
+	case Supported:
+		return "supported"
+	case Partial:
+		return "partial"
+	default:
+		return "unsupported"
+	}
+}
+
+// Capability reports the parser's support for one feature.
+type Capability struct {
+	Feature Feature
+	Support Support
+	Note    string
+}
+
+// capabilityTable lists feature support per document type, in report order.
+var capabilityTable = map[string][]Capability{
+	"hwp": {
+		{FeatureText, Supported, ""},
+		{FeatureTables, Supported, ""},
+		{FeatureNestedTables, Supported, ""},
+		{FeatureImages, Partial, "picture data, formats, sizes and descriptions are extracted (ExtractImages); crops are not applied"},
+		{FeatureFootnotes, Partial, "notes follow the paragraph holding them as numbered footnote nodes; their place in the text is not marked"},
+		{FeatureHeadersFooters, Partial, "header and footer text follows the paragraph holding their control as floating paragraphs"},
+		{FeatureStyles, Partial, "character formatting and paragraph layout are kept; outline styles become headings, other named styles are dropped"},
+		{FeatureHyperlinks, Partial, "paragraph links keep their targets; links in table cells and captions keep only their text"},
+		{FeatureEquations, Partial, "equation scripts are translated to LaTeX; unknown commands are kept as written"},
+		{FeatureTrackChanges, Unsupported, "change-tracking records are not decoded; text is read as stored, with a warning when another view is asked for"},
+		{FeatureMultiSection, Supported, ""},
+		{FeatureEncryption, Partial, "distribution documents are decrypted; password-protected documents are rejected"},
+		{FeatureEmbeddedDocs, Partial, "embedded HWP documents are extracted with WithEmbeddedDocuments; other OLE objects are placeholders"},
+		{FeatureForms, Partial, "click-here fields and form objects are extracted; list boxes are not"},
+		{FeatureMedia, Partial, "video sources are reported; video data is not extracted"},
+		{FeatureHiddenText, Supported, "hidden comments are extracted with WithHiddenText"},
+		{FeatureCharts, Unsupported, "charts are OLE objects shown as placeholders"},
+		{FeatureComments, Partial, "memos come after the body text of their section; authors and dates are not extracted"},
+	},
+	"hwpx": {
+		{FeatureText, Supported, ""},
+		{FeatureTables, Supported, ""},
+		{FeatureNestedTables, Unsupported, "tables inside table cells are not extracted"},
+		{FeatureImages, Partial, "picture data, formats, sizes and descriptions are extracted (ExtractImages); crops are not applied"},
+		{FeatureFootnotes, Partial, "notes follow the paragraph holding them as numbered footnote nodes; their place in the text is not marked"},
+		{FeatureHeadersFooters, Partial, "header, footer and master page text follows the paragraph holding them as floating paragraphs"},
+		{FeatureStyles, Partial, "character formatting and paragraph layout are kept; outline styles become headings, other named styles are dropped"},
+		{FeatureHyperlinks, Partial, "link text is kept, targets are dropped"},
+		{FeatureEquations, Partial, "equation scripts are translated to LaTeX; unknown commands are kept as written"},
+		{FeatureTrackChanges, Supported, "changes are applied, rejected or marked with WithTrackChanges"},
+		{FeatureMultiSection, Supported, ""},
+		{FeatureEncryption, Unsupported, "encrypted and DRM-protected packages are rejected with an EncryptionError"},
+		{FeatureEmbeddedDocs, Partial, "OLE objects are extracted with ExtractAttachments and report their class; their content is not extracted"},
+		{FeatureForms, Supported, ""},
+		{FeatureMedia, Partial, "local videos are identified by their manifest item ID"},
+		{FeatureHiddenText, Supported, "hidden comments are extracted with WithHiddenText"},
+		{FeatureCharts, Partial, "series labels and values cached in chart parts are extracted; formatting is dropped"},
+		{FeatureComments, Supported, ""},
+	},
+}
+
+// supportedMajorVersion is the format major version the parsers understand,
+// for both HWP (5.x.x.x) and HWPX (version.xml major="5").
+const supportedMajorVersion = 5
+
+// Capabilities reports which features the parser supports for a document of
+// the given type ("hwp" or "hwpx", as in Info.Type) and format version (as in
+// Info.Version). Integrators can use it to tell end users what may be missing
+// from converted output.
+//
+// Unknown types and unsupported major versions report every feature as
+// Unsupported.
+func Capabilities(docType string, version string) []Capability {
+	table, ok := capabilityTable[docType]
+	if !ok {
+		table = capabilityTable["hwp"]
+		return unsupportedCapabilities(table, "unknown document type "+strconv.Quote(docType))
+	}
+
+	if major, ok := majorVersion(version); ok && major != supportedMajorVersion {
+		return unsupportedCapabilities(table, "format version "+version+" is not supported")
+	}
+
+	caps := make([]Capability, len(table))
+	copy(caps, table)
+	return caps
+}
+
+// Capabilities reports feature support for the described document.
+func (info *Info) Capabilities() []Capability {
+	return Capabilities(info.Type, info.Version)
+}
+
+func unsupportedCapabilities(table []Capability, note string) []Capability {
+	caps := make([]Capability, len(table))
+	for i, c := range table {
+		caps[i] = Capability{Feature: c.Feature, Support: Unsupported, Note: note}
+	}
+	return caps
+}
+
+// majorVersion parses the leading component of a dotted version string.
+func majorVersion(version string) (int, bool) {
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	return n, err == nil
+}
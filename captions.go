@@ -0,0 +1,41 @@
+package hwp
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hanpama/hwp/internal/transform"
+)
+
+// CaptionLists holds the table and figure captions of a document in
+// document order, as used for a "List of Tables" and a "List of Figures".
+// Captions include their numbers ("표 3. 예산 현황").
+type CaptionLists struct {
+	Tables  []string
+	Figures []string
+}
+
+// Captions scans a document and returns its table and figure captions.
+func Captions(file *os.File, opts ...Option) (*CaptionLists, error) {
+	cfg := newConfig(opts)
+
+	scanner, err := openScanner(file, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var lists transform.CaptionLists
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error reading content: %w", err)
+		}
+		lists.Add(node)
+	}
+
+	return &CaptionLists{Tables: lists.Tables, Figures: lists.Figures}, nil
+}
@@ -0,0 +1,39 @@
+package hwp
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hanpama/hwp/internal/hwpv5"
+)
+
+// ReplaceText writes a copy of an HWP or HWPX document to out with text
+// replaced, e.g. to fill in the placeholders of a template. oldnew holds
+// (old, new) pairs as for strings.NewReplacer; old texts are matched within
+// the text of each paragraph of the body, headers, footers, notes, table
+// cells and, for HWPX, master pages, even when split across runs of
+// different formatting, and the replacement takes the formatting of the
+// text where its match starts. The copy is in the format of the original.
+// It returns the number of replacements made. WithMaxStreamSize applies.
+//
+// Only the changed text is rewritten. In HWPX every other part of the
+// package is copied byte for byte, as are the sections and the XML around
+// the text. In HWP the changed paragraphs' records are rewritten and their
+// sections compressed again; every other stream is copied byte for byte
+// into a new compound file. Distribution documents cannot be edited.
+func ReplaceText(file *os.File, out io.Writer, oldnew []string, opts ...Option) (int, error) {
+	cfg := newConfig(opts)
+	if !isHWPX(file.Name()) {
+		reader, err := hwpv5.OpenReader(file, cfg.scan)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse HWP file: %w", err)
+		}
+		return reader.ReplaceText(out, oldnew)
+	}
+	reader, err := openHWPXPackage(file)
+	if err != nil {
+		return 0, err
+	}
+	return reader.ReplaceText(out, oldnew, cfg.scan.MaxStreamSize)
+}
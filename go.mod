@@ -2,6 +2,12 @@ module github.com/hanpama/hwp
 
 go 1.24.6
 
+require (
+	github.com/mattn/go-runewidth v0.0.19
+	github.com/richardlehane/mscfb v1.0.4
+	golang.org/x/text v0.22.0
+)
+
 require (
 	github.com/alexeyco/simpletable v1.0.0 // indirect
 	github.com/clipperhouse/uax29/v2 v2.2.0 // indirect
@@ -9,13 +15,10 @@ require (
 	github.com/jedib0t/go-pretty/v6 v6.6.8 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
-	github.com/mattn/go-runewidth v0.0.19 // indirect
 	github.com/olekukonko/errors v1.1.0 // indirect
 	github.com/olekukonko/ll v0.0.9 // indirect
 	github.com/olekukonko/tablewriter v1.1.0 // indirect
-	github.com/richardlehane/mscfb v1.0.4 // indirect
 	github.com/richardlehane/msoleps v1.0.1 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.30.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 )
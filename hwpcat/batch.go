@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// fileResult records the outcome of processing one input file.
+type fileResult struct {
+	File  string `json:"file"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// batchReport summarizes a multi-file run.
+type batchReport struct {
+	Files     []fileResult `json:"files"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Aborted   bool         `json:"aborted"`
+}
+
+// runBatch processes each file in order. With keepGoing unset it stops at the
+// first failure; otherwise every file is attempted.
+func runBatch(files []string, keepGoing bool, process func(string) error) *batchReport {
+	report := &batchReport{Files: make([]fileResult, 0, len(files))}
+
+	for i, name := range files {
+		result := fileResult{File: name, OK: true}
+		if err := process(name); err != nil {
+			result.OK = false
+			result.Error = err.Error()
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.Files = append(report.Files, result)
+
+		if !result.OK && !keepGoing {
+			report.Aborted = i < len(files)-1
+			break
+		}
+	}
+
+	return report
+}
+
+// printSummary writes a human-readable list of failures.
+func (r *batchReport) printSummary(w io.Writer) {
+	if r.Failed == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d of %d files failed:\n", r.Failed, len(r.Files))
+	for _, f := range r.Files {
+		if !f.OK {
+			fmt.Fprintf(w, "  %s: %s\n", f.File, f.Error)
+		}
+	}
+}
+
+// writeJSON writes the report to path, or to stderr when path is "-".
+func (r *batchReport) writeJSON(path string) error {
+	var w io.Writer = os.Stderr
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
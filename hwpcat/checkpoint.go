@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	hwpcat "github.com/hanpama/hwp"
+)
+
+// loadCheckpoint reads a checkpoint file. A missing file means starting from
+// the beginning.
+func loadCheckpoint(path string) (*hwpcat.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp hwpcat.Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// checkpointWriter saves the latest checkpoint to a file. Checkpoints are
+// reported after the preceding output has been written, so the file never
+// claims more progress than the output holds.
+type checkpointWriter struct {
+	path string
+	err  error
+}
+
+func (w *checkpointWriter) save(cp hwpcat.Checkpoint) {
+	if w.err != nil {
+		return
+	}
+	data, _ := json.Marshal(cp)
+	// Write and rename so that an interruption never leaves a partial file
+	tmp := w.path + ".tmp"
+	if w.err = os.WriteFile(tmp, data, 0o644); w.err == nil {
+		w.err = os.Rename(tmp, w.path)
+	}
+	if w.err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving checkpoint: %v\n", w.err)
+	}
+}
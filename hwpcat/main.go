@@ -3,30 +3,345 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	hwpcat "github.com/hanpama/hwp"
 )
 
 func main() {
+	profile := flag.String("profile", "", "option preset: faithful, search-index, llm-corpus, csv-data (other flags override it)")
+	to := flag.String("to", string(hwpcat.FormatText), "output format: text, jsonl, asciidoc, rst, pandoc-json, docbook, markdown, html, xlsx, csv, xliff, form-json, xfdf")
+	cellSep := flag.String("cell-sep", "\n", "separator between paragraphs inside a table cell")
+	splitParaBreak := flag.Bool("split-para-break", false, "start a new paragraph at each paragraph break character (HWP only)")
+	embedded := flag.Bool("embedded", false, "extract HWP documents embedded as OLE objects inline (HWP only)")
+	changes := flag.String("changes", string(hwpcat.ChangesFinal), "tracked changes view: final, original, markup (HWPX only)")
+	previewFallback := flag.Bool("preview-fallback", false, "read the rest of the document from its preview text when a section fails to parse, with a warning (HWPX only)")
+	pageNumber := flag.String("page-number", "", "placeholder for page numbers, e.g. \"[PAGE]\"; empty drops them")
+	hidden := flag.Bool("hidden", false, "include hidden comments")
+	breaks := flag.Bool("breaks", false, "report page and section breaks as nodes in JSONL output")
+	layoutLines := flag.Bool("layout-lines", false, "break paragraphs where their lines broke in the original layout (HWP only)")
+	strict := flag.Bool("strict", false, "fail on references to missing DocInfo items (HWP only)")
+	recoverFlag := flag.Bool("recover", false, "skip records and sections that fail to decode, with a warning, instead of failing (HWP only)")
+	linearTables := flag.Bool("linear-tables", false, "render tables as \"header: value\" lines instead of grids")
+	captionLists := flag.Bool("caption-lists", false, "append a list of tables and a list of figures built from captions")
+	textRuns := flag.Bool("text-runs", false, "give every paragraph runs covering all of its text, with link targets")
+	crlf := flag.Bool("crlf", false, "end text lines with CRLF")
+	collapseBlank := flag.Bool("collapse-blank", false, "collapse runs of blank lines in text output")
+	trim := flag.Bool("trim", false, "trim trailing whitespace from text lines")
+	nfc := flag.Bool("nfc", false, "normalize text output to Unicode NFC")
+	rawXML := flag.Bool("raw-xml", false, "print the section XML instead of parsed content (HWPX only)")
+	xmlIndent := flag.Bool("xml-indent", false, "pretty-print -raw-xml output")
+	xmlNS := flag.Bool("xml-ns", false, "rewrite -raw-xml output to the standard OWPML namespace prefixes")
+	maxStream := flag.Int64("max-stream-size", 0, "maximum decompressed size of a single stream in bytes (0 = unlimited)")
+	maxRecord := flag.Int64("max-record-size", 0, "maximum size of a single HWP record in bytes (0 = unlimited)")
+	maxCells := flag.Int64("max-table-cells", 0, "maximum rows times columns of a table (0 = unlimited)")
+	maxOutput := flag.Int64("max-output-size", 0, "maximum output size in bytes per file (0 = unlimited)")
+	info := flag.Bool("info", false, "print format, version, stream sizes and fonts instead of content")
+	title := flag.Bool("title", false, "print the inferred document title instead of content")
+	listStreams := flag.Bool("list-streams", false, "list the storages and streams of the file, or the parts of an HWPX package, instead of content")
+	dumpStream := flag.String("dump-stream", "", "write this stream as stored, or this HWPX package part, instead of content, e.g. \"DocOptions/_LinkDoc\"")
+	validate := flag.Bool("validate", false, "report structural violations instead of content; fails if there are any")
+	scripts := flag.Bool("scripts", false, "print the document's script (macro) code instead of content (HWP only)")
+	imagesDir := flag.String("extract-images", "", "write the document's pictures to this directory and print their paths instead of content")
+	attachmentsDir := flag.String("extract-attachments", "", "write the document's embedded OLE objects to this directory and print their paths instead of content (HWPX only)")
+	failFast := flag.Bool("fail-fast", false, "stop at the first file that fails (default)")
+	keepGoing := flag.Bool("keep-going", false, "continue after a file fails and summarize failures at the end")
+	checkpoint := flag.String("checkpoint", "", "resume from and save progress to this file; append output to the interrupted run's (one HWP file only)")
+	report := flag.String("report", "", "write a JSON batch report to this file (\"-\" for stderr)")
 	flag.Parse()
 
 	if flag.NArg() < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <hwp-file>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [-to format] [-info] [-keep-going] <hwp-file>...\n", os.Args[0])
 		os.Exit(1)
 	}
+	if *failFast && *keepGoing {
+		fmt.Fprintln(os.Stderr, "-fail-fast and -keep-going are mutually exclusive")
+		os.Exit(1)
+	}
+
+	flagOpts := []struct {
+		name string
+		opt  hwpcat.Option
+	}{
+		{"to", hwpcat.WithFormat(hwpcat.Format(*to))},
+		{"cell-sep", hwpcat.WithCellSeparator(*cellSep)},
+		{"split-para-break", hwpcat.WithSplitOnParaBreak(*splitParaBreak)},
+		{"max-stream-size", hwpcat.WithMaxStreamSize(*maxStream)},
+		{"max-record-size", hwpcat.WithMaxRecordSize(*maxRecord)},
+		{"max-table-cells", hwpcat.WithMaxTableCells(*maxCells)},
+		{"max-output-size", hwpcat.WithMaxOutputSize(*maxOutput)},
+		{"embedded", hwpcat.WithEmbeddedDocuments(*embedded)},
+		{"hidden", hwpcat.WithHiddenText(*hidden)},
+		{"breaks", hwpcat.WithBreaks(*breaks)},
+		{"layout-lines", hwpcat.WithLayoutLineBreaks(*layoutLines)},
+		{"strict", hwpcat.WithStrictReferences(*strict)},
+		{"recover", hwpcat.WithRecovery(*recoverFlag)},
+		{"preview-fallback", hwpcat.WithPreviewFallback(*previewFallback)},
+		{"page-number", hwpcat.WithPageNumbers(*pageNumber)},
+		{"changes", hwpcat.WithTrackChanges(hwpcat.ChangeView(*changes))},
+		{"linear-tables", hwpcat.WithTableLinearization(*linearTables)},
+		{"caption-lists", hwpcat.WithCaptionLists(*captionLists)},
+		{"text-runs", hwpcat.WithTextRuns(*textRuns)},
+		{"crlf", hwpcat.WithCRLF(*crlf)},
+		{"collapse-blank", hwpcat.WithCollapseBlankLines(*collapseBlank)},
+		{"trim", hwpcat.WithTrimTrailingSpace(*trim)},
+		{"nfc", hwpcat.WithNFC(*nfc)},
+		{"raw-xml", hwpcat.WithRawXML(*rawXML)},
+		{"xml-indent", hwpcat.WithXMLIndent(*xmlIndent)},
+		{"xml-ns", hwpcat.WithXMLNormalizedNamespaces(*xmlNS)},
+	}
 
-	filename := flag.Arg(0)
+	// With a profile, only flags given explicitly override its settings
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var opts []hwpcat.Option
+	if *profile != "" {
+		opts = append(opts, hwpcat.WithProfile(hwpcat.Profile(*profile)))
+	}
+	for _, fo := range flagOpts {
+		if *profile == "" || set[fo.name] {
+			opts = append(opts, fo.opt)
+		}
+	}
 
+	if *checkpoint != "" {
+		if flag.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "-checkpoint requires a single input file")
+			os.Exit(1)
+		}
+		cp, err := loadCheckpoint(*checkpoint)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading checkpoint: %v\n", err)
+			os.Exit(1)
+		}
+		if cp != nil {
+			opts = append(opts, hwpcat.WithResume(*cp))
+		}
+		writer := &checkpointWriter{path: *checkpoint}
+		opts = append(opts, hwpcat.WithCheckpoints(writer.save))
+	}
+
+	result := runBatch(flag.Args(), *keepGoing, func(filename string) error {
+		return processFile(filename, outputMode{
+			info:           *info,
+			title:          *title,
+			scripts:        *scripts,
+			validate:       *validate,
+			listStreams:    *listStreams,
+			dumpStream:     *dumpStream,
+			imagesDir:      *imagesDir,
+			attachmentsDir: *attachmentsDir,
+		}, opts)
+	})
+	if *checkpoint != "" && result.Failed == 0 {
+		// Progress is only kept for an interrupted run
+		os.Remove(*checkpoint)
+	}
+
+	if result.Failed > 0 {
+		if *keepGoing {
+			result.printSummary(os.Stderr)
+		} else {
+			last := result.Files[len(result.Files)-1]
+			fmt.Fprintf(os.Stderr, "Error reading file %s: %s\n", last.File, last.Error)
+		}
+	}
+	if *report != "" {
+		if err := result.writeJSON(*report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// outputMode selects what processFile prints instead of the content.
+type outputMode struct {
+	info, title, scripts, validate, listStreams bool
+	dumpStream, imagesDir, attachmentsDir       string
+}
+
+// isHWPX reports whether a file is an HWPX package (or template) by its
+// extension, as hwp.Read detects it.
+func isHWPX(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".hwpx" || ext == ".hwtx"
+}
+
+func processFile(filename string, mode outputMode, opts []hwpcat.Option) error {
 	file, err := os.Open(filename)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 	defer file.Close()
 
-	if err := hwpcat.Read(file, os.Stdout); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
-		os.Exit(1)
+	opts = append(opts[:len(opts):len(opts)], hwpcat.WithWarnings(func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", filename, err)
+	}))
+
+	if mode.info {
+		return printInfo(file, os.Stdout)
+	}
+	if mode.title {
+		t, err := hwpcat.InferTitle(file, opts...)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Println(t)
+		return err
+	}
+	if mode.scripts {
+		s, err := hwpcat.ReadScripts(file)
+		if err != nil {
+			return err
+		}
+		if code := s.Code(); code != "" {
+			_, err = fmt.Println(code)
+		}
+		return err
+	}
+	if mode.validate {
+		violations, err := hwpcat.Validate(file, opts...)
+		for _, v := range violations {
+			fmt.Println(v)
+		}
+		if err == nil && len(violations) > 0 {
+			err = fmt.Errorf("%d structural violations", len(violations))
+		}
+		return err
+	}
+	if mode.listStreams && isHWPX(filename) {
+		parts, err := hwpcat.ListParts(file)
+		if err != nil {
+			return err
+		}
+		for _, p := range parts {
+			fmt.Printf("%s\t%d\t%s\t%s\n", p.Name, p.Size, p.Kind, p.MediaType)
+		}
+		return nil
+	}
+	if mode.listStreams {
+		streams, err := hwpcat.ListStreams(file)
+		if err != nil {
+			return err
+		}
+		for _, s := range streams {
+			if s.Storage {
+				fmt.Println(s.Name + "/")
+				continue
+			}
+			fmt.Printf("%s\t%d\n", s.Name, s.Size)
+		}
+		return nil
+	}
+	if mode.dumpStream != "" && isHWPX(filename) {
+		part, err := hwpcat.OpenPart(file, mode.dumpStream)
+		if err != nil {
+			return err
+		}
+		defer part.Close()
+		_, err = io.Copy(os.Stdout, part)
+		return err
+	}
+	if mode.dumpStream != "" {
+		stream, err := hwpcat.OpenRawStream(file, mode.dumpStream)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(os.Stdout, stream)
+		return err
+	}
+	if mode.imagesDir != "" {
+		paths, err := hwpcat.ExtractImages(file, mode.imagesDir, opts...)
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		return err
+	}
+	if mode.attachmentsDir != "" {
+		paths, err := hwpcat.ExtractAttachments(file, mode.attachmentsDir, opts...)
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		return err
+	}
+	return hwpcat.Read(file, os.Stdout, opts...)
+}
+
+func printInfo(file *os.File, out io.Writer) error {
+	info, err := hwpcat.ReadInfo(file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Type:    %s\n", info.Type)
+	fmt.Fprintf(out, "Version: %s\n", info.Version)
+	fmt.Fprintf(out, "Macros:  %v\n", info.Macros)
+	fmt.Fprintf(out, "Signed:  %v\n\n", info.Signature != nil)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STREAM\tSIZE\tDECOMPRESSED\tRATIO")
+	for _, s := range info.Streams {
+		decompressed := "?"
+		if s.DecompressedSize >= 0 {
+			decompressed = fmt.Sprint(s.DecompressedSize)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.2f\n", s.Name, s.Size, decompressed, s.CompressionRatio())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(info.Fonts) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(tw, "FONT\tLANGUAGE\tSUBSTITUTE\tEMBEDDED")
+		for _, f := range info.Fonts {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%v\n", f.Name, f.Language, f.Substitute, f.Embedded)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(info.Pages) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(tw, "SECTION\tPAPER (PT)\tORIENTATION\tMARGINS L/R/T/B (PT)\tCOLUMNS")
+		for i, p := range info.Pages {
+			orientation := "portrait"
+			if p.Landscape {
+				orientation = "landscape"
+			}
+			fmt.Fprintf(tw, "%d\t%gx%g\t%s\t%g/%g/%g/%g\t%d\n", i, p.Width, p.Height, orientation,
+				p.MarginLeft, p.MarginRight, p.MarginTop, p.MarginBottom, p.Columns)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if info.Signature != nil && len(info.Signature.Signers) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(tw, "SIGNER\tISSUER\tVALID UNTIL")
+		for _, s := range info.Signature.Signers {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Subject, s.Issuer, s.NotAfter.Format(time.DateOnly))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(tw, "FEATURE\tSUPPORT\tNOTE")
+	for _, c := range info.Capabilities() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Feature, c.Support, c.Note)
 	}
+	return tw.Flush()
 }
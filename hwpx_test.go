@@ -0,0 +1,968 @@
+package hwp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+type hwpxPart struct{ name, data string }
+
+// hwpxPackage builds an HWPX file with the given parts, and a mimetype and
+// version.xml unless given.
+func hwpxPackage(t *testing.T, parts ...hwpxPart) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	defaults := []hwpxPart{
+		{"mimetype", "application/hwp+zip"},
+		{"version.xml", `<HCFVersion major="5" minor="1" micro="0" buildNumber="1"/>`},
+	}
+	defaults = slices.DeleteFunc(defaults, func(d hwpxPart) bool {
+		return slices.ContainsFunc(parts, func(p hwpxPart) bool { return p.name == d.name })
+	})
+	parts = append(defaults, parts...)
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(part.data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// hwpxFile writes an HWPX file to a temporary file of the given name and
+// opens it, for functions that take a file.
+func hwpxFile(t *testing.T, name string, in *bytes.Reader) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	data := make([]byte, in.Size())
+	in.Read(data)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+// readHWPXJSONL returns the JSONL output for an HWPX file.
+func readHWPXJSONL(t *testing.T, in *bytes.Reader) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := ReadHWPX(in, in.Size(), &out, WithFormat(FormatJSONL)); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestHWPXSections(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>첫째</t></run></p></sec>`},
+		hwpxPart{"Contents/section1.xml", `<sec/>`},
+		hwpxPart{"Contents/section2.xml", `<sec><p><run><t>셋째</t></run></p></sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"첫째"}` + "\n" +
+		`{"type":"paragraph","id":"s2.p0","text":"셋째"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXBreaks(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p pageBreak="1"><run><t>첫째</t></run></p>` +
+			`<p pageBreak="1"><run><t>둘째 쪽</t></run></p></sec>`},
+		hwpxPart{"Contents/section1.xml", `<sec><p pageBreak="1"><run><t>둘째 구역</t></run></p></sec>`},
+	)
+	var out bytes.Buffer
+	if err := ReadHWPX(in, in.Size(), &out, WithFormat(FormatJSONL), WithBreaks(true)); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"paragraph","id":"s0.p0","text":"첫째"}` + "\n" +
+		`{"type":"page-break","id":"s0.p1.pb"}` + "\n" +
+		`{"type":"paragraph","id":"s0.p1","text":"둘째 쪽"}` + "\n" +
+		`{"type":"section-break","id":"s1","section":1}` + "\n" +
+		`{"type":"paragraph","id":"s1.p0","text":"둘째 구역"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+	in.Seek(0, io.SeekStart)
+	if got := readHWPXJSONL(t, in); strings.Contains(got, "break") {
+		t.Errorf("output = %s, want no breaks by default", got)
+	}
+}
+
+func TestHWPXSpine(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"META-INF/container.xml", `<container><rootfiles>` +
+			`<rootfile full-path="Preview/PrvText.txt" media-type="text/plain"/>` +
+			`<rootfile full-path="Package/doc.hpf" media-type="application/hwpml-package+xml"/>` +
+			`</rootfiles></container>`},
+		// Hangul refers to parts from the package root, other OPF
+		// packages from the package file's directory
+		hwpxPart{"Package/doc.hpf", `<package><manifest>` +
+			`<item id="header" href="Contents/header.xml"/>` +
+			`<item id="intro" href="../Body/intro.xml"/>` +
+			`<item id="main" href="Contents/section0.xml"/>` +
+			`</manifest><spine>` +
+			`<itemref idref="header"/><itemref idref="intro"/><itemref idref="main"/>` +
+			`</spine></package>`},
+		hwpxPart{"Contents/header.xml", `<head/>`},
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>본문</t></run></p></sec>`},
+		hwpxPart{"Body/intro.xml", `<sec><p><run><t>머리</t></run></p></sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"머리"}` + "\n" +
+		`{"type":"paragraph","id":"s1.p0","text":"본문"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXHeaderProperties(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/header.xml", `<head><refList>` +
+			`<paraProperties>` +
+			`<paraPr id="0"><align horizontal="JUSTIFY"/></paraPr>` +
+			`<paraPr id="1"><align horizontal="CENTER"/><switch><case><margin><left value="2"/></margin></case>` +
+			`<default><margin><left value="1000"/><prev value="500"/></margin><lineSpacing type="PERCENT" value="160"/></default></switch></paraPr>` +
+			`<paraPr id="2"><align horizontal="JUSTIFY"/><heading type="OUTLINE" idRef="1" level="1"/></paraPr>` +
+			`<paraPr id="3"><align horizontal="JUSTIFY"/><heading type="NUMBER" idRef="1" level="0"/></paraPr>` +
+			`</paraProperties><styles>` +
+			`<style id="0" type="PARA" name="바탕글" engName="Normal" paraPrIDRef="0"/>` +
+			`<style id="2" type="PARA" name="개요 1" engName="Outline 1" paraPrIDRef="1"/>` +
+			`</styles></refList></head>`},
+		hwpxPart{"Contents/section0.xml", `<sec>` +
+			`<p paraPrIDRef="1" styleIDRef="2"><run><t>제목</t></run></p>` +
+			`<p paraPrIDRef="0" styleIDRef="0"><run><t>본문</t></run></p>` +
+			`<p paraPrIDRef="2" styleIDRef="0"><run><t>소제목</t></run></p>` +
+			`<p paraPrIDRef="3" styleIDRef="0"><run><t>번호 문단</t></run></p>` +
+			`</sec>`},
+	)
+	want := `{"type":"heading","id":"s0.p0","text":"제목","layout":{"align":"center","marginLeft":10,"spaceBefore":5,"lineSpacing":160},"level":1}` + "\n" +
+		`{"type":"paragraph","id":"s0.p1","text":"본문"}` + "\n" +
+		`{"type":"heading","id":"s0.p2","text":"소제목","level":2}` + "\n" +
+		`{"type":"paragraph","id":"s0.p3","text":"번호 문단"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXImages(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n"
+	parts := []hwpxPart{
+		{"Contents/content.hpf", `<package><manifest>` +
+			`<item id="image1" href="BinData/image1.PNG" media-type="image/png"/>` +
+			`</manifest></package>`},
+		{"BinData/image1.PNG", png},
+		{"BinData/image2.jpg", "jpeg"},
+		{"Contents/section0.xml", `<sec><p><run>` +
+			`<pic><img binaryItemIDRef="image1"/></pic>` +
+			`<pic><img binaryItemIDRef="image2"/><caption><subList><p><run><t>사진</t></run></p></subList></caption></pic>` +
+			`<pic><img binaryItemIDRef="image1"/></pic>` +
+			`</run></p></sec>`},
+	}
+
+	want := `{"type":"image","id":"s0.p0.i0","format":"png","filename":"image1.PNG"}` + "\n" +
+		`{"type":"image","id":"s0.p0.i1","caption":"사진","format":"jpg","filename":"image2.jpg"}` + "\n" +
+		`{"type":"image","id":"s0.p0.i2","format":"png","filename":"image1.PNG"}` + "\n"
+	if got := readHWPXJSONL(t, hwpxPackage(t, parts...)); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+
+	// ExtractImages takes a file
+	path := filepath.Join(t.TempDir(), "images.hwpx")
+	in := hwpxPackage(t, parts...)
+	data := make([]byte, in.Size())
+	in.Read(data)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	dir := t.TempDir()
+	paths, err := ExtractImages(file, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "image1.PNG" || filepath.Base(paths[1]) != "image2.jpg" {
+		t.Fatalf("paths = %q, want image1.PNG and image2.jpg", paths)
+	}
+	if got, _ := os.ReadFile(paths[0]); string(got) != png {
+		t.Errorf("image1.PNG = %q, want %q", got, png)
+	}
+}
+
+func TestHWPXNotes(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>본문</t>` +
+			`<ctrl><footNote number="1"><subList><p><run><ctrl><autoNum num="1" numType="FOOTNOTE"/></ctrl><t> 출처</t></run></p></subList></footNote></ctrl>` +
+			`<t>이어서</t>` +
+			`<ctrl><footNote><subList><p><run><t>둘째 각주</t></run></p></subList></footNote></ctrl>` +
+			`<ctrl><endNote number="1"><subList><p><run><t>미주</t></run></p></subList></endNote></ctrl>` +
+			`</run></p></sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"본문이어서"}` + "\n" +
+		`{"type":"footnote","id":"s0.p0.n0","number":1,"text":"출처","anchor":"s0.p0","paragraphs":[{"text":" 출처"}]}` + "\n" +
+		`{"type":"footnote","id":"s0.p0.n1","number":2,"text":"둘째 각주","anchor":"s0.p0","paragraphs":[{"text":"둘째 각주"}]}` + "\n" +
+		`{"type":"footnote","id":"s0.p0.n2","number":1,"text":"미주","endnote":true,"anchor":"s0.p0","paragraphs":[{"text":"미주"}]}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXComments(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>예산은 </t>` +
+			`<ctrl><fieldBegin id="9" type="MEMO"><parameters>` +
+			`<stringParam name="Author">김검토</stringParam><stringParam name="CreateDateTime">2024-03-02 10:30:00</stringParam>` +
+			`</parameters><subList><p><run><t>확인 필요</t></run></p></subList></fieldBegin></ctrl>` +
+			`<t>3억 원</t><ctrl><fieldEnd beginIDRef="9"/></ctrl><t>이다</t></run></p></sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"예산은 3억 원이다"}` + "\n" +
+		`{"type":"comment","id":"s0.p0.a0","author":"김검토","date":"2024-03-02 10:30:00","text":"확인 필요","anchor":"s0.p0","offset":10,"quote":"3억 원"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXHeadersFooters(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/content.hpf", `<package><manifest>` +
+			`<item id="masterpage0" href="Contents/masterpage0.xml"/>` +
+			`<item id="section0" href="Contents/section0.xml"/>` +
+			`</manifest><spine><itemref idref="section0"/></spine></package>`},
+		hwpxPart{"Contents/masterpage0.xml", `<masterPage id="masterpage0" type="BOTH"><subList>` +
+			`<p><run><t>대외비</t></run></p></subList></masterPage>`},
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><secPr><masterPage idRef="masterpage0"/></secPr>` +
+			`<ctrl><header applyPageType="BOTH"><subList><p><run><t>보고서</t></run></p></subList></header></ctrl>` +
+			`<ctrl><footer applyPageType="BOTH"><subList><p><run><t>- </t><ctrl><autoNum num="1" numType="PAGE"/></ctrl><t> -</t></run></p></subList></footer></ctrl>` +
+			`<t>본문</t></run></p></sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"본문"}` + "\n" +
+		`{"type":"paragraph","id":"s0.p0.mp0","text":"대외비","floating":true}` + "\n" +
+		`{"type":"paragraph","id":"s0.p0.hf0","text":"보고서","floating":true}` + "\n" +
+		`{"type":"paragraph","id":"s0.p0.hf1","text":"-  -","floating":true}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXAutoNumbers(t *testing.T) {
+	section := `<sec><p><run><ctrl><pageNum pos="BOTTOM_CENTER" formatType="DIGIT" sideChar="-"/></ctrl>` +
+		`<ctrl><pageNum pos="NONE" formatType="DIGIT" sideChar=""/></ctrl><t>쪽 </t>` +
+		`<ctrl><autoNum num="3" numType="PAGE"><autoNumFormat type="DIGIT" prefixChar="(" suffixChar=")"/></autoNum></ctrl>` +
+		`<ctrl><footer applyPageType="BOTH"><subList><p><run><ctrl><autoNum num="1" numType="PAGE"/></ctrl></run></p></subList></footer></ctrl>` +
+		`</run></p>` +
+		`<p><run><t>표 </t><ctrl><autoNum num="3" numType="TABLE"><autoNumFormat type="CIRCLED_DIGIT"/></autoNum></ctrl>` +
+		`<t>, 그림 </t><ctrl><autoNum num="4" numType="PICTURE"><autoNumFormat type="ROMAN_SMALL" prefixChar="[" suffixChar="]"/></autoNum></ctrl>` +
+		`</run></p></sec>`
+	for _, tc := range []struct{ placeholder, want string }{
+		{"", `{"type":"paragraph","id":"s0.p0","text":"쪽 "}` + "\n" +
+			`{"type":"paragraph","id":"s0.p1","text":"표 ③, 그림 [iv]"}` + "\n"},
+		{"[PAGE]", `{"type":"paragraph","id":"s0.p0","text":"-[PAGE]-쪽 ([PAGE])"}` + "\n" +
+			`{"type":"paragraph","id":"s0.p0.hf0","text":"[PAGE]","floating":true}` + "\n" +
+			`{"type":"paragraph","id":"s0.p1","text":"표 ③, 그림 [iv]"}` + "\n"},
+	} {
+		in := hwpxPackage(t, hwpxPart{"Contents/section0.xml", section})
+		var out bytes.Buffer
+		if err := ReadHWPX(in, in.Size(), &out, WithFormat(FormatJSONL), WithPageNumbers(tc.placeholder)); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != tc.want {
+			t.Errorf("placeholder %q: output = %s, want %s", tc.placeholder, got, tc.want)
+		}
+	}
+}
+
+func TestHWPXShapes(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run>` +
+			`<rect><drawText><subList><p><run><t>글상자</t></run></p></subList></drawText></rect>` +
+			`<ellipse/>` +
+			`<container><line/><textart text="글맵시"/></container>` +
+			`<line><caption><subList><p><run><t>화살표</t></run></p></subList></caption></line>` +
+			`</run></p></sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0.b0","text":"글상자","floating":true}` + "\n" +
+		`{"type":"image","id":"s0.p0.i0"}` + "\n" +
+		`{"type":"paragraph","id":"s0.p0.b1","text":"글맵시","floating":true}` + "\n" +
+		`{"type":"image","id":"s0.p0.i1","caption":"화살표"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXPictureProperties(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run>` +
+			`<pic><sz width="14173" height="7086"/><pos treatAsChar="1" vertRelTo="PARA"/><orgSz width="28346" height="14173"/>` +
+			`<shapeComment> 회사 로고 </shapeComment></pic>` +
+			`<rect><sz width="1000" height="500"/><pos treatAsChar="0" vertRelTo="PAPER"/><shapeComment>도형</shapeComment></rect>` +
+			`</run></p></sec>`},
+	)
+	want := `{"type":"image","id":"s0.p0.i0","width":141.73,"height":70.86,"originalWidth":283.46,"originalHeight":141.73,"alt":"회사 로고","anchor":"inline"}` + "\n" +
+		`{"type":"image","id":"s0.p0.i1","width":10,"height":5,"alt":"도형","anchor":"paper"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXChart(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Chart/chart1.xml", `<c:chartSpace xmlns:c="c" xmlns:a="a"><c:chart>` +
+			`<c:title><c:tx><c:rich><a:p><a:r><a:t>매출</a:t></a:r></a:p></c:rich></c:tx></c:title>` +
+			`<c:plotArea><c:layout/><c:barChart><c:ser>` +
+			`<c:tx><c:strRef><c:strCache><c:ptCount val="1"/><c:pt idx="0"><c:v>2024</c:v></c:pt></c:strCache></c:strRef></c:tx>` +
+			`<c:cat><c:strRef><c:strCache><c:ptCount val="2"/>` +
+			`<c:pt idx="0"><c:v>1분기</c:v></c:pt><c:pt idx="1"><c:v>2분기</c:v></c:pt></c:strCache></c:strRef></c:cat>` +
+			`<c:val><c:numRef><c:numCache><c:ptCount val="2"/>` +
+			`<c:pt idx="0"><c:v>10</c:v></c:pt><c:pt idx="1"><c:v>12.5</c:v></c:pt></c:numCache></c:numRef></c:val>` +
+			`</c:ser></c:barChart><c:catAx/></c:plotArea></c:chart></c:chartSpace>`},
+		hwpxPart{"Contents/section0.xml", `<sec><p><run>` +
+			`<chart chartIDRef="Chart/chart1.xml"/><chart chartIDRef="Chart/chart2.xml"/>` +
+			`</run></p></sec>`},
+	)
+	want := `{"type":"chart","id":"s0.p0.c0","kind":"bar","title":"매출","categories":["1분기","2분기"],"series":[{"name":"2024","values":[10,12.5]}]}` + "\n" +
+		`{"type":"image","id":"s0.p0.i0"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+// compoundFile builds an OLE compound file whose root storage holds a
+// CompObj stream naming progID, as embedded OLE objects do. The stream is
+// padded to the mini stream cutoff so that it lives in regular sectors.
+func compoundFile(progID string) []byte {
+	const sector, endOfChain, free, noStream = 512, 0xFFFFFFFE, 0xFFFFFFFF, 0xFFFFFFFF
+	le := binary.LittleEndian
+	file := make([]byte, sector*11)
+
+	header := file[:sector]
+	copy(header, "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+	le.PutUint16(header[24:], 0x3E)
+	le.PutUint16(header[26:], 3)
+	le.PutUint16(header[28:], 0xFFFE)
+	le.PutUint16(header[30:], 9)
+	le.PutUint16(header[32:], 6)
+	le.PutUint32(header[44:], 1) // one FAT sector, sector 0
+	le.PutUint32(header[48:], 1) // directory in sector 1
+	le.PutUint32(header[56:], 4096)
+	le.PutUint32(header[60:], endOfChain)
+	le.PutUint32(header[68:], endOfChain)
+	for i := 76; i < sector; i += 4 {
+		le.PutUint32(header[i:], free)
+	}
+	le.PutUint32(header[76:], 0)
+
+	fat := file[sector : 2*sector]
+	for i := 0; i < sector; i += 4 {
+		le.PutUint32(fat[i:], free)
+	}
+	le.PutUint32(fat[0:], 0xFFFFFFFD)
+	le.PutUint32(fat[4:], endOfChain)
+	for i := 2; i < 9; i++ {
+		le.PutUint32(fat[i*4:], uint32(i+1))
+	}
+	le.PutUint32(fat[9*4:], endOfChain)
+
+	dir := file[2*sector : 3*sector]
+	entry := func(i int, name string, kind byte, child, start, size uint32) {
+		e := dir[i*128 : (i+1)*128]
+		units := utf16.Encode([]rune(name))
+		for j, u := range units {
+			le.PutUint16(e[j*2:], u)
+		}
+		le.PutUint16(e[64:], uint16(len(units)+1)*2)
+		e[66], e[67] = kind, 1
+		le.PutUint32(e[68:], noStream)
+		le.PutUint32(e[72:], noStream)
+		le.PutUint32(e[76:], child)
+		le.PutUint32(e[116:], start)
+		le.PutUint32(e[120:], size)
+	}
+	entry(0, "Root Entry", 5, 1, endOfChain, 0)
+	entry(1, "\x01CompObj", 2, noStream, 2, 4096)
+	for i := 2; i < 4; i++ {
+		entry(i, "", 0, noStream, 0, 0)
+	}
+
+	stream := file[3*sector:]
+	b := stream[28:]
+	for _, s := range []string{"Microsoft Excel Worksheet\x00", "", progID + "\x00"} {
+		le.PutUint32(b, uint32(len(s)))
+		b = b[4+copy(b[4:], s):]
+	}
+	return file
+}
+
+func TestHWPXOLEObjects(t *testing.T) {
+	sheet := string(compoundFile("Excel.Sheet.12"))
+	parts := []hwpxPart{
+		{"BinData/ole1.ole", sheet},
+		{"BinData/ole2.ole", "not a compound file"},
+		{"BinData/image1.png", "png"},
+		{"Contents/section0.xml", `<sec><p><run>` +
+			`<ole binaryItemIDRef="ole1"><sz width="2000" height="1000"/></ole>` +
+			`<ole binaryItemIDRef="ole2"/><pic><img binaryItemIDRef="image1"/></pic>` +
+			`</run></p></sec>`},
+	}
+
+	want := `{"type":"image","id":"s0.p0.i0","format":"ole","filename":"ole1.ole","class":"Excel.Sheet.12","width":20,"height":10}` + "\n" +
+		`{"type":"image","id":"s0.p0.i1","format":"ole","filename":"ole2.ole"}` + "\n" +
+		`{"type":"image","id":"s0.p0.i2","format":"png","filename":"image1.png"}` + "\n"
+	if got := readHWPXJSONL(t, hwpxPackage(t, parts...)); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "ole.hwpx")
+	in := hwpxPackage(t, parts...)
+	data := make([]byte, in.Size())
+	in.Read(data)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	paths, err := ExtractAttachments(file, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "ole1.ole" || filepath.Base(paths[1]) != "ole2.ole" {
+		t.Fatalf("attachments = %q, want ole1.ole and ole2.ole", paths)
+	}
+	if got, _ := os.ReadFile(paths[0]); string(got) != sheet {
+		t.Errorf("ole1.ole differs from the stored object")
+	}
+
+	paths, err = ExtractImages(file, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || filepath.Base(paths[0]) != "image1.png" {
+		t.Errorf("images = %q, want image1.png", paths)
+	}
+}
+
+func TestHWPXPageLayout(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><secPr>` +
+			`<pagePr landscape="WIDELY" width="59528" height="84186">` +
+			`<margin header="4252" footer="4252" gutter="0" left="8504" right="8504" top="5668" bottom="4252"/></pagePr>` +
+			`</secPr><ctrl><colPr colCount="2" sameGap="2268"/></ctrl></run></p>` +
+			`<p><run><ctrl><colPr colCount="3"/></ctrl></run></p></sec>`},
+		hwpxPart{"Contents/section1.xml", `<sec><p><run><secPr>` +
+			`<pagePr landscape="NARROWLY" width="59528" height="84186"><margin left="1000"/></pagePr>` +
+			`</secPr></run></p></sec>`},
+	)
+	info, err := readHWPXInfo(in, in.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PageLayout{
+		{Width: 595.28, Height: 841.86, MarginLeft: 85.04, MarginRight: 85.04, MarginTop: 56.68, MarginBottom: 42.52,
+			MarginHeader: 42.52, MarginFooter: 42.52, Columns: 2, ColumnGap: 22.68},
+		{Width: 595.28, Height: 841.86, Landscape: true, MarginLeft: 10, Columns: 1},
+	}
+	if !reflect.DeepEqual(info.Pages, want) {
+		t.Errorf("pages = %+v, want %+v", info.Pages, want)
+	}
+}
+
+func TestHWPXSettings(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>본문</t></run></p></sec>`},
+		hwpxPart{"settings.xml", `<ha:HWPApplicationSetting xmlns:ha="ha" xmlns:config="config">` +
+			`<ha:CaretPosition listIDRef="0" paraIDRef="3" pos="16"/>` +
+			`<config:config-item-set name="PrintInfo">` +
+			`<config:config-item name="PrintAutoFootNote" type="boolean">false</config:config-item>` +
+			`<config:config-item name="ZoomX" type="short"> 100 </config:config-item>` +
+			`</config:config-item-set></ha:HWPApplicationSetting>`},
+	)
+	info, err := readHWPXInfo(in, in.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Settings{
+		Caret:  CaretPosition{ListID: 0, ParagraphID: 3, Pos: 16},
+		Config: map[string]map[string]string{"PrintInfo": {"PrintAutoFootNote": "false", "ZoomX": "100"}},
+	}
+	if !reflect.DeepEqual(info.Settings, want) {
+		t.Errorf("settings = %+v, want %+v", info.Settings, want)
+	}
+}
+
+func TestHWPXSwitch(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<hs:sec xmlns:hs="http://www.hancom.co.kr/hwpml/2011/section" xmlns:hp="http://www.hancom.co.kr/hwpml/2011/paragraph">` +
+			`<hp:switch>` +
+			`<hp:case hp:required-namespace="http://www.hancom.co.kr/hwpml/2016/HwpUnitChar"><hp:p><hp:run><hp:t>새 단위</hp:t></hp:run></hp:p></hp:case>` +
+			`<hp:case hp:required-namespace="http://www.hancom.co.kr/hwpml/2011/paragraph"><hp:p><hp:run><hp:t>선택</hp:t>` +
+			`<hp:switch><hp:default><hp:t>, 중첩</hp:t></hp:default></hp:switch></hp:run></hp:p></hp:case>` +
+			`<hp:default><hp:p><hp:run><hp:t>기본</hp:t></hp:run></hp:p></hp:default>` +
+			`</hp:switch>` +
+			`<hp:switch><hp:case hp:required-namespace="urn:unknown"><hp:p><hp:run><hp:t>미지원</hp:t></hp:run></hp:p></hp:case>` +
+			`<hp:default><hp:p><hp:run><hp:t>대체</hp:t></hp:run></hp:p></hp:default></hp:switch>` +
+			`</hs:sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"선택, 중첩"}` + "\n" +
+		`{"type":"paragraph","id":"s0.p1","text":"대체"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXRunFormatting(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/header.xml", `<head><refList><charProperties>` +
+			`<charPr id="0" height="1000" textColor="#000000"/>` +
+			`<charPr id="1" height="1000" textColor="#000000"><bold/></charPr>` +
+			`<charPr id="2" height="1200" textColor="#FF0000"><underline type="BOTTOM" shape="SOLID"/></charPr>` +
+			`</charProperties></refList></head>`},
+		hwpxPart{"Contents/section0.xml", `<sec>` +
+			`<p><run charPrIDRef="0"><t>보통 </t></run><run charPrIDRef="1"><t>굵게</t></run>` +
+			`<run charPrIDRef="1"><t>!</t></run><run charPrIDRef="2"><t> 밑줄</t></run></p>` +
+			`<p><run charPrIDRef="0"><t>강조 </t></run><run charPrIDRef="0"><t>없음</t></run></p>` +
+			`</sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"보통 굵게! 밑줄","runs":[` +
+		`{"offset":0,"text":"보통 ","size":10},` +
+		`{"offset":7,"text":"굵게!","bold":true,"size":10},` +
+		`{"offset":14,"text":" 밑줄","underline":true,"size":12,"color":"#ff0000"}]}` + "\n" +
+		`{"type":"paragraph","id":"s0.p1","text":"강조 없음"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXPreviewFallback(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>첫째</t></run></p></sec>`},
+		hwpxPart{"Contents/section1.xml", `<sec><p><run><t>깨진</t></run>`},
+		hwpxPart{"Preview/PrvText.txt", "첫째\r\n\r\n<셋째>\r\n"},
+	)
+	if err := ReadHWPX(in, in.Size(), &bytes.Buffer{}); err == nil {
+		t.Error("broken section read without the fallback")
+	}
+
+	var out bytes.Buffer
+	var warnings []error
+	err := ReadHWPX(in, in.Size(), &out, WithFormat(FormatJSONL), WithPreviewFallback(true),
+		WithWarnings(func(err error) { warnings = append(warnings, err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"paragraph","id":"s0.p0","text":"첫째"}` + "\n" +
+		`{"type":"paragraph","id":"preview.p0","text":"첫째"}` + "\n" +
+		`{"type":"paragraph","id":"preview.p1","text":"<셋째>"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one", warnings)
+	}
+}
+
+func TestHWPXVersion(t *testing.T) {
+	section := hwpxPart{"Contents/section0.xml", `<sec><p><run><t>본문</t></run></p></sec>`}
+
+	in := hwpxPackage(t, hwpxPart{"version.xml", `<HCFVersion major="6" minor="0" micro="0" buildNumber="0"/>`}, section)
+	err := ReadHWPX(in, in.Size(), &bytes.Buffer{})
+	var versionErr *VersionError
+	if !errors.Is(err, ErrUnsupportedVersion) || !errors.As(err, &versionErr) || versionErr.Version.Major != 6 {
+		t.Errorf("error = %v, want a VersionError for 6.0.0.0", err)
+	}
+
+	in = hwpxPackage(t, hwpxPart{"version.xml", `<HCFVersion major="5" minor="2" micro="0" buildNumber="0"/>`}, section)
+	var out bytes.Buffer
+	var warnings []error
+	if err := ReadHWPX(in, in.Size(), &out, WithWarnings(func(err error) { warnings = append(warnings, err) })); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "본문\n" || len(warnings) != 1 {
+		t.Errorf("output = %q, warnings = %v, want the text and one warning", out.String(), warnings)
+	}
+}
+
+func TestHWPXEncryption(t *testing.T) {
+	section := hwpxPart{"Contents/section0.xml", `<sec><p><run><t>본문</t></run></p></sec>`}
+	for _, tc := range []struct {
+		name string
+		part hwpxPart
+		want EncryptionError
+	}{
+		{"password", hwpxPart{"META-INF/manifest.xml", `<odf:manifest xmlns:odf="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0">` +
+			`<odf:file-entry odf:full-path="Contents/section0.xml" odf:media-type="application/xml">` +
+			`<odf:encryption-data odf:checksum-type="SHA1"><odf:algorithm odf:algorithm-name="AES256-CBC"/></odf:encryption-data>` +
+			`</odf:file-entry><odf:file-entry odf:full-path="version.xml"/></odf:manifest>`},
+			EncryptionError{Parts: []string{"Contents/section0.xml"}, Algorithm: "AES256-CBC"}},
+		{"drm", hwpxPart{"META-INF/drm.xml", `<drm/>`}, EncryptionError{Parts: []string{"META-INF/drm.xml"}, DRM: true}},
+	} {
+		in := hwpxPackage(t, section, tc.part)
+		err := ReadHWPX(in, in.Size(), &bytes.Buffer{})
+		var encErr *EncryptionError
+		if !errors.Is(err, ErrEncrypted) || !errors.As(err, &encErr) || !reflect.DeepEqual(*encErr, tc.want) {
+			t.Errorf("%s: error = %v, want %+v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestHWPXTemplate(t *testing.T) {
+	// Templates share the container, under a mimetype of their own
+	file := hwpxFile(t, "form.hwtx", hwpxPackage(t,
+		hwpxPart{"mimetype", "application/hwpt+zip"},
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>상투 문구</t></run></p></sec>`},
+	))
+	var out bytes.Buffer
+	if err := Read(file, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "상투 문구\n" {
+		t.Errorf("output = %q, want the template text", out.String())
+	}
+}
+
+func TestHWPXValidate(t *testing.T) {
+	file := hwpxFile(t, "generated.hwpx", hwpxPackage(t,
+		hwpxPart{"Contents/header.xml", `<head><refList><charProperties><charPr id="0"/></charProperties></refList></head>`},
+		hwpxPart{"Contents/section0.xml", `<hs:sec xmlns:hs="http://www.hancom.co.kr/hwpml/2011/section" xmlns:hp="http://www.hancom.co.kr/hwpml/2011/paragraph">` +
+			`<hp:p><hp:run charPrIDRef="0"><hp:t>정상</hp:t></hp:run><hp:run charPrIDRef="9"><hp:t>참조</hp:t></hp:run></hp:p>` +
+			`<hp:p><hp:t>run 없음</hp:t><hp:run><hp:tbl rowCnt="2" colCnt="2">` +
+			`<hp:tr><hp:tc><hp:cellAddr colAddr="0" rowAddr="0"/><hp:cellSpan colSpan="1" rowSpan="1"/></hp:tc></hp:tr>` +
+			`<hp:tr><hp:tc><hp:cellAddr colAddr="1" rowAddr="1"/><hp:cellSpan colSpan="2" rowSpan="1"/></hp:tc></hp:tr>` +
+			`</hp:tbl></hp:run></hp:p></hs:sec>`},
+		hwpxPart{"Contents/section1.xml", `<sec><p><run><t>닫히지 않음</run></p></sec>`},
+	))
+	violations, err := Validate(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Message)
+	}
+	want := []string{
+		"reference to missing char shape 9",
+		"t element inside p, not run",
+		"cells cover 3 of the 4 cells of the 2x2 table",
+		"cell at row 1, column 1 spanning 1x2 is outside the 2x2 table",
+		"root element is {}sec, not hs:sec",
+		"malformed XML: XML syntax error on line 1: element <t> closed by </run>",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %q, want %q", got, want)
+	}
+}
+
+func TestHWPXCellProperties(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/header.xml", `<head><refList>` +
+			`<borderFills>` +
+			`<borderFill id="1"><leftBorder type="NONE"/><rightBorder type="NONE"/><topBorder type="NONE"/><bottomBorder type="NONE"/></borderFill>` +
+			`<borderFill id="2"><leftBorder type="SOLID"/><rightBorder type="NONE"/><topBorder type="DASH"/><bottomBorder type="NONE"/></borderFill>` +
+			`</borderFills><paraProperties>` +
+			`<paraPr id="0"><align horizontal="JUSTIFY"/></paraPr>` +
+			`<paraPr id="1"><align horizontal="RIGHT"/></paraPr>` +
+			`</paraProperties></refList></head>`},
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><tbl rowCnt="1" colCnt="2">` +
+			`<tr><tc borderFillIDRef="1"><subList vertAlign="TOP"><p paraPrIDRef="0"><run><t>가</t></run></p></subList>` +
+			`<cellAddr colAddr="0" rowAddr="0"/><cellSz width="3000" height="1000"/></tc>` +
+			`<tc borderFillIDRef="2"><subList vertAlign="CENTER"><p paraPrIDRef="0"/><p paraPrIDRef="1"><run><t>1,000</t></run></p></subList>` +
+			`<cellAddr colAddr="1" rowAddr="0"/><cellSz width="6000" height="1000"/></tc></tr>` +
+			`</tbl></run></p></sec>`},
+	)
+	want := `{"type":"table","id":"s0.p0.t0","rows":1,"cols":2,"cells":[` +
+		`{"row":0,"col":0,"rowSpan":1,"colSpan":1,"text":"가","width":30,"height":10,"valign":"top","borders":{}},` +
+		`{"row":0,"col":1,"rowSpan":1,"colSpan":1,"text":"1,000","width":60,"height":10,"align":"right","valign":"middle","borders":{"left":true,"top":true}}]}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestHWPXTrackChanges(t *testing.T) {
+	header := hwpxPart{"Contents/header.xml", `<head><refList>` +
+		`<trackChanges><trackChange id="1" type="Insert" date="2024-05-01T09:00:00Z" authorID="1"/>` +
+		`<trackChange id="2" type="Delete" authorID="1"/></trackChanges>` +
+		`<trackChangeAuthors><trackChangeAuthor id="1" name="Kim"/></trackChangeAuthors>` +
+		`</refList></head>`}
+	section := hwpxPart{"Contents/section0.xml", `<sec>` +
+		`<p><run><t>a<insertBegin TcId="1"/>b<insertEnd TcId="1"/><deleteBegin TcId="2"/>c<deleteEnd TcId="2"/></t></run></p>` +
+		`<p><run><tbl rowCnt="1" colCnt="1"><tr><tc><subList>` +
+		`<p><run><t>x</t></run></p><p><run><t><deleteBegin TcId="2"/>old<deleteEnd TcId="2"/>new</t></run></p>` +
+		`</subList><cellAddr colAddr="0" rowAddr="0"/></tc></tr></tbl></run></p>` +
+		`<p><run><rect><drawText><subList><p><run><t>box<insertBegin TcId="1"/>!<insertEnd TcId="1"/></t></run></p></subList></drawText></rect></run></p>` +
+		`</sec>`}
+
+	for _, tc := range []struct {
+		view ChangeView
+		want string
+	}{
+		{ChangesFinal, `{"type":"paragraph","id":"s0.p0","text":"ab"}` + "\n" +
+			`{"type":"table","id":"s0.p1.t0","rows":1,"cols":1,"cells":[{"row":0,"col":0,"rowSpan":1,"colSpan":1,"text":"x\nnew"}]}` + "\n" +
+			`{"type":"paragraph","id":"s0.p2.b0","text":"box!","floating":true}` + "\n"},
+		{ChangesMarkup, `{"type":"paragraph","id":"s0.p0","text":"abc","changes":[` +
+			`{"type":"insert","offset":1,"text":"b","author":"Kim","date":"2024-05-01T09:00:00Z"},` +
+			`{"type":"delete","offset":2,"text":"c","author":"Kim"}]}` + "\n" +
+			`{"type":"table","id":"s0.p1.t0","rows":1,"cols":1,"cells":[{"row":0,"col":0,"rowSpan":1,"colSpan":1,"text":"x\noldnew",` +
+			`"changes":[{"type":"delete","offset":2,"text":"old","author":"Kim"}]}]}` + "\n" +
+			`{"type":"paragraph","id":"s0.p2.b0","text":"box!","floating":true,"changes":[` +
+			`{"type":"insert","offset":3,"text":"!","author":"Kim","date":"2024-05-01T09:00:00Z"}]}` + "\n"},
+	} {
+		in := hwpxPackage(t, header, section)
+		var out bytes.Buffer
+		if err := ReadHWPX(in, in.Size(), &out, WithFormat(FormatJSONL), WithTrackChanges(tc.view)); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != tc.want {
+			t.Errorf("%s: output = %s, want %s", tc.view, got, tc.want)
+		}
+	}
+}
+
+func TestHWPXTableRows(t *testing.T) {
+	section := hwpxPart{"Contents/section0.xml", `<sec><p><run><tbl rowCnt="2" colCnt="1" repeatHeader="1">` +
+		`<caption><subList><p><run><t>표 1</t></run></p></subList></caption>` +
+		`<tr><tc><subList><p><run><t>머리</t></run></p></subList><cellAddr colAddr="0" rowAddr="0"/></tc></tr>` +
+		`<tr><tc><subList><p><run><t>값</t></run></p></subList><cellAddr colAddr="0" rowAddr="1"/></tc></tr>` +
+		`</tbl><t>무시</t></run></p><p><run><t>다음</t></run></p></sec>`}
+
+	in := hwpxPackage(t, section)
+	want := `{"type":"table","id":"s0.p0.t0","rows":2,"cols":1,"cells":[` +
+		`{"row":0,"col":0,"rowSpan":1,"colSpan":1,"text":"머리"},` +
+		`{"row":1,"col":0,"rowSpan":1,"colSpan":1,"text":"값"}],"caption":"표 1","headerRow":true}` + "\n" +
+		`{"type":"paragraph","id":"s0.p1","text":"다음"}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+
+	in = hwpxPackage(t, section)
+	var rows []TableRow
+	var out bytes.Buffer
+	if err := ReadHWPX(in, in.Size(), &out, WithFormat(FormatJSONL), WithTableRows(func(row TableRow) { rows = append(rows, row) })); err != nil {
+		t.Fatal(err)
+	}
+	want = `{"type":"table","id":"s0.p0.t0","rows":2,"cols":1,"cells":[],"caption":"표 1","headerRow":true}` + "\n" +
+		`{"type":"paragraph","id":"s0.p1","text":"다음"}` + "\n"
+	if out.String() != want {
+		t.Errorf("output with WithTableRows = %s, want %s", out.String(), want)
+	}
+	if len(rows) != 2 || rows[1].Table != "s0.p0.t0" || rows[1].Row != 1 || rows[1].Cells[0].Text != "값" {
+		t.Errorf("rows = %+v, want the two rows of s0.p0.t0", rows)
+	}
+}
+
+func TestHWPXParts(t *testing.T) {
+	file := hwpxFile(t, "parts.hwpx", hwpxPackage(t,
+		hwpxPart{"Contents/content.hpf", `<package><manifest>` +
+			`<item id="header" href="Contents/header.xml" media-type="application/xml"/>` +
+			`<item id="section0" href="Contents/section0.xml" media-type="application/xml"/>` +
+			`<item id="image1" href="BinData/image1.png" media-type="image/png"/>` +
+			`</manifest><spine><itemref idref="header"/><itemref idref="section0"/></spine></package>`},
+		hwpxPart{"Contents/header.xml", `<head/>`},
+		hwpxPart{"Contents/section0.xml", `<sec><p><run><t>본문</t></run></p></sec>`},
+		hwpxPart{"BinData/image1.png", "png"},
+		hwpxPart{"Chart/chart1.xml", `<chartSpace/>`},
+		hwpxPart{"Preview/PrvText.txt", "본문"},
+	))
+	parts, err := ListParts(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Part{
+		{Name: "mimetype", Kind: PartPackage},
+		{Name: "version.xml", Kind: PartPackage},
+		{Name: "Contents/content.hpf", Kind: PartPackage},
+		{Name: "Contents/header.xml", ID: "header", MediaType: "application/xml", Kind: PartHeader},
+		{Name: "Contents/section0.xml", ID: "section0", MediaType: "application/xml", Kind: PartSection},
+		{Name: "BinData/image1.png", ID: "image1", MediaType: "image/png", Kind: PartBinData},
+		{Name: "Chart/chart1.xml", Kind: PartChart},
+		{Name: "Preview/PrvText.txt", Kind: PartPreview},
+	}
+	if len(parts) > 5 && parts[5].Size != 3 {
+		t.Errorf("image size = %d, want 3", parts[5].Size)
+	}
+	for i := range parts {
+		parts[i].Size = 0
+	}
+	if !reflect.DeepEqual(parts, want) {
+		t.Errorf("parts = %+v, want %+v", parts, want)
+	}
+
+	part, err := OpenPart(file, "BinData/image1.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer part.Close()
+	if data, _ := io.ReadAll(part); string(data) != "png" {
+		t.Errorf("part data = %q, want png", data)
+	}
+}
+
+func TestHWPXReplaceText(t *testing.T) {
+	section := `<hs:sec xmlns:hs="http://www.hancom.co.kr/hwpml/2011/section" xmlns:hp="http://www.hancom.co.kr/hwpml/2011/paragraph">` +
+		`<hp:p id="0"><hp:run charPrIDRef="0"><hp:t>성명: {{na</hp:t></hp:run><hp:run charPrIDRef="1"><hp:t>me}}, {{name}}</hp:t></hp:run></hp:p>` +
+		`<hp:p id="1"><hp:run><hp:t>{{date}}&amp;</hp:t></hp:run></hp:p></hs:sec>`
+	file := hwpxFile(t, "template.hwpx", hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", section},
+		hwpxPart{"Contents/section1.xml", `<hs:sec><hp:p><hp:run><hp:t>변경 없음</hp:t></hp:run></hp:p></hs:sec>`},
+		hwpxPart{"BinData/image1.png", "png"},
+	))
+
+	var out bytes.Buffer
+	n, err := ReplaceText(file, &out, []string{"{{name}}", "홍길동 <&>", "{{date}}", "2024-01-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("replacements = %d, want 3", n)
+	}
+
+	in := bytes.NewReader(out.Bytes())
+	zr, err := zip.NewReader(in, in.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		parts[f.Name] = string(data)
+	}
+	wantSection := `<hs:sec xmlns:hs="http://www.hancom.co.kr/hwpml/2011/section" xmlns:hp="http://www.hancom.co.kr/hwpml/2011/paragraph">` +
+		`<hp:p id="0"><hp:run charPrIDRef="0"><hp:t>성명: 홍길동 &lt;&amp;&gt;</hp:t></hp:run><hp:run charPrIDRef="1"><hp:t>, 홍길동 &lt;&amp;&gt;</hp:t></hp:run></hp:p>` +
+		`<hp:p id="1"><hp:run><hp:t>2024-01-02&amp;</hp:t></hp:run></hp:p></hs:sec>`
+	if got := parts["Contents/section0.xml"]; got != wantSection {
+		t.Errorf("section0 = %s, want %s", got, wantSection)
+	}
+	if got := parts["Contents/section1.xml"]; got != `<hs:sec><hp:p><hp:run><hp:t>변경 없음</hp:t></hp:run></hp:p></hs:sec>` {
+		t.Errorf("section1 = %s, want it unchanged", got)
+	}
+	if got := parts["BinData/image1.png"]; got != "png" {
+		t.Errorf("image = %q, want png", got)
+	}
+	if zr.File[0].Name != "mimetype" {
+		t.Errorf("first part = %s, want mimetype", zr.File[0].Name)
+	}
+
+	if _, err := ReplaceText(file, io.Discard, []string{"{{name}}"}); err == nil {
+		t.Error("odd replacement count: no error")
+	}
+}
+
+func TestHWPXReplaceTextNestedTable(t *testing.T) {
+	// The text before and after a table within a paragraph is not matched
+	// as one
+	section := `<hs:sec><hp:p><hp:run><hp:t>{{a</hp:t>` +
+		`<hp:tbl><hp:tr><hp:tc><hp:subList><hp:p><hp:run><hp:t>{{b}}</hp:t></hp:run></hp:p></hp:subList></hp:tc></hp:tr></hp:tbl>` +
+		`<hp:t>}}</hp:t></hp:run></hp:p></hs:sec>`
+	file := hwpxFile(t, "nested.hwpx", hwpxPackage(t, hwpxPart{"Contents/section0.xml", section}))
+
+	var out bytes.Buffer
+	n, err := ReplaceText(file, &out, []string{"{{a}}", "A", "{{b}}", "B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("replacements = %d, want 1", n)
+	}
+	in := bytes.NewReader(out.Bytes())
+	zr, err := zip.NewReader(in, in.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zr.Open("Contents/section0.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	want := bytes.Replace([]byte(section), []byte("{{b}}"), []byte("B"), 1)
+	if !bytes.Equal(data, want) {
+		t.Errorf("section0 = %s, want %s", data, want)
+	}
+}
+
+func TestHWPXLists(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/header.xml", `<head><refList>` +
+			`<numberings><numbering id="1" start="0">` +
+			`<paraHead start="1" level="1" numFormat="DIGIT">^1.</paraHead>` +
+			`<paraHead start="1" level="2" numFormat="HANGUL_SYLLABLE">^2)</paraHead>` +
+			`</numbering></numberings>` +
+			`<bullets><bullet id="1" char="-"/></bullets>` +
+			`<paraProperties>` +
+			`<paraPr id="0"><align horizontal="JUSTIFY"/></paraPr>` +
+			`<paraPr id="1"><align horizontal="JUSTIFY"/><heading type="NUMBER" idRef="1" level="0"/></paraPr>` +
+			`<paraPr id="2"><align horizontal="JUSTIFY"/><heading type="NUMBER" idRef="1" level="1"/></paraPr>` +
+			`<paraPr id="3"><align horizontal="JUSTIFY"/><heading type="BULLET" idRef="1" level="0"/></paraPr>` +
+			`</paraProperties></refList></head>`},
+		hwpxPart{"Contents/section0.xml", `<sec>` +
+			`<p paraPrIDRef="1"><run><t>준비</t></run></p>` +
+			`<p paraPrIDRef="2"><run><t>설치</t></run></p>` +
+			`<p paraPrIDRef="0"><run><t>본문</t></run></p>` +
+			`<p paraPrIDRef="1"><run><t>실행</t></run></p>` +
+			`<p paraPrIDRef="3"><run><t>참고</t></run></p>` +
+			`</sec>`},
+	)
+	var out bytes.Buffer
+	if err := ReadHWPX(in, in.Size(), &out, WithFormat(FormatMarkdown)); err != nil {
+		t.Fatal(err)
+	}
+	want := "1. 준비\n    1. 설치\n\n본문\n\n2. 실행\n- 참고\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	in.Seek(0, io.SeekStart)
+	jsonl := readHWPXJSONL(t, in)
+	if !strings.Contains(jsonl, `"text":"설치","list":{"ordered":true,"level":2,"marker":"가)","number":1}`) {
+		t.Errorf("output = %s, want the second item numbered 가)", jsonl)
+	}
+}
+
+func TestHWPXHyperlinks(t *testing.T) {
+	in := hwpxPackage(t,
+		hwpxPart{"Contents/section0.xml", `<sec><p>` +
+			`<run><t>자세한 내용은 </t><ctrl><fieldBegin id="7" type="HYPERLINK" name="누리집">` +
+			`<parameters><stringParam name="Command">https\://www.hancom.com/;1;0;0;</stringParam></parameters>` +
+			`</fieldBegin></ctrl><t>한컴</t></run>` +
+			`<run charPrIDRef="1"><t>오피스</t><ctrl><fieldEnd beginIDRef="7"/></ctrl><t> 참고, </t></run>` +
+			`<run><ctrl><fieldBegin id="8" type="HYPERLINK"><parameters>` +
+			`<stringParam name="Path">mailto:help@example.com</stringParam>` +
+			`</parameters></fieldBegin></ctrl><t>메일</t><ctrl><fieldEnd beginIDRef="8"/></ctrl></run>` +
+			`</p></sec>`},
+	)
+	want := `{"type":"paragraph","id":"s0.p0","text":"자세한 내용은 한컴오피스 참고, 메일","hyperlinks":[` +
+		`{"offset":20,"text":"한컴오피스","url":"https://www.hancom.com/","title":"누리집"},` +
+		`{"offset":44,"text":"메일","url":"mailto:help@example.com"}]}` + "\n"
+	if got := readHWPXJSONL(t, in); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package hwp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// ExtractImages writes the pictures stored in a document to dir, which must
+// exist, and returns the paths written in document order. Files are named
+// after their BinData streams (BIN0001.png, ...) or, in HWPX documents,
+// their BinData parts (image1.png, ...); a picture shown several times is
+// written once. Linked pictures, which live outside the document, are
+// skipped.
+func ExtractImages(file *os.File, dir string, opts ...Option) ([]string, error) {
+	return extractBinData(file, dir, func(img *document.Image) bool {
+		return img.Format != document.OLEFormat
+	}, opts)
+}
+
+// ExtractAttachments writes the OLE objects embedded in an HWPX document,
+// such as spreadsheets, to dir, which must exist, and returns the paths
+// written in document order. Files are named after their BinData parts
+// (ole1.ole, ...) and hold the objects as OLE compound files; the class of
+// each object is reported as the class of its image placeholder. HWPX only.
+func ExtractAttachments(file *os.File, dir string, opts ...Option) ([]string, error) {
+	return extractBinData(file, dir, func(img *document.Image) bool {
+		return img.Format == document.OLEFormat
+	}, opts)
+}
+
+// extractBinData writes the data of the images accepted by keep to dir, once
+// per file name.
+func extractBinData(file *os.File, dir string, keep func(*document.Image) bool, opts []Option) ([]string, error) {
+	cfg := newConfig(opts)
+	scanner, err := openScanner(file, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	written := make(map[string]bool)
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return paths, fmt.Errorf("error reading content: %w", err)
+		}
+
+		img, ok := node.(*document.Image)
+		if !ok || img.Filename == "" || written[img.Filename] || !keep(img) {
+			continue
+		}
+		// Missing or unreadable data is skipped, as for linked pictures
+		data, err := img.Data()
+		if err != nil || data == nil {
+			continue
+		}
+		path := filepath.Join(dir, img.Filename)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return paths, err
+		}
+		written[img.Filename] = true
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
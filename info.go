@@ -0,0 +1,230 @@
+package hwp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/hwpv5"
+	"github.com/hanpama/hwp/internal/hwpx"
+)
+
+// Info describes a document container without rendering its content.
+type Info struct {
+	// Type is "hwp" for binary HWP v5 files and "hwpx" for HWPX packages.
+	Type    string
+	Version string
+	Streams []StreamInfo
+	// Fonts lists the fonts the document declares, by language, in
+	// declaration order.
+	Fonts []Font
+	// Macros is set when the document holds script code that would run,
+	// such as a handler run when it is opened (see ReadScripts). HWP only.
+	Macros bool
+	// Signature describes the document's digital signature, nil for
+	// unsigned documents. The signature is not verified. HWP only.
+	Signature *Signature
+	// Pages holds the page layout of each section, in section order; it is
+	// empty if a section cannot be read. HWPX only.
+	Pages []PageLayout
+	// Settings holds the application settings saved with the document,
+	// such as the caret position, nil if there are none or they cannot be
+	// read. HWPX only.
+	Settings *Settings
+}
+
+// Settings holds the application settings saved with an HWPX document
+// (settings.xml).
+type Settings = hwpx.Settings
+
+// CaretPosition is where the caret was when a document was saved.
+type CaretPosition = hwpx.CaretPosition
+
+// PageLayout describes the pages of a section. Lengths are in points.
+type PageLayout struct {
+	// Width and Height are the paper size, as if in portrait orientation;
+	// Landscape pages turn it sideways.
+	Width, Height float64
+	Landscape     bool
+	// Margins between the paper edge and the body text, and the header and
+	// footer distances from the edge
+	MarginLeft, MarginRight, MarginTop, MarginBottom float64
+	MarginHeader, MarginFooter                       float64
+	// Gutter is the extra margin left for binding.
+	Gutter float64
+	// Columns is the number of text columns, and ColumnGap the space
+	// between them.
+	Columns   int
+	ColumnGap float64
+}
+
+// Signature describes the digital signature of a document: the signers
+// whose certificates it holds.
+type Signature = hwpv5.Signature
+
+// Signer describes the certificate of a document signer.
+type Signer = hwpv5.Signer
+
+// Font is a font declared by a document.
+type Font struct {
+	// Language is the script the font is declared for: "hangul", "latin",
+	// "hanja", "japanese", "other", "symbol" or "user".
+	Language string
+	Name     string
+	// Substitute is the font to use when Name is not installed, if given.
+	Substitute string
+	// Embedded is set for fonts whose data is stored in the document. Only
+	// HWPX records font embedding.
+	Embedded bool
+}
+
+// StreamInfo reports the stored and decompressed size of a container stream.
+//
+// For HWP files this covers the DocInfo, section, BinData and Scripts
+// streams; for
+// HWPX files it covers every entry of the ZIP package.
+type StreamInfo struct {
+	Name string
+	// Size is the number of bytes stored in the container.
+	Size int64
+	// DecompressedSize is the number of bytes after decryption and
+	// decompression, or -1 if the stream could not be decoded.
+	DecompressedSize int64
+}
+
+// CompressionRatio returns DecompressedSize divided by Size.
+// It returns 0 if either size is unknown or zero.
+func (s StreamInfo) CompressionRatio() float64 {
+	if s.Size <= 0 || s.DecompressedSize < 0 {
+		return 0
+	}
+	return float64(s.DecompressedSize) / float64(s.Size)
+}
+
+// ReadInfo reports the format, version, stream sizes and fonts of a document.
+//
+// Format detection follows the same rules as Read. Section streams are
+// decompressed to measure them, which makes ReadInfo useful for estimating
+// extraction cost and spotting anomalous files before processing.
+func ReadInfo(file *os.File) (*Info, error) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	if isHWPX(file.Name()) {
+		return readHWPXInfo(file, fileInfo.Size())
+	}
+
+	return readHWPInfo(file)
+}
+
+func readHWPInfo(in io.ReaderAt) (*Info, error) {
+	reader, err := hwpv5.OpenReader(in, document.DefaultScanOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HWP file: %w", err)
+	}
+
+	stats, err := reader.StreamStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HWP streams: %w", err)
+	}
+
+	info := &Info{
+		Type:    "hwp",
+		Version: reader.Header.Version.String(),
+		Streams: make([]StreamInfo, 0, len(stats)),
+	}
+	for _, stat := range stats {
+		info.Streams = append(info.Streams, StreamInfo{
+			Name:             stat.Name,
+			Size:             stat.Size,
+			DecompressedSize: stat.DecodedSize,
+		})
+	}
+	for lang, faces := range reader.DocInfo.FaceNames {
+		for _, face := range faces {
+			info.Fonts = append(info.Fonts, Font{
+				Language:   hwpv5.LangName(lang),
+				Name:       face.Name,
+				Substitute: face.AltName,
+			})
+		}
+	}
+	scripts, err := reader.Scripts()
+	if err != nil {
+		return nil, err
+	}
+	info.Macros = scripts.HasMacros()
+	info.Signature, err = reader.Signature()
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func readHWPXInfo(in io.ReaderAt, size int64) (*Info, error) {
+	reader, err := hwpx.Open(in, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HWPX file: %w", err)
+	}
+
+	stats := reader.StreamStats()
+	info := &Info{
+		Type:    "hwpx",
+		Version: reader.Version().String(),
+		Streams: make([]StreamInfo, 0, len(stats)),
+	}
+	for _, stat := range stats {
+		info.Streams = append(info.Streams, StreamInfo{
+			Name:             stat.Name,
+			Size:             stat.Size,
+			DecompressedSize: stat.UncompressedSize,
+		})
+	}
+
+	header, err := reader.Header()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HWPX header: %w", err)
+	}
+	for _, ff := range header.FontFaces {
+		for _, font := range ff.Fonts {
+			info.Fonts = append(info.Fonts, Font{
+				Language:   strings.ToLower(ff.Lang),
+				Name:       font.Face,
+				Substitute: font.SubstFont.Face,
+				Embedded:   font.IsEmbedded,
+			})
+		}
+	}
+
+	// Damaged sections leave the page layouts out rather than failing, as
+	// they do not keep the other information from being read
+	defs, _ := reader.PageDefs(document.DefaultScanOptions().MaxStreamSize)
+	for _, def := range defs {
+		info.Pages = append(info.Pages, pageLayout(def))
+	}
+	info.Settings, _ = reader.Settings()
+	return info, nil
+}
+
+func pageLayout(def hwpx.PageDef) PageLayout {
+	// HWPUNITs are 1/7200 inch
+	points := func(v int32) float64 { return float64(v) / 100 }
+	return PageLayout{
+		Width:        points(def.Width),
+		Height:       points(def.Height),
+		Landscape:    def.Landscape,
+		MarginLeft:   points(def.MarginLeft),
+		MarginRight:  points(def.MarginRight),
+		MarginTop:    points(def.MarginTop),
+		MarginBottom: points(def.MarginBottom),
+		MarginHeader: points(def.MarginHeader),
+		MarginFooter: points(def.MarginFooter),
+		Gutter:       points(def.MarginGutter),
+		Columns:      def.Columns,
+		ColumnGap:    points(def.ColumnGap),
+	}
+}
@@ -0,0 +1,32 @@
+package document
+
+import "strings"
+
+// TextWithTables returns the text of the cell followed by the text of its
+// nested tables, for output formats that cannot nest tables. Each row of a
+// nested table becomes a line, with its cells separated by " | ".
+func (c *Cell) TextWithTables() string {
+	if len(c.Tables) == 0 {
+		return c.Text
+	}
+
+	lines := []string{}
+	if c.Text != "" {
+		lines = append(lines, c.Text)
+	}
+	for _, t := range c.Tables {
+		var row []string
+		for i := range t.Cells {
+			cell := &t.Cells[i]
+			if i > 0 && cell.Row != t.Cells[i-1].Row {
+				lines = append(lines, strings.Join(row, " | "))
+				row = nil
+			}
+			row = append(row, strings.Join(strings.Fields(cell.TextWithTables()), " "))
+		}
+		if len(row) > 0 {
+			lines = append(lines, strings.Join(row, " | "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
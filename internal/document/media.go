@@ -0,0 +1,25 @@
+package document
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	embedSrc = regexp.MustCompile(`(?i)\b(?:src|data|value)\s*=\s*["']?([^"'\s>]+)`)
+	embedURL = regexp.MustCompile(`(?i)(?:https?:)?//[^"'\s<>]+`)
+)
+
+// EmbedURL returns the URL of web media from its embed tag, such as the
+// <iframe src="https://www.youtube.com/embed/..."> HTML code stored for web
+// videos. A tag without a URL is returned as it is.
+func EmbedURL(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if m := embedSrc.FindStringSubmatch(tag); m != nil {
+		return m[1]
+	}
+	if u := embedURL.FindString(tag); u != "" {
+		return u
+	}
+	return tag
+}
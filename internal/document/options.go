@@ -0,0 +1,124 @@
+package document
+
+// ScanOptions controls how scanners turn source records into content nodes.
+// Both the HWP v5 and HWPX scanners honor these options.
+type ScanOptions struct {
+	// CellParagraphSeparator is inserted between paragraphs inside a table cell.
+	CellParagraphSeparator string
+
+	// SplitOnParaBreak makes ParaBreak elements inside a paragraph's text start
+	// a new paragraph. By default text is merged until the paragraph's
+	// CharShape/LineSeg record, which is how HWP v5 normally delimits
+	// paragraphs. HWP v5 only.
+	SplitOnParaBreak bool
+
+	// MaxStreamSize caps the number of bytes a single stream may expand to
+	// after decompression. Zero means no limit.
+	MaxStreamSize int64
+
+	// MaxRecordSize caps the size of a single HWP record, which is
+	// allocated as declared by its header. Zero means no limit. HWP v5
+	// only.
+	MaxRecordSize int64
+
+	// MaxTableCells caps the rows times columns of a table, which renderers
+	// lay out as a grid. Zero means no limit.
+	MaxTableCells int64
+
+	// EmbeddedDepth is how many levels of HWP documents embedded as OLE
+	// objects are extracted inline, between begin and end markers. Zero
+	// leaves embedded documents as image placeholders. HWP v5 only.
+	EmbeddedDepth int
+
+	// LayoutLineBreaks breaks paragraph text where its lines broke when the
+	// document was last laid out, as recorded in its line segments, instead
+	// of only at explicit line breaks. HWP v5 only.
+	LayoutLineBreaks bool
+
+	// HiddenText extracts hidden comments (숨은 설명) as Hidden paragraphs
+	// after the paragraph they are attached to. They are left out by default.
+	HiddenText bool
+
+	// Breaks emits a PageBreak before each paragraph that starts a new
+	// page, and a SectionBreak at the start of each section after the
+	// first. They are left out by default.
+	Breaks bool
+
+	// PageNumber stands in for page numbers, which depend on the page
+	// layout: page-number fields and the page number of page number
+	// position controls. Empty drops them.
+	PageNumber string
+
+	// Changes selects how tracked changes appear: ChangesFinal (the
+	// default) applies them, ChangesOriginal rejects them and ChangesMarkup
+	// keeps both inserted and deleted text, reported as Paragraph.Changes
+	// and Cell.Changes. HWPX only: HWP v5 scanners warn when a document with
+	// tracked changes is read in another view than ChangesFinal.
+	Changes string
+
+	// StrictReferences fails the scan when DocInfo does not hold the items
+	// its ID mappings declare, or when the body refers to a missing char
+	// shape, paragraph shape, style, tab definition, border fill or binary
+	// data item. By default such references are ignored. HWP v5 only.
+	StrictReferences bool
+
+	// Recover skips records that fail to decode, and the rest of sections
+	// that fail to read, reporting each to OnWarning, instead of failing
+	// the scan. HWP v5 only.
+	Recover bool
+
+	// PreviewFallback reads the rest of an HWPX document from its preview
+	// text (Preview/PrvText.txt), one paragraph per line, when a section
+	// fails to parse, reporting the failure to OnWarning. HWPX only.
+	PreviewFallback bool
+
+	// Resume starts scanning at a checkpoint taken from an earlier scan of
+	// the same file. HWP v5 only.
+	Resume *Checkpoint
+
+	// OnCheckpoint is called with the position after the nodes returned so
+	// far whenever the scanner is between top-level nodes. HWP v5 only.
+	OnCheckpoint func(Checkpoint)
+
+	// OnTableRow is called with each row of a table as it is read. The
+	// rows are then not kept in the Table node, which is still returned
+	// with its size and caption, so that the memory a table takes does not
+	// grow with its rows. HWPX only.
+	OnTableRow func(TableRow)
+
+	// OnWarning is called for each problem the scanner works around, such
+	// as a section count that disagrees with the file.
+	OnWarning func(error)
+}
+
+// Warn reports a problem to OnWarning, if set.
+func (o ScanOptions) Warn(err error) {
+	if o.OnWarning != nil {
+		o.OnWarning(err)
+	}
+}
+
+// Checkpoint is a scanner position from which scanning can resume: a
+// section, the offset of a record in the section's decoded stream and that
+// record's index, which node IDs derive from. Resuming skips earlier
+// sections entirely and does not parse earlier records of the section.
+type Checkpoint struct {
+	Section int   `json:"section"`
+	Offset  int64 `json:"offset"`
+	Record  int   `json:"record"`
+}
+
+// Views of tracked changes, for ScanOptions.Changes
+const (
+	ChangesFinal    = "final"
+	ChangesOriginal = "original"
+	ChangesMarkup   = "markup"
+)
+
+// DefaultScanOptions returns the options used when none are specified.
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{
+		CellParagraphSeparator: "\n",
+		Changes:                ChangesFinal,
+	}
+}
@@ -1,42 +1,498 @@
 package document
 
+import "slices"
+
 // ContentNode is the interface for document content items
 type ContentNode interface {
 	IsContent()
 }
 
+// Node IDs identify a node by its position in the source document (section
+// and record or element index), so the same file always yields the same IDs.
+// They are opaque strings; nodes synthesized by transforms have none.
+
 // Paragraph represents a paragraph with text
 type Paragraph struct {
-	Text string
+	ID   string `json:"id,omitempty"`
+	Text string `json:"text"`
+	// Preformatted is set when all of the paragraph's text uses a monospaced
+	// font, e.g. code or ASCII diagrams whose alignment must be preserved.
+	Preformatted bool `json:"preformatted,omitempty"`
+	// Bookmarks holds the names of bookmarks placed in the paragraph.
+	Bookmarks []string `json:"bookmarks,omitempty"`
+	// Fields holds the form fields filled in within the paragraph.
+	Fields []Field `json:"fields,omitempty"`
+	// Hyperlinks holds the links on spans of Text, in text order.
+	Hyperlinks []Hyperlink `json:"hyperlinks,omitempty"`
+	// Floating is set for paragraphs of text boxes and callouts, page
+	// headers and footers, and notes, which are placed apart from the flow
+	// of the body text.
+	Floating bool `json:"floating,omitempty"`
+	// Hidden is set for the text of hidden comments (숨은 설명), which
+	// word processors do not display or print.
+	Hidden bool `json:"hidden,omitempty"`
+	// Changes holds the tracked insertions and deletions on spans of Text,
+	// in text order. They are only reported when scanning with ChangesMarkup.
+	Changes []Change `json:"changes,omitempty"`
+	// Runs holds the character formatting of Text as consecutive spans. It
+	// is only set for paragraphs with emphasis (bold, italic, underline,
+	// strikeout, superscript or subscript) somewhere in their text.
+	Runs []Run `json:"runs,omitempty"`
+	// Layout holds the paragraph's alignment, indentation and spacing. It is
+	// only set for paragraphs that are centered, aligned right or
+	// distributed, or indented.
+	Layout *Layout `json:"layout,omitempty"`
+	// TabStops holds the tab stops of paragraphs whose text has tabs.
+	TabStops []TabStop `json:"tabStops,omitempty"`
+	// List is set for the items of numbered and bulleted lists.
+	List *ListItem `json:"list,omitempty"`
 }
 
 func (p *Paragraph) IsContent() {}
 
+// Tab stop alignments
+const (
+	TabLeft    = "left"
+	TabRight   = "right"
+	TabCenter  = "center"
+	TabDecimal = "decimal"
+)
+
+// TabStop is a tab stop (탭) of a paragraph.
+type TabStop struct {
+	// Position is the distance from the paragraph's left edge in points.
+	Position float64 `json:"position"`
+	// Align is how the text up to the next tab lines up with the stop.
+	Align string `json:"align"`
+	// Leader is the character that fills the tab, if any.
+	Leader string `json:"leader,omitempty"`
+}
+
+// ListItem places a paragraph in a list: one with paragraph numbers (문단
+// 번호) or bullets (글머리표), which word processors draw before the text.
+// Consecutive items of the same numbering or bullet make up a list.
+type ListItem struct {
+	Ordered bool `json:"ordered"`
+	// Level is the nesting level, from 1.
+	Level int `json:"level"`
+	// Marker is the paragraph number as displayed, e.g. "1." or "가)", or
+	// the bullet character.
+	Marker string `json:"marker"`
+	// Number is the item's number at its level, for ordered lists.
+	Number int `json:"number,omitempty"`
+}
+
+// Heading is a paragraph in an outline style (개요 1-7): a section title.
+type Heading struct {
+	Paragraph
+	// Level is the outline level, from 1.
+	Level int `json:"level"`
+}
+
+func (h *Heading) IsContent() {}
+
+// Alignments
+const (
+	AlignJustify    = "justify"
+	AlignLeft       = "left"
+	AlignRight      = "right"
+	AlignCenter     = "center"
+	AlignDistribute = "distribute" // letters spread to fill every line
+	AlignDivide     = "divide"     // words spread to fill every line
+)
+
+// Layout is the paragraph shape (문단 모양) of a paragraph. Lengths are in
+// points.
+type Layout struct {
+	Align       string  `json:"align"`
+	MarginLeft  float64 `json:"marginLeft,omitempty"`
+	MarginRight float64 `json:"marginRight,omitempty"`
+	// Indent is the first line indent; negative for a hanging indent.
+	Indent      float64 `json:"indent,omitempty"`
+	SpaceBefore float64 `json:"spaceBefore,omitempty"`
+	SpaceAfter  float64 `json:"spaceAfter,omitempty"`
+	// LineSpacing is the line height as a percentage of the font size,
+	// zero when it is set otherwise.
+	LineSpacing int `json:"lineSpacing,omitempty"`
+}
+
+// Indented reports whether the layout has a margin or a first line indent.
+func (l *Layout) Indented() bool {
+	return l.MarginLeft != 0 || l.MarginRight != 0 || l.Indent != 0
+}
+
 // Table represents a table with cells
 type Table struct {
-	Rows  int
-	Cols  int
-	Cells []Cell
+	ID      string `json:"id,omitempty"`
+	Rows    int    `json:"rows"`
+	Cols    int    `json:"cols"`
+	Cells   []Cell `json:"cells"`
+	Caption string `json:"caption,omitempty"`
+	// Borderless is set for tables whose cells have no borders, which
+	// documents use to lay out content rather than to present data.
+	Borderless bool `json:"borderless,omitempty"`
+	// HeaderRow is set when the first row is a header row, repeated on
+	// each page the table spans.
+	HeaderRow bool `json:"headerRow,omitempty"`
 }
 
 func (t *Table) IsContent() {}
 
+// TableRow is a row of table cells passed to ScanOptions.OnTableRow as the
+// table is read.
+type TableRow struct {
+	// Table is the ID of the table, and Row the index of the row in it.
+	Table string `json:"table"`
+	Row   int    `json:"row"`
+	Cells []Cell `json:"cells"`
+}
+
 // Cell represents a table cell
 type Cell struct {
-	Row     int
-	Col     int
-	RowSpan int
-	ColSpan int
-	Text    string
+	Row     int     `json:"row"`
+	Col     int     `json:"col"`
+	RowSpan int     `json:"rowSpan"`
+	ColSpan int     `json:"colSpan"`
+	Text    string  `json:"text"`
+	Fields  []Field `json:"fields,omitempty"`
+	// Tables holds the tables nested in the cell, in document order.
+	Tables []*Table `json:"tables,omitempty"`
+	// Background is the shading of the cell as "#rrggbb"; empty for none
+	// or white.
+	Background string `json:"background,omitempty"`
+	// Width and Height are the cell size in points, spans included, zero
+	// when unknown.
+	Width  float64 `json:"width,omitempty"`
+	Height float64 `json:"height,omitempty"`
+	// Align is the alignment of the cell's first paragraph with text, one
+	// of the Align constants; empty for text aligned to both sides or the
+	// left.
+	Align string `json:"align,omitempty"`
+	// VAlign is the vertical alignment of the cell's text, one of the
+	// VAlign constants; empty when unknown.
+	VAlign string `json:"valign,omitempty"`
+	// Borders tells which sides of the cell have a border line; nil when
+	// unknown.
+	Borders *CellBorders `json:"borders,omitempty"`
+	// Changes are the tracked changes in the cell text, kept with
+	// ChangesMarkup as for paragraphs.
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Vertical alignments of cell text
+const (
+	VAlignTop    = "top"
+	VAlignMiddle = "middle"
+	VAlignBottom = "bottom"
+)
+
+// CellBorders tells which sides of a cell have a border line.
+type CellBorders struct {
+	Left   bool `json:"left,omitempty"`
+	Right  bool `json:"right,omitempty"`
+	Top    bool `json:"top,omitempty"`
+	Bottom bool `json:"bottom,omitempty"`
+}
+
+// Field types
+const (
+	FieldClickHere = "click-here" // 누름틀, a fill-in placeholder within text
+	FieldEdit      = "edit"
+	FieldCheckBox  = "checkbox"
+	FieldRadio     = "radio"
+	FieldComboBox  = "combobox"
+)
+
+// Field is a named form field and its value as filled in. Check boxes and
+// radio buttons have the value "on" or "off".
+type Field struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Hyperlink is a link on a span of paragraph text.
+type Hyperlink struct {
+	// Offset is the byte offset of the link text within the paragraph text.
+	Offset int    `json:"offset"`
+	Text   string `json:"text"`
+	URL    string `json:"url"`
+	// Title is the name given to the link field, if any, which describes
+	// the target and suits a tooltip.
+	Title string `json:"title,omitempty"`
+}
+
+// Run is a span of paragraph text in one character formatting.
+type Run struct {
+	// Offset is the byte offset of the run text within the paragraph text.
+	Offset      int    `json:"offset"`
+	Text        string `json:"text"`
+	Bold        bool   `json:"bold,omitempty"`
+	Italic      bool   `json:"italic,omitempty"`
+	Underline   bool   `json:"underline,omitempty"`
+	Strikeout   bool   `json:"strikeout,omitempty"`
+	Superscript bool   `json:"superscript,omitempty"`
+	Subscript   bool   `json:"subscript,omitempty"`
+	// Size is the font size in points.
+	Size float64 `json:"size,omitempty"`
+	// Color is the text color as "#rrggbb"; empty for black.
+	Color string `json:"color,omitempty"`
+	// URL is the hyperlink target of the run text in runs from TextRuns.
+	URL string `json:"url,omitempty"`
+}
+
+// Emphasized reports whether the run has any emphasis.
+func (r *Run) Emphasized() bool {
+	return r.Bold || r.Italic || r.Underline || r.Strikeout || r.Superscript || r.Subscript
+}
+
+// SameFormat reports whether two runs have the same formatting.
+func (r *Run) SameFormat(o *Run) bool {
+	a, b := *r, *o
+	a.Offset, a.Text, b.Offset, b.Text = 0, "", 0, ""
+	return a == b
+}
+
+// TextRuns returns the text of the paragraph as consecutive runs, each in
+// one character formatting and hyperlink, which together make up Text. It
+// combines Runs and Hyperlinks, so that it covers paragraphs without
+// emphasis too; spans of them that do not match the text are left out.
+func (p *Paragraph) TextRuns() []Run {
+	if p.Text == "" {
+		return nil
+	}
+	matches := func(offset int, text string) bool {
+		return text != "" && offset >= 0 && offset+len(text) <= len(p.Text) && p.Text[offset:offset+len(text)] == text
+	}
+	// Runs cut the text where their formatting or link starts
+	cuts := map[int]bool{0: true, len(p.Text): true}
+	for _, r := range p.Runs {
+		if matches(r.Offset, r.Text) {
+			cuts[r.Offset], cuts[r.Offset+len(r.Text)] = true, true
+		}
+	}
+	for _, l := range p.Hyperlinks {
+		if matches(l.Offset, l.Text) {
+			cuts[l.Offset], cuts[l.Offset+len(l.Text)] = true, true
+		}
+	}
+	offsets := make([]int, 0, len(cuts))
+	for offset := range cuts {
+		offsets = append(offsets, offset)
+	}
+	slices.Sort(offsets)
+
+	var runs []Run
+	for i, start := range offsets[:len(offsets)-1] {
+		run := Run{}
+		for _, r := range p.Runs {
+			if matches(r.Offset, r.Text) && r.Offset <= start && start < r.Offset+len(r.Text) {
+				run = r
+				break
+			}
+		}
+		for _, l := range p.Hyperlinks {
+			if matches(l.Offset, l.Text) && l.Offset <= start && start < l.Offset+len(l.Text) {
+				run.URL = l.URL
+				break
+			}
+		}
+		run.Offset, run.Text = start, p.Text[start:offsets[i+1]]
+		if n := len(runs); n > 0 && runs[n-1].SameFormat(&run) {
+			runs[n-1].Text += run.Text
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// Change types
+const (
+	ChangeInsert = "insert"
+	ChangeDelete = "delete"
+)
+
+// Change is a tracked change (변경 추적) on a span of paragraph text.
+type Change struct {
+	Type string `json:"type"`
+	// Offset is the byte offset of the changed text within the paragraph text.
+	Offset int    `json:"offset"`
+	Text   string `json:"text"`
+	Author string `json:"author,omitempty"`
+	// Date is when the change was made, as stored in the document.
+	Date string `json:"date,omitempty"`
 }
 
 // Image represents an image or drawing object
 type Image struct {
-	// TODO: Add metadata fields (size, caption, format) when image extraction is implemented
+	ID      string `json:"id,omitempty"`
+	Caption string `json:"caption,omitempty"`
+	// Format is the lowercase file extension of a picture's data ("png",
+	// "jpg", "bmp", ...), or OLEFormat for an embedded OLE object, and
+	// Filename a suggested file name for it. Both are empty for drawings
+	// without picture data.
+	Format   string `json:"format,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	// Class is the class of an OLE object: the ProgID of the application
+	// that made it, e.g. "Excel.Sheet.12", or else its class ID.
+	Class string `json:"class,omitempty"`
+	// Width and Height are the displayed size in points, zero when unknown.
+	Width  float64 `json:"width,omitempty"`
+	Height float64 `json:"height,omitempty"`
+	// OriginalWidth and OriginalHeight are the size of the picture before
+	// it was scaled, in points, zero when unknown. Only HWPX records it.
+	OriginalWidth  float64 `json:"originalWidth,omitempty"`
+	OriginalHeight float64 `json:"originalHeight,omitempty"`
+	// Alt is the author's description of the image, its alternative text.
+	Alt string `json:"alt,omitempty"`
+	// Anchor tells how the image sits in the text, one of the Anchor
+	// constants, or is empty when unknown.
+	Anchor string `json:"anchor,omitempty"`
+
+	// load reads the bytes of the picture or object, and data holds them
+	// once read
+	load func() ([]byte, error)
+	data []byte
 }
 
+// OLEFormat is the Image.Format of an embedded OLE object, whose data is an
+// OLE compound file.
+const OLEFormat = "ole"
+
+// Image anchors
+const (
+	AnchorInline    = "inline"    // treated as a character of the text (글자처럼 취급)
+	AnchorParagraph = "paragraph" // placed relative to its paragraph
+	AnchorPage      = "page"      // placed relative to the page
+	AnchorPaper     = "paper"     // placed relative to the paper
+)
+
 func (i *Image) IsContent() {}
 
+// SetData sets the function Data reads the image's bytes with. Scanners set
+// it for pictures and objects stored in the document, so that their bytes
+// are read only when asked for.
+func (i *Image) SetData(load func() ([]byte, error)) {
+	i.load, i.data = load, nil
+}
+
+// Data returns the bytes of the picture or object, reading them from the
+// document on first use, while it is still open. It returns nil for
+// drawings without picture data and for linked pictures, which live
+// outside the document.
+func (i *Image) Data() ([]byte, error) {
+	if i.data != nil || i.load == nil {
+		return i.data, nil
+	}
+	data, err := i.load()
+	if err != nil {
+		return nil, err
+	}
+	i.data = data
+	return data, nil
+}
+
+// Media represents a video or other media object
+type Media struct {
+	ID string `json:"id,omitempty"`
+	// Source is the URL of web media, or the file name of media stored in
+	// the document (Embedded), or the path of a linked local file.
+	Source   string `json:"source"`
+	Embedded bool   `json:"embedded,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+func (m *Media) IsContent() {}
+
+// Equation represents a formula
+type Equation struct {
+	ID string `json:"id,omitempty"`
+	// Script is the formula in Hangul equation script ("1 over 2"), and
+	// LaTeX its translation to LaTeX math ("\frac{1}{2}").
+	Script  string `json:"script"`
+	LaTeX   string `json:"latex"`
+	Caption string `json:"caption,omitempty"`
+}
+
+func (e *Equation) IsContent() {}
+
+// Chart is a chart and the data it plots.
+type Chart struct {
+	ID string `json:"id,omitempty"`
+	// Kind is the kind of chart, e.g. "bar", "line" or "pie".
+	Kind  string `json:"kind,omitempty"`
+	Title string `json:"title,omitempty"`
+	// Categories label the points of each series.
+	Categories []string      `json:"categories,omitempty"`
+	Series     []ChartSeries `json:"series"`
+	Caption    string        `json:"caption,omitempty"`
+}
+
+func (c *Chart) IsContent() {}
+
+// ChartSeries is a data series of a chart: a value for each category.
+type ChartSeries struct {
+	Name   string    `json:"name,omitempty"`
+	Values []float64 `json:"values"`
+}
+
+// Footnote is a footnote (각주), or with Endnote set an endnote (미주). It
+// follows the paragraph that refers to it.
+type Footnote struct {
+	ID string `json:"id,omitempty"`
+	// Number is the note's number, from 1.
+	Number int `json:"number"`
+	// Text is the text of the note's paragraphs, one per line.
+	Text    string `json:"text"`
+	Endnote bool   `json:"endnote,omitempty"`
+	// Anchor is the ID of the paragraph whose text refers to the note.
+	Anchor string `json:"anchor,omitempty"`
+	// Paragraphs holds the non-empty paragraphs of the note, so that
+	// renderers can keep their formatting and links.
+	Paragraphs []Paragraph `json:"paragraphs,omitempty"`
+}
+
+func (f *Footnote) IsContent() {}
+
+// PageBreak marks that the text after it starts on a new page (쪽 나누기).
+type PageBreak struct {
+	ID string `json:"id,omitempty"`
+}
+
+func (b *PageBreak) IsContent() {}
+
+// SectionBreak marks the start of a section (구역) after the first, which
+// may have its own page size, columns, headers and footers.
+type SectionBreak struct {
+	ID string `json:"id,omitempty"`
+	// Section is the index of the section starting, from 0.
+	Section int `json:"section"`
+}
+
+func (b *SectionBreak) IsContent() {}
+
+// Comment is a memo (메모) on a span of paragraph text. In HWPX documents
+// it follows the paragraph holding the span; HWP files keep the memos of a
+// section after its body text, where they come out.
+type Comment struct {
+	ID     string `json:"id,omitempty"`
+	Author string `json:"author,omitempty"`
+	// Date is when the memo was written, as stored in the document.
+	Date string `json:"date,omitempty"`
+	// Text is the text of the memo's paragraphs, one per line.
+	Text string `json:"text"`
+	// Anchor is the ID of the paragraph holding the span, Offset the byte
+	// offset of the span within the paragraph text, and Quote its text.
+	// Anchor is empty for spans outside body paragraphs, such as in table
+	// cells.
+	Anchor string `json:"anchor,omitempty"`
+	Offset int    `json:"offset"`
+	Quote  string `json:"quote,omitempty"`
+}
+
+func (c *Comment) IsContent() {}
+
 type ContentNodeScanner interface {
 	Next() (ContentNode, error)
 }
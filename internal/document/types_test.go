@@ -0,0 +1,56 @@
+package document
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTextRuns(t *testing.T) {
+	p := &Paragraph{
+		Text: "see bold link here",
+		Runs: []Run{
+			{Offset: 0, Text: "see "},
+			{Offset: 4, Text: "bold li", Bold: true},
+			{Offset: 11, Text: "nk here"},
+		},
+		Hyperlinks: []Hyperlink{
+			{Offset: 9, Text: "link", URL: "https://example.com"},
+			{Offset: 0, Text: "mismatch", URL: "https://example.org"},
+		},
+	}
+	want := []Run{
+		{Offset: 0, Text: "see "},
+		{Offset: 4, Text: "bold ", Bold: true},
+		{Offset: 9, Text: "li", Bold: true, URL: "https://example.com"},
+		{Offset: 11, Text: "nk", URL: "https://example.com"},
+		{Offset: 13, Text: " here"},
+	}
+	if got := p.TextRuns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TextRuns() = %+v, want %+v", got, want)
+	}
+
+	plain := &Paragraph{Text: "plain"}
+	if got := plain.TextRuns(); !reflect.DeepEqual(got, []Run{{Text: "plain"}}) {
+		t.Errorf("TextRuns() = %+v, want one plain run", got)
+	}
+}
+
+func TestImageData(t *testing.T) {
+	img := &Image{}
+	if data, err := img.Data(); data != nil || err != nil {
+		t.Errorf("Data() = %q, %v without data", data, err)
+	}
+	reads := 0
+	img.SetData(func() ([]byte, error) {
+		reads++
+		return []byte("png"), nil
+	})
+	for range 2 {
+		if data, err := img.Data(); string(data) != "png" || err != nil {
+			t.Errorf("Data() = %q, %v, want png", data, err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("data read %d times, want once", reads)
+	}
+}
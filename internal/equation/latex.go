@@ -0,0 +1,343 @@
+// Package equation translates Hangul equation scripts, the text form of
+// formulas in HWP and HWPX documents, to LaTeX.
+package equation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LaTeX translates an equation script such as "{a+b} over 2 ^{n}" to LaTeX
+// math ("\frac{a+b}{2^{n}}"). Unknown commands are kept as they are, so the
+// result stays readable even for constructs the translator does not know.
+func LaTeX(script string) string {
+	p := &parser{tokens: tokenize(script)}
+	return p.sequence("")
+}
+
+// Commands translated to a LaTeX command of their own. Keywords match
+// case-insensitively unless they are Greek letters, whose case selects the
+// letter case.
+var symbols = map[string]string{
+	// Operators and relations
+	"times": `\times`, "div": `\div`, "cdot": `\cdot`, "pm": `\pm`, "mp": `\mp`,
+	"le": `\le`, "leq": `\le`, "ge": `\ge`, "geq": `\ge`, "ne": `\ne`, "neq": `\ne`,
+	"approx": `\approx`, "equiv": `\equiv`, "sim": `\sim`, "simeq": `\simeq`,
+	"cong": `\cong`, "propto": `\propto`, "ll": `\ll`, "gg": `\gg`,
+	"in": `\in`, "notin": `\notin`, "owns": `\ni`, "subset": `\subset`,
+	"supset": `\supset`, "subseteq": `\subseteq`, "supseteq": `\supseteq`,
+	"cup": `\cup`, "cap": `\cap`, "emptyset": `\emptyset`,
+	"forall": `\forall`, "exist": `\exists`, "exists": `\exists`, "not": `\neg`,
+	"therefore": `\therefore`, "because": `\because`, "and": `\land`, "or": `\lor`,
+	"inf": `\infty`, "infinity": `\infty`, "partial": `\partial`, "nabla": `\nabla`,
+	"prime": `'`, "deg": `^{\circ}`, "angle": `\angle`, "perp": `\perp`,
+	"cdots": `\cdots`, "ldots": `\ldots`, "vdots": `\vdots`, "ddots": `\ddots`, "dots": `\dots`,
+	"larrow": `\leftarrow`, "rarrow": `\rightarrow`, "lrarrow": `\leftrightarrow`,
+	"uparrow": `\uparrow`, "downarrow": `\downarrow`, "hbar": `\hbar`,
+	"lbrace": `\{`, "rbrace": `\}`, "vert": `|`, "dvert": `\|`,
+	// Large operators
+	"sum": `\sum`, "prod": `\prod`, "coprod": `\coprod`, "int": `\int`,
+	"dint": `\iint`, "tint": `\iiint`, "oint": `\oint`,
+	"union": `\bigcup`, "inter": `\bigcap`, "lim": `\lim`,
+	// Functions
+	"sin": `\sin`, "cos": `\cos`, "tan": `\tan`, "cot": `\cot`, "sec": `\sec`,
+	"csc": `\csc`, "arcsin": `\arcsin`, "arccos": `\arccos`, "arctan": `\arctan`,
+	"sinh": `\sinh`, "cosh": `\cosh`, "tanh": `\tanh`, "log": `\log`, "ln": `\ln`,
+	"exp": `\exp`, "max": `\max`, "min": `\min`, "det": `\det`, "gcd": `\gcd`,
+	"mod": `\bmod`,
+}
+
+// Multi-character operator tokens.
+var operators = map[string]string{
+	"<=": `\le`, ">=": `\ge`, "!=": `\ne`, "==": `\equiv`, "->": `\to`,
+	"<-": `\leftarrow`, "<->": `\leftrightarrow`, "=>": `\Rightarrow`,
+	"+-": `\pm`, "-+": `\mp`, "...": `\ldots`,
+}
+
+var greek = []string{
+	"alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta",
+	"iota", "kappa", "lambda", "mu", "nu", "xi", "omicron", "pi", "rho",
+	"sigma", "tau", "upsilon", "phi", "chi", "psi", "omega",
+}
+
+// Upper-case Greek letters LaTeX has commands for; the others look like
+// Latin capitals.
+var upperGreek = map[string]string{
+	"gamma": `\Gamma`, "delta": `\Delta`, "theta": `\Theta`, "lambda": `\Lambda`,
+	"xi": `\Xi`, "pi": `\Pi`, "sigma": `\Sigma`, "upsilon": `\Upsilon`,
+	"phi": `\Phi`, "psi": `\Psi`, "omega": `\Omega`,
+	"alpha": "A", "beta": "B", "epsilon": "E", "zeta": "Z", "eta": "H",
+	"iota": "I", "kappa": "K", "mu": "M", "nu": "N", "omicron": "O",
+	"rho": "P", "tau": "T", "chi": "X",
+}
+
+// Commands taking one argument.
+var unary = map[string]string{
+	"sqrt": `\sqrt`, "hat": `\hat`, "check": `\check`, "tilde": `\tilde`,
+	"acute": `\acute`, "grave": `\grave`, "dot": `\dot`, "ddot": `\ddot`,
+	"bar": `\overline`, "vec": `\vec`, "dyad": `\overleftrightarrow`,
+	"under": `\underline`, "arch": `\overparen`,
+	"rm": `\mathrm`, "it": `\mathit`, "bold": `\mathbf`,
+}
+
+// Commands taking a group of rows (#) and columns (&).
+var environments = map[string]string{
+	"matrix": "matrix", "pmatrix": "pmatrix", "bmatrix": "bmatrix",
+	"dmatrix": "vmatrix", "cases": "cases",
+	"pile": "matrix", "lpile": "matrix", "rpile": "matrix",
+}
+
+// LaTeX characters that must be escaped outside of commands.
+var latexSpecial = map[string]string{
+	"%": `\%`, "$": `\$`, `\`: `\backslash`,
+}
+
+type token struct {
+	text   string
+	quoted bool // a "..." string
+}
+
+// tokenize splits a script into words, numbers, quoted strings and
+// symbols. White space only separates tokens.
+func tokenize(script string) []token {
+	var tokens []token
+	runes := []rune(script)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			tokens = append(tokens, token{text: string(runes[i+1 : j]), quoted: true})
+			i = j + 1
+		case unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && unicode.IsLetter(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{text: string(runes[i:j])})
+			i = j
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{text: string(runes[i:j])})
+			i = j
+		default:
+			n := 1
+			for _, l := range []int{3, 2} {
+				if i+l <= len(runes) {
+					if _, ok := operators[string(runes[i:i+l])]; ok {
+						n = l
+						break
+					}
+				}
+			}
+			tokens = append(tokens, token{text: string(runes[i : i+n])})
+			i += n
+		}
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (token, bool) {
+	t, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return t, ok
+}
+
+// isWord reports whether t is the keyword kw, ignoring case.
+func isWord(t token, kw string) bool {
+	return !t.quoted && strings.EqualFold(t.text, kw)
+}
+
+// sequence translates tokens up to the closing token (or the end) into a
+// space-separated list of items, applying the infix over, sub/sup and
+// RIGHT delimiters to their neighbours.
+func (p *parser) sequence(closing string) string {
+	var items []string
+	last := func() string {
+		if len(items) == 0 {
+			return "{}"
+		}
+		item := items[len(items)-1]
+		items = items[:len(items)-1]
+		return item
+	}
+
+	for {
+		t, ok := p.peek()
+		if !ok || (!t.quoted && t.text == closing) {
+			break
+		}
+		switch {
+		case isWord(t, "over"):
+			p.pos++
+			num := last()
+			items = append(items, `\frac{`+unwrap(num)+"}{"+unwrap(p.item())+"}")
+		case !t.quoted && (t.text == "_" || t.text == "^" || isWord(t, "sub") || isWord(t, "sup")):
+			p.pos++
+			op := t.text
+			if isWord(t, "sub") {
+				op = "_"
+			} else if isWord(t, "sup") {
+				op = "^"
+			}
+			base := last()
+			items = append(items, base+op+"{"+unwrap(p.item())+"}")
+		default:
+			items = append(items, p.item())
+		}
+	}
+	return strings.Join(items, " ")
+}
+
+// item translates the next item: a group, a command with its arguments or
+// a single symbol. Groups are returned in braces.
+func (p *parser) item() string {
+	t, ok := p.next()
+	if !ok {
+		return "{}"
+	}
+	if t.quoted {
+		return `\text{` + t.text + "}"
+	}
+
+	switch t.text {
+	case "{":
+		inner := p.sequence("}")
+		p.next() // the closing brace
+		return "{" + inner + "}"
+	case "}":
+		return ""
+	case "~":
+		return `\ `
+	case "`":
+		return `\,`
+	case "&":
+		return "&"
+	case "#":
+		return `\\`
+	}
+	if s, ok := operators[t.text]; ok {
+		return s
+	}
+	if s, ok := latexSpecial[t.text]; ok {
+		return s
+	}
+
+	lower := strings.ToLower(t.text)
+	switch {
+	case isWord(t, "left") || isWord(t, "right"):
+		return `\` + lower + p.delimiter()
+	case isWord(t, "root"):
+		// root n of x
+		index := p.sequence("of")
+		if next, ok := p.peek(); ok && isWord(next, "of") {
+			p.pos++
+		}
+		return `\sqrt[` + index + "]{" + unwrap(p.item()) + "}"
+	case environments[lower] != "":
+		env := environments[lower]
+		body := unwrap(p.item())
+		return `\begin{` + env + "} " + body + ` \end{` + env + "}"
+	case unary[lower] != "":
+		return unary[lower] + "{" + unwrap(p.item()) + "}"
+	}
+
+	if letter, ok := greekLetter(t.text); ok {
+		return letter
+	}
+	if s, ok := symbols[lower]; ok {
+		return s
+	}
+	return t.text
+}
+
+// delimiter translates the delimiter following LEFT or RIGHT.
+func (p *parser) delimiter() string {
+	t, ok := p.next()
+	if !ok {
+		return "."
+	}
+	switch t.text {
+	case "{":
+		return `\{`
+	case "}":
+		return `\}`
+	case "(", ")", "[", "]", "|", ".", "<", ">":
+		if t.text == "<" {
+			return `\langle`
+		}
+		if t.text == ">" {
+			return `\rangle`
+		}
+		return t.text
+	}
+	if s, ok := symbols[strings.ToLower(t.text)]; ok {
+		return s
+	}
+	return t.text
+}
+
+// greekLetter translates the name of a Greek letter: lower case for the
+// small letter, upper case or capitalized for the capital.
+func greekLetter(word string) (string, bool) {
+	lower := strings.ToLower(word)
+	for _, name := range greek {
+		if lower != name {
+			continue
+		}
+		if word == lower {
+			return `\` + name, true
+		}
+		return upperGreek[name], true
+	}
+	return "", false
+}
+
+// unwrap removes the braces of a group used as a command argument.
+func unwrap(item string) string {
+	if strings.HasPrefix(item, "{") && strings.HasSuffix(item, "}") && balanced(item[1:len(item)-1]) {
+		return item[1 : len(item)-1]
+	}
+	return item
+}
+
+// balanced reports whether the braces of s, outside escapes, are balanced.
+func balanced(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
@@ -0,0 +1,35 @@
+package equation
+
+import "testing"
+
+func TestLaTeX(t *testing.T) {
+	tests := []struct {
+		script, want string
+	}{
+		{"a+b", "a + b"},
+		{"1 over 2", `\frac{1}{2}`},
+		{"{a+b} over {c-d}", `\frac{a + b}{c - d}`},
+		{"a+b over c", `a + \frac{b}{c}`},
+		{"x^2 + y _{i}", `x^{2} + y_{i}`},
+		{"x sup 2 sub k", `x^{2}_{k}`},
+		{"sqrt {b^2 -4ac}", `\sqrt{b^{2} - 4 ac}`},
+		{"root 3 of x", `\sqrt[3]{x}`},
+		{"sum _{i=1} ^{n} i", `\sum_{i = 1}^{n} i`},
+		{"lim _{x -> inf} {1 over x}", `\lim_{x \to \infty} {\frac{1}{x}}`},
+		{"alpha + BETA + Omega + pi", `\alpha + B + \Omega + \pi`},
+		{"LEFT ( a RIGHT )", `\left( a \right)`},
+		{"LEFT { a RIGHT .", `\left\{ a \right.`},
+		{"matrix{a & b # c & d}", `\begin{matrix} a & b \\ c & d \end{matrix}`},
+		{"dmatrix{1&0#0&1}", `\begin{vmatrix} 1 & 0 \\ 0 & 1 \end{vmatrix}`},
+		{"rm {kg} `m", `\mathrm{kg} \, m`},
+		{`"if" x <= 3`, `\text{if} x \le 3`},
+		{"vec a cdot hat b", `\vec{a} \cdot \hat{b}`},
+		{"100 %", `100 \%`},
+		{"f(x) = unknownword", `f ( x ) = unknownword`},
+	}
+	for _, tt := range tests {
+		if got := LaTeX(tt.script); got != tt.want {
+			t.Errorf("LaTeX(%q) = %q, want %q", tt.script, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package hwpv5
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/limits"
+	"github.com/richardlehane/mscfb"
+)
+
+// binDataItem returns the DocInfo item with the given ID. Without a DocInfo
+// record the ID is taken as the stream ID of an embedded stream.
+func (r *Reader) binDataItem(id uint16) BinDataItem {
+	if r.DocInfo != nil {
+		if item, err := r.DocInfo.BinDataItem(id); err == nil {
+			return item
+		}
+	}
+	return BinDataItem{Type: BinDataEmbedding, StreamID: id}
+}
+
+// binDataFile returns the suggested file name and the format (lowercase
+// extension) of a binary data item.
+func (r *Reader) binDataFile(id uint16) (name, format string) {
+	item := r.binDataItem(id)
+	if item.Type == BinDataLink {
+		link := item.LinkPath
+		if link == "" {
+			link = item.LinkRelPath
+		}
+		name = path.Base(strings.ReplaceAll(link, `\`, "/"))
+		if name == "." || name == "/" {
+			name = ""
+		}
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			format = strings.ToLower(name[i+1:])
+		}
+		return name, format
+	}
+	return path.Base(item.StreamName()), strings.ToLower(item.Extension)
+}
+
+// readBinData returns the decoded contents of a binary data item's stream.
+// Streams are named BIN%04X with the item's extension. A stream expected to
+// be compressed that does not inflate is returned as stored.
+func (r *Reader) readBinData(id uint16) ([]byte, error) {
+	item := r.binDataItem(id)
+	if item.Type == BinDataLink {
+		return nil, fmt.Errorf("BinData %d links to %s", id, item.LinkPath)
+	}
+
+	compressed := r.Header.Properties.Compressed()
+	switch item.Compression {
+	case binDataCompress:
+		compressed = true
+	case binDataNoCompress:
+		compressed = false
+	}
+
+	doc, err := mscfb.New(r.ra)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("BIN%04X.", item.StreamID)
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		if len(entry.Path) != 1 || entry.Path[0] != "BinData" || !strings.HasPrefix(strings.ToUpper(entry.Name), prefix) {
+			continue
+		}
+
+		name := "BinData/" + entry.Name
+		raw, err := io.ReadAll(limits.NewReader(doc, r.opts.MaxStreamSize, name))
+		if err != nil || !compressed {
+			return raw, err
+		}
+
+		fr := flate.NewReader(bytes.NewReader(raw))
+		defer fr.Close()
+		data, err := io.ReadAll(limits.NewReader(fr, r.opts.MaxStreamSize, name))
+		if errors.Is(err, limits.ErrExceeded) {
+			return nil, err
+		}
+		if err != nil {
+			return raw, nil
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("BinData %d not found", id)
+}
@@ -0,0 +1,373 @@
+package hwpv5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// Compound file constants (MS-CFB)
+const (
+	cfbHeaderSize    = 512
+	cfbEntrySize     = 128
+	cfbMiniSector    = 64
+	cfbMiniCutoff    = 4096
+	cfbHeaderDIFAT   = 109
+	cfbFreeSector    = 0xFFFFFFFF
+	cfbEndOfChain    = 0xFFFFFFFE
+	cfbFATSector     = 0xFFFFFFFD
+	cfbDIFATSector   = 0xFFFFFFFC
+	cfbNoStream      = 0xFFFFFFFF
+	cfbObjectStream  = 2
+	cfbObjectRoot    = 5
+	cfbWriteShift    = 9 // compound files are written with 512-byte sectors
+	cfbWriteSector   = 1 << cfbWriteShift
+	cfbSectorEntries = cfbWriteSector / 4
+)
+
+// compoundFile is an OLE compound file read whole so that it can be
+// written back with some streams changed. Directory entries are kept as
+// stored, so that names, class IDs, timestamps and the directory tree stay
+// as they were; only the location and size of the streams are rewritten.
+type compoundFile struct {
+	// entries holds the raw directory entries by entry ID
+	entries [][]byte
+	// paths holds the path of each entry, such as "BodyText/Section0";
+	// empty for the root and for entries outside the directory tree
+	paths []string
+	// data holds the data of each stream entry by entry ID
+	data map[int][]byte
+}
+
+// readCompoundFile reads the directory and every stream of a compound
+// file. Streams larger than maxSize, if positive, fail the read.
+func readCompoundFile(ra io.ReaderAt, maxSize int64) (*compoundFile, error) {
+	header := make([]byte, cfbHeaderSize)
+	if _, err := ra.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("read compound file header: %w", err)
+	}
+	if !bytes.HasPrefix(header, cfbSignature) {
+		return nil, errors.New("not a compound file")
+	}
+	le := binary.LittleEndian
+	shift := le.Uint16(header[30:])
+	if shift != 9 && shift != 12 {
+		return nil, fmt.Errorf("unsupported sector size 2^%d", shift)
+	}
+	sectorSize := 1 << shift
+	readSector := func(n uint32) ([]byte, error) {
+		sector := make([]byte, sectorSize)
+		// The last sector may be cut short at the end of the file
+		if read, err := ra.ReadAt(sector, int64(n+1)<<shift); err != nil && (err != io.EOF || read == 0) {
+			return nil, fmt.Errorf("read sector %d: %w", n, err)
+		}
+		return sector, nil
+	}
+
+	// The FAT sectors are listed in the header and the DIFAT chain
+	fatCount := le.Uint32(header[44:])
+	var fatSectors []uint32
+	for i := 0; i < cfbHeaderDIFAT && uint32(len(fatSectors)) < fatCount; i++ {
+		fatSectors = append(fatSectors, le.Uint32(header[76+4*i:]))
+	}
+	next := le.Uint32(header[68:])
+	for i := uint32(0); i < le.Uint32(header[72:]) && next < cfbEndOfChain && uint32(len(fatSectors)) < fatCount; i++ {
+		sector, err := readSector(next)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < sectorSize/4-1 && uint32(len(fatSectors)) < fatCount; j++ {
+			fatSectors = append(fatSectors, le.Uint32(sector[4*j:]))
+		}
+		next = le.Uint32(sector[sectorSize-4:])
+	}
+	var fat []uint32
+	seen := make(map[uint32]bool)
+	for _, n := range fatSectors {
+		if seen[n] {
+			return nil, fmt.Errorf("FAT sector %d listed twice", n)
+		}
+		seen[n] = true
+		sector, err := readSector(n)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < sectorSize; j += 4 {
+			fat = append(fat, le.Uint32(sector[j:]))
+		}
+	}
+
+	readChain := func(start uint32) ([]byte, error) {
+		var data []byte
+		for n, count := start, 0; n != cfbEndOfChain; count++ {
+			if int(n) >= len(fat) || count >= len(fat) {
+				return nil, fmt.Errorf("broken sector chain at sector %d", n)
+			}
+			sector, err := readSector(n)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, sector...)
+			n = fat[n]
+		}
+		return data, nil
+	}
+
+	dir, err := readChain(le.Uint32(header[48:]))
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+	file := &compoundFile{data: make(map[int][]byte)}
+	for i := 0; i+cfbEntrySize <= len(dir); i += cfbEntrySize {
+		file.entries = append(file.entries, dir[i:i+cfbEntrySize])
+	}
+	if len(file.entries) == 0 || file.entries[0][66] != cfbObjectRoot {
+		return nil, errors.New("compound file has no root entry")
+	}
+
+	var miniFAT []uint32
+	if le.Uint32(header[64:]) > 0 {
+		data, err := readChain(le.Uint32(header[60:]))
+		if err != nil {
+			return nil, fmt.Errorf("read mini FAT: %w", err)
+		}
+		for j := 0; j+4 <= len(data); j += 4 {
+			miniFAT = append(miniFAT, le.Uint32(data[j:]))
+		}
+	}
+	root := file.entries[0]
+	var miniStream []byte
+	if start := le.Uint32(root[116:]); start != cfbEndOfChain && start != cfbFreeSector {
+		if miniStream, err = readChain(start); err != nil {
+			return nil, fmt.Errorf("read mini stream: %w", err)
+		}
+	}
+
+	major := le.Uint16(header[26:])
+	cutoff := int64(le.Uint32(header[56:]))
+	for id, entry := range file.entries {
+		if entry[66] != cfbObjectStream {
+			continue
+		}
+		size := int64(le.Uint64(entry[120:]))
+		if major == 3 {
+			// Version 3 files may leave garbage in the high 32 bits
+			size &= 0xFFFFFFFF
+		}
+		if err := limits.Check("stream", size, maxSize, "bytes"); err != nil {
+			return nil, err
+		}
+		var data []byte
+		start := le.Uint32(entry[116:])
+		switch {
+		case size == 0:
+		case size < cutoff:
+			for n, count := start, 0; n != cfbEndOfChain && int64(len(data)) < size; count++ {
+				offset := int(n) * cfbMiniSector
+				if int(n) >= len(miniFAT) || count >= len(miniFAT) || offset+cfbMiniSector > len(miniStream) {
+					return nil, fmt.Errorf("broken mini sector chain at sector %d", n)
+				}
+				data = append(data, miniStream[offset:offset+cfbMiniSector]...)
+				n = miniFAT[n]
+			}
+		default:
+			if data, err = readChain(start); err != nil {
+				return nil, err
+			}
+		}
+		if int64(len(data)) < size {
+			return nil, fmt.Errorf("stream %d is shorter than its size", id)
+		}
+		file.data[id] = data[:size]
+	}
+
+	file.paths = make([]string, len(file.entries))
+	visited := make([]bool, len(file.entries))
+	var walk func(id uint32, prefix string)
+	walk = func(id uint32, prefix string) {
+		if int(id) >= len(file.entries) || visited[id] {
+			return
+		}
+		visited[id] = true
+		entry := file.entries[id]
+		file.paths[id] = prefix + entryName(entry)
+		walk(le.Uint32(entry[68:]), prefix)
+		walk(le.Uint32(entry[72:]), prefix)
+		walk(le.Uint32(entry[76:]), file.paths[id]+"/")
+	}
+	visited[0] = true
+	walk(le.Uint32(root[76:]), "")
+	return file, nil
+}
+
+// entryName decodes the UTF-16 name of a directory entry.
+func entryName(entry []byte) string {
+	size := min(int(binary.LittleEndian.Uint16(entry[64:])), 64)
+	units := make([]uint16, 0, size/2)
+	for i := 0; i+1 < size; i += 2 {
+		if u := binary.LittleEndian.Uint16(entry[i:]); u != 0 {
+			units = append(units, u)
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// writeTo writes the compound file as a version 3 file with 512-byte
+// sectors: the streams, the mini stream holding the streams smaller than
+// 4096 bytes, the mini FAT, the directory and the FAT, in that order.
+func (c *compoundFile) writeTo(w io.Writer) error {
+	le := binary.LittleEndian
+	var sectors []byte
+	var fat []uint32
+	// alloc appends data as a chain of sectors, returning its first sector
+	alloc := func(data []byte) uint32 {
+		if len(data) == 0 {
+			return cfbEndOfChain
+		}
+		first := uint32(len(fat))
+		n := (len(data) + cfbWriteSector - 1) / cfbWriteSector
+		for i := range n {
+			fat = append(fat, first+uint32(i)+1)
+		}
+		fat[len(fat)-1] = cfbEndOfChain
+		sectors = append(sectors, data...)
+		sectors = append(sectors, make([]byte, n*cfbWriteSector-len(data))...)
+		return first
+	}
+
+	entries := make([][]byte, len(c.entries))
+	var miniStream []byte
+	var miniFAT []uint32
+	for id, entry := range c.entries {
+		entries[id] = bytes.Clone(entry)
+		if entry[66] != cfbObjectStream {
+			continue
+		}
+		data := c.data[id]
+		start := uint32(cfbEndOfChain)
+		switch {
+		case len(data) == 0:
+		case len(data) < cfbMiniCutoff:
+			start = uint32(len(miniFAT))
+			n := (len(data) + cfbMiniSector - 1) / cfbMiniSector
+			for i := range n {
+				miniFAT = append(miniFAT, start+uint32(i)+1)
+			}
+			miniFAT[len(miniFAT)-1] = cfbEndOfChain
+			miniStream = append(miniStream, data...)
+			miniStream = append(miniStream, make([]byte, n*cfbMiniSector-len(data))...)
+		default:
+			start = alloc(data)
+		}
+		le.PutUint32(entries[id][116:], start)
+		le.PutUint64(entries[id][120:], uint64(len(data)))
+	}
+	le.PutUint32(entries[0][116:], alloc(miniStream))
+	le.PutUint64(entries[0][120:], uint64(len(miniStream)))
+
+	var miniFATData []byte
+	for _, n := range miniFAT {
+		miniFATData = le.AppendUint32(miniFATData, n)
+	}
+	for len(miniFATData)%cfbWriteSector != 0 {
+		miniFATData = le.AppendUint32(miniFATData, cfbFreeSector)
+	}
+	miniFATStart := alloc(miniFATData)
+
+	// Unused directory entries have no siblings or child
+	var dir []byte
+	for _, entry := range entries {
+		dir = append(dir, entry...)
+	}
+	for len(dir)%cfbWriteSector != 0 {
+		empty := make([]byte, cfbEntrySize)
+		le.PutUint32(empty[68:], cfbNoStream)
+		le.PutUint32(empty[72:], cfbNoStream)
+		le.PutUint32(empty[76:], cfbNoStream)
+		dir = append(dir, empty...)
+	}
+	dirStart := alloc(dir)
+
+	// The FAT covers the sectors so far and its own and the DIFAT sectors
+	fatCount, difatCount := 0, 0
+	for {
+		fatCount++
+		difatCount = 0
+		if fatCount > cfbHeaderDIFAT {
+			difatCount = (fatCount - cfbHeaderDIFAT + cfbSectorEntries - 2) / (cfbSectorEntries - 1)
+		}
+		if len(fat)+fatCount+difatCount <= fatCount*cfbSectorEntries {
+			break
+		}
+	}
+	fatStart := uint32(len(fat))
+	for range fatCount {
+		fat = append(fat, cfbFATSector)
+	}
+	difatStart := uint32(len(fat))
+	for range difatCount {
+		fat = append(fat, cfbDIFATSector)
+	}
+	for len(fat) < fatCount*cfbSectorEntries {
+		fat = append(fat, cfbFreeSector)
+	}
+
+	header := make([]byte, cfbHeaderSize)
+	copy(header, cfbSignature)
+	le.PutUint16(header[24:], 0x3E)
+	le.PutUint16(header[26:], 3)
+	le.PutUint16(header[28:], 0xFFFE)
+	le.PutUint16(header[30:], cfbWriteShift)
+	le.PutUint16(header[32:], 6)
+	le.PutUint32(header[44:], uint32(fatCount))
+	le.PutUint32(header[48:], dirStart)
+	le.PutUint32(header[56:], cfbMiniCutoff)
+	le.PutUint32(header[60:], miniFATStart)
+	le.PutUint32(header[64:], uint32(len(miniFATData)/cfbWriteSector))
+	le.PutUint32(header[68:], cfbEndOfChain)
+	if difatCount > 0 {
+		le.PutUint32(header[68:], difatStart)
+	}
+	le.PutUint32(header[72:], uint32(difatCount))
+	var difat []byte
+	for i := range fatCount {
+		if i < cfbHeaderDIFAT {
+			le.PutUint32(header[76+4*i:], fatStart+uint32(i))
+			continue
+		}
+		difat = le.AppendUint32(difat, fatStart+uint32(i))
+		// Each DIFAT sector ends with the next one
+		if len(difat)%cfbWriteSector == cfbWriteSector-4 {
+			next := difatStart + uint32(len(difat)/cfbWriteSector) + 1
+			if i == fatCount-1 {
+				next = cfbEndOfChain
+			}
+			difat = le.AppendUint32(difat, next)
+		}
+	}
+	for i := fatCount; i < cfbHeaderDIFAT; i++ {
+		le.PutUint32(header[76+4*i:], cfbFreeSector)
+	}
+	if len(difat)%cfbWriteSector != 0 {
+		for len(difat)%cfbWriteSector != cfbWriteSector-4 {
+			difat = le.AppendUint32(difat, cfbFreeSector)
+		}
+		difat = le.AppendUint32(difat, cfbEndOfChain)
+	}
+
+	fatData := make([]byte, 0, len(fat)*4)
+	for _, n := range fat {
+		fatData = le.AppendUint32(fatData, n)
+	}
+	for _, part := range [][]byte{header, sectors, fatData, difat} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
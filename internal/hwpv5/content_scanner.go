@@ -1,32 +1,518 @@
 package hwpv5
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"unicode/utf16"
 
 	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/equation"
+	"github.com/hanpama/hwp/internal/limits"
 )
 
 // ContentScanner implements document.ContentNodeScanner using a state machine approach.
 // It converts flat record stream into hierarchical content nodes.
 type ContentScanner struct {
 	reader         *Reader
+	opts           document.ScanOptions
 	currentSection int
 	scanner        *RecScanner
 	sectionCloser  io.Closer
 
 	// Single-record lookahead buffer (needed for skipChildren and table-end detection)
 	bufferedRec Rec
+	bufferedIdx int
+	bufferedOff int64
 	hasBuffered bool
 
+	// Index of the last returned record within its section stream, and the
+	// number of records read from the section so far; used for node IDs
+	recIndex int
+	recCount int
+	// Stream offset of the last returned record, for checkpoints, and its
+	// tag, for errors
+	recOffset int64
+	recTag    uint16
+
+	// Nodes completed but not yet returned (one paragraph record can yield several)
+	pending []document.ContentNode
+
+	// Content of an embedded document, returned after pending nodes
+	embedded document.ContentNodeScanner
+
 	// State machine fields
-	currentPara  *paragraphBuilder
+	currentPara *paragraphBuilder
+	// currentTable is the innermost open table; tables nested in cells
+	// link to the table they are nested in
 	currentTable *tableBuilder
-	tableLevel   uint16 // Level at which table started
+	tableLevel   uint16 // Level of the innermost table control
+	inTableCtrl  bool   // Between a table control and the end of its table
+	tableID      string // Node ID of the innermost table control
+
+	// Caption paragraphs of the current table, collected while inCaption is set
+	inCaption    bool
+	captionTexts []string
+
+	// refErr is the first reference to a missing DocInfo item
+	refErr error
+
+	// listNumbers holds the current numbers of the levels of each
+	// numbering, by ID
+	listNumbers map[uint16][]int
+	// footnotes and endnotes count the notes so far, which number notes
+	// whose control does not
+	footnotes, endnotes int
+	// lastParaID is the ID of the last body paragraph finished, which
+	// notes refer to; it is empty after table cell paragraphs
+	lastParaID string
+	// memoSpans holds the spans of body text that the memos of the section
+	// are on, by memo index, until the memos are read
+	memoSpans map[uint32]document.Comment
 }
 
 type paragraphBuilder struct {
 	textParts []string
+	// partPos holds the WCHAR position of each text part
+	partPos []uint32
+	// runStyles holds the formatting of the runs marked in the text
+	runStyles []document.Run
+	// monospace is set when every character shape of the paragraph is fixed-pitch
+	monospace bool
+	layout    *document.Layout
+	tabStops  []document.TabStop
+	// outline is the outline level of the paragraph's style or shape, 0 for
+	// body text
+	outline   int
+	shapeID   uint16
+	bookmarks []string
+	id        string
+	// openFields holds the control IDs of the fields started but not ended;
+	// fieldNames and links hold the click-here field names and hyperlink
+	// targets and titles from their controls, and memos the indexes of the
+	// memos of memo fields, in text order
+	openFields []uint32
+	fieldNames []string
+	links      []document.Hyperlink
+	memos      []uint32
+	// forms holds the fields of form objects in the paragraph
+	forms []document.Field
+	// resume is the position of a top-level paragraph's header record
+	resume *document.Checkpoint
+}
+
+// autoNumberMark stands in for an auto-number until its control record,
+// which carries the number, has been read. It cannot occur in decoded text.
+const autoNumberMark = "\x00"
+
+// pageControlMark likewise stands in for a page control.
+const pageControlMark = "\x05"
+
+// runMark starts a run of text in the next character shape, and splitMark
+// ends a paragraph text at a ParaBreak element when ScanOptions.SplitOnParaBreak
+// is set.
+const (
+	runMark   = '\x06'
+	splitMark = "\x07"
+)
+
+// Click-here fields and hyperlinks are delimited by marks until the
+// paragraph is finished and their controls, which carry the names and
+// targets, have been read.
+const (
+	fieldStartMark = '\x01'
+	fieldEndMark   = '\x02'
+	linkStartMark  = '\x03'
+	linkEndMark    = '\x04'
+	memoStartMark  = '\x0e'
+	memoEndMark    = '\x0f'
+)
+
+// Field control IDs
+const (
+	ctrlIDClickHere = 0x25636c6b // MAKE_4CHID('%','c','l','k'), click-here field (누름틀)
+	ctrlIDHyperlink = 0x25686c6b // MAKE_4CHID('%','h','l','k'), hyperlink
+	ctrlIDMemo      = 0x25256d65 // MAKE_4CHID('%','%','m','e'), memo (메모)
+)
+
+// fieldMarks returns the start and end marks of fields with the given
+// control ID, or false for fields whose extent is not kept.
+func fieldMarks(ctrlID uint32) (start, end rune, ok bool) {
+	switch ctrlID {
+	case ctrlIDClickHere:
+		return fieldStartMark, fieldEndMark, true
+	case ctrlIDHyperlink:
+		return linkStartMark, linkEndMark, true
+	case ctrlIDMemo:
+		return memoStartMark, memoEndMark, true
+	}
+	return 0, 0, false
+}
+
+// addText appends the text elements of a ParaText record.
+func (b *paragraphBuilder) addText(els []ParaTextElement, splitOnParaBreak bool) {
+	for _, el := range els {
+		part := ""
+		switch elem := el.(type) {
+		case ParaTextString:
+			part = elem.Value
+		case ParaTextLineBreak:
+			part = "\n"
+		case ParaTextTab:
+			part = "\t"
+		case ParaTextAutoNumber:
+			part = autoNumberMark
+		case ParaTextPageControl:
+			part = pageControlMark
+		case ParaTextFieldStart:
+			b.openFields = append(b.openFields, elem.CtrlID)
+			if start, _, ok := fieldMarks(elem.CtrlID); ok {
+				part = string(start)
+			}
+		case ParaTextFieldEnd:
+			if n := len(b.openFields); n > 0 {
+				if _, end, ok := fieldMarks(b.openFields[n-1]); ok {
+					part = string(end)
+				}
+				b.openFields = b.openFields[:n-1]
+			}
+		case ParaTextParaBreak:
+			if splitOnParaBreak {
+				part = splitMark
+			}
+		}
+		if part != "" {
+			b.textParts = append(b.textParts, part)
+			b.partPos = append(b.partPos, el.Position())
+		}
+	}
+}
+
+// setRuns marks the start of each character shape run in the text, given
+// the formatting of a character shape. Text strings hold one rune per
+// WCHAR, so runs starting within them split them.
+func (b *paragraphBuilder) setRuns(runs []CharShapeRun, style func(shapeID uint32) document.Run) {
+	positions := make([]uint32, len(runs))
+	for i, run := range runs {
+		positions[i] = run.Pos
+	}
+	b.insertAt(positions, func(i int, _ string) string {
+		b.runStyles = append(b.runStyles, style(runs[i].ShapeID))
+		return string(runMark)
+	})
+}
+
+// setLineBreaks breaks the text where the lines of its layout start, unless
+// a line break already ends the previous line.
+func (b *paragraphBuilder) setLineBreaks(segs []LineSeg) {
+	var positions []uint32
+	for _, seg := range segs {
+		if seg.TextStart > 0 {
+			positions = append(positions, seg.TextStart)
+		}
+	}
+	b.insertAt(positions, func(_ int, before string) string {
+		if strings.HasSuffix(before, "\n") {
+			return ""
+		}
+		return "\n"
+	})
+}
+
+// insertAt inserts the text mark(i, before) before the text at each of the
+// ascending WCHAR positions, where before is the text preceding the
+// position. Positions within text strings split them; characters outside
+// the BMP take two WCHARs.
+func (b *paragraphBuilder) insertAt(positions []uint32, mark func(i int, before string) string) {
+	var parts []string
+	var partPos []uint32
+	var before string
+	p := 0
+	for i, part := range b.textParts {
+		start := b.partPos[i]
+		runes := []rune(part)
+		for len(runes) > 0 {
+			for p < len(positions) && positions[p] <= start {
+				if text := mark(p, before); text != "" {
+					parts, partPos = append(parts, text), append(partPos, start)
+				}
+				p++
+			}
+			// Take the runes up to the next position; a position within a
+			// surrogate pair splits after it
+			n, end := 0, start
+			for n < len(runes) && (p == len(positions) || end < positions[p]) {
+				end += uint32(utf16.RuneLen(runes[n]))
+				n++
+			}
+			if text := string(runes[:n]); text != string(runMark) {
+				before = text
+			}
+			parts, partPos = append(parts, string(runes[:n])), append(partPos, start)
+			runes, start = runes[n:], end
+		}
+	}
+	b.textParts, b.partPos = parts, partPos
+}
+
+// setAutoNumber replaces the first unresolved auto-number with the number
+// stored in an auto-number control ('atno': ctrl ID, property, WORD number,
+// user symbol, prefix and suffix WCHARs), written in its number format
+// (property bits 4-11). Page numbers, which depend on the
+// layout, become the page number placeholder instead, or are dropped when
+// it is empty.
+func (b *paragraphBuilder) setAutoNumber(data []byte, pageNumber string) {
+	if len(data) < 10 {
+		return
+	}
+	property := binary.LittleEndian.Uint32(data[4:])
+	var user rune
+	if len(data) >= 12 {
+		user = rune(binary.LittleEndian.Uint16(data[10:]))
+	}
+	number := FormatNumber(uint8(property>>4), int(binary.LittleEndian.Uint16(data[8:])), user)
+	if property&0xf == autoNumberPage {
+		number = pageNumber
+	}
+	if number != "" {
+		number = decorate(number, data, 12)
+	}
+	b.resolveMark(autoNumberMark, number)
+}
+
+// Page control IDs
+const (
+	ctrlIDPageHiding  = 0x70676864 // MAKE_4CHID('p','g','h','d'), hide header, footer, ... on a page
+	ctrlIDNewNumber   = 0x6e776e6f // MAKE_4CHID('n','w','n','o'), start numbering anew
+	ctrlIDPageNumCtrl = 0x70676374 // MAKE_4CHID('p','g','c','t'), odd/even page adjustment
+	ctrlIDPageNumPos  = 0x70676e70 // MAKE_4CHID('p','g','n','p'), page number position
+)
+
+const (
+	// autoNumberPage is the number type of page numbers in auto-number
+	// controls (property bits 0-3)
+	autoNumberPage = 0
+	// pageNumPosMask selects the position of a page number position
+	// control (property bits 8-11), which is 0 for hidden page numbers
+	pageNumPosMask = 0xf00
+)
+
+// setPageControl resolves the first unresolved page control. A page number
+// position control ('pgnp': ctrl ID, property, user symbol, prefix and
+// suffix WCHARs) that shows page numbers becomes the page number
+// placeholder with its decoration; other page controls affect only the
+// layout and leave no text.
+func (b *paragraphBuilder) setPageControl(ctrlID uint32, data []byte, pageNumber string) {
+	text := ""
+	if ctrlID == ctrlIDPageNumPos && len(data) >= 8 && pageNumber != "" {
+		if binary.LittleEndian.Uint32(data[4:])&pageNumPosMask != 0 {
+			text = decorate(pageNumber, data, 10)
+		}
+	}
+	b.resolveMark(pageControlMark, text)
+}
+
+// decorate adds the prefix and suffix WCHARs at offset off of data, if set,
+// around a number. Records too short to hold them leave the number as is.
+func decorate(number string, data []byte, off int) string {
+	if len(data) >= off+4 {
+		if prefix := binary.LittleEndian.Uint16(data[off:]); prefix != 0 {
+			number = string(rune(prefix)) + number
+		}
+		if suffix := binary.LittleEndian.Uint16(data[off+2:]); suffix != 0 {
+			number += string(rune(suffix))
+		}
+	}
+	return number
+}
+
+// resolveMark replaces the first occurrence of mark in the paragraph text.
+func (b *paragraphBuilder) resolveMark(mark, text string) {
+	for i, part := range b.textParts {
+		if strings.Contains(part, mark) {
+			b.textParts[i] = strings.Replace(part, mark, text, 1)
+			return
+		}
+	}
+}
+
+// paraText is a paragraph text with the fields, hyperlinks and formatting
+// runs within it.
+type paraText struct {
+	text   string
+	fields []document.Field
+	links  []document.Hyperlink
+	runs   []document.Run
+	memos  []memoSpan
+}
+
+// memoSpan is the span of text of a memo field, with the index of its memo.
+type memoSpan struct {
+	index  uint32
+	offset int
+	quote  string
+}
+
+// memoUnknown is the memo index of memo fields whose control gives none.
+const memoUnknown = ^uint32(0)
+
+// texts returns the paragraph texts collected by the builder.
+func (b *paragraphBuilder) texts() []string {
+	var texts []string
+	for _, t := range b.paraTexts() {
+		texts = append(texts, t.text)
+	}
+	return texts
+}
+
+// paraTexts returns the paragraph texts with their click-here fields,
+// hyperlinks and formatting runs. A field's value, or a link's text, is the
+// text between its start and end.
+func (b *paragraphBuilder) paraTexts() []paraText {
+	texts := strings.Split(joinTextParts(b.textParts), splitMark)
+	if n := len(texts); n > 1 && texts[n-1] == "" {
+		// The last ParaBreak terminated the paragraph
+		texts = texts[:n-1]
+	}
+
+	result := make([]paraText, len(texts))
+	fieldCount, linkCount, memoCount, runCount := 0, 0, 0, 0
+	// style is the formatting of the run in effect, which continues into
+	// the next text
+	var style *document.Run
+	for i, text := range texts {
+		text = strings.ReplaceAll(text, autoNumberMark, "")
+		text = strings.ReplaceAll(text, pageControlMark, "")
+		if style == nil && !strings.ContainsAny(text, string([]rune{fieldStartMark, fieldEndMark, linkStartMark, linkEndMark, memoStartMark, memoEndMark, runMark})) {
+			result[i].text = text
+			continue
+		}
+
+		pt := &result[i]
+		var out strings.Builder
+		var open, starts, openMemos []int
+		link := -1
+		if style != nil {
+			pt.runs = append(pt.runs, *style)
+		}
+		for _, r := range text {
+			switch r {
+			case runMark:
+				style = &document.Run{}
+				if runCount < len(b.runStyles) {
+					style = &b.runStyles[runCount]
+				}
+				runCount++
+				run := *style
+				run.Offset = out.Len()
+				pt.runs = append(pt.runs, run)
+			case fieldStartMark:
+				field := document.Field{Type: document.FieldClickHere}
+				if fieldCount < len(b.fieldNames) {
+					field.Name = b.fieldNames[fieldCount]
+				}
+				fieldCount++
+				open = append(open, len(pt.fields))
+				starts = append(starts, out.Len())
+				pt.fields = append(pt.fields, field)
+			case fieldEndMark:
+				if n := len(open); n > 0 {
+					pt.fields[open[n-1]].Value = out.String()[starts[n-1]:]
+					open, starts = open[:n-1], starts[:n-1]
+				}
+			case linkStartMark:
+				var hl document.Hyperlink
+				if linkCount < len(b.links) {
+					hl = b.links[linkCount]
+				}
+				hl.Offset = out.Len()
+				linkCount++
+				link = len(pt.links)
+				pt.links = append(pt.links, hl)
+			case linkEndMark:
+				if link >= 0 {
+					pt.links[link].Text = out.String()[pt.links[link].Offset:]
+					link = -1
+				}
+			case memoStartMark:
+				m := memoSpan{offset: out.Len(), index: memoUnknown}
+				if memoCount < len(b.memos) {
+					m.index = b.memos[memoCount]
+				}
+				memoCount++
+				openMemos = append(openMemos, len(pt.memos))
+				pt.memos = append(pt.memos, m)
+			case memoEndMark:
+				if n := len(openMemos); n > 0 {
+					m := &pt.memos[openMemos[n-1]]
+					m.quote = out.String()[m.offset:]
+					openMemos = openMemos[:n-1]
+				}
+			default:
+				out.WriteRune(r)
+			}
+		}
+		// Fields and links left open end with the text
+		for n := range open {
+			pt.fields[open[n]].Value = out.String()[starts[n]:]
+		}
+		if link >= 0 {
+			pt.links[link].Text = out.String()[pt.links[link].Offset:]
+		}
+		for _, i := range openMemos {
+			pt.memos[i].quote = out.String()[pt.memos[i].offset:]
+		}
+		pt.text = out.String()
+		pt.runs = finishRuns(pt.text, pt.runs)
+	}
+	// Form objects go with the first text, like bookmarks
+	result[0].fields = append(result[0].fields, b.forms...)
+	return result
+}
+
+// finishRuns sets the text of runs, given their offsets in text, merging
+// runs of the same formatting. Runs are dropped if none has emphasis.
+func finishRuns(text string, runs []document.Run) []document.Run {
+	var result []document.Run
+	emphasis := false
+	for i := range runs {
+		end := len(text)
+		if i+1 < len(runs) {
+			end = runs[i+1].Offset
+		}
+		run := runs[i]
+		if run.Text = text[run.Offset:end]; run.Text == "" {
+			continue
+		}
+		emphasis = emphasis || run.Emphasized()
+		if n := len(result) - 1; n >= 0 && result[n].SameFormat(&run) {
+			result[n].Text += run.Text
+			continue
+		}
+		result = append(result, run)
+	}
+	if !emphasis {
+		return nil
+	}
+	return result
+}
+
+// setFieldName records the name of the next click-here field from its
+// control: the name in the CtrlData parameter set, or else the guide text
+// shown in the empty field.
+func (b *paragraphBuilder) setFieldName(name string, ctrl []byte) {
+	if name == "" {
+		name = clickHereDirection(ctrl)
+	}
+	b.fieldNames = append(b.fieldNames, name)
+}
+
+// setLink records the target of the next hyperlink from its control, and
+// its title from the CtrlData parameter set.
+func (b *paragraphBuilder) setLink(ctrl []byte, title string) {
+	b.links = append(b.links, document.Hyperlink{URL: hyperlinkURL(ctrl), Title: title})
 }
 
 type tableBuilder struct {
@@ -35,20 +521,37 @@ type tableBuilder struct {
 	cells       []document.Cell
 	currentCell *document.Cell
 	tableLevel  uint16 // Level at which table started
+	caption     string
+	id          string
+	// parent is the table in whose current cell this table is nested
+	parent *tableBuilder
+	// borderless is cleared by the first cell with a border or an unknown
+	// border fill
+	borderless bool
+	// header is set when the first row repeats as a header on each page
+	header bool
 }
 
 // Open opens an HWP 5.0 file and returns a ContentNodeScanner
-func Open(file io.ReaderAt) (document.ContentNodeScanner, error) {
-	reader, err := OpenReader(file)
+func Open(file io.ReaderAt, opts document.ScanOptions) (document.ContentNodeScanner, error) {
+	reader, err := OpenReader(file, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open HWP reader: %w", err)
 	}
 
 	scanner := &ContentScanner{
 		reader:         reader,
+		opts:           opts,
 		currentSection: -1,
 	}
 
+	if cp := opts.Resume; cp != nil {
+		if err := scanner.resume(*cp); err != nil {
+			return nil, err
+		}
+		return scanner, nil
+	}
+
 	if err := scanner.advanceSection(); err != nil {
 		return nil, err
 	}
@@ -56,6 +559,54 @@ func Open(file io.ReaderAt) (document.ContentNodeScanner, error) {
 	return scanner, nil
 }
 
+// resume positions the scanner at a checkpoint. Earlier sections are not
+// opened; earlier records of the section are decoded but not parsed.
+func (s *ContentScanner) resume(cp document.Checkpoint) error {
+	if cp.Section < 0 || cp.Section >= s.reader.SectionCount() || cp.Offset < 0 || cp.Record < 0 {
+		return fmt.Errorf("invalid checkpoint %+v", cp)
+	}
+	s.currentSection = cp.Section - 1
+	if err := s.advanceSection(); err != nil {
+		return err
+	}
+	return s.seek(cp)
+}
+
+// seek skips the current section stream forward to a checkpoint's record.
+func (s *ContentScanner) seek(cp document.Checkpoint) error {
+	if _, err := io.CopyN(io.Discard, s.scanner.r, cp.Offset-s.scanner.offset); err != nil {
+		return fmt.Errorf("checkpoint beyond end of section %d: %w", cp.Section, err)
+	}
+	s.scanner.offset = cp.Offset
+	s.scanner.count = cp.Record
+	s.recCount = cp.Record
+	return nil
+}
+
+// checkpoint returns the position after the nodes returned so far. It fails
+// while a node is partly returned or a table or other control is open.
+func (s *ContentScanner) checkpoint() (document.Checkpoint, bool) {
+	if len(s.pending) > 0 || s.embedded != nil || s.currentTable != nil || s.inTableCtrl ||
+		s.inCaption || len(s.captionTexts) > 0 || s.currentSection >= s.reader.SectionCount() {
+		return document.Checkpoint{}, false
+	}
+
+	cp := document.Checkpoint{Section: s.currentSection}
+	switch {
+	case s.currentPara != nil:
+		// The open paragraph is read again
+		if s.currentPara.resume == nil {
+			return document.Checkpoint{}, false
+		}
+		cp = *s.currentPara.resume
+	case s.hasBuffered:
+		cp.Offset, cp.Record = s.bufferedOff, s.bufferedIdx
+	default:
+		cp.Offset, cp.Record = s.scanner.Offset(), s.recCount
+	}
+	return cp, true
+}
+
 func (s *ContentScanner) advanceSection() error {
 	if s.sectionCloser != nil {
 		s.sectionCloser.Close()
@@ -70,23 +621,64 @@ func (s *ContentScanner) advanceSection() error {
 
 	sectionReader, err := s.reader.OpenSection(s.currentSection)
 	if err != nil {
-		return fmt.Errorf("failed to open section %d: %w", s.currentSection, err)
+		err = fmt.Errorf("failed to open section %d: %w", s.currentSection, err)
+		if s.opts.Recover {
+			s.opts.Warn(fmt.Errorf("skipped section: %w", err))
+			return s.advanceSection()
+		}
+		return err
 	}
 
 	s.sectionCloser = sectionReader
-	s.scanner = NewRecScanner(sectionReader)
+	s.scanner = s.reader.newRecScanner(s.reader.SectionName(s.currentSection), sectionReader)
+	s.recCount = 0
 	return nil
 }
 
+// nodeID returns the ID of a node starting at the last returned record.
+func (s *ContentScanner) nodeID() string {
+	return fmt.Sprintf("s%d.r%d", s.currentSection, s.recIndex)
+}
+
 // Next returns the next content node using state machine pattern
 func (s *ContentScanner) Next() (document.ContentNode, error) {
+	if s.opts.OnCheckpoint != nil {
+		if cp, ok := s.checkpoint(); ok {
+			s.opts.OnCheckpoint(cp)
+		}
+	}
+
 	for {
+		if s.opts.StrictReferences && s.refErr != nil {
+			return nil, s.refErr
+		}
+		if len(s.pending) > 0 {
+			node := s.pending[0]
+			s.pending = s.pending[1:]
+			return node, nil
+		}
+		if s.embedded != nil {
+			node, err := s.embedded.Next()
+			if err != io.EOF {
+				return node, err
+			}
+			s.embedded = nil
+		}
+
 		rec, err := s.nextRecord()
+		if len(s.pending) > 0 && (err == nil || err == io.EOF) {
+			// A section end flushed a paragraph; return it before this record
+			if err == nil {
+				s.putBack(rec)
+			}
+			continue
+		}
 		if err != nil {
 			// If EOF and we have a table in progress, return it first
-			if s.currentTable != nil {
-				table := s.finishTable()
-				return table, nil
+			for s.currentTable != nil {
+				if table := s.finishTable(); table != nil {
+					return table, nil
+				}
 			}
 			return nil, err
 		}
@@ -94,83 +686,279 @@ func (s *ContentScanner) Next() (document.ContentNode, error) {
 		// Check if we're in a table and the level has dropped to or below table level
 		// This means the table has ended
 		if s.currentTable != nil && rec.Lvl() <= s.currentTable.tableLevel {
-			table := s.finishTable()
+			if table := s.finishTable(); table != nil {
+				s.pending = append(s.pending, table)
+			}
 			// Put this record back in buffer to process in next iteration
 			s.putBack(rec)
-			return table, nil
+			continue
 		}
 
 		switch r := rec.(type) {
 		case RecParaHeader:
+			// Some documents omit CharShape/LineSeg records, so a previous
+			// paragraph may still be open
+			s.finishParagraph()
+			// A page break at the start of a section goes with the section
+			if s.opts.Breaks && r.PageBreak() && r.Lvl() == 0 && s.recIndex > 0 && s.currentTable == nil {
+				s.pending = append(s.pending, &document.PageBreak{ID: s.nodeID() + ".pb"})
+			}
 			// Start new paragraph
-			s.currentPara = &paragraphBuilder{
-				textParts: make([]string, 0),
+			s.currentPara = &paragraphBuilder{id: s.nodeID(), layout: s.layout(r.ParaShapeID), outline: s.outlineLevel(r.StyleID, r.ParaShapeID), shapeID: r.ParaShapeID}
+			s.currentPara.tabStops = s.tabStops(r.ParaShapeID)
+			if r.Lvl() == 0 {
+				s.currentPara.resume = &document.Checkpoint{Section: s.currentSection, Offset: s.recOffset, Record: s.recIndex}
 			}
 
 		case RecParaText:
 			// Add text to current paragraph
 			if s.currentPara != nil {
-				for _, el := range r.Els {
-					switch elem := el.(type) {
-					case ParaTextString:
-						s.currentPara.textParts = append(s.currentPara.textParts, elem.Value)
-					case ParaTextLineBreak:
-						s.currentPara.textParts = append(s.currentPara.textParts, "\n")
-					case ParaTextTab:
-						s.currentPara.textParts = append(s.currentPara.textParts, "\t")
-					}
-				}
+				s.currentPara.addText(r.Els, s.opts.SplitOnParaBreak)
 			}
 
-		case RecParaCharShape, RecParaLineSeg:
-			// Paragraph complete (these records mark end of paragraph)
+		case RecMemoList:
+			// The memos of a section follow its body text, each with its
+			// paragraph list
+			s.finishParagraph()
+			comment := s.memoSpans[r.Index]
+			comment.ID = s.nodeID()
+			obj, err := s.readObject(r.Lvl())
+			if err != nil {
+				return nil, err
+			}
+			comment.Text = obj.caption
+			s.pending = append(s.pending, &comment)
+
+		case RecParaLineSeg:
+			if s.currentPara != nil && s.opts.LayoutLineBreaks {
+				s.currentPara.setLineBreaks(r.Segs)
+			}
+
+		case RecParaCharShape:
+			// The paragraph stays open: its controls (bookmarks, tables, ...)
+			// follow as CtrlHeader records
 			if s.currentPara != nil {
-				text := joinTextParts(s.currentPara.textParts)
-				s.currentPara = nil
+				s.currentPara.monospace = s.monospaceRuns(r.Runs)
+				s.currentPara.setRuns(r.Runs, s.runStyle)
+			}
 
-				if s.currentTable != nil && s.currentTable.currentCell != nil {
-					// Inside table: add to current cell
-					if s.currentTable.currentCell.Text != "" {
-						s.currentTable.currentCell.Text += "\n"
-					}
-					s.currentTable.currentCell.Text += text
-				} else {
-					// Regular paragraph: return it
-					return &document.Paragraph{Text: text}, nil
+		case RecCtrlHeader:
+			// Inline controls belong to the open paragraph
+			switch r.CtrlID {
+			case ctrlIDBookmark:
+				name, err := s.readCtrlDataString(r.Lvl())
+				if err != nil {
+					return nil, err
 				}
+				if s.currentPara != nil && name != "" {
+					s.currentPara.bookmarks = append(s.currentPara.bookmarks, name)
+				}
+				continue
+			case ctrlIDClickHere:
+				name, err := s.readCtrlDataString(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				if s.currentPara != nil {
+					s.currentPara.setFieldName(name, r.Data)
+				}
+				continue
+			case ctrlIDForm:
+				field, ok, err := s.readFormObject(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				if ok && s.currentPara != nil {
+					s.currentPara.forms = append(s.currentPara.forms, field)
+				}
+				continue
+			case ctrlIDHyperlink:
+				title, err := s.readCtrlDataString(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				if s.currentPara != nil {
+					s.currentPara.setLink(r.Data, title)
+				}
+				continue
+			case ctrlIDMemo:
+				index, ok := memoIndex(r.Data)
+				if !ok {
+					index = memoUnknown
+				}
+				if s.currentPara != nil {
+					s.currentPara.memos = append(s.currentPara.memos, index)
+				}
+				if err := s.skipChildren(r.Lvl()); err != nil {
+					return nil, err
+				}
+				continue
+			case ctrlIDAutoNumber:
+				if s.currentPara != nil {
+					s.currentPara.setAutoNumber(r.Data, s.opts.PageNumber)
+				}
+				if err := s.skipChildren(r.Lvl()); err != nil {
+					return nil, err
+				}
+				continue
+			case ctrlIDPageHiding, ctrlIDNewNumber, ctrlIDPageNumCtrl, ctrlIDPageNumPos:
+				if s.currentPara != nil {
+					s.currentPara.setPageControl(r.CtrlID, r.Data, s.opts.PageNumber)
+				}
+				if err := s.skipChildren(r.Lvl()); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			kind, known := controlKindOf(r.CtrlID)
+			if !known {
+				s.opts.Warn(s.locate(fmt.Errorf("skipped unknown control %s", CtrlName(r.CtrlID))))
+			}
+			if kind == inlineControl {
+				// Definitions, marks and fields whose text is already in
+				// the paragraph
+				if err := s.skipChildren(r.Lvl()); err != nil {
+					return nil, err
+				}
+				continue
 			}
 
-		case RecCtrlHeader:
+			// The paragraph owning this control precedes it in the output
+			s.finishParagraph()
 			switch r.CtrlID {
-			case 0x74626c20: // MAKE_4CHID('t','b','l',' ') - TABLE
+			case ctrlIDTable:
 				// Mark that we're entering a table control
 				s.tableLevel = r.Lvl()
+				s.inTableCtrl = true
+				s.tableID = s.nodeID()
 				// Table will be created when we see RecTable
 
-			case 0x67736f20: // MAKE_4CHID('g','s','o',' ') - Drawing Object
-				// Skip drawing object children and return image placeholder
-				s.skipChildren(r.Lvl())
-				return &document.Image{}, nil
+			case ctrlIDShape:
+				// Return an image placeholder, followed by the text of the
+				// object's text boxes. Shapes holding only text need no
+				// placeholder.
+				id := s.nodeID()
+				obj, err := s.readObject(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				obj.width, obj.height, obj.description = objectProperties(r.Data)
+				obj.anchor = objectAnchor(r.Data)
+				if obj.video != nil {
+					s.pending = append(s.pending, s.media(id, obj))
+					break
+				}
+				if obj.picture || obj.ole || obj.caption != "" || len(obj.boxes) == 0 {
+					s.pending = append(s.pending, s.image(id, obj))
+				}
+				for _, p := range obj.boxes {
+					s.pending = append(s.pending, p)
+				}
+				if obj.ole && s.opts.EmbeddedDepth > 0 {
+					if inner := s.reader.openEmbedded(obj.binDataID); inner != nil {
+						s.embedded = &embeddedScanner{id: id, inner: inner}
+					}
+				}
+
+			case ctrlIDEquation:
+				id := s.nodeID()
+				obj, err := s.readObject(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				s.pending = append(s.pending, &document.Equation{
+					ID:      id,
+					Script:  obj.script,
+					LaTeX:   equation.LaTeX(obj.script),
+					Caption: obj.caption,
+				})
 
-			default:
-				// Unknown control, skip its children
-				s.skipChildren(r.Lvl())
+			case ctrlIDHiddenComment:
+				if !s.opts.HiddenText {
+					if err := s.skipChildren(r.Lvl()); err != nil {
+						return nil, err
+					}
+					break
+				}
+				// The comment's paragraph list reads like a caption list
+				id := s.nodeID()
+				obj, err := s.readObject(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				if obj.caption != "" {
+					s.pending = append(s.pending, &document.Paragraph{ID: id, Text: obj.caption, Hidden: true})
+				}
+
+			case ctrlIDHeader, ctrlIDFooter:
+				// Their paragraph lists are set apart from the body text
+				id := s.nodeID()
+				obj, err := s.readObject(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				if obj.caption != "" {
+					s.pending = append(s.pending, &document.Paragraph{ID: id, Text: obj.caption, Floating: true})
+				}
+
+			case ctrlIDFootnote, ctrlIDEndnote:
+				count := &s.footnotes
+				if r.CtrlID == ctrlIDEndnote {
+					count = &s.endnotes
+				}
+				*count++
+				note := &document.Footnote{
+					ID:      s.nodeID(),
+					Number:  noteNumber(r.Data, *count),
+					Endnote: r.CtrlID == ctrlIDEndnote,
+					Anchor:  s.lastParaID,
+				}
+				obj, err := s.readObject(r.Lvl())
+				if err != nil {
+					return nil, err
+				}
+				note.Text, note.Paragraphs = obj.caption, obj.paragraphs
+				s.pending = append(s.pending, note)
 			}
 
 		case RecTable:
-			// Create table (must be inside a table control)
-			if s.currentTable == nil {
+			// Create table (must be inside a table control). A table whose
+			// control lies in a cell of the current table is nested in it.
+			if s.currentTable == nil || s.tableLevel > s.currentTable.tableLevel {
 				s.currentTable = &tableBuilder{
 					rows:       int(r.RowCount),
 					cols:       int(r.ColCount),
 					cells:      make([]document.Cell, 0),
 					tableLevel: s.tableLevel,
+					id:         s.tableID,
+					parent:     s.currentTable,
+					borderless: true,
+					header:     r.RepeatHeader(),
+				}
+				// Zones draw borders over the borders of their cells
+				for _, zone := range r.Zones {
+					if fill, ok := s.borderFill(zone.BorderFillID); !ok || fill.HasBorders() {
+						s.currentTable.borderless = false
+					}
+				}
+				if err := s.checkTableSize(); err != nil {
+					return nil, err
 				}
 			}
+			s.endCaption()
 
 		case RecListHeader:
+			if !r.IsCell && s.inTableCtrl && r.Lvl() == s.tableLevel+1 {
+				// Caption list of the table
+				s.finishParagraph()
+				s.inCaption = true
+			}
+
 			// Start new cell in table
 			if s.currentTable != nil && r.IsCell {
+				s.endCaption()
+				s.finishParagraph()
+
 				// Update table dimensions if needed
 				if int(r.RowIndex)+int(r.RowSpan) > s.currentTable.rows {
 					s.currentTable.rows = int(r.RowIndex) + int(r.RowSpan)
@@ -178,6 +966,9 @@ func (s *ContentScanner) Next() (document.ContentNode, error) {
 				if int(r.ColIndex)+int(r.ColSpan) > s.currentTable.cols {
 					s.currentTable.cols = int(r.ColIndex) + int(r.ColSpan)
 				}
+				if err := s.checkTableSize(); err != nil {
+					return nil, err
+				}
 
 				cell := document.Cell{
 					Row:     int(r.RowIndex),
@@ -185,7 +976,18 @@ func (s *ContentScanner) Next() (document.ContentNode, error) {
 					RowSpan: int(r.RowSpan),
 					ColSpan: int(r.ColSpan),
 					Text:    "",
+					Width:   float64(r.Width) / 100,
+					Height:  float64(r.Height) / 100,
+					VAlign:  cellVAligns[r.VertAlign()],
+				}
+				fill, ok := s.borderFill(r.BorderFillID)
+				if !ok || fill.HasBorders() {
+					s.currentTable.borderless = false
 				}
+				if ok {
+					cell.Borders = cellBorders(fill)
+				}
+				cell.Background = fill.Shading()
 				s.currentTable.cells = append(s.currentTable.cells, cell)
 				s.currentTable.currentCell = &s.currentTable.cells[len(s.currentTable.cells)-1]
 			}
@@ -193,56 +995,655 @@ func (s *ContentScanner) Next() (document.ContentNode, error) {
 	}
 }
 
+// cellVAligns maps the vertical alignments of list headers to those of
+// cells.
+var cellVAligns = map[int]string{
+	ListAlignTop:    document.VAlignTop,
+	ListAlignCenter: document.VAlignMiddle,
+	ListAlignBottom: document.VAlignBottom,
+}
+
 // nextRecord returns the next record, automatically advancing sections
 func (s *ContentScanner) nextRecord() (Rec, error) {
+	for {
+		rec, err := s.scanRecord()
+		if err == io.EOF && s.scanner != nil {
+			// Paragraphs never continue into the next section
+			s.finishParagraph()
+			if advErr := s.advanceSection(); advErr != nil {
+				return nil, advErr
+			}
+			if s.opts.Breaks {
+				s.pending = append(s.pending, &document.SectionBreak{ID: fmt.Sprintf("s%d", s.currentSection), Section: s.currentSection})
+			}
+			continue
+		}
+		return rec, err
+	}
+}
+
+// scanRecord returns the next record of the current section, or io.EOF at
+// its end. Unlike nextRecord it leaves the open paragraph alone, so that the
+// children of its last control can still be read.
+func (s *ContentScanner) scanRecord() (Rec, error) {
 	// Return buffered record if available
 	if s.hasBuffered {
 		rec := s.bufferedRec
+		s.recIndex = s.bufferedIdx
+		s.recOffset = s.bufferedOff
+		s.recTag = rec.Tag()
 		s.hasBuffered = false
 		s.bufferedRec = nil
 		return rec, nil
 	}
 
-	for {
-		if s.scanner == nil {
-			return nil, io.EOF
-		}
+	if s.scanner == nil {
+		return nil, io.EOF
+	}
 
-		rec, err := s.scanner.ScanNext()
-		if err != nil {
-			if err == io.EOF {
-				if advErr := s.advanceSection(); advErr != nil {
-					return nil, advErr
-				}
-				continue
-			}
-			return nil, err
+	offset := s.scanner.Offset()
+	rec, err := s.scanner.ScanNext()
+	// Resource limits are never worked around
+	for err != nil && err != io.EOF && s.opts.Recover && !errors.Is(err, limits.ErrExceeded) {
+		if !errors.Is(err, ErrTruncatedRecord) {
+			// The stream itself is damaged, so the rest of the section is
+			// lost; later reads of it end at once
+			s.opts.Warn(fmt.Errorf("skipped rest of section: %w", err))
+			s.scanner.r = strings.NewReader("")
+			return nil, io.EOF
 		}
-		return rec, nil
+		// The record was read in full; go on with the next one
+		s.opts.Warn(fmt.Errorf("skipped record: %w", err))
+		s.recCount++
+		offset = s.scanner.Offset()
+		rec, err = s.scanner.ScanNext()
+	}
+	if err != nil {
+		return nil, err
 	}
+	s.recIndex = s.recCount
+	s.recOffset = offset
+	s.recTag = rec.Tag()
+	s.recCount++
+	return rec, nil
 }
 
 // putBack puts a record back into the buffer to be read again
 func (s *ContentScanner) putBack(rec Rec) {
 	s.bufferedRec = rec
+	s.bufferedIdx = s.recIndex
+	s.bufferedOff = s.recOffset
 	s.hasBuffered = true
 }
 
-// finishTable completes the current table and returns it
+// finishParagraph completes the current paragraph. Its text is appended to the
+// current table cell, or queued as Paragraph nodes outside of tables.
+func (s *ContentScanner) finishParagraph() {
+	if s.currentPara == nil {
+		return
+	}
+	texts := s.currentPara.paraTexts()
+	monospace := s.currentPara.monospace
+	layout := s.currentPara.layout
+	outline := s.currentPara.outline
+	shapeID := s.currentPara.shapeID
+	tabStops := s.currentPara.tabStops
+	bookmarks := s.currentPara.bookmarks
+	id := s.currentPara.id
+	s.currentPara = nil
+
+	for i, pt := range texts {
+		text := pt.text
+		if s.inCaption {
+			s.captionTexts = append(s.captionTexts, text)
+		} else if s.currentTable != nil && s.currentTable.currentCell != nil {
+			// Inside table: add to current cell, which takes the alignment
+			// of its first paragraph with text
+			if cell := s.currentTable.currentCell; cell.Text == "" && text != "" {
+				cell.Align = cellAlign(layout)
+			}
+			if s.currentTable.currentCell.Text != "" {
+				s.currentTable.currentCell.Text += s.opts.CellParagraphSeparator
+			}
+			s.currentTable.currentCell.Text += text
+			s.currentTable.currentCell.Fields = append(s.currentTable.currentCell.Fields, pt.fields...)
+			s.lastParaID = ""
+		} else {
+			s.lastParaID = id
+			s.addMemoSpans(id, pt.memos)
+			para := &document.Paragraph{
+				ID:           id,
+				Text:         text,
+				Preformatted: monospace && text != "",
+				Fields:       pt.fields,
+				Hyperlinks:   pt.links,
+				Runs:         pt.runs,
+				Layout:       layout,
+			}
+			if strings.Contains(text, "\t") {
+				para.TabStops = tabStops
+			}
+			if i == 0 {
+				para.Bookmarks = bookmarks
+				para.List = s.listItem(shapeID)
+			} else {
+				// Paragraphs split at ParaBreak share the record
+				para.ID = fmt.Sprintf("%s.%d", id, i)
+			}
+			if outline > 0 && strings.TrimSpace(text) != "" {
+				s.pending = append(s.pending, &document.Heading{Paragraph: *para, Level: outline})
+				continue
+			}
+			s.pending = append(s.pending, para)
+		}
+	}
+}
+
+// finishTable completes the current table and returns it. A nested table is
+// added to the cell it is nested in instead, and nil is returned.
 func (s *ContentScanner) finishTable() *document.Table {
 	if s.currentTable == nil {
 		return nil
 	}
+	// Flush the last cell's paragraph if it was not terminated
+	s.finishParagraph()
+	s.endCaption()
 
 	table := &document.Table{
-		Rows:  s.currentTable.rows,
-		Cols:  s.currentTable.cols,
-		Cells: s.currentTable.cells,
+		ID:         s.currentTable.id,
+		Rows:       s.currentTable.rows,
+		Cols:       s.currentTable.cols,
+		Cells:      s.currentTable.cells,
+		Caption:    s.currentTable.caption,
+		Borderless: s.currentTable.borderless && len(s.currentTable.cells) > 0,
+		HeaderRow:  s.currentTable.header,
+	}
+	parent := s.currentTable.parent
+	s.currentTable = parent
+	if parent != nil {
+		s.tableLevel = parent.tableLevel
+		if parent.currentCell != nil {
+			parent.currentCell.Tables = append(parent.currentCell.Tables, table)
+		}
+		return nil
 	}
-	s.currentTable = nil
+	s.inTableCtrl = false
 	return table
 }
 
+// endCaption ends a table caption list. The caption is kept until the table
+// it belongs to has been created, since it can precede the Table record.
+func (s *ContentScanner) endCaption() {
+	if s.inCaption {
+		s.finishParagraph()
+		s.inCaption = false
+	}
+	if s.currentTable != nil && len(s.captionTexts) > 0 {
+		s.currentTable.caption = joinCaption(s.captionTexts)
+		s.captionTexts = nil
+	}
+}
+
+// drawingObject holds what the scanner keeps of a drawing object control.
+type drawingObject struct {
+	caption string
+	// ole and picture are set for OLE objects and pictures, whose data is
+	// stored in BinData binDataID
+	ole       bool
+	picture   bool
+	binDataID uint16
+	// script is the equation script of an equation object
+	script string
+	// video is the video data of a video object
+	video *RecVideoData
+	// boxes holds the non-empty paragraphs of text boxes, and paragraphs
+	// those of the caption list
+	boxes      []*document.Paragraph
+	paragraphs []document.Paragraph
+	// width and height are the displayed size in HWPUNITs, and description
+	// the author's description of the object (개체 설명문)
+	width, height uint32
+	description   string
+	// anchor is the Image.Anchor of the object
+	anchor string
+}
+
+// objectProperties returns the size and description from the common
+// properties of an object control header: ctrl ID, property, vertical and
+// horizontal offsets, HWPUNIT width and height, z-order, four HWPUNIT16
+// margins, instance ID and page break prevention, followed by the
+// description as a WORD length and WCHARs.
+func objectProperties(data []byte) (width, height uint32, description string) {
+	if len(data) < 24 {
+		return 0, 0, ""
+	}
+	width = binary.LittleEndian.Uint32(data[16:])
+	height = binary.LittleEndian.Uint32(data[20:])
+	description, _ = readLenWString(data, 44)
+	return width, height, description
+}
+
+// objectAnchor returns the Image.Anchor from the property of an object
+// control header: bit 0 treats the object as a character, and bits 3-4 give
+// what its vertical offset is relative to (paper, page, paragraph).
+func objectAnchor(data []byte) string {
+	if len(data) < 8 {
+		return ""
+	}
+	prop := binary.LittleEndian.Uint32(data[4:])
+	if prop&1 != 0 {
+		return document.AnchorInline
+	}
+	switch prop >> 3 & 3 {
+	case 0:
+		return document.AnchorPaper
+	case 1:
+		return document.AnchorPage
+	case 2:
+		return document.AnchorParagraph
+	}
+	return ""
+}
+
+// image returns the Image node of a drawing object.
+func (s *ContentScanner) image(id string, obj drawingObject) *document.Image {
+	img := &document.Image{
+		ID:      id,
+		Caption: obj.caption,
+		Width:   float64(obj.width) / 100,
+		Height:  float64(obj.height) / 100,
+		Alt:     strings.TrimSpace(obj.description),
+		Anchor:  obj.anchor,
+	}
+	if !obj.picture {
+		return img
+	}
+	_, err := s.docInfo().BinDataItem(obj.binDataID)
+	s.resolved(err)
+	img.Filename, img.Format = s.reader.binDataFile(obj.binDataID)
+	if item := s.reader.binDataItem(obj.binDataID); item.Type != BinDataLink {
+		reader, id := s.reader, obj.binDataID
+		img.SetData(func() ([]byte, error) { return reader.readBinData(id) })
+	}
+	return img
+}
+
+// media returns the Media node of a video object.
+func (s *ContentScanner) media(id string, obj drawingObject) *document.Media {
+	m := &document.Media{ID: id, Caption: obj.caption}
+	if obj.video.Web {
+		m.Source = document.EmbedURL(obj.video.EmbedTag)
+		return m
+	}
+	_, err := s.docInfo().BinDataItem(obj.video.BinDataID)
+	s.resolved(err)
+	item := s.reader.binDataItem(obj.video.BinDataID)
+	if item.Type == BinDataLink {
+		m.Source = item.LinkPath
+		return m
+	}
+	m.Source, _ = s.reader.binDataFile(obj.video.BinDataID)
+	m.Embedded = true
+	return m
+}
+
+// readObject consumes the children of a drawing object control and returns
+// the text of its caption list, if any, its picture or OLE data reference,
+// its equation script and the paragraphs of its text boxes. The caption list
+// is a direct child of the control; lists nested deeper belong to text
+// boxes, including those of shapes within groups, and are read in record
+// order. Errors reading the children end the scan, as they would at the
+// top level.
+func (s *ContentScanner) readObject(parentLevel uint16) (drawingObject, error) {
+	var obj drawingObject
+	var texts []string
+	var para *paragraphBuilder
+	var paraLevel uint16
+	inBox := false
+	inCaption := false
+	flush := func() {
+		if para == nil {
+			return
+		}
+		for i, pt := range para.paraTexts() {
+			if !inBox {
+				texts = append(texts, pt.text)
+				if strings.TrimSpace(pt.text) != "" {
+					obj.paragraphs = append(obj.paragraphs, document.Paragraph{Text: pt.text, Fields: pt.fields, Hyperlinks: pt.links, Runs: pt.runs})
+				}
+				continue
+			}
+			if strings.TrimSpace(pt.text) == "" {
+				continue
+			}
+			p := &document.Paragraph{ID: para.id, Text: pt.text, Fields: pt.fields, Hyperlinks: pt.links, Floating: true}
+			if i > 0 {
+				p.ID = fmt.Sprintf("%s.%d", para.id, i)
+			}
+			obj.boxes = append(obj.boxes, p)
+		}
+		para = nil
+	}
+
+	for {
+		rec, err := s.nextRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return obj, err
+		}
+		if rec.Lvl() <= parentLevel {
+			s.putBack(rec)
+			break
+		}
+		switch r := rec.(type) {
+		case RecShapeComponentOLE:
+			obj.ole = true
+			obj.binDataID = r.BinDataID
+		case RecShapeComponentPicture:
+			obj.picture = true
+			obj.binDataID = r.BinDataID
+		case RecEqEdit:
+			obj.script = r.Script
+		case RecVideoData:
+			obj.video = &r
+		}
+		if para != nil && rec.Lvl() <= paraLevel {
+			flush()
+		}
+
+		if rec.Lvl() == parentLevel+1 {
+			switch rec.(type) {
+			case RecListHeader:
+				inCaption = true
+			case RecParaHeader:
+				if inCaption {
+					para = &paragraphBuilder{}
+					paraLevel, inBox = rec.Lvl(), false
+				}
+			default:
+				inCaption = false
+			}
+			continue
+		}
+		if _, ok := rec.(RecParaHeader); ok {
+			// A text box paragraph, at any depth
+			para = &paragraphBuilder{id: s.nodeID()}
+			paraLevel, inBox = rec.Lvl(), true
+			continue
+		}
+		if para == nil || rec.Lvl() != paraLevel+1 {
+			continue
+		}
+		switch r := rec.(type) {
+		case RecParaText:
+			para.addText(r.Els, false)
+		case RecCtrlHeader:
+			switch r.CtrlID {
+			case ctrlIDAutoNumber:
+				para.setAutoNumber(r.Data, s.opts.PageNumber)
+			case ctrlIDHyperlink:
+				para.setLink(r.Data, "")
+			}
+		}
+	}
+	flush()
+	obj.caption = joinCaption(texts)
+	return obj, nil
+}
+
+// cellBorders returns which sides of a border fill have a line.
+func cellBorders(fill BorderFill) *document.CellBorders {
+	return &document.CellBorders{
+		Left:   fill.Borders[0].Type != 0,
+		Right:  fill.Borders[1].Type != 0,
+		Top:    fill.Borders[2].Type != 0,
+		Bottom: fill.Borders[3].Type != 0,
+	}
+}
+
+// cellAlign returns the Cell.Align of a paragraph layout.
+func cellAlign(layout *document.Layout) string {
+	if layout == nil || layout.Align == document.AlignJustify || layout.Align == document.AlignLeft {
+		return ""
+	}
+	return layout.Align
+}
+
+// addMemoSpans records the spans of memo fields in a body paragraph for the
+// comments made of their memos.
+func (s *ContentScanner) addMemoSpans(id string, spans []memoSpan) {
+	for _, m := range spans {
+		if m.index == memoUnknown {
+			continue
+		}
+		if s.memoSpans == nil {
+			s.memoSpans = make(map[uint32]document.Comment)
+		}
+		s.memoSpans[m.index] = document.Comment{Anchor: id, Offset: m.offset, Quote: m.quote}
+	}
+}
+
+// noteNumber returns the number of a footnote or endnote from its control
+// header (ctrl ID, then the UINT32 number), or else the count of notes so
+// far.
+func noteNumber(data []byte, count int) int {
+	if len(data) >= 8 {
+		if n := binary.LittleEndian.Uint32(data[4:]); n > 0 && n < 1<<16 {
+			return int(n)
+		}
+	}
+	return count
+}
+
+// joinCaption joins the paragraphs of a caption, dropping empty ones.
+func joinCaption(texts []string) string {
+	var lines []string
+	for _, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// docInfo returns the document's DocInfo, or an empty one for scanners
+// created without it.
+func (s *ContentScanner) docInfo() *DocInfo {
+	if s.reader.DocInfo == nil {
+		return &DocInfo{}
+	}
+	return s.reader.DocInfo
+}
+
+// resolved reports whether a DocInfo lookup succeeded, keeping the first
+// failure, which ends the scan with ScanOptions.StrictReferences.
+func (s *ContentScanner) resolved(err error) bool {
+	if err != nil && s.refErr == nil {
+		s.refErr = s.locate(err)
+	}
+	return err == nil
+}
+
+// checkTableSize fails once the grid of the current table, which grows
+// with cells beyond its declared size, exceeds ScanOptions.MaxTableCells.
+func (s *ContentScanner) checkTableSize() error {
+	t := s.currentTable
+	if err := limits.Check("table "+t.id, int64(t.rows)*int64(t.cols), s.opts.MaxTableCells, "cells"); err != nil {
+		return s.locate(err)
+	}
+	return nil
+}
+
+// locate wraps an error about the last returned record in a *RecordError.
+func (s *ContentScanner) locate(err error) error {
+	rerr := &RecordError{Record: s.recIndex, Offset: s.recOffset, Tag: s.recTag, Err: err}
+	if s.scanner != nil {
+		rerr.Stream = s.scanner.stream
+	}
+	return rerr
+}
+
+// runStyle returns the formatting of a character shape.
+func (s *ContentScanner) runStyle(shapeID uint32) document.Run {
+	shape, err := s.docInfo().CharShape(shapeID)
+	if !s.resolved(err) {
+		return document.Run{}
+	}
+	run := document.Run{
+		Bold:        shape.Bold(),
+		Italic:      shape.Italic(),
+		Underline:   shape.Underline(),
+		Strikeout:   shape.Strikeout(),
+		Superscript: shape.Superscript(),
+		Subscript:   shape.Subscript(),
+		Size:        shape.Points(),
+	}
+	if shape.Color != 0 {
+		run.Color = shape.RGB()
+	}
+	return run
+}
+
+// borderFill returns the border fill with the given 1-based ID.
+func (s *ContentScanner) borderFill(id uint16) (BorderFill, bool) {
+	fill, err := s.docInfo().BorderFill(id)
+	return fill, s.resolved(err)
+}
+
+// outlineLevel returns the outline level of a paragraph from its style, or
+// else from the outline numbering of its shape, or 0 if it has neither.
+func (s *ContentScanner) outlineLevel(styleID uint8, shapeID uint16) int {
+	if style, err := s.docInfo().Style(styleID); s.resolved(err) {
+		if level := style.OutlineLevel(); level > 0 {
+			return level
+		}
+	}
+	shape, err := s.docInfo().ParaShape(shapeID)
+	if !s.resolved(err) {
+		return 0
+	}
+	return shape.OutlineLevel()
+}
+
+// listItem returns the list item of a paragraph with paragraph numbers or
+// bullets, counting it in its numbered list, or nil for other paragraphs.
+func (s *ContentScanner) listItem(shapeID uint16) *document.ListItem {
+	shape, err := s.docInfo().ParaShape(shapeID)
+	if !s.resolved(err) {
+		return nil
+	}
+	level, numbered := shape.ListLevel()
+	if level == 0 {
+		return nil
+	}
+	if !numbered {
+		bullet, err := s.docInfo().Bullet(shape.NumberingID)
+		if !s.resolved(err) {
+			return nil
+		}
+		return &document.ListItem{Level: level, Marker: string(bullet.Char)}
+	}
+	numbering, err := s.docInfo().Numbering(shape.NumberingID)
+	if !s.resolved(err) {
+		return nil
+	}
+	if s.listNumbers == nil {
+		s.listNumbers = make(map[uint16][]int)
+	}
+	numbers, ok := s.listNumbers[shape.NumberingID]
+	if !ok {
+		numbers = make([]int, len(numbering.Levels))
+		s.listNumbers[shape.NumberingID] = numbers
+	}
+	marker, number := numbering.Number(numbers, level)
+	return &document.ListItem{Ordered: true, Level: level, Marker: marker, Number: number}
+}
+
+// tabStops returns the tab stops of a paragraph shape.
+func (s *ContentScanner) tabStops(shapeID uint16) []document.TabStop {
+	shape, err := s.docInfo().ParaShape(shapeID)
+	if !s.resolved(err) {
+		return nil
+	}
+	def, err := s.docInfo().TabDef(shape.TabDefID)
+	if !s.resolved(err) {
+		return nil
+	}
+	var stops []document.TabStop
+	for _, stop := range def.Stops {
+		stops = append(stops, document.TabStop{
+			Position: float64(stop.Position) / 100,
+			Align:    stop.Align(),
+			Leader:   stop.Leader(),
+		})
+	}
+	return stops
+}
+
+// layout returns the layout of a paragraph shape, or nil for a plain one:
+// aligned to both sides or the left, without margins or indent.
+func (s *ContentScanner) layout(shapeID uint16) *document.Layout {
+	shape, err := s.docInfo().ParaShape(shapeID)
+	if !s.resolved(err) {
+		return nil
+	}
+	points := func(v int32) float64 { return float64(v) / 100 }
+	layout := &document.Layout{
+		Align:       shape.Align(),
+		MarginLeft:  points(shape.MarginLeft),
+		MarginRight: points(shape.MarginRight),
+		Indent:      points(shape.Indent),
+		SpaceBefore: points(shape.SpaceBefore),
+		SpaceAfter:  points(shape.SpaceAfter),
+	}
+	if shape.LineSpacingType == paraLineSpacingPercent {
+		layout.LineSpacing = int(shape.LineSpacing)
+	}
+	if (layout.Align == document.AlignJustify || layout.Align == document.AlignLeft) && !layout.Indented() {
+		return nil
+	}
+	return layout
+}
+
+// monospaceRuns reports whether all runs use a fixed-pitch Latin font.
+func (s *ContentScanner) monospaceRuns(runs []CharShapeRun) bool {
+	if len(runs) == 0 || s.reader.DocInfo == nil {
+		return false
+	}
+	for _, run := range runs {
+		face, ok := s.reader.DocInfo.Face(run.ShapeID, LangLatin)
+		if !ok || !face.Monospace() {
+			return false
+		}
+	}
+	return true
+}
+
+// readCtrlDataString consumes the children of a control and returns the
+// string stored in its CtrlData parameter set, such as a bookmark or field
+// name.
+func (s *ContentScanner) readCtrlDataString(parentLevel uint16) (string, error) {
+	name := ""
+	for {
+		rec, err := s.scanRecord()
+		if err == io.EOF {
+			return name, nil
+		}
+		if err != nil {
+			return name, err
+		}
+		if rec.Lvl() <= parentLevel {
+			s.putBack(rec)
+			return name, nil
+		}
+		if data, ok := rec.(RecCtrlData); ok && name == "" {
+			name = parameterSetString(data.Data)
+		}
+	}
+}
+
 // skipChildren skips all records that are children of the given parent level
 func (s *ContentScanner) skipChildren(parentLevel uint16) error {
 	for {
@@ -0,0 +1,1398 @@
+package hwpv5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// recordStream builds a raw section stream from records.
+type recordStream struct {
+	buf bytes.Buffer
+}
+
+func (rs *recordStream) add(tag uint16, level uint16, data []byte) *recordStream {
+	header := uint32(tag) | uint32(level)<<10 | uint32(len(data))<<20
+	binary.Write(&rs.buf, binary.LittleEndian, header)
+	rs.buf.Write(data)
+	return rs
+}
+
+// para appends a ParaHeader/ParaText/ParaCharShape triple. Elements are
+// strings, uint16 char control codes or raw bytes (extended control payloads).
+func (rs *recordStream) para(level uint16, elems ...any) *recordStream {
+	var text []byte
+	for _, el := range elems {
+		switch v := el.(type) {
+		case string:
+			text = append(text, utf16Bytes(v)...)
+		case uint16:
+			text = binary.LittleEndian.AppendUint16(text, v)
+		case []byte:
+			text = append(text, v...)
+		}
+	}
+	rs.add(recTagParaHeader, level, make([]byte, 12))
+	rs.add(recTagParaText, level+1, text)
+	rs.add(recTagParaCharShape, level+1, nil)
+	return rs
+}
+
+// utf16Bytes encodes s as UTF-16LE WCHARs.
+func utf16Bytes(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+func newTestScanner(stream *recordStream, opts document.ScanOptions) *ContentScanner {
+	return &ContentScanner{
+		reader:  &Reader{sections: []int{0}},
+		opts:    opts,
+		scanner: NewRecScanner(bytes.NewReader(stream.buf.Bytes())),
+	}
+}
+
+func collectTexts(t *testing.T, s document.ContentNodeScanner) []string {
+	t.Helper()
+	var texts []string
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			return texts
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, ok := node.(*document.Paragraph); ok {
+			texts = append(texts, p.Text)
+		}
+	}
+}
+
+func TestParaBreakMerge(t *testing.T) {
+	stream := (&recordStream{}).para(0, "첫째", paraTextCodeParaBreak, "둘째", paraTextCodeParaBreak)
+
+	texts := collectTexts(t, newTestScanner(stream, document.DefaultScanOptions()))
+	if len(texts) != 1 || texts[0] != "첫째둘째" {
+		t.Errorf("unexpected paragraphs: %q", texts)
+	}
+}
+
+func TestParaBreakSplit(t *testing.T) {
+	stream := (&recordStream{}).para(0, "첫째", paraTextCodeParaBreak, "둘째", paraTextCodeParaBreak)
+
+	opts := document.DefaultScanOptions()
+	opts.SplitOnParaBreak = true
+	texts := collectTexts(t, newTestScanner(stream, opts))
+	if len(texts) != 2 || texts[0] != "첫째" || texts[1] != "둘째" {
+		t.Errorf("unexpected paragraphs: %q", texts)
+	}
+}
+
+func TestParagraphWithoutCharShape(t *testing.T) {
+	stream := &recordStream{}
+	stream.add(recTagParaHeader, 0, make([]byte, 12)).add(recTagParaText, 1, utf16Bytes("하나"))
+	stream.add(recTagParaHeader, 0, make([]byte, 12)).add(recTagParaText, 1, utf16Bytes("둘"))
+
+	texts := collectTexts(t, newTestScanner(stream, document.DefaultScanOptions()))
+	if len(texts) != 2 || texts[0] != "하나" || texts[1] != "둘" {
+		t.Errorf("unexpected paragraphs: %q", texts)
+	}
+}
+
+func TestBookmark(t *testing.T) {
+	ctrl := binary.LittleEndian.AppendUint32(nil, 0x626f6b6d)
+	// Parameter set with a single BSTR item holding the bookmark name
+	set := []byte{0, 0, 1, 0, 0, 0, paramTypeBSTR, 0}
+	set = binary.LittleEndian.AppendUint16(set, uint16(len([]rune("요약"))))
+	set = append(set, utf16Bytes("요약")...)
+
+	stream := (&recordStream{}).para(0, "첫째")
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	stream.add(recTagCtrlData, 2, set)
+	stream.para(0, "둘째")
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	var got [][]string
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, node.(*document.Paragraph).Bookmarks)
+	}
+	if len(got) != 2 || len(got[0]) != 1 || got[0][0] != "요약" || got[1] != nil {
+		t.Errorf("bookmarks = %q, want [[요약] []]", got)
+	}
+}
+
+// fieldPayload returns the 14-byte payload of a field start or end code.
+func fieldPayload(ctrlID uint32) []byte {
+	return append(binary.LittleEndian.AppendUint32(nil, ctrlID), make([]byte, 10)...)
+}
+
+func TestClickHereFields(t *testing.T) {
+	// Click-here control whose command carries the guide text
+	command := "Clickhere:set:40:Direction:wstring:5:이름 입력 HelpState:wstring:0: "
+	ctrl := binary.LittleEndian.AppendUint32(nil, ctrlIDClickHere)
+	ctrl = append(ctrl, 0, 0, 0, 0, 0)
+	ctrl = binary.LittleEndian.AppendUint16(ctrl, uint16(len([]rune(command))))
+	ctrl = append(ctrl, utf16Bytes(command)...)
+
+	// Parameter set naming the second field
+	set := []byte{0, 0, 1, 0, 0, 0, paramTypeBSTR, 0}
+	set = binary.LittleEndian.AppendUint16(set, uint16(len([]rune("부서"))))
+	set = append(set, utf16Bytes("부서")...)
+
+	stream := (&recordStream{}).para(0,
+		"성명: ", paraTextCodeFieldStart, fieldPayload(ctrlIDClickHere), "홍길동", paraTextCodeFieldEnd, fieldPayload(ctrlIDClickHere),
+		", 부서: ", paraTextCodeFieldStart, fieldPayload(ctrlIDClickHere), "총무과", paraTextCodeFieldEnd, fieldPayload(ctrlIDClickHere))
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	stream.add(recTagCtrlData, 2, set)
+
+	node, err := newTestScanner(stream, document.DefaultScanOptions()).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := node.(*document.Paragraph)
+	if p.Text != "성명: 홍길동, 부서: 총무과" {
+		t.Errorf("text = %q", p.Text)
+	}
+	want := []document.Field{
+		{Name: "이름 입력", Type: document.FieldClickHere, Value: "홍길동"},
+		{Name: "부서", Type: document.FieldClickHere, Value: "총무과"},
+	}
+	if len(p.Fields) != len(want) {
+		t.Fatalf("fields = %+v, want %+v", p.Fields, want)
+	}
+	for i := range want {
+		if p.Fields[i] != want[i] {
+			t.Errorf("field %d = %+v, want %+v", i, p.Fields[i], want[i])
+		}
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	command := `https\://www.hancom.com/;1;0;0;`
+	ctrl := binary.LittleEndian.AppendUint32(nil, ctrlIDHyperlink)
+	ctrl = append(ctrl, 0, 0, 0, 0, 0)
+	ctrl = binary.LittleEndian.AppendUint16(ctrl, uint16(len([]rune(command))))
+	ctrl = append(ctrl, utf16Bytes(command)...)
+
+	stream := (&recordStream{}).para(0,
+		"자세한 내용은 ", paraTextCodeFieldStart, fieldPayload(ctrlIDHyperlink), "한컴", paraTextCodeFieldEnd, fieldPayload(ctrlIDHyperlink), " 참고")
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	// Parameter set giving the link a title
+	set := []byte{0, 0, 1, 0, 0, 0, paramTypeBSTR, 0}
+	set = binary.LittleEndian.AppendUint16(set, uint16(len([]rune("한컴 누리집"))))
+	set = append(set, utf16Bytes("한컴 누리집")...)
+	stream.add(recTagCtrlData, 2, set)
+
+	node, err := newTestScanner(stream, document.DefaultScanOptions()).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := node.(*document.Paragraph)
+	if p.Text != "자세한 내용은 한컴 참고" {
+		t.Errorf("text = %q", p.Text)
+	}
+	want := document.Hyperlink{Offset: len("자세한 내용은 "), Text: "한컴", URL: "https://www.hancom.com/", Title: "한컴 누리집"}
+	if len(p.Hyperlinks) != 1 || p.Hyperlinks[0] != want {
+		t.Errorf("hyperlinks = %+v, want [%+v]", p.Hyperlinks, want)
+	}
+}
+
+func TestMemo(t *testing.T) {
+	// Memo field control: empty command, instance ID, then the memo index
+	ctrl := binary.LittleEndian.AppendUint32(nil, ctrlIDMemo)
+	ctrl = append(ctrl, 0, 0, 0, 0, 0, 0, 0)
+	ctrl = binary.LittleEndian.AppendUint32(ctrl, 7)
+	ctrl = binary.LittleEndian.AppendUint32(ctrl, 1)
+
+	stream := (&recordStream{}).para(0,
+		"예산은 ", paraTextCodeFieldStart, fieldPayload(ctrlIDMemo), "3억 원", paraTextCodeFieldEnd, fieldPayload(ctrlIDMemo), "이다")
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	// The memos follow the body text
+	stream.add(recTagMemoList, 0, binary.LittleEndian.AppendUint32(nil, 1))
+	stream.add(recTagListHeader, 1, make([]byte, 8))
+	stream.para(1, "확인 필요")
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	var nodes []document.ContentNode
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("nodes = %+v, want paragraph and comment", nodes)
+	}
+	p := nodes[0].(*document.Paragraph)
+	if p.Text != "예산은 3억 원이다" {
+		t.Errorf("text = %q", p.Text)
+	}
+	want := document.Comment{ID: "s0.r4", Text: "확인 필요", Anchor: p.ID, Offset: len("예산은 "), Quote: "3억 원"}
+	if got, ok := nodes[1].(*document.Comment); !ok || *got != want {
+		t.Errorf("comment = %+v, want %+v", nodes[1], want)
+	}
+}
+
+func TestEquation(t *testing.T) {
+	script := "1 over 2"
+	eq := binary.LittleEndian.AppendUint32(nil, 0)
+	eq = binary.LittleEndian.AppendUint16(eq, uint16(len(script)))
+	eq = append(eq, utf16Bytes(script)...)
+
+	stream := (&recordStream{}).para(0, "식")
+	stream.add(recTagCtrlHeader, 1, binary.LittleEndian.AppendUint32(nil, 0x65716564))
+	stream.add(recTagEqEdit, 2, eq)
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	var got *document.Equation
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e, ok := node.(*document.Equation); ok {
+			got = e
+		}
+	}
+	if got == nil || got.Script != script || got.LaTeX != `\frac{1}{2}` {
+		t.Errorf("equation = %+v, want script %q as \\frac{1}{2}", got, script)
+	}
+}
+
+// formObject returns a FORM_OBJECT payload: the type ID, the ID repeated
+// and the property command with its length.
+func formObject(typeID uint32, command string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, typeID)
+	data = binary.LittleEndian.AppendUint32(data, typeID)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len([]rune(command))))
+	return append(data, utf16Bytes(command)...)
+}
+
+func TestFormObjects(t *testing.T) {
+	ctrl := binary.LittleEndian.AppendUint32(nil, ctrlIDForm)
+	stream := (&recordStream{}).para(0, "동의 여부")
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	stream.add(recTagFormObject, 2, formObject(formTypeCheckButton,
+		"CheckBtnSet:set:70:Name:wstring:5:agree SelectedValue:int:1:0 Value:int:1:1 Caption:wstring:2:동의"))
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	stream.add(recTagFormObject, 2, formObject(formTypeEdit, "EditSet:set:40:Name:wstring:4:name Text:wstring:3:홍길동"))
+	stream.add(recTagCtrlHeader, 1, ctrl)
+	stream.add(recTagFormObject, 2, formObject(formTypePushButton, "ButtonSet:set:20:Name:wstring:2:ok"))
+
+	node, err := newTestScanner(stream, document.DefaultScanOptions()).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []document.Field{
+		{Name: "agree", Type: document.FieldCheckBox, Value: "on"},
+		{Name: "name", Type: document.FieldEdit, Value: "홍길동"},
+	}
+	got := node.(*document.Paragraph).Fields
+	if len(got) != len(want) {
+		t.Fatalf("fields = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestVideo(t *testing.T) {
+	tag := `<iframe width="560" src="https://www.youtube.com/embed/abc123" frameborder="0"></iframe>`
+	web := binary.LittleEndian.AppendUint32(nil, 1)
+	web = binary.LittleEndian.AppendUint16(web, uint16(len(tag)))
+	web = append(web, utf16Bytes(tag)...)
+	web = binary.LittleEndian.AppendUint16(web, 2)
+
+	local := binary.LittleEndian.AppendUint32(nil, 0)
+	local = binary.LittleEndian.AppendUint16(local, 1)
+	local = binary.LittleEndian.AppendUint16(local, 2)
+
+	gso := binary.LittleEndian.AppendUint32(nil, 0x67736f20)
+	stream := (&recordStream{}).para(0, "영상")
+	stream.add(recTagCtrlHeader, 1, gso)
+	stream.add(recTagVideoData, 2, web)
+	stream.add(recTagCtrlHeader, 1, gso)
+	stream.add(recTagVideoData, 2, local)
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = &DocInfo{BinData: []BinDataItem{{Type: BinDataEmbedding, StreamID: 1, Extension: "mp4"}}}
+	var got []document.Media
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m, ok := node.(*document.Media); ok {
+			got = append(got, *m)
+		}
+	}
+	want := []document.Media{
+		{ID: "s0.r3", Source: "https://www.youtube.com/embed/abc123"},
+		{ID: "s0.r5", Source: "BIN0001.mp4", Embedded: true},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("media = %+v, want %+v", got, want)
+	}
+}
+
+// autoNumberCtrl returns a CtrlHeader payload for an auto-number control.
+func autoNumberCtrl(number uint16) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, 0x61746e6f)
+	data = binary.LittleEndian.AppendUint32(data, 4) // table number
+	data = binary.LittleEndian.AppendUint16(data, number)
+	return append(data, 0, 0, 0, 0, 0, 0)
+}
+
+func TestPageNumbers(t *testing.T) {
+	pageNum := binary.LittleEndian.AppendUint32(nil, 0x61746e6f)
+	pageNum = binary.LittleEndian.AppendUint32(pageNum, 0) // page number
+	pageNum = append(pageNum, 7, 0, 0, 0, '-', 0, '-', 0)
+	pagePos := binary.LittleEndian.AppendUint32(nil, 0x70676e70)
+	pagePos = binary.LittleEndian.AppendUint32(pagePos, 5<<8) // bottom center
+	pagePos = append(pagePos, 0, 0, '(', 0, ')', 0)
+
+	stream := (&recordStream{}).para(0, paraTextCodePageControl, make([]byte, 14), "쪽", paraTextCodeAutoNumber, make([]byte, 14), paraTextCodePageControl, make([]byte, 14), "끝")
+	stream.add(recTagCtrlHeader, 1, binary.LittleEndian.AppendUint32(nil, 0x70676864))
+	stream.add(recTagCtrlHeader, 1, pageNum)
+	stream.add(recTagCtrlHeader, 1, pagePos)
+
+	for _, tc := range []struct{ placeholder, want string }{
+		{"", "쪽끝"},
+		{"[PAGE]", "쪽-[PAGE]-([PAGE])끝"},
+	} {
+		opts := document.DefaultScanOptions()
+		opts.PageNumber = tc.placeholder
+		texts := collectTexts(t, newTestScanner(stream, opts))
+		if len(texts) != 1 || texts[0] != tc.want {
+			t.Errorf("placeholder %q: paragraphs = %q, want [%s]", tc.placeholder, texts, tc.want)
+		}
+	}
+}
+
+func TestTruncatedPageControls(t *testing.T) {
+	// Headers cut before the prefix and suffix lose their decoration
+	pageNum := binary.LittleEndian.AppendUint32(nil, 0x61746e6f)
+	pageNum = binary.LittleEndian.AppendUint32(pageNum, 0) // page number
+	pageNum = append(pageNum, 7, 0, 0)
+	pagePos := binary.LittleEndian.AppendUint32(nil, 0x70676e70)
+	pagePos = binary.LittleEndian.AppendUint32(pagePos, 5<<8) // bottom center
+	pagePos = append(pagePos, 0)
+
+	stream := (&recordStream{}).para(0, "쪽", paraTextCodeAutoNumber, make([]byte, 14), paraTextCodePageControl, make([]byte, 14), "끝")
+	stream.add(recTagCtrlHeader, 1, pageNum)
+	stream.add(recTagCtrlHeader, 1, pagePos)
+
+	opts := document.DefaultScanOptions()
+	opts.PageNumber = "[PAGE]"
+	texts := collectTexts(t, newTestScanner(stream, opts))
+	if want := "쪽[PAGE][PAGE]끝"; len(texts) != 1 || texts[0] != want {
+		t.Errorf("paragraphs = %q, want [%s]", texts, want)
+	}
+}
+
+func TestCaptions(t *testing.T) {
+	cell := make([]byte, 34)
+	cell[7+5], cell[7+7] = 1, 1 // 1x1 span
+
+	stream := (&recordStream{}).para(0, "본문")
+	// Table with a caption list preceding the Table record
+	stream.add(recTagCtrlHeader, 1, binary.LittleEndian.AppendUint32(nil, 0x74626c20))
+	stream.add(recTagListHeader, 2, make([]byte, 20))
+	stream.para(2, "표 ", paraTextCodeAutoNumber, make([]byte, 14), ". 예산")
+	stream.add(recTagCtrlHeader, 3, autoNumberCtrl(3))
+	stream.add(recTagTable, 2, tableRecord(1, 1))
+	stream.add(recTagListHeader, 2, cell)
+	stream.para(2, "셀")
+	// Drawing object whose caption list precedes its shape component
+	stream.para(0, "그림")
+	stream.add(recTagCtrlHeader, 1, binary.LittleEndian.AppendUint32(nil, 0x67736f20))
+	stream.add(recTagListHeader, 2, make([]byte, 20))
+	stream.para(2, "조직도")
+	stream.add(recTagShapeComponent, 2, nil)
+	stream.add(recTagListHeader, 3, make([]byte, 20))
+	stream.para(3, "글상자")
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	var table *document.Table
+	var image *document.Image
+	var texts []string
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch n := node.(type) {
+		case *document.Table:
+			table = n
+		case *document.Image:
+			image = n
+		case *document.Paragraph:
+			texts = append(texts, n.Text)
+		}
+	}
+
+	if table == nil || table.Caption != "표 3. 예산" || table.Cells[0].Text != "셀" {
+		t.Errorf("table = %+v, want caption 표 3. 예산 and cell 셀", table)
+	}
+	if image == nil || image.Caption != "조직도" {
+		t.Errorf("image = %+v, want caption 조직도", image)
+	}
+	// The text box is text, not part of the caption
+	if len(texts) != 3 || texts[0] != "본문" || texts[1] != "그림" || texts[2] != "글상자" {
+		t.Errorf("paragraphs = %q, want [본문 그림 글상자]", texts)
+	}
+}
+
+func TestHiddenComment(t *testing.T) {
+	stream := (&recordStream{}).para(0, "본문")
+	stream.add(recTagCtrlHeader, 1, binary.LittleEndian.AppendUint32(nil, 0x74636d74))
+	stream.add(recTagListHeader, 2, make([]byte, 8))
+	stream.para(2, "검토 의견")
+	stream.para(0, "끝")
+
+	if texts := collectTexts(t, newTestScanner(stream, document.DefaultScanOptions())); len(texts) != 2 {
+		t.Errorf("paragraphs = %q, want hidden comment left out", texts)
+	}
+
+	opts := document.DefaultScanOptions()
+	opts.HiddenText = true
+	s := newTestScanner(stream, opts)
+	var paras []*document.Paragraph
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, ok := node.(*document.Paragraph); ok {
+			paras = append(paras, p)
+		}
+	}
+	if len(paras) != 3 || paras[1].Text != "검토 의견" || !paras[1].Hidden || paras[0].Hidden || paras[2].Text != "끝" {
+		t.Errorf("paragraphs = %+v, want hidden comment after 본문", paras)
+	}
+}
+
+func TestPageBreaks(t *testing.T) {
+	pageBreak := make([]byte, 12)
+	pageBreak[11] = 1 << 2
+	stream := (&recordStream{}).add(recTagParaHeader, 0, pageBreak).
+		add(recTagParaText, 1, utf16Bytes("첫 쪽")).
+		add(recTagParaHeader, 0, pageBreak).
+		add(recTagParaText, 1, utf16Bytes("둘째 쪽"))
+
+	for _, breaks := range []bool{false, true} {
+		opts := document.DefaultScanOptions()
+		opts.Breaks = breaks
+		s := newTestScanner(stream, opts)
+		var got []string
+		for {
+			node, err := s.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch n := node.(type) {
+			case *document.Paragraph:
+				got = append(got, n.Text)
+			case *document.PageBreak:
+				got = append(got, "break "+n.ID)
+			}
+		}
+		// The break at the start of the section is not reported
+		want := []string{"첫 쪽", "둘째 쪽"}
+		if breaks {
+			want = []string{"첫 쪽", "break s0.r2.pb", "둘째 쪽"}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("breaks %v: nodes = %q, want %q", breaks, got, want)
+		}
+	}
+}
+
+func TestControls(t *testing.T) {
+	ctrl := func(id string) []byte {
+		return binary.LittleEndian.AppendUint32(nil, binary.BigEndian.Uint32([]byte(id)))
+	}
+	set := []byte{0, 0, 1, 0, 0, 0, paramTypeBSTR, 0}
+	set = binary.LittleEndian.AppendUint16(set, uint16(len([]rune("요약"))))
+	set = append(set, utf16Bytes("요약")...)
+
+	stream := (&recordStream{}).para(0, "본문")
+	stream.add(recTagCtrlHeader, 1, ctrl("secd"))
+	// A field leaves the paragraph open for the bookmark after it
+	stream.add(recTagCtrlHeader, 1, ctrl("%dte"))
+	stream.add(recTagCtrlHeader, 1, ctrl("bokm"))
+	stream.add(recTagCtrlData, 2, set)
+	stream.add(recTagCtrlHeader, 1, ctrl("head"))
+	stream.add(recTagListHeader, 2, make([]byte, 8))
+	stream.para(2, "머리말")
+	stream.add(recTagCtrlHeader, 1, ctrl("fn  "))
+	stream.add(recTagListHeader, 2, make([]byte, 8))
+	stream.para(2, "각주")
+	stream.add(recTagCtrlHeader, 1, ctrl("zzzz"))
+	stream.para(2, "모름")
+	stream.para(0, "끝")
+
+	var warnings []error
+	opts := document.DefaultScanOptions()
+	opts.OnWarning = func(err error) { warnings = append(warnings, err) }
+	s := newTestScanner(stream, opts)
+	var got []string
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch n := node.(type) {
+		case *document.Paragraph:
+			got = append(got, fmt.Sprintf("%s %v %v", n.Text, n.Floating, n.Bookmarks))
+		case *document.Footnote:
+			got = append(got, fmt.Sprintf("%d) %s %s %d", n.Number, n.Text, n.Anchor, len(n.Paragraphs)))
+		}
+	}
+	want := []string{"본문 false [요약]", "머리말 true []", "1) 각주 s0.r0 1", "끝 false []"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paragraphs = %q, want %q", got, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), `skipped unknown control "zzzz"`) {
+		t.Errorf("warnings = %v, want the unknown control", warnings)
+	}
+	if got := CtrlName(ctrlIDFootnote); got != "footnote 'fn  '" {
+		t.Errorf("CtrlName = %q", got)
+	}
+}
+
+// cellHeader returns a table cell ListHeader for the cell at row, col.
+func cellHeader(row, col uint16) []byte {
+	cell := make([]byte, 34)
+	binary.LittleEndian.PutUint16(cell[8:], col)
+	binary.LittleEndian.PutUint16(cell[10:], row)
+	cell[12], cell[14] = 1, 1 // 1x1 span
+	return cell
+}
+
+// tableRecord returns a borderless Table record with rows by cols cells.
+func tableRecord(rows, cols uint16) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, 0)
+	data = binary.LittleEndian.AppendUint16(data, rows)
+	data = binary.LittleEndian.AppendUint16(data, cols)
+	return append(data, make([]byte, 10+2*int(rows)+2)...)
+}
+
+func TestCellProperties(t *testing.T) {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 1)    // paragraph count
+	data = binary.LittleEndian.AppendUint32(data, 2<<5) // property: bottom
+	for _, n := range []uint16{2, 1, 1, 3} {            // column, row, spans
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+	data = binary.LittleEndian.AppendUint32(data, 7200)
+	data = binary.LittleEndian.AppendUint32(data, 2400)
+	for _, n := range []uint16{510, 510, 141, 141, 4} { // margins, border fill
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+
+	rec, err := NewRecScanner(&(&recordStream{}).add(recTagListHeader, 2, data).buf).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RecListHeader{
+		recHeader: recHeader{TagID: recTagListHeader, Level: 2, Size: 34},
+		IsCell:    true, ParaCount: 1, Property: 2 << 5,
+		ColIndex: 2, RowIndex: 1, ColSpan: 1, RowSpan: 3,
+		Width: 7200, Height: 2400,
+		MarginLeft: 510, MarginRight: 510, MarginTop: 141, MarginBottom: 141,
+		BorderFillID: 4,
+	}
+	if rec != want {
+		t.Errorf("cell = %+v, want %+v", rec, want)
+	}
+	if got := rec.(RecListHeader).VertAlign(); got != ListAlignBottom {
+		t.Errorf("VertAlign() = %d, want %d", got, ListAlignBottom)
+	}
+}
+
+func TestNestedTable(t *testing.T) {
+	tblCtrl := binary.LittleEndian.AppendUint32(nil, 0x74626c20)
+
+	stream := (&recordStream{}).para(0, "앞")
+	stream.add(recTagCtrlHeader, 1, tblCtrl)
+	stream.add(recTagTable, 2, tableRecord(1, 2))
+	stream.add(recTagListHeader, 2, cellHeader(0, 0))
+	stream.para(2, "바깥")
+	// A table in the first cell
+	stream.add(recTagCtrlHeader, 3, tblCtrl)
+	stream.add(recTagTable, 4, tableRecord(1, 1))
+	stream.add(recTagListHeader, 4, cellHeader(0, 0))
+	stream.para(4, "안쪽")
+	stream.add(recTagListHeader, 2, cellHeader(0, 1))
+	stream.para(2, "둘째")
+	stream.para(0, "뒤")
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	var tables []*document.Table
+	var texts []string
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch n := node.(type) {
+		case *document.Table:
+			tables = append(tables, n)
+		case *document.Paragraph:
+			texts = append(texts, n.Text)
+		}
+	}
+
+	if len(texts) != 2 || texts[0] != "앞" || texts[1] != "뒤" {
+		t.Errorf("paragraphs = %q, want [앞 뒤]", texts)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("got %d top-level tables, want 1", len(tables))
+	}
+	outer := tables[0]
+	if len(outer.Cells) != 2 || outer.Cells[0].Text != "바깥" || outer.Cells[1].Text != "둘째" {
+		t.Fatalf("outer cells = %+v, want 바깥 and 둘째", outer.Cells)
+	}
+	nested := outer.Cells[0].Tables
+	if len(nested) != 1 || len(nested[0].Cells) != 1 || nested[0].Cells[0].Text != "안쪽" {
+		t.Errorf("nested tables = %+v, want one table with cell 안쪽", nested)
+	}
+	if len(outer.Cells[1].Tables) != 0 {
+		t.Errorf("second cell has nested tables %+v", outer.Cells[1].Tables)
+	}
+}
+
+func TestGroupedShapeText(t *testing.T) {
+	stream := (&recordStream{}).para(0, "앞")
+	stream.add(recTagCtrlHeader, 1, binary.LittleEndian.AppendUint32(nil, 0x67736f20))
+	stream.add(recTagShapeComponent, 2, nil)
+	stream.add(recTagShapeComponentContainer, 3, nil)
+	// Two grouped rectangles with text boxes; the second holds a nested
+	// group with its own text box
+	stream.add(recTagShapeComponent, 3, nil)
+	stream.add(recTagListHeader, 4, make([]byte, 20))
+	stream.para(4, "첫 상자")
+	stream.para(4, "")
+	stream.add(recTagShapeComponentRectangle, 4, nil)
+	stream.add(recTagShapeComponent, 3, nil)
+	stream.add(recTagShapeComponentContainer, 4, nil)
+	stream.add(recTagShapeComponent, 4, nil)
+	stream.add(recTagListHeader, 5, make([]byte, 20))
+	stream.para(5, "안쪽 상자")
+	stream.para(0, "뒤")
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	var got []string
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch n := node.(type) {
+		case *document.Paragraph:
+			if n.Floating != (n.Text != "앞" && n.Text != "뒤") {
+				t.Errorf("paragraph %q: floating = %v", n.Text, n.Floating)
+			}
+			got = append(got, n.Text)
+		case *document.Image:
+			got = append(got, "[IMAGE]")
+		}
+	}
+	// Shapes holding only text get no image placeholder
+	want := []string{"앞", "첫 상자", "안쪽 상자", "뒤"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("nodes = %q, want %q", got, want)
+	}
+}
+
+func TestNodeIDs(t *testing.T) {
+	stream := (&recordStream{}).para(0, "a").para(0, "b", paraTextCodeParaBreak, "c")
+	opts := document.DefaultScanOptions()
+	opts.SplitOnParaBreak = true
+
+	s := newTestScanner(stream, opts)
+	var ids []string
+	for {
+		node, err := s.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, node.(*document.Paragraph).ID)
+	}
+
+	want := []string{"s0.r0", "s0.r3", "s0.r3.1"}
+	if len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+}
+
+func TestEmbeddedDocument(t *testing.T) {
+	gso := binary.LittleEndian.AppendUint32(nil, 0x67736f20)
+	ole := make([]byte, 20)
+	binary.LittleEndian.PutUint16(ole[12:], 3)
+	outer := (&recordStream{}).add(recTagCtrlHeader, 1, gso).
+		add(recTagShapeComponent, 2, nil).
+		add(recTagShapeComponentOLE, 3, ole)
+
+	s := newTestScanner(outer, document.DefaultScanOptions())
+	rec, err := s.nextRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := s.readObject(rec.Lvl())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !obj.ole || obj.binDataID != 3 {
+		t.Errorf("object = %+v, want OLE object with BinData 3", obj)
+	}
+
+	inner := newTestScanner((&recordStream{}).para(0, "첨부"), document.DefaultScanOptions())
+	e := &embeddedScanner{id: "s0.r7", inner: inner}
+	var got []string
+	for {
+		node, err := e.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := node.(*document.Paragraph)
+		got = append(got, p.ID+"="+p.Text)
+	}
+	want := []string{"s0.r7.begin=" + embeddedBeginText, "s0.r7:s0.r0=첨부", "s0.r7.end=" + embeddedEndText}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("nodes = %q, want %q", got, want)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	tbl := binary.LittleEndian.AppendUint32(nil, 0x74626c20)
+	stream := func() *recordStream {
+		rs := (&recordStream{}).para(0, "a").para(0, "b")
+		rs.add(recTagCtrlHeader, 1, tbl).add(recTagTable, 2, tableRecord(1, 1))
+		rs.add(recTagListHeader, 2, make([]byte, 34)).para(2, "cell")
+		return rs.para(0, "c").para(0, "d")
+	}
+
+	var checkpoints []document.Checkpoint
+	opts := document.DefaultScanOptions()
+	opts.OnCheckpoint = func(cp document.Checkpoint) { checkpoints = append(checkpoints, cp) }
+	s := newTestScanner(stream(), opts)
+
+	var ids []string
+	var afterNode []int // number of nodes returned before each checkpoint
+	for {
+		n := len(checkpoints)
+		node, err := s.Next()
+		if len(checkpoints) > n {
+			afterNode = append(afterNode, len(ids))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch node := node.(type) {
+		case *document.Paragraph:
+			ids = append(ids, node.ID+"="+node.Text)
+		case *document.Table:
+			ids = append(ids, node.ID+"=table")
+		}
+	}
+	if len(checkpoints) == 0 {
+		t.Fatal("no checkpoints reported")
+	}
+
+	for i, cp := range checkpoints {
+		resumed := newTestScanner(stream(), document.DefaultScanOptions())
+		if err := resumed.seek(cp); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for {
+			node, err := resumed.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch node := node.(type) {
+			case *document.Paragraph:
+				got = append(got, node.ID+"="+node.Text)
+			case *document.Table:
+				got = append(got, node.ID+"=table")
+			}
+		}
+		want := ids[afterNode[i]:]
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("resume from %+v = %q, want %q", cp, got, want)
+		}
+	}
+}
+
+func TestParaHeaderRecord(t *testing.T) {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 1<<31|12)
+	data = binary.LittleEndian.AppendUint32(data, 1<<paraTextCodeTab)
+	data = binary.LittleEndian.AppendUint16(data, 3)
+	data = append(data, 2, 4) // style, page break
+	for _, n := range []uint16{2, 0, 1} {
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+	data = binary.LittleEndian.AppendUint32(data, 0x8000_0001)
+
+	stream := (&recordStream{}).add(recTagParaHeader, 0, data)
+	rec, err := NewRecScanner(&stream.buf).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RecParaHeader{
+		recHeader:   rec.(RecParaHeader).recHeader,
+		TextLength:  12,
+		ControlMask: 1 << paraTextCodeTab,
+		ParaShapeID: 3, StyleID: 2, BreakType: 4,
+		CharShapeCount: 2, LineSegCount: 1,
+		InstanceID: 0x8000_0001,
+	}
+	if rec != want {
+		t.Errorf("ParaHeader = %+v, want %+v", rec, want)
+	}
+}
+
+func TestLayoutLineBreaks(t *testing.T) {
+	lineSegs := func(starts ...uint32) []byte {
+		var data []byte
+		for i, start := range starts {
+			data = binary.LittleEndian.AppendUint32(data, start)
+			data = binary.LittleEndian.AppendUint32(data, uint32(i*1600)) // vertical position
+			data = append(data, make([]byte, 28)...)
+		}
+		return data
+	}
+	rec, err := NewRecScanner(&(&recordStream{}).add(recTagParaLineSeg, 0, lineSegs(0, 4, 7)).buf).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segs := rec.(RecParaLineSeg).Segs; len(segs) != 3 || segs[1].TextStart != 4 || segs[2].VerticalPos != 3200 {
+		t.Errorf("line segments = %+v, want starts 0, 4, 7", segs)
+	}
+
+	// "가나다 라마" wraps before "라마" and ends with a line break
+	text := append(append(utf16Bytes("가나다 라마"), binary.LittleEndian.AppendUint16(nil, paraTextCodeLineBreak)...), utf16Bytes("바사")...)
+	for _, tc := range []struct {
+		layout bool
+		want   string
+	}{
+		{false, "가나다 라마\n바사"},
+		{true, "가나다 \n라마\n바사"},
+	} {
+		opts := document.DefaultScanOptions()
+		opts.LayoutLineBreaks = tc.layout
+		stream := &recordStream{}
+		stream.add(recTagParaHeader, 0, make([]byte, 12))
+		stream.add(recTagParaText, 1, text)
+		stream.add(recTagParaLineSeg, 1, lineSegs(0, 4, 7))
+		s := newTestScanner(stream, opts)
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := node.(*document.Paragraph).Text; got != tc.want {
+			t.Errorf("layout %v: text = %q, want %q", tc.layout, got, tc.want)
+		}
+	}
+}
+
+func TestFootnoteShapeRecord(t *testing.T) {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 1<<12|NoteNumberingPerSection<<10|uint32(NumberCircledDigits))
+	for _, c := range []uint16{0, 0, ')', 1} { // user symbol, prefix, suffix, start
+		data = binary.LittleEndian.AppendUint16(data, c)
+	}
+	data = binary.LittleEndian.AppendUint32(data, 5000)
+	for _, n := range []uint16{850, 567, 283} {
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+	data = append(data, 1, 2)
+	data = binary.LittleEndian.AppendUint32(data, 0x000000ff)
+
+	stream := (&recordStream{}).add(recTagFootnoteShape, 1, data)
+	rec, err := NewRecScanner(&stream.buf).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shape := rec.(RecFootnoteShape)
+	if shape.SeparatorLength != 5000 || shape.NoteSpacing != 283 || shape.SeparatorColor != 0xff || shape.StartNumber != 1 {
+		t.Errorf("footnote shape = %+v", shape)
+	}
+	if shape.Numbering() != NoteNumberingPerSection || shape.Placement() != NotePlaceColumnBottom || !shape.Superscript() {
+		t.Errorf("footnote shape property = %#x", shape.Property)
+	}
+	if got := shape.Number(3); got != "③)" {
+		t.Errorf("Number(3) = %q, want %q", got, "③)")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	for _, tc := range []struct {
+		format uint8
+		n      int
+		want   string
+	}{
+		{NumberDigits, 12, "12"},
+		{NumberCircledDigits, 20, "⑳"},
+		{NumberCircledDigits, 21, "21"},
+		{NumberUpperRoman, 14, "XIV"},
+		{NumberLowerRoman, 1999, "mcmxcix"},
+		{NumberUpperLatin, 28, "BB"},
+		{NumberCircledLowerLatin, 3, "ⓒ"},
+		{NumberHangulSyllable, 4, "라"},
+		{NumberCircledHangul, 2, "㉯"},
+		{NumberHangulJamo, 14, "ㅎ"},
+		{NumberHangulSyllable, 15, "15"},
+		{NumberHangulNumeral, 13, "십삼"},
+		{NumberHangulNumeral, 205, "이백오"},
+		{NumberIdeograph, 21, "二十一"},
+		{NumberCircledIdeograph, 10, "㊉"},
+		{NumberHeavenlyStemHangul, 3, "병"},
+		{NumberHeavenlyStem, 10, "癸"},
+		{NumberSymbols, 6, "††"},
+		{NumberUserSymbol, 2, "※"},
+	} {
+		if got := FormatNumber(tc.format, tc.n, '※'); got != tc.want {
+			t.Errorf("FormatNumber(%d, %d) = %q, want %q", tc.format, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestPageBorderFillRecord(t *testing.T) {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, PageFillBorder<<3|1<<1|1)
+	for _, n := range []uint16{1417, 1417, 1417, 1417, 2} {
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+
+	stream := (&recordStream{}).add(recTagPageBorderFill, 1, data)
+	rec, err := NewRecScanner(&stream.buf).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill := rec.(RecPageBorderFill)
+	if fill.MarginLeft != 1417 || fill.MarginBottom != 1417 || fill.BorderFillID != 2 {
+		t.Errorf("page border fill = %+v", fill)
+	}
+	if !fill.FromPaperEdge() || !fill.IncludesHeader() || fill.IncludesFooter() || fill.FillArea() != PageFillBorder {
+		t.Errorf("page border fill property = %#x", fill.Property)
+	}
+}
+
+func TestImageProperties(t *testing.T) {
+	gso := binary.LittleEndian.AppendUint32(nil, 0x67736f20)
+	gso = binary.LittleEndian.AppendUint32(gso, 2<<3) // relative to the paragraph
+	gso = append(gso, make([]byte, 8)...)             // offsets
+	gso = binary.LittleEndian.AppendUint32(gso, 30000)
+	gso = binary.LittleEndian.AppendUint32(gso, 15000)
+	gso = append(gso, make([]byte, 20)...) // z-order, margins, instance ID, page break
+	gso = binary.LittleEndian.AppendUint16(gso, 5)
+	gso = append(gso, utf16Bytes("회사 로고")...)
+
+	var picture []byte
+	picture = append(picture, make([]byte, 12)...) // border
+	for _, v := range []int32{0, 0, 6000, 0, 6000, 4000, 0, 4000, 100, 200, 5900, 3800} {
+		picture = binary.LittleEndian.AppendUint32(picture, uint32(v)) // corners, crop
+	}
+	picture = append(picture, make([]byte, 8)...) // margins
+	picture = append(picture, 0xf6, 10, 1, 0, 0)  // brightness, contrast, effect, BinData ID
+	picture = append(picture, make([]byte, 5)...)
+
+	stream := (&recordStream{}).add(recTagCtrlHeader, 1, gso).
+		add(recTagShapeComponent, 2, nil).
+		add(recTagShapeComponentPicture, 3, picture)
+	rec, err := NewRecScanner(bytes.NewReader(stream.buf.Bytes())).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width, height, desc := objectProperties(rec.(RecCtrlHeader).Data); width != 30000 || height != 15000 || desc != "회사 로고" {
+		t.Errorf("object properties = %d, %d, %q", width, height, desc)
+	}
+	if got := objectAnchor(rec.(RecCtrlHeader).Data); got != document.AnchorParagraph {
+		t.Errorf("object anchor = %q, want %q", got, document.AnchorParagraph)
+	}
+	pictureRec, err := NewRecScanner(&(&recordStream{}).add(recTagShapeComponentPicture, 3, picture).buf).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RecShapeComponentPicture{
+		recHeader:  pictureRec.(RecShapeComponentPicture).recHeader,
+		ImageWidth: 6000, ImageHeight: 4000,
+		CropLeft: 100, CropTop: 200, CropRight: 5900, CropBottom: 3800,
+		Brightness: -10, Contrast: 10, Effect: 1,
+	}
+	if pictureRec != want {
+		t.Errorf("picture = %+v, want %+v", pictureRec, want)
+	}
+
+	node, err := newTestScanner(stream, document.DefaultScanOptions()).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img := node.(*document.Image); img.Width != 300 || img.Height != 150 || img.Alt != "회사 로고" {
+		t.Errorf("image = %+v, want 300x150pt with alt text", img)
+	}
+}
+
+func TestTableRecord(t *testing.T) {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 1<<2|TableBreakCell)
+	for _, n := range []uint16{2, 2, 0, 510, 510, 141, 141, 2, 1, 1} { // rows, cols, spacing, margins, row sizes, border fill
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+	data = binary.LittleEndian.AppendUint16(data, 1)
+	for _, n := range []uint16{0, 0, 1, 0, 2} {
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+
+	rec, err := NewRecScanner(&(&recordStream{}).add(recTagTable, 2, data).buf).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := rec.(RecTable)
+	if table.RowCount != 2 || table.MarginLeft != 510 || table.MarginBottom != 141 || table.BorderFillID != 1 {
+		t.Errorf("table = %+v", table)
+	}
+	if len(table.RowSizes) != 2 || table.RowSizes[0] != 2 || table.RowSizes[1] != 1 {
+		t.Errorf("row sizes = %v, want [2 1]", table.RowSizes)
+	}
+	if want := []TableZone{{EndCol: 1, BorderFillID: 2}}; !reflect.DeepEqual(table.Zones, want) {
+		t.Errorf("zones = %+v, want %+v", table.Zones, want)
+	}
+	if !table.RepeatHeader() || table.PageBreak() != TableBreakCell {
+		t.Errorf("table property = %#x", table.Property)
+	}
+}
+
+func TestTruncatedRecord(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		tag  uint16
+		data []byte
+	}{
+		{"list header", recTagListHeader, make([]byte, 4)},
+		{"table row sizes", recTagTable, tableRecord(3, 1)[:20]},
+		{"extended control", recTagParaText, append(utf16Bytes("표"), 11, 0, 0x20, 0x6c, 0x62, 0x74)},
+		{"char shape run", recTagParaCharShape, make([]byte, 12)},
+	} {
+		stream := (&recordStream{}).add(recTagCtrlHeader, 0, make([]byte, 4)).add(tc.tag, 1, tc.data)
+		s := NewRecScanner(&stream.buf)
+		if _, err := s.ScanNext(); err != nil {
+			t.Fatal(err)
+		}
+		_, err := s.ScanNext()
+		var recErr *RecordError
+		if !errors.Is(err, ErrTruncatedRecord) || !errors.As(err, &recErr) {
+			t.Errorf("%s: error = %v, want a truncated RecordError", tc.name, err)
+			continue
+		}
+		if recErr.Tag != tc.tag || recErr.Offset != 8 || recErr.Record != 1 {
+			t.Errorf("%s: RecordError = %+v, want record 1, tag %#x at offset 8", tc.name, recErr, tc.tag)
+		}
+	}
+}
+
+func TestRecordErrorLocation(t *testing.T) {
+	stream := (&recordStream{}).add(recTagCtrlHeader, 0, make([]byte, 4)).add(recTagListHeader, 1, make([]byte, 4))
+	// A header cut off by the end of the stream
+	stream.buf.Write([]byte{0x42, 0})
+
+	s := NewRecScanner(&stream.buf)
+	s.stream = "BodyText/Section1"
+	var errs []string
+	for {
+		_, err := s.ScanNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			if !errors.Is(err, ErrTruncatedRecord) {
+				break
+			}
+		}
+	}
+	want := []string{
+		"BodyText/Section1 offset 0x8 record 1 tag 0x48 (HWPTAG_LIST_HEADER): truncated record: 4 bytes needed at byte 4, 0 left",
+		"BodyText/Section1 offset 0x10 record 2: read record header: unexpected EOF",
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors =\n%s\nwant\n%s", strings.Join(errs, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	stream := (&recordStream{}).add(recTagCtrlHeader, 0, make([]byte, 4)).add(recTagParaText, 1, make([]byte, 64))
+	s := NewRecScanner(bytes.NewReader(stream.buf.Bytes()))
+	s.maxSize = 32
+	if _, err := s.ScanNext(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ScanNext(); !errors.Is(err, limits.ErrExceeded) {
+		t.Errorf("oversized record error = %v, want ErrExceeded", err)
+	}
+
+	// A header declaring far more data than the stream holds
+	stream = &recordStream{}
+	stream.buf.Write(binary.LittleEndian.AppendUint32(nil, 0xfff<<20|recTagParaText))
+	stream.buf.Write(binary.LittleEndian.AppendUint32(nil, 0xfffffff0))
+	if _, err := NewRecScanner(&stream.buf).ScanNext(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("missing record data error = %v, want ErrUnexpectedEOF", err)
+	}
+
+	tblCtrl := binary.LittleEndian.AppendUint32(nil, 0x74626c20)
+	stream = (&recordStream{}).para(0, "앞")
+	stream.add(recTagCtrlHeader, 1, tblCtrl)
+	stream.add(recTagTable, 2, tableRecord(2, 2))
+	stream.add(recTagListHeader, 2, cellHeader(0, 0))
+	stream.para(2, "a")
+	stream.add(recTagListHeader, 2, cellHeader(0, 2))
+	stream.para(2, "b")
+	opts := document.DefaultScanOptions()
+	opts.MaxTableCells = 4
+	scanner := newTestScanner(stream, opts)
+	var err error
+	for err == nil {
+		_, err = scanner.Next()
+	}
+	if !errors.Is(err, limits.ErrExceeded) {
+		t.Errorf("table error = %v, want ErrExceeded", err)
+	}
+}
+
+func TestNestedResourceLimits(t *testing.T) {
+	// An oversized record under a drawing object or a skipped control ends
+	// the scan, with recovery too, instead of being read past
+	gso := binary.LittleEndian.AppendUint32(nil, ctrlIDShape)
+	atno := binary.LittleEndian.AppendUint32(nil, ctrlIDAutoNumber)
+	for _, ctrl := range [][]byte{gso, atno} {
+		for _, recovery := range []bool{false, true} {
+			stream := (&recordStream{}).para(0, "앞")
+			stream.add(recTagCtrlHeader, 1, ctrl)
+			stream.add(recTagShapeComponent, 2, make([]byte, 200))
+			stream.para(0, "뒤")
+			opts := document.DefaultScanOptions()
+			opts.Recover = recovery
+			s := newTestScanner(stream, opts)
+			s.scanner.maxSize = 64
+			var texts []string
+			var err error
+			for err == nil {
+				var node document.ContentNode
+				if node, err = s.Next(); err == nil {
+					if p, ok := node.(*document.Paragraph); ok {
+						texts = append(texts, p.Text)
+					}
+				}
+			}
+			if !errors.Is(err, limits.ErrExceeded) {
+				t.Errorf("%s, recover %v: error = %v, want ErrExceeded", CtrlName(binary.LittleEndian.Uint32(ctrl)), recovery, err)
+			}
+			if slices.Contains(texts, "뒤") {
+				t.Errorf("%s, recover %v: scan went on past the oversized record: %q", CtrlName(binary.LittleEndian.Uint32(ctrl)), recovery, texts)
+			}
+		}
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	stream := (&recordStream{}).para(0, "앞")
+	stream.add(recTagListHeader, 0, make([]byte, 4))
+	stream.para(0, "뒤")
+	// A record whose data is cut off by the end of the stream
+	stream.buf.Write(binary.LittleEndian.AppendUint32(nil, 100<<20|recTagParaHeader))
+	stream.buf.Write(make([]byte, 10))
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	var err error
+	for err == nil {
+		_, err = s.Next()
+	}
+	if !errors.Is(err, ErrTruncatedRecord) {
+		t.Errorf("error = %v, want ErrTruncatedRecord", err)
+	}
+
+	opts := document.DefaultScanOptions()
+	opts.Recover = true
+	var warnings []error
+	opts.OnWarning = func(err error) { warnings = append(warnings, err) }
+	if got := collectTexts(t, newTestScanner(stream, opts)); !reflect.DeepEqual(got, []string{"앞", "뒤"}) {
+		t.Errorf("texts = %q, want [앞 뒤]", got)
+	}
+	if len(warnings) != 2 || !errors.Is(warnings[0], ErrTruncatedRecord) || !errors.Is(warnings[1], io.ErrUnexpectedEOF) {
+		t.Errorf("warnings = %v, want a truncated record and a cut-off stream", warnings)
+	}
+}
+
+func TestNestedTruncatedRecord(t *testing.T) {
+	// A truncated record under a drawing object or a skipped control fails
+	// the scan as at the top level, and is skipped with a warning only with
+	// recovery
+	for _, ctrlID := range []uint32{ctrlIDShape, ctrlIDAutoNumber} {
+		stream := (&recordStream{}).para(0, "앞")
+		stream.add(recTagCtrlHeader, 1, binary.LittleEndian.AppendUint32(nil, ctrlID))
+		stream.add(recTagListHeader, 2, make([]byte, 4))
+		stream.para(0, "뒤")
+
+		s := newTestScanner(stream, document.DefaultScanOptions())
+		var err error
+		for err == nil {
+			_, err = s.Next()
+		}
+		var recErr *RecordError
+		if !errors.Is(err, ErrTruncatedRecord) || !errors.As(err, &recErr) || recErr.Tag != recTagListHeader {
+			t.Errorf("%s: error = %v, want a truncated list header", CtrlName(ctrlID), err)
+		}
+
+		opts := document.DefaultScanOptions()
+		opts.Recover = true
+		var warnings []error
+		opts.OnWarning = func(err error) { warnings = append(warnings, err) }
+		if got := collectTexts(t, newTestScanner(stream, opts)); !slices.Contains(got, "뒤") {
+			t.Errorf("%s: texts with recovery = %q, want 뒤 read", CtrlName(ctrlID), got)
+		}
+		if len(warnings) != 1 || !errors.Is(warnings[0], ErrTruncatedRecord) {
+			t.Errorf("%s: warnings = %v, want a truncated record", CtrlName(ctrlID), warnings)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tblCtrl := binary.LittleEndian.AppendUint32(nil, 0x74626c20)
+	stream := (&recordStream{}).para(0, "앞")
+	stream.add(recTagParaCharShape, 1, binary.LittleEndian.AppendUint32(make([]byte, 4), 5))
+	stream.add(recTagCtrlHeader, 1, tblCtrl)
+	stream.add(recTagTable, 2, tableRecord(2, 2))
+	stream.add(recTagListHeader, 2, cellHeader(0, 0))
+	stream.para(2, "a")
+	stream.add(recTagListHeader, 2, cellHeader(2, 1))
+	stream.para(2, "b")
+	stream.add(recTagListHeader, 2, make([]byte, 4))
+	stream.para(0, "뒤")
+	stream.add(recTagParaText, 3, utf16Bytes("x"))
+
+	v := &validator{docInfo: &DocInfo{
+		CharShapes: make([]CharShape, 1),
+		ParaShapes: make([]ParaShape, 1),
+		Styles:     make([]Style, 1),
+	}}
+	if err := v.run(NewRecScanner(&stream.buf)); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, violation := range v.violations {
+		got = append(got, fmt.Sprintf("%d %s: %s", violation.Record, violation.Tag, violation.Message))
+	}
+	want := []string{
+		"3 HWPTAG_PARA_CHAR_SHAPE: reference to char shape 5, but the document has 1",
+		"5 HWPTAG_TABLE: cells cover 2 of the 4 cells of the 2x2 table",
+		"10 HWPTAG_LIST_HEADER: cell at row 2, column 1 spanning 1x1 is outside the 2x2 table",
+		"14 HWPTAG_LIST_HEADER: truncated record: 4 bytes needed at byte 4, 0 left",
+		"18 HWPTAG_PARA_TEXT: level 3 record follows a level 1 record",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSectionDiscovery(t *testing.T) {
+	streams := []Stream{
+		{Name: "BodyText", Storage: true},
+		{Name: "BodyText/Section10", Size: 10},
+		{Name: "BodyText/Section2", Size: 10},
+		{Name: "BodyText/Section0", Size: 10},
+		{Name: "BodyText/Section1", Size: 10},
+		{Name: "BodyText/SectionX", Size: 10},
+		{Name: "ViewText/Section0", Size: 10},
+	}
+	for _, tc := range []struct {
+		declared int
+		streams  []Stream
+		want     []int
+		warning  string
+	}{
+		{4, streams, []int{0, 1, 2, 10}, "not numbered consecutively"},
+		{1, streams, []int{0, 1, 2, 10}, "declares 1 sections, but the file holds 4"},
+		{3, streams[2:5], []int{0, 1, 2}, ""},
+		{2, nil, []int{0, 1}, ""},
+	} {
+		var warnings []string
+		r := &Reader{DocInfo: &DocInfo{}}
+		r.DocInfo.Properties.SectionCount = tc.declared
+		r.opts.OnWarning = func(err error) { warnings = append(warnings, err.Error()) }
+		r.setSections(tc.streams)
+		if !reflect.DeepEqual(r.sections, tc.want) {
+			t.Errorf("declared %d: sections = %v, want %v", tc.declared, r.sections, tc.want)
+		}
+		if tc.warning == "" && len(warnings) > 0 || tc.warning != "" && (len(warnings) != 1 || !strings.Contains(warnings[0], tc.warning)) {
+			t.Errorf("declared %d: warnings = %q, want %q", tc.declared, warnings, tc.warning)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package hwpv5
+
+import "fmt"
+
+// Control IDs are four ASCII characters packed big-endian, MAKE_4CHID(a, b,
+// c, d). Field controls start with '%'; the click-here, hyperlink, form and
+// page controls are declared with the code that reads them.
+const (
+	ctrlIDTable         = 0x74626c20 // 'tbl ', table
+	ctrlIDShape         = 0x67736f20 // 'gso ', drawing object
+	ctrlIDEquation      = 0x65716564 // 'eqed', equation
+	ctrlIDSectionDef    = 0x73656364 // 'secd', section definition
+	ctrlIDColumnDef     = 0x636f6c64 // 'cold', column definition
+	ctrlIDHeader        = 0x68656164 // 'head', page header (머리말)
+	ctrlIDFooter        = 0x666f6f74 // 'foot', page footer (꼬리말)
+	ctrlIDFootnote      = 0x666e2020 // 'fn  ', footnote (각주)
+	ctrlIDEndnote       = 0x656e2020 // 'en  ', endnote (미주)
+	ctrlIDAutoNumber    = 0x61746e6f // 'atno', auto number
+	ctrlIDIndexMark     = 0x6964786d // 'idxm', index mark (찾아보기 표식)
+	ctrlIDBookmark      = 0x626f6b6d // 'bokm', bookmark
+	ctrlIDOverlap       = 0x74637073 // 'tcps', overlapping letters (글자 겹침)
+	ctrlIDDutmal        = 0x74647574 // 'tdut', ruby text (덧말)
+	ctrlIDHiddenComment = 0x74636d74 // 'tcmt', hidden comment (숨은 설명)
+)
+
+// controlKind tells where a control belongs in the content.
+type controlKind int
+
+const (
+	// inlineControl belongs to the paragraph that holds it: a field or
+	// a mark, or a definition that leaves no content.
+	inlineControl controlKind = iota
+	// blockControl has content of its own that follows the paragraph.
+	blockControl
+)
+
+type controlInfo struct {
+	name string
+	kind controlKind
+}
+
+// controls holds the control IDs of the specification.
+var controls = map[uint32]controlInfo{
+	ctrlIDTable:         {"table", blockControl},
+	ctrlIDShape:         {"drawing object", blockControl},
+	ctrlIDEquation:      {"equation", blockControl},
+	ctrlIDHeader:        {"header", blockControl},
+	ctrlIDFooter:        {"footer", blockControl},
+	ctrlIDFootnote:      {"footnote", blockControl},
+	ctrlIDEndnote:       {"endnote", blockControl},
+	ctrlIDHiddenComment: {"hidden comment", blockControl},
+	ctrlIDSectionDef:    {"section definition", inlineControl},
+	ctrlIDColumnDef:     {"column definition", inlineControl},
+	ctrlIDAutoNumber:    {"auto number", inlineControl},
+	ctrlIDNewNumber:     {"new number", inlineControl},
+	ctrlIDPageHiding:    {"page hiding", inlineControl},
+	ctrlIDPageNumCtrl:   {"odd/even page adjustment", inlineControl},
+	ctrlIDPageNumPos:    {"page number position", inlineControl},
+	ctrlIDIndexMark:     {"index mark", inlineControl},
+	ctrlIDBookmark:      {"bookmark", inlineControl},
+	ctrlIDOverlap:       {"overlapping letters", inlineControl},
+	ctrlIDDutmal:        {"ruby text", inlineControl},
+	ctrlIDForm:          {"form object", inlineControl},
+	ctrlIDClickHere:     {"click-here field", inlineControl},
+	ctrlIDHyperlink:     {"hyperlink field", inlineControl},
+	0x25756e6b:          {"unknown field", inlineControl},             // '%unk'
+	0x25647465:          {"date field", inlineControl},                // '%dte'
+	0x25646474:          {"document date field", inlineControl},       // '%ddt'
+	0x25706174:          {"file path field", inlineControl},           // '%pat'
+	0x25626d6b:          {"bookmark field", inlineControl},            // '%bmk'
+	0x256d6d67:          {"mail merge field", inlineControl},          // '%mmg'
+	0x25787266:          {"cross-reference field", inlineControl},     // '%xrf'
+	0x25666d75:          {"formula field", inlineControl},             // '%fmu'
+	0x25736d72:          {"summary field", inlineControl},             // '%smr'
+	0x25757372:          {"user information field", inlineControl},    // '%usr'
+	0x25736967:          {"revision sign field", inlineControl},       // '%sig'
+	ctrlIDMemo:          {"memo field", inlineControl},                // '%%me'
+	0x25637072:          {"private information field", inlineControl}, // '%cpr'
+	0x25746f63:          {"table of contents field", inlineControl},   // '%toc'
+}
+
+// ctrlChars returns the four characters of a control ID.
+func ctrlChars(id uint32) string {
+	return string([]byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)})
+}
+
+// CtrlName returns a description of a control ID with its characters, e.g.
+// "table 'tbl '", or just the characters for IDs not in the specification.
+func CtrlName(id uint32) string {
+	if c, ok := controls[id]; ok {
+		return fmt.Sprintf("%s '%s'", c.name, ctrlChars(id))
+	}
+	return fmt.Sprintf("%q", ctrlChars(id))
+}
+
+// controlKindOf returns the kind of a control, and false for unknown IDs.
+// Any ID starting with '%' is a field.
+func controlKindOf(id uint32) (controlKind, bool) {
+	if c, ok := controls[id]; ok {
+		return c.kind, true
+	}
+	return inlineControl, id>>24 == '%'
+}
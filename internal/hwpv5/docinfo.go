@@ -0,0 +1,927 @@
+package hwpv5
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// DocInfo record tags
+const (
+	recTagDocumentProperties = recTagBegin
+	recTagIDMappings         = recTagBegin + 1
+	recTagBinData            = recTagBegin + 2
+	recTagFaceName           = recTagBegin + 3
+	recTagBorderFill         = recTagBegin + 4
+	recTagCharShape          = recTagBegin + 5
+	recTagTabDef             = recTagBegin + 6
+	recTagNumbering          = recTagBegin + 7
+	recTagBullet             = recTagBegin + 8
+	recTagParaShape          = recTagBegin + 9
+	recTagStyle              = recTagBegin + 10
+	recTagDocData            = recTagBegin + 11
+	recTagDistributeDocData  = recTagBegin + 12
+	recTagCompatibleDocument = recTagBegin + 14
+	recTagLayoutCompat       = recTagBegin + 15
+	recTagTrackChangeInfo    = recTagBegin + 16
+	recTagForbiddenChar      = recTagBegin + 78
+	recTagTrackChange        = recTagBegin + 80
+	recTagTrackChangeAuthor  = recTagBegin + 81
+)
+
+// Font languages, in the order used by ID mappings and CharShape face IDs.
+const (
+	LangHangul = iota
+	LangLatin
+	LangHanja
+	LangJapanese
+	LangOther
+	LangSymbol
+	LangUser
+	langCount
+)
+
+// langNames holds the lowercase names of the font languages.
+var langNames = [langCount]string{"hangul", "latin", "hanja", "japanese", "other", "symbol", "user"}
+
+// LangName returns the lowercase name of a font language, e.g. "hangul".
+func LangName(lang int) string {
+	if lang < 0 || lang >= langCount {
+		return ""
+	}
+	return langNames[lang]
+}
+
+// DocumentProperties holds the document properties (HWPTAG_DOCUMENT_PROPERTIES).
+type DocumentProperties struct {
+	SectionCount int
+	// Start numbers of pages, footnotes, endnotes, pictures, tables and
+	// equations
+	PageStart     int
+	FootnoteStart int
+	EndnoteStart  int
+	PictureStart  int
+	TableStart    int
+	EquationStart int
+	// Caret is where the caret was when the document was saved.
+	Caret Caret
+}
+
+// Caret is a position in the document text.
+type Caret struct {
+	ListID    uint32
+	ParaID    uint32
+	CharIndex uint32
+}
+
+// ID mapping indices (HWPTAG_ID_MAPPINGS)
+const (
+	idMapBinData    = 0
+	idMapFontFirst  = 1 // one count per language, langCount entries
+	idMapBorderFill = idMapFontFirst + langCount
+	idMapCharShape  = idMapBorderFill + 1
+	idMapTabDef     = idMapCharShape + 1
+	idMapNumbering  = idMapTabDef + 1
+	idMapBullet     = idMapNumbering + 1
+	idMapParaShape  = idMapBullet + 1
+	idMapStyle      = idMapParaShape + 1
+)
+
+// ReferenceError reports a reference to a DocInfo item that the document
+// does not have.
+type ReferenceError struct {
+	// Kind names the item, e.g. "char shape".
+	Kind string
+	ID   int
+	// Count is the number of items of the kind the document has.
+	Count int
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("reference to %s %d, but the document has %d", e.Kind, e.ID, e.Count)
+}
+
+// lookup returns the item with the given ID, where the first item has the
+// ID base.
+func lookup[T any](kind string, items []T, id, base int) (T, error) {
+	if id < base || id-base >= len(items) {
+		var zero T
+		return zero, &ReferenceError{Kind: kind, ID: id, Count: len(items)}
+	}
+	return items[id-base], nil
+}
+
+// DocInfo holds the DocInfo records that body records refer to.
+type DocInfo struct {
+	Properties DocumentProperties
+	// IDMappings holds the item counts of HWPTAG_ID_MAPPINGS.
+	IDMappings []int32
+	// FaceNames holds the font list of each language, indexed by Lang*.
+	FaceNames  [langCount][]FaceName
+	CharShapes []CharShape
+	ParaShapes []ParaShape
+	TabDefs    []TabDef
+	Styles     []Style
+	// BorderFills holds the borders and fills of cells and other boxes;
+	// body records refer to them by 1-based ID.
+	BorderFills []BorderFill
+	// BinData holds the binary data items; body records refer to them by
+	// 1-based index.
+	BinData []BinDataItem
+	// Numberings and Bullets hold the paragraph numberings and bullets that
+	// paragraph shapes refer to by 1-based ID.
+	Numberings []Numbering
+	Bullets    []Bullet
+	// TrackChanges reports whether the document holds tracked changes
+	// (HWPTAG_TRACK_CHANGE and HWPTAG_TRACK_CHANGE_AUTHOR records), which
+	// are not decoded.
+	TrackChanges bool
+}
+
+// Bullet is a bullet (HWPTAG_BULLET) of bulleted paragraphs.
+type Bullet struct {
+	Char rune
+}
+
+// BinData item types (HWPTAG_BIN_DATA property bits 0-3)
+const (
+	BinDataLink      = 0 // file outside the document
+	BinDataEmbedding = 1 // stream in the BinData storage
+	BinDataStorage   = 2 // OLE storage in the BinData storage
+)
+
+// BinData compression modes (property bits 4-5)
+const (
+	binDataCompressDefault = 0 // as the document
+	binDataCompress        = 1
+	binDataNoCompress      = 2
+)
+
+// BinDataItem is a binary data item declared in DocInfo (HWPTAG_BIN_DATA).
+type BinDataItem struct {
+	Type        int
+	Compression int
+	// LinkPath and LinkRelPath are the absolute path of a linked file and
+	// its path relative to the document.
+	LinkPath    string
+	LinkRelPath string
+	// StreamID and Extension name the BinData stream (BIN%04X.ext) of an
+	// embedded item. Storage items have no extension.
+	StreamID  uint16
+	Extension string
+}
+
+// StreamName returns the name of the item's stream in the compound file.
+func (b BinDataItem) StreamName() string {
+	name := fmt.Sprintf("BinData/BIN%04X", b.StreamID)
+	if b.Extension == "" {
+		return name
+	}
+	return name + "." + strings.ToLower(b.Extension)
+}
+
+// FaceName is a font declared in DocInfo (HWPTAG_FACE_NAME).
+type FaceName struct {
+	Name string
+	// AltName is the substitute font, used when Name is not installed.
+	AltName string
+	// TypeInfo holds the 10-byte PANOSE-like font type information, if present.
+	TypeInfo []byte
+}
+
+// panoseMonospaced is the PANOSE proportion value of fixed-pitch fonts.
+const panoseMonospaced = 9
+
+// monospaceNameHints are name fragments of common fixed-pitch fonts.
+var monospaceNameHints = []string{"mono", "courier", "consolas", "code", "coding", "terminal", "fixedsys", "lucida console", "menlo", "monaco"}
+
+// Monospace reports whether the font is fixed-pitch, judged from its type
+// information when present and from well-known font names otherwise.
+func (f FaceName) Monospace() bool {
+	var proportion byte
+	if len(f.TypeInfo) >= 4 {
+		proportion = f.TypeInfo[3]
+	}
+	return MonospaceFont(f.Name, proportion)
+}
+
+// MonospaceFont reports whether the font of the name is fixed-pitch, from
+// its PANOSE proportion value, 0 if unknown, or else from well-known font
+// names. Korean fonts whose names end in 체 (굴림체, 바탕체, ...) are
+// fixed-pitch. HWPX fonts are judged the same way.
+func MonospaceFont(name string, proportion byte) bool {
+	if proportion == panoseMonospaced {
+		return true
+	}
+	name = strings.ToLower(name)
+	if strings.HasSuffix(name, "체") {
+		return true
+	}
+	for _, hint := range monospaceNameHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// CharShape is a character shape (HWPTAG_CHAR_SHAPE).
+type CharShape struct {
+	// FaceIDs indexes FaceNames for each language.
+	FaceIDs [langCount]uint16
+	// Size is the base font size in HWPUNITs (1/100 pt).
+	Size     int32
+	Property uint32
+	// Color is the text color as a COLORREF (0x00BBGGRR).
+	Color uint32
+	// BorderFillID is the 1-based ID of the border fill around the text,
+	// zero before 5.0.2.1.
+	BorderFillID uint16
+	// StrikeoutColor is the color of the strikeout line, zero before
+	// 5.0.3.0.
+	StrikeoutColor uint32
+}
+
+// CharShape property bits
+const (
+	charShapeItalic      = 1 << 0
+	charShapeBold        = 1 << 1
+	charShapeUnderline   = 3 << 2 // underline type: 0 none, 1 below, 3 above
+	charShapeSuperscript = 1 << 15
+	charShapeSubscript   = 1 << 16
+	charShapeStrikeout   = 7 << 18 // strikeout type, 0 for none
+)
+
+func (c CharShape) Italic() bool      { return c.Property&charShapeItalic != 0 }
+func (c CharShape) Bold() bool        { return c.Property&charShapeBold != 0 }
+func (c CharShape) Underline() bool   { return c.Property&charShapeUnderline != 0 }
+func (c CharShape) Superscript() bool { return c.Property&charShapeSuperscript != 0 }
+func (c CharShape) Subscript() bool   { return c.Property&charShapeSubscript != 0 }
+func (c CharShape) Strikeout() bool   { return c.Property&charShapeStrikeout != 0 }
+
+// Points returns the font size in points.
+func (c CharShape) Points() float64 { return float64(c.Size) / 100 }
+
+// RGB returns the text color as "#rrggbb".
+func (c CharShape) RGB() string { return colorRGB(c.Color) }
+
+// colorRGB formats a COLORREF (0x00BBGGRR) as "#rrggbb".
+func colorRGB(c uint32) string {
+	return fmt.Sprintf("#%02x%02x%02x", c&0xff, c>>8&0xff, c>>16&0xff)
+}
+
+// BorderFill is a border and fill definition (HWPTAG_BORDER_FILL).
+type BorderFill struct {
+	// Borders holds the left, right, top and bottom borders.
+	Borders [4]Border
+	// FillType is a combination of the borderFill* fill types.
+	FillType uint32
+	// Background is the color of a color fill, as a COLORREF.
+	Background uint32
+}
+
+// Border is one side of a BorderFill.
+type Border struct {
+	// Type is the line type; 0 for no line.
+	Type  uint8
+	Width uint8
+	Color uint32
+}
+
+// BorderFill fill types
+const (
+	borderFillColor    = 1 << 0
+	borderFillImage    = 1 << 1
+	borderFillGradient = 1 << 2
+)
+
+// colorWhite is white as a COLORREF.
+const colorWhite = 0xffffff
+
+// HasBorders reports whether any side has a line.
+func (b BorderFill) HasBorders() bool {
+	for _, border := range b.Borders {
+		if border.Type != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Shading returns the background color as "#rrggbb", or "" when the fill is
+// not a color or is white.
+func (b BorderFill) Shading() string {
+	if b.FillType&borderFillColor == 0 || b.Background&colorWhite == colorWhite {
+		return ""
+	}
+	return colorRGB(b.Background)
+}
+
+// ParaShape is a paragraph shape (HWPTAG_PARA_SHAPE). Lengths are in
+// HWPUNITs.
+type ParaShape struct {
+	Property    uint32
+	MarginLeft  int32
+	MarginRight int32
+	// Indent is the first line indent; negative for a hanging indent.
+	Indent      int32
+	SpaceBefore int32
+	SpaceAfter  int32
+	// LineSpacingType is one of the paraLineSpacing* types, and
+	// LineSpacing a percentage for paraLineSpacingPercent.
+	LineSpacingType uint32
+	LineSpacing     int32
+	// TabDefID indexes TabDefs.
+	TabDefID uint16
+	// NumberingID is the 1-based ID of the numbering or bullet of
+	// paragraphs with paragraph numbers or bullets.
+	NumberingID uint16
+}
+
+// TabDef is a set of tab stops (HWPTAG_TAB_DEF).
+type TabDef struct {
+	Property uint32
+	Stops    []TabStop
+}
+
+// TabStop is a tab stop of a TabDef.
+type TabStop struct {
+	// Position is the distance from the paragraph's left edge in HWPUNITs.
+	Position int32
+	// Type is one of the tabType* alignments.
+	Type uint8
+	// Fill is the leader line type; 0 for none.
+	Fill uint8
+}
+
+// Tab stop types
+const (
+	tabTypeLeft = iota
+	tabTypeRight
+	tabTypeCenter
+	tabTypeDecimal
+)
+
+// Align returns the alignment of the text after the tab as "left",
+// "right", "center" or "decimal".
+func (t TabStop) Align() string {
+	switch t.Type {
+	case tabTypeRight:
+		return "right"
+	case tabTypeCenter:
+		return "center"
+	case tabTypeDecimal:
+		return "decimal"
+	default:
+		return "left"
+	}
+}
+
+// Leader returns the character drawn across the tab: "" for none, "_" for
+// solid lines, "-" for dashes and "." for dots and other patterns.
+func (t TabStop) Leader() string {
+	switch t.Fill {
+	case 0:
+		return ""
+	case 1:
+		return "_"
+	case 2:
+		return "-"
+	default:
+		return "."
+	}
+}
+
+// ParaShape alignments (property bits 2-4)
+const (
+	paraAlignJustify = iota
+	paraAlignLeft
+	paraAlignRight
+	paraAlignCenter
+	paraAlignDistribute
+	paraAlignDivide
+)
+
+// ParaShape heading types (property bits 23-24): the numbering of the
+// paragraph
+const (
+	paraHeadingNone = iota
+	paraHeadingOutline
+	paraHeadingNumber
+	paraHeadingBullet
+)
+
+// Line spacing types
+const (
+	paraLineSpacingPercent = iota
+	paraLineSpacingFixed
+	paraLineSpacingBetween
+	paraLineSpacingAtLeast
+)
+
+// Align returns the alignment as "justify", "left", "right", "center",
+// "distribute" or "divide".
+func (p ParaShape) Align() string {
+	switch p.Property >> 2 & 7 {
+	case paraAlignLeft:
+		return "left"
+	case paraAlignRight:
+		return "right"
+	case paraAlignCenter:
+		return "center"
+	case paraAlignDistribute:
+		return "distribute"
+	case paraAlignDivide:
+		return "divide"
+	default:
+		return "justify"
+	}
+}
+
+// OutlineLevel returns the level of a paragraph shape with outline
+// numbering (개요 번호), from 1, or 0 for other shapes. Paragraphs can be
+// made headings this way without an outline style.
+func (p ParaShape) OutlineLevel() int {
+	if p.Property>>23&3 != paraHeadingOutline {
+		return 0
+	}
+	if level := int(p.Property>>25&7) + 1; level <= maxOutlineLevel {
+		return level
+	}
+	return 0
+}
+
+// ListLevel returns the level of a paragraph shape with paragraph numbers
+// (문단 번호) or bullets (글머리표), from 1, and whether it is numbered, or
+// 0 for other shapes.
+func (p ParaShape) ListLevel() (level int, numbered bool) {
+	switch p.Property >> 23 & 3 {
+	case paraHeadingNumber:
+		return int(p.Property>>25&7) + 1, true
+	case paraHeadingBullet:
+		return int(p.Property>>25&7) + 1, false
+	}
+	return 0, false
+}
+
+// Style is a named style (HWPTAG_STYLE).
+type Style struct {
+	Name        string
+	EnglishName string
+	// Type is 0 for paragraph styles and 1 for character styles.
+	Type        uint8
+	ParaShapeID uint16
+	CharShapeID uint16
+}
+
+// maxOutlineLevel is the deepest outline style.
+const maxOutlineLevel = 7
+
+// OutlineLevel returns the level of an outline style (개요 1-7, "Outline 1"
+// in English), or 0 for other styles.
+func (s Style) OutlineLevel() int {
+	for _, name := range []string{s.Name, s.EnglishName} {
+		for _, prefix := range []string{"개요 ", "Outline "} {
+			n, ok := strings.CutPrefix(name, prefix)
+			if !ok || len(n) != 1 {
+				continue
+			}
+			if level := int(n[0] - '0'); level >= 1 && level <= maxOutlineLevel {
+				return level
+			}
+		}
+	}
+	return 0
+}
+
+// BorderFill returns the border fill with the given 1-based ID.
+func (d *DocInfo) BorderFill(id uint16) (BorderFill, error) {
+	return lookup("border fill", d.BorderFills, int(id), 1)
+}
+
+// CharShape returns the char shape with the given ID.
+func (d *DocInfo) CharShape(id uint32) (CharShape, error) {
+	return lookup("char shape", d.CharShapes, int(id), 0)
+}
+
+// ParaShape returns the paragraph shape with the given ID.
+func (d *DocInfo) ParaShape(id uint16) (ParaShape, error) {
+	return lookup("paragraph shape", d.ParaShapes, int(id), 0)
+}
+
+// TabDef returns the tab definition with the given ID.
+func (d *DocInfo) TabDef(id uint16) (TabDef, error) {
+	return lookup("tab definition", d.TabDefs, int(id), 0)
+}
+
+// Numbering returns the numbering with the given 1-based ID.
+func (d *DocInfo) Numbering(id uint16) (Numbering, error) {
+	return lookup("numbering", d.Numberings, int(id), 1)
+}
+
+// Bullet returns the bullet with the given 1-based ID.
+func (d *DocInfo) Bullet(id uint16) (Bullet, error) {
+	return lookup("bullet", d.Bullets, int(id), 1)
+}
+
+// Style returns the style with the given ID.
+func (d *DocInfo) Style(id uint8) (Style, error) {
+	return lookup("style", d.Styles, int(id), 0)
+}
+
+// BinDataItem returns the item with the given 1-based ID.
+func (d *DocInfo) BinDataItem(id uint16) (BinDataItem, error) {
+	return lookup("binary data item", d.BinData, int(id), 1)
+}
+
+// CheckMappings compares the item counts declared in the ID mappings with
+// the records read, and reports the first kind of item with missing or
+// extra records.
+func (d *DocInfo) CheckMappings() error {
+	for _, m := range []struct {
+		kind  string
+		index int
+		count int
+	}{
+		{"binary data items", idMapBinData, len(d.BinData)},
+		{"border fills", idMapBorderFill, len(d.BorderFills)},
+		{"char shapes", idMapCharShape, len(d.CharShapes)},
+		{"tab definitions", idMapTabDef, len(d.TabDefs)},
+		{"numberings", idMapNumbering, len(d.Numberings)},
+		{"bullets", idMapBullet, len(d.Bullets)},
+		{"paragraph shapes", idMapParaShape, len(d.ParaShapes)},
+		{"styles", idMapStyle, len(d.Styles)},
+	} {
+		if m.index >= len(d.IDMappings) {
+			break
+		}
+		if declared := int(d.IDMappings[m.index]); declared != m.count {
+			return fmt.Errorf("DocInfo declares %d %s but has %d", declared, m.kind, m.count)
+		}
+	}
+	for lang := range langCount {
+		if idMapFontFirst+lang >= len(d.IDMappings) {
+			break
+		}
+		if declared := int(d.IDMappings[idMapFontFirst+lang]); declared != len(d.FaceNames[lang]) {
+			return fmt.Errorf("DocInfo declares %d %s fonts but has %d", declared, LangName(lang), len(d.FaceNames[lang]))
+		}
+	}
+	return nil
+}
+
+// Face returns the font the shape uses for the given language, if known.
+func (d *DocInfo) Face(shapeID uint32, lang int) (FaceName, bool) {
+	if int(shapeID) >= len(d.CharShapes) {
+		return FaceName{}, false
+	}
+	faceID := int(d.CharShapes[shapeID].FaceIDs[lang])
+	if faceID >= len(d.FaceNames[lang]) {
+		return FaceName{}, false
+	}
+	return d.FaceNames[lang][faceID], true
+}
+
+// readDocInfo scans the (decompressed) DocInfo stream.
+func readDocInfo(scanner *RecScanner) (*DocInfo, error) {
+	info := &DocInfo{}
+	var faceNames []FaceName
+
+	for {
+		rec, err := scanner.ScanNext()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		raw, ok := rec.(RecUnknown)
+		if !ok {
+			continue
+		}
+		data := raw.Data
+
+		switch rec.Tag() {
+		case recTagDocumentProperties:
+			info.Properties = decodeDocumentProperties(data)
+		case recTagIDMappings:
+			for i := 0; i+4 <= len(data); i += 4 {
+				info.IDMappings = append(info.IDMappings, int32(binary.LittleEndian.Uint32(data[i:])))
+			}
+		case recTagBinData:
+			info.BinData = append(info.BinData, decodeBinData(data))
+		case recTagFaceName:
+			faceNames = append(faceNames, decodeFaceName(data))
+		case recTagBorderFill:
+			info.BorderFills = append(info.BorderFills, decodeBorderFill(data))
+		case recTagCharShape:
+			info.CharShapes = append(info.CharShapes, decodeCharShape(data, scanner.version))
+		case recTagTabDef:
+			info.TabDefs = append(info.TabDefs, decodeTabDef(data))
+		case recTagNumbering:
+			info.Numberings = append(info.Numberings, decodeNumbering(data, scanner.version))
+		case recTagBullet:
+			info.Bullets = append(info.Bullets, decodeBullet(data))
+		case recTagParaShape:
+			info.ParaShapes = append(info.ParaShapes, decodeParaShape(data, scanner.version))
+		case recTagStyle:
+			info.Styles = append(info.Styles, decodeStyle(data))
+		case recTagTrackChange, recTagTrackChangeAuthor:
+			info.TrackChanges = true
+		}
+	}
+
+	info.groupFaceNames(faceNames)
+	return info, nil
+}
+
+// groupFaceNames splits the face names, stored one language after another,
+// using the per-language counts from the ID mappings.
+func (d *DocInfo) groupFaceNames(faceNames []FaceName) {
+	if len(d.IDMappings) < idMapFontFirst+langCount {
+		// Without counts the grouping is unknown; assume a single list
+		d.FaceNames[LangHangul] = faceNames
+		return
+	}
+	for lang := 0; lang < langCount; lang++ {
+		n := int(d.IDMappings[idMapFontFirst+lang])
+		n = max(min(n, len(faceNames)), 0)
+		d.FaceNames[lang] = faceNames[:n]
+		faceNames = faceNames[n:]
+	}
+}
+
+func decodeFaceName(data []byte) FaceName {
+	var face FaceName
+	if len(data) < 1 {
+		return face
+	}
+	prop := data[0]
+	pos := 1
+
+	face.Name, pos = readLenWString(data, pos)
+	if prop&0x80 != 0 {
+		pos++ // alternate font type
+		face.AltName, pos = readLenWString(data, pos)
+	}
+	if prop&0x40 != 0 && pos+10 <= len(data) {
+		face.TypeInfo = data[pos : pos+10]
+	}
+	return face
+}
+
+func decodeBinData(data []byte) BinDataItem {
+	var item BinDataItem
+	if len(data) < 2 {
+		return item
+	}
+	prop := binary.LittleEndian.Uint16(data)
+	item.Type = int(prop & 0x0F)
+	item.Compression = int(prop >> 4 & 0x03)
+	pos := 2
+
+	switch item.Type {
+	case BinDataLink:
+		item.LinkPath, pos = readLenWString(data, pos)
+		item.LinkRelPath, _ = readLenWString(data, pos)
+	case BinDataEmbedding, BinDataStorage:
+		if pos+2 <= len(data) {
+			item.StreamID = binary.LittleEndian.Uint16(data[pos:])
+		}
+		pos += 2
+		if item.Type == BinDataEmbedding {
+			item.Extension, _ = readLenWString(data, pos)
+		}
+	}
+	return item
+}
+
+// decodeCharShape decodes a char shape: WORD face IDs, BYTE ratios, INT8
+// spacings, BYTE relative sizes and INT8 offsets per language, then the
+// INT32 base size, UINT32 property, two INT8 shadow gaps, the text,
+// underline, shade and shadow colors, the WORD border fill ID since 5.0.2.1
+// and the strikeout color since 5.0.3.0.
+func decodeCharShape(data []byte, v Version) CharShape {
+	var shape CharShape
+	for lang := 0; lang < langCount && lang*2+2 <= len(data); lang++ {
+		shape.FaceIDs[lang] = binary.LittleEndian.Uint16(data[lang*2:])
+	}
+	const sizeOffset = langCount * 6
+	if len(data) >= sizeOffset+8 {
+		shape.Size = int32(binary.LittleEndian.Uint32(data[sizeOffset:]))
+		shape.Property = binary.LittleEndian.Uint32(data[sizeOffset+4:])
+	}
+	if len(data) >= sizeOffset+14 {
+		shape.Color = binary.LittleEndian.Uint32(data[sizeOffset+10:])
+	}
+	const borderFillOffset = sizeOffset + 26
+	if v.AtLeast(5, 0, 2, 1) && len(data) >= borderFillOffset+2 {
+		shape.BorderFillID = binary.LittleEndian.Uint16(data[borderFillOffset:])
+	}
+	if v.AtLeast(5, 0, 3, 0) && len(data) >= borderFillOffset+6 {
+		shape.StrikeoutColor = binary.LittleEndian.Uint32(data[borderFillOffset+2:])
+	}
+	return shape
+}
+
+// decodeDocumentProperties decodes the WORD section count and start
+// numbers, then the UINT32 list ID, paragraph ID and character index of the
+// caret. Fields missing from a short record are left zero.
+func decodeDocumentProperties(data []byte) DocumentProperties {
+	var props DocumentProperties
+	counts := []*int{&props.SectionCount, &props.PageStart, &props.FootnoteStart, &props.EndnoteStart,
+		&props.PictureStart, &props.TableStart, &props.EquationStart}
+	for i, count := range counts {
+		if i*2+2 > len(data) {
+			return props
+		}
+		*count = int(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	const caretOffset = 14
+	if len(data) >= caretOffset+12 {
+		props.Caret = Caret{
+			ListID:    binary.LittleEndian.Uint32(data[caretOffset:]),
+			ParaID:    binary.LittleEndian.Uint32(data[caretOffset+4:]),
+			CharIndex: binary.LittleEndian.Uint32(data[caretOffset+8:]),
+		}
+	}
+	return props
+}
+
+// decodeBorderFill decodes a border fill: UINT16 property, the line type,
+// width and color of the left, right, top and bottom borders and of the
+// diagonal, then the UINT32 fill type followed, for color fills, by the
+// background color.
+func decodeBorderFill(data []byte) BorderFill {
+	var fill BorderFill
+	for i := range fill.Borders {
+		pos := 2 + i*6
+		if pos+6 > len(data) {
+			return fill
+		}
+		fill.Borders[i] = Border{Type: data[pos], Width: data[pos+1], Color: binary.LittleEndian.Uint32(data[pos+2:])}
+	}
+	const fillOffset = 2 + 5*6
+	if len(data) >= fillOffset+4 {
+		fill.FillType = binary.LittleEndian.Uint32(data[fillOffset:])
+	}
+	if fill.FillType&borderFillColor != 0 && len(data) >= fillOffset+8 {
+		fill.Background = binary.LittleEndian.Uint32(data[fillOffset+4:])
+	}
+	return fill
+}
+
+// decodeTabDef decodes a tab definition: UINT32 property and the tab count,
+// then the HWPUNIT position, BYTE type, BYTE fill and two reserved bytes of
+// each tab. The specification gives an INT16 count, but documents store an
+// INT32; whichever accounts for the record length is used.
+func decodeTabDef(data []byte) TabDef {
+	var def TabDef
+	if len(data) < 6 {
+		return def
+	}
+	def.Property = binary.LittleEndian.Uint32(data)
+	count, pos := int(int16(binary.LittleEndian.Uint16(data[4:]))), 6
+	if (len(data)-8)%8 == 0 {
+		pos = 8
+	}
+	for i := 0; i < count && pos+8 <= len(data); i++ {
+		def.Stops = append(def.Stops, TabStop{
+			Position: int32(binary.LittleEndian.Uint32(data[pos:])),
+			Type:     data[pos+4],
+			Fill:     data[pos+5],
+		})
+		pos += 8
+	}
+	return def
+}
+
+// decodeParaShape decodes a paragraph shape: UINT32 property, INT32 left
+// and right margins, indent, spacing before and after and line spacing, then
+// WORD tab, numbering and border IDs, INT16 border offsets, UINT32 property
+// 2 since 5.0.1.7 and, since 5.0.2.5, UINT32 property 3 and the line spacing
+// that replaces the first one. The lengths are stored doubled.
+func decodeParaShape(data []byte, v Version) ParaShape {
+	var shape ParaShape
+	if len(data) < 28 {
+		return shape
+	}
+	i32 := func(pos int) int32 { return int32(binary.LittleEndian.Uint32(data[pos:])) }
+	shape.Property = binary.LittleEndian.Uint32(data)
+	shape.MarginLeft = i32(4) / 2
+	shape.MarginRight = i32(8) / 2
+	shape.Indent = i32(12) / 2
+	shape.SpaceBefore = i32(16) / 2
+	shape.SpaceAfter = i32(20) / 2
+	shape.LineSpacingType = shape.Property & 3
+	shape.LineSpacing = i32(24)
+	if len(data) >= 30 {
+		shape.TabDefID = binary.LittleEndian.Uint16(data[28:])
+	}
+	if len(data) >= 32 {
+		shape.NumberingID = binary.LittleEndian.Uint16(data[30:])
+	}
+	if v.AtLeast(5, 0, 2, 5) && len(data) >= 54 {
+		shape.LineSpacingType = binary.LittleEndian.Uint32(data[46:]) & 0x1f
+		shape.LineSpacing = i32(50)
+	}
+	return shape
+}
+
+// paraHeadSize is the size of the paragraph head info of numberings and
+// bullets: UINT32 property, HWPUNIT16 width adjustment and distance from the
+// text and UINT32 char shape ID.
+const paraHeadSize = 12
+
+// decodeNumbering decodes a numbering: the paragraph head info and number
+// text of each of 7 levels, the WORD start number and, since 5.0.2.5, the
+// UINT32 start number of each level. Since 5.1 levels 8 to 10 and their
+// start numbers may follow. The number format is in property bits 5-8.
+func decodeNumbering(data []byte, v Version) Numbering {
+	var n Numbering
+	pos := 0
+	readLevels := func(count int) {
+		for range count {
+			if pos+paraHeadSize > len(data) {
+				return
+			}
+			head := ParaHead{Format: uint8(binary.LittleEndian.Uint32(data[pos:]) >> 5 & 0xf), Start: 1}
+			head.Text, pos = readLenWString(data, pos+paraHeadSize)
+			n.Levels = append(n.Levels, head)
+		}
+	}
+	readStarts := func(levels []ParaHead) {
+		for i := range levels {
+			if pos+4 > len(data) {
+				return
+			}
+			levels[i].Start = int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		}
+	}
+
+	readLevels(maxOutlineLevel)
+	if pos+2 > len(data) {
+		return n
+	}
+	if start := int(binary.LittleEndian.Uint16(data[pos:])); start > 0 && len(n.Levels) > 0 {
+		n.Levels[0].Start = start
+	}
+	pos += 2
+	if !v.AtLeast(5, 0, 2, 5) {
+		return n
+	}
+	readStarts(n.Levels)
+	if len(n.Levels) == maxOutlineLevel {
+		readLevels(3)
+		readStarts(n.Levels[maxOutlineLevel:])
+	}
+	return n
+}
+
+// decodeBullet decodes a bullet: the paragraph head info, then the WCHAR
+// bullet character.
+func decodeBullet(data []byte) Bullet {
+	var b Bullet
+	if len(data) >= paraHeadSize+2 {
+		b.Char = rune(binary.LittleEndian.Uint16(data[paraHeadSize:]))
+	}
+	return b
+}
+
+// decodeStyle decodes a style: the local and English names, BYTE property,
+// BYTE next style ID, INT16 language ID and WORD paragraph and character
+// shape IDs.
+func decodeStyle(data []byte) Style {
+	var style Style
+	var pos int
+	style.Name, pos = readLenWString(data, 0)
+	style.EnglishName, pos = readLenWString(data, pos)
+	if pos+8 <= len(data) {
+		style.Type = data[pos] & 7
+		style.ParaShapeID = binary.LittleEndian.Uint16(data[pos+4:])
+		style.CharShapeID = binary.LittleEndian.Uint16(data[pos+6:])
+	}
+	return style
+}
+
+// readLenWString reads a WORD length followed by that many WCHARs, returning
+// the string and the position after it.
+func readLenWString(data []byte, pos int) (string, int) {
+	if pos+2 > len(data) {
+		return "", len(data)
+	}
+	n := int(binary.LittleEndian.Uint16(data[pos:]))
+	pos += 2
+	if pos+n*2 > len(data) {
+		n = (len(data) - pos) / 2
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[pos+i*2:])
+	}
+	return string(utf16.Decode(units)), pos + n*2
+}
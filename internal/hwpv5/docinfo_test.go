@@ -0,0 +1,741 @@
+package hwpv5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func faceNameData(name string) []byte {
+	data := []byte{0}
+	data = binary.LittleEndian.AppendUint16(data, uint16(len([]rune(name))))
+	return append(data, utf16Bytes(name)...)
+}
+
+func charShapeData(faceIDs ...uint16) []byte {
+	var data []byte
+	for lang := 0; lang < langCount; lang++ {
+		id := uint16(0)
+		if lang < len(faceIDs) {
+			id = faceIDs[lang]
+		}
+		data = binary.LittleEndian.AppendUint16(data, id)
+	}
+	return data
+}
+
+// testDocInfo declares one Hangul font and two Latin fonts (Arial and
+// D2Coding), and char shapes 0 (Arial) and 1 (D2Coding).
+func testDocInfo(t *testing.T) *DocInfo {
+	t.Helper()
+	var mappings []byte
+	for _, n := range []uint32{0, 1, 2, 0, 0, 0, 0, 0, 0, 2} {
+		mappings = binary.LittleEndian.AppendUint32(mappings, n)
+	}
+
+	stream := &recordStream{}
+	stream.add(recTagDocumentProperties, 0, []byte{1, 0})
+	stream.add(recTagIDMappings, 0, mappings)
+	stream.add(recTagFaceName, 1, faceNameData("함초롬바탕"))
+	stream.add(recTagFaceName, 1, faceNameData("Arial"))
+	stream.add(recTagFaceName, 1, faceNameData("D2Coding"))
+	stream.add(recTagCharShape, 1, charShapeData(0, 0))
+	stream.add(recTagCharShape, 1, charShapeData(0, 1))
+
+	info, err := readDocInfo(NewRecScanner(bytes.NewReader(stream.buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestReadDocInfo(t *testing.T) {
+	info := testDocInfo(t)
+
+	if info.Properties.SectionCount != 1 {
+		t.Errorf("SectionCount = %d, want 1", info.Properties.SectionCount)
+	}
+	if len(info.FaceNames[LangHangul]) != 1 || len(info.FaceNames[LangLatin]) != 2 {
+		t.Fatalf("face names not grouped by language: %+v", info.FaceNames)
+	}
+	face, ok := info.Face(1, LangLatin)
+	if !ok || face.Name != "D2Coding" || !face.Monospace() {
+		t.Errorf("Face(1, Latin) = %+v, %v; want monospaced D2Coding", face, ok)
+	}
+	if face, _ := info.Face(0, LangLatin); face.Monospace() {
+		t.Errorf("%s reported as monospaced", face.Name)
+	}
+	if _, ok := info.Face(5, LangLatin); ok {
+		t.Error("Face succeeded for an undefined char shape")
+	}
+}
+
+func TestMonospaceFont(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		proportion byte
+		want       bool
+	}{
+		{"Arial", 0, false},
+		{"Arial", panoseMonospaced, true},
+		{"굴림체", 0, true},
+		{"Courier New", 0, true},
+		{"함초롬바탕", 3, false},
+	} {
+		if got := MonospaceFont(tc.name, tc.proportion); got != tc.want {
+			t.Errorf("MonospaceFont(%q, %d) = %v, want %v", tc.name, tc.proportion, got, tc.want)
+		}
+	}
+}
+
+func TestMonospaceParagraph(t *testing.T) {
+	runs := func(ids ...uint32) []byte {
+		var data []byte
+		for i, id := range ids {
+			data = binary.LittleEndian.AppendUint32(data, uint32(i))
+			data = binary.LittleEndian.AppendUint32(data, id)
+		}
+		return data
+	}
+
+	stream := &recordStream{}
+	for _, shapes := range [][]byte{runs(1), runs(0, 1)} {
+		stream.add(recTagParaHeader, 0, make([]byte, 12))
+		stream.add(recTagParaText, 1, utf16Bytes("x = 1"))
+		stream.add(recTagParaCharShape, 1, shapes)
+	}
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = testDocInfo(t)
+
+	for i, want := range []bool{true, false} {
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := node.(*document.Paragraph).Preformatted; got != want {
+			t.Errorf("paragraph %d: Preformatted = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestBinDataImage(t *testing.T) {
+	embedded := binary.LittleEndian.AppendUint16(nil, BinDataEmbedding|binDataNoCompress<<4)
+	embedded = binary.LittleEndian.AppendUint16(embedded, 0x1A)
+	embedded = append(embedded, faceNameData("PNG")[1:]...)
+	link := binary.LittleEndian.AppendUint16(nil, BinDataLink)
+	link = append(link, faceNameData(`C:\사진\logo.JPG`)[1:]...)
+	link = append(link, faceNameData(`사진\logo.JPG`)[1:]...)
+	storage := binary.LittleEndian.AppendUint16(nil, BinDataStorage)
+	storage = binary.LittleEndian.AppendUint16(storage, 3)
+
+	docInfo := &recordStream{}
+	docInfo.add(recTagBinData, 0, embedded)
+	docInfo.add(recTagBinData, 0, link)
+	docInfo.add(recTagBinData, 0, storage)
+	info, err := readDocInfo(NewRecScanner(bytes.NewReader(docInfo.buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item, _ := info.BinDataItem(1); item.StreamName() != "BinData/BIN001A.png" || item.Compression != binDataNoCompress {
+		t.Errorf("BinDataItem(1) = %+v", item)
+	}
+	if item, _ := info.BinDataItem(2); item.LinkRelPath != `사진\logo.JPG` {
+		t.Errorf("BinDataItem(2) = %+v, want relative path", item)
+	}
+	if item, _ := info.BinDataItem(3); item.StreamName() != "BinData/BIN0003" {
+		t.Errorf("storage stream = %q, want BinData/BIN0003", item.StreamName())
+	}
+
+	gso := binary.LittleEndian.AppendUint32(nil, 0x67736f20)
+	picture := make([]byte, 80)
+	picture[71] = 2
+	stream := (&recordStream{}).add(recTagCtrlHeader, 1, gso).
+		add(recTagShapeComponent, 2, nil).
+		add(recTagShapeComponentPicture, 3, picture)
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+	node, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := node.(*document.Image)
+	if img.Filename != "logo.JPG" || img.Format != "jpg" {
+		t.Errorf("linked image = %+v, want logo.JPG in jpg format", img)
+	}
+	if name, format := s.reader.binDataFile(1); name != "BIN001A.png" || format != "png" {
+		t.Errorf("binDataFile(1) = %q, %q", name, format)
+	}
+}
+
+// formattedCharShapeData returns a char shape with a size, property and
+// text color.
+func formattedCharShapeData(size int32, property, color uint32) []byte {
+	data := append(charShapeData(), make([]byte, langCount*4)...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(size))
+	data = binary.LittleEndian.AppendUint32(data, property)
+	data = append(data, 0, 0)
+	return binary.LittleEndian.AppendUint32(data, color)
+}
+
+func TestFormattingRuns(t *testing.T) {
+	info := &DocInfo{}
+	for _, data := range [][]byte{
+		formattedCharShapeData(1000, 0, 0),
+		formattedCharShapeData(1200, charShapeBold|charShapeItalic, 0x0000ff),
+	} {
+		info.CharShapes = append(info.CharShapes, decodeCharShape(data, latestVersion))
+	}
+	if shape := info.CharShapes[1]; !shape.Bold() || !shape.Italic() || shape.Underline() || shape.RGB() != "#ff0000" || shape.Points() != 12 {
+		t.Errorf("char shape = %+v, want bold italic red 12pt", shape)
+	}
+
+	shapes := func(runs ...uint32) []byte {
+		var data []byte
+		for _, v := range runs {
+			data = binary.LittleEndian.AppendUint32(data, v)
+		}
+		return data
+	}
+	stream := &recordStream{}
+	// "보통 " plain, then "굵게" bold up to the tab, then plain again
+	stream.add(recTagParaHeader, 0, make([]byte, 12))
+	stream.add(recTagParaText, 1, append(append(utf16Bytes("보통 굵게"), binary.LittleEndian.AppendUint16(nil, paraTextCodeTab)...), append(make([]byte, 14), utf16Bytes("끝")...)...))
+	stream.add(recTagParaCharShape, 1, shapes(0, 0, 3, 1, 5, 0))
+	stream.add(recTagParaHeader, 0, make([]byte, 12))
+	stream.add(recTagParaText, 1, utf16Bytes("plain"))
+	stream.add(recTagParaCharShape, 1, shapes(0, 0))
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+	texts := []*document.Paragraph{}
+	for range 2 {
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, node.(*document.Paragraph))
+	}
+
+	want := []document.Run{
+		{Offset: 0, Text: "보통 ", Size: 10},
+		{Offset: 7, Text: "굵게", Bold: true, Italic: true, Size: 12, Color: "#ff0000"},
+		{Offset: 13, Text: "\t끝", Size: 10},
+	}
+	if got := texts[0].Runs; !reflect.DeepEqual(got, want) {
+		t.Errorf("runs = %+v, want %+v", got, want)
+	}
+	if texts[1].Runs != nil {
+		t.Errorf("runs without emphasis = %+v, want none", texts[1].Runs)
+	}
+}
+
+// paraShapeData builds a 5.0.2.5 paragraph shape record, with the doubled
+// lengths the format stores.
+func paraShapeData(align uint32, left, indent, before int32, lineSpacing int32) []byte {
+	data := make([]byte, 54)
+	binary.LittleEndian.PutUint32(data, align<<2)
+	binary.LittleEndian.PutUint32(data[4:], uint32(left*2))
+	binary.LittleEndian.PutUint32(data[12:], uint32(indent*2))
+	binary.LittleEndian.PutUint32(data[16:], uint32(before*2))
+	binary.LittleEndian.PutUint32(data[50:], uint32(lineSpacing))
+	return data
+}
+
+func TestParagraphLayout(t *testing.T) {
+	docInfo := &recordStream{}
+	docInfo.add(recTagParaShape, 0, paraShapeData(paraAlignJustify, 0, 0, 0, 160))
+	docInfo.add(recTagParaShape, 0, paraShapeData(paraAlignCenter, 0, 0, 1000, 160))
+	docInfo.add(recTagParaShape, 0, paraShapeData(paraAlignJustify, 2000, -1000, 0, 130))
+	info, err := readDocInfo(NewRecScanner(bytes.NewReader(docInfo.buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := &recordStream{}
+	for id := range uint16(3) {
+		header := make([]byte, 12)
+		binary.LittleEndian.PutUint16(header[8:], id)
+		stream.add(recTagParaHeader, 0, header)
+		stream.add(recTagParaText, 1, utf16Bytes("text"))
+	}
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+	want := []*document.Layout{
+		nil,
+		{Align: document.AlignCenter, SpaceBefore: 10, LineSpacing: 160},
+		{Align: document.AlignJustify, MarginLeft: 20, Indent: -10, LineSpacing: 130},
+	}
+	for i, want := range want {
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := node.(*document.Paragraph).Layout; !reflect.DeepEqual(got, want) {
+			t.Errorf("paragraph %d: Layout = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+// styleData builds a paragraph style record with the given names.
+func styleData(name, englishName string) []byte {
+	data := faceNameData(name)[1:]
+	data = append(data, faceNameData(englishName)[1:]...)
+	return append(data, make([]byte, 8)...)
+}
+
+func TestHeadings(t *testing.T) {
+	docInfo := &recordStream{}
+	docInfo.add(recTagParaShape, 0, paraShapeData(paraAlignJustify, 0, 0, 0, 160))
+	// Outline numbering at the second level
+	outline := paraShapeData(paraAlignJustify, 0, 0, 0, 160)
+	binary.LittleEndian.PutUint32(outline, paraHeadingOutline<<23|1<<25)
+	docInfo.add(recTagParaShape, 0, outline)
+	docInfo.add(recTagStyle, 0, styleData("바탕글", "Normal"))
+	docInfo.add(recTagStyle, 0, styleData("개요 1", "Outline 1"))
+	docInfo.add(recTagStyle, 0, styleData("제목", "Outline 3"))
+	docInfo.add(recTagStyle, 0, styleData("개요 10", ""))
+	info, err := readDocInfo(NewRecScanner(bytes.NewReader(docInfo.buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := &recordStream{}
+	for _, p := range []struct {
+		style uint8
+		shape uint16
+		text  string
+	}{{0, 0, "본문"}, {1, 0, "1. 개요"}, {2, 0, "가. 목적"}, {3, 0, "본문"}, {1, 0, ""}, {0, 1, "나. 범위"}, {2, 1, "다. 용어"}} {
+		header := make([]byte, 12)
+		binary.LittleEndian.PutUint16(header[8:], p.shape)
+		header[10] = p.style
+		stream.add(recTagParaHeader, 0, header)
+		stream.add(recTagParaText, 1, utf16Bytes(p.text))
+	}
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+	for i, want := range []int{0, 1, 3, 0, 0, 2, 3} {
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		level := 0
+		if h, ok := node.(*document.Heading); ok {
+			level = h.Level
+		}
+		if level != want {
+			t.Errorf("paragraph %d: heading level %d, want %d", i, level, want)
+		}
+	}
+}
+
+// numberingData builds a 5.0.2.5 numbering record whose levels have the
+// given number texts and formats, starting at 1.
+func numberingData(texts []string, formats []uint8) []byte {
+	var data []byte
+	for i := range maxOutlineLevel {
+		head := make([]byte, paraHeadSize)
+		var text string
+		if i < len(texts) {
+			binary.LittleEndian.PutUint32(head, uint32(formats[i])<<5)
+			text = texts[i]
+		}
+		data = append(data, head...)
+		data = append(data, faceNameData(text)[1:]...)
+	}
+	data = binary.LittleEndian.AppendUint16(data, 1)
+	for range maxOutlineLevel {
+		data = binary.LittleEndian.AppendUint32(data, 1)
+	}
+	return data
+}
+
+func TestLists(t *testing.T) {
+	listShape := func(heading, level uint32, id uint16) []byte {
+		data := paraShapeData(paraAlignJustify, 0, 0, 0, 160)
+		binary.LittleEndian.PutUint32(data, heading<<23|level<<25)
+		binary.LittleEndian.PutUint16(data[30:], id)
+		return data
+	}
+	docInfo := &recordStream{}
+	docInfo.add(recTagNumbering, 0, numberingData([]string{"^1.", "^1.^2)"}, []uint8{NumberDigits, NumberHangulSyllable}))
+	docInfo.add(recTagBullet, 0, append(make([]byte, paraHeadSize), utf16Bytes("●")...))
+	docInfo.add(recTagParaShape, 0, paraShapeData(paraAlignJustify, 0, 0, 0, 160))
+	docInfo.add(recTagParaShape, 0, listShape(paraHeadingNumber, 0, 1))
+	docInfo.add(recTagParaShape, 0, listShape(paraHeadingNumber, 1, 1))
+	docInfo.add(recTagParaShape, 0, listShape(paraHeadingBullet, 0, 1))
+	scanner := NewRecScanner(bytes.NewReader(docInfo.buf.Bytes()))
+	scanner.version = Version{5, 0, 2, 5}
+	info, err := readDocInfo(scanner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := &recordStream{}
+	for _, shape := range []uint16{1, 2, 2, 0, 1, 2, 3} {
+		header := make([]byte, 12)
+		binary.LittleEndian.PutUint16(header[8:], shape)
+		stream.add(recTagParaHeader, 0, header)
+		stream.add(recTagParaText, 1, utf16Bytes("항목"))
+	}
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+	for i, want := range []string{"1 1.", "2 1.가)", "2 1.나)", "", "1 2.", "2 2.가)", "1 ●"} {
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if item := node.(*document.Paragraph).List; item != nil {
+			got = fmt.Sprintf("%d %s", item.Level, item.Marker)
+		}
+		if got != want {
+			t.Errorf("paragraph %d: list item %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestFaceNameSubstitute(t *testing.T) {
+	data := append([]byte{0x80}, faceNameData("HY견명조")[1:]...)
+	data = append(data, 1) // TTF
+	data = append(data, faceNameData("바탕")[1:]...)
+
+	face := decodeFaceName(data)
+	if face.Name != "HY견명조" || face.AltName != "바탕" {
+		t.Errorf("face = %+v, want HY견명조 substituted by 바탕", face)
+	}
+	if LangName(LangHanja) != "hanja" || LangName(langCount) != "" {
+		t.Errorf("LangName(LangHanja) = %q", LangName(LangHanja))
+	}
+}
+
+// borderFillData builds a border fill with the given line type on every
+// side and, unless background is negative, a color fill.
+func borderFillData(line uint8, background int64) []byte {
+	data := make([]byte, 2+5*6+4)
+	for side := range 4 {
+		data[2+side*6] = line
+	}
+	if background >= 0 {
+		binary.LittleEndian.PutUint32(data[32:], borderFillColor)
+		data = binary.LittleEndian.AppendUint32(data, uint32(background))
+		data = append(data, make([]byte, 8)...)
+	}
+	return data
+}
+
+func TestBorderFills(t *testing.T) {
+	docInfo := &recordStream{}
+	docInfo.add(recTagBorderFill, 0, borderFillData(0, -1))
+	docInfo.add(recTagBorderFill, 0, borderFillData(0, 0xffffff))
+	docInfo.add(recTagBorderFill, 0, borderFillData(1, 0x00ccff))
+	info, err := readDocInfo(NewRecScanner(bytes.NewReader(docInfo.buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cell := func(col, borderFill uint16) []byte {
+		data := append(cellHeader(0, col), 0)
+		binary.LittleEndian.PutUint16(data[32:], borderFill)
+		return data
+	}
+	tblCtrl := binary.LittleEndian.AppendUint32(nil, 0x74626c20)
+	stream := &recordStream{}
+	// A layout table without borders, then a table with a shaded header cell
+	for _, fills := range [][]uint16{{1, 2}, {3, 1}} {
+		stream.add(recTagCtrlHeader, 1, tblCtrl)
+		stream.add(recTagTable, 2, tableRecord(1, 2))
+		for col, fill := range fills {
+			stream.add(recTagListHeader, 2, cell(uint16(col), fill))
+			stream.para(2, "x")
+		}
+	}
+
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+	var tables []*document.Table
+	for range 2 {
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, node.(*document.Table))
+	}
+	if !tables[0].Borderless || tables[1].Borderless {
+		t.Errorf("Borderless = %v, %v; want true, false", tables[0].Borderless, tables[1].Borderless)
+	}
+	if got := tables[0].Cells[1].Background; got != "" {
+		t.Errorf("white cell Background = %q, want none", got)
+	}
+	if got := tables[1].Cells[0].Background; got != "#ffcc00" {
+		t.Errorf("shaded cell Background = %q, want #ffcc00", got)
+	}
+}
+
+func TestTabStops(t *testing.T) {
+	tabDef := binary.LittleEndian.AppendUint32(nil, 0)
+	tabDef = binary.LittleEndian.AppendUint32(tabDef, 2)
+	for _, tab := range []struct {
+		pos        uint32
+		kind, fill uint8
+	}{{4000, tabTypeLeft, 0}, {15000, tabTypeRight, 3}} {
+		tabDef = binary.LittleEndian.AppendUint32(tabDef, tab.pos)
+		tabDef = append(tabDef, tab.kind, tab.fill, 0, 0)
+	}
+	shape := paraShapeData(paraAlignJustify, 0, 0, 0, 160)
+	binary.LittleEndian.PutUint16(shape[28:], 1)
+
+	docInfo := &recordStream{}
+	docInfo.add(recTagTabDef, 0, nil)
+	docInfo.add(recTagTabDef, 0, tabDef)
+	docInfo.add(recTagParaShape, 0, shape)
+	info, err := readDocInfo(NewRecScanner(bytes.NewReader(docInfo.buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tab := append(binary.LittleEndian.AppendUint16(nil, paraTextCodeTab), make([]byte, 14)...)
+	stream := &recordStream{}
+	for _, text := range [][]byte{append(append(utf16Bytes("성명"), tab...), utf16Bytes("홍길동")...), utf16Bytes("탭 없음")} {
+		stream.add(recTagParaHeader, 0, make([]byte, 12))
+		stream.add(recTagParaText, 1, text)
+	}
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+
+	want := [][]document.TabStop{{
+		{Position: 40, Align: document.TabLeft},
+		{Position: 150, Align: document.TabRight, Leader: "."},
+	}, nil}
+	for i, want := range want {
+		node, err := s.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		para := node.(*document.Paragraph)
+		if i == 0 && para.Text != "성명\t홍길동" {
+			t.Errorf("paragraph %d: Text = %q", i, para.Text)
+		}
+		if got := para.TabStops; !reflect.DeepEqual(got, want) {
+			t.Errorf("paragraph %d: TabStops = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestDocumentProperties(t *testing.T) {
+	var data []byte
+	for _, n := range []uint16{2, 5, 1, 1, 3, 4, 1} {
+		data = binary.LittleEndian.AppendUint16(data, n)
+	}
+	for _, n := range []uint32{0, 12, 7} {
+		data = binary.LittleEndian.AppendUint32(data, n)
+	}
+
+	want := DocumentProperties{
+		SectionCount: 2, PageStart: 5, FootnoteStart: 1, EndnoteStart: 1,
+		PictureStart: 3, TableStart: 4, EquationStart: 1,
+		Caret: Caret{ParaID: 12, CharIndex: 7},
+	}
+	if got := decodeDocumentProperties(data); got != want {
+		t.Errorf("properties = %+v, want %+v", got, want)
+	}
+	if got := decodeDocumentProperties(data[:4]); got != (DocumentProperties{SectionCount: 2, PageStart: 5}) {
+		t.Errorf("short properties = %+v", got)
+	}
+}
+
+func TestTrackChangeRecords(t *testing.T) {
+	if testDocInfo(t).TrackChanges {
+		t.Error("TrackChanges set without change records")
+	}
+
+	stream := &recordStream{}
+	stream.add(recTagTrackChangeInfo, 0, make([]byte, 8))
+	stream.add(recTagTrackChangeAuthor, 0, make([]byte, 4))
+	info, err := readDocInfo(NewRecScanner(bytes.NewReader(stream.buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.TrackChanges {
+		t.Error("TrackChanges not set with a change author record")
+	}
+}
+
+func TestStrictReferences(t *testing.T) {
+	info := testDocInfo(t)
+	info.ParaShapes = []ParaShape{{}}
+	info.TabDefs = []TabDef{{}}
+	info.Styles = []Style{{Name: "바탕글"}}
+	if err := info.CheckMappings(); err != nil {
+		t.Errorf("CheckMappings: %v", err)
+	}
+	info.IDMappings[idMapCharShape] = 3
+	if err := info.CheckMappings(); err == nil || !strings.Contains(err.Error(), "3 char shapes but has 2") {
+		t.Errorf("CheckMappings = %v, want a char shape count mismatch", err)
+	}
+
+	stream := &recordStream{}
+	stream.add(recTagParaHeader, 0, make([]byte, 12))
+	stream.add(recTagParaText, 1, utf16Bytes("본문"))
+	stream.add(recTagParaCharShape, 1, binary.LittleEndian.AppendUint32(make([]byte, 4), 5))
+
+	for _, strict := range []bool{false, true} {
+		opts := document.DefaultScanOptions()
+		opts.StrictReferences = strict
+		s := newTestScanner(stream, opts)
+		s.reader.DocInfo = info
+
+		node, err := s.Next()
+		var refErr *ReferenceError
+		switch {
+		case !strict && err != nil:
+			t.Errorf("lenient scan: %v", err)
+		case !strict && node.(*document.Paragraph).Text != "본문":
+			t.Errorf("lenient scan returned %+v", node)
+		case strict && !errors.As(err, &refErr):
+			t.Errorf("strict scan error = %v, want a ReferenceError", err)
+		case strict && (refErr.Kind != "char shape" || refErr.ID != 5 || refErr.Count != 2):
+			t.Errorf("ReferenceError = %+v", refErr)
+		case strict && !strings.Contains(err.Error(), "record 2 tag 0x44 (HWPTAG_PARA_CHAR_SHAPE)"):
+			t.Errorf("strict scan error %q does not locate the record", err)
+		}
+	}
+}
+
+func TestSurrogatePairText(t *testing.T) {
+	info := &DocInfo{}
+	for _, data := range [][]byte{
+		formattedCharShapeData(1000, 0, 0),
+		formattedCharShapeData(1000, charShapeBold, 0),
+	} {
+		info.CharShapes = append(info.CharShapes, decodeCharShape(data, latestVersion))
+	}
+	runs := func(pairs ...uint32) []byte {
+		var data []byte
+		for _, v := range pairs {
+			data = binary.LittleEndian.AppendUint32(data, v)
+		}
+		return data
+	}
+
+	// U+20000 and U+2A6A5 are CJK Extension B Hanja, each a surrogate pair:
+	// "김" 0, "𠀀" 1-2, "수" 3, " " 4, "𪚥" 5-6, "체" 7
+	text := utf16Bytes("김𠀀수 𪚥체")
+	if els, err := (&paraTextDecoder{r: recordReader{data: text}}).decodeParaTextElements(); err != nil || len(els) != 1 || els[0].(ParaTextString).Value != "김𠀀수 𪚥체" {
+		t.Fatalf("elements = %+v", els)
+	}
+
+	stream := &recordStream{}
+	stream.add(recTagParaHeader, 0, make([]byte, 12))
+	stream.add(recTagParaText, 1, text)
+	// Bold from "𪚥" on; the plain run starting within its pair only
+	// starts after it, where the next bold run starts
+	stream.add(recTagParaCharShape, 1, runs(0, 0, 5, 1, 6, 0, 7, 1))
+	s := newTestScanner(stream, document.DefaultScanOptions())
+	s.reader.DocInfo = info
+	node, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	para := node.(*document.Paragraph)
+	if para.Text != "김𠀀수 𪚥체" {
+		t.Errorf("text = %q", para.Text)
+	}
+	want := []document.Run{
+		{Offset: 0, Text: "김𠀀수 ", Size: 10},
+		{Offset: 11, Text: "𪚥체", Bold: true, Size: 10},
+	}
+	if !reflect.DeepEqual(para.Runs, want) {
+		t.Errorf("runs = %+v, want %+v", para.Runs, want)
+	}
+}
+
+func TestVersionLayouts(t *testing.T) {
+	if v := (Version{5, 0, 2, 5}); !v.AtLeast(5, 0, 2, 5) || !v.AtLeast(5, 0, 1, 7) || v.AtLeast(5, 0, 3, 0) {
+		t.Errorf("AtLeast comparisons of %s are wrong", v)
+	}
+
+	// A 5.0.2.5 paragraph shape: 150% in the old field, then 3pt fixed
+	// in the one replacing it
+	shape := make([]byte, 54)
+	binary.LittleEndian.PutUint32(shape[24:], 150)
+	binary.LittleEndian.PutUint32(shape[46:], paraLineSpacingFixed)
+	binary.LittleEndian.PutUint32(shape[50:], 600)
+	if got := decodeParaShape(shape, Version{5, 0, 2, 5}); got.LineSpacingType != paraLineSpacingFixed || got.LineSpacing != 600 {
+		t.Errorf("5.0.2.5 line spacing = %d type %d, want 600 fixed", got.LineSpacing, got.LineSpacingType)
+	}
+	if got := decodeParaShape(shape, Version{5, 0, 1, 7}); got.LineSpacingType != paraLineSpacingPercent || got.LineSpacing != 150 {
+		t.Errorf("5.0.1.7 line spacing = %d type %d, want 150%%", got.LineSpacing, got.LineSpacingType)
+	}
+
+	// Before 5.0.1.0 nothing follows a table's border fill
+	table := append(tableRecord(1, 1), 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0)
+	for _, tc := range []struct {
+		version Version
+		zones   int
+	}{{Version{5, 0, 0, 6}, 0}, {Version{5, 0, 1, 0}, 1}} {
+		s := NewRecScanner(&(&recordStream{}).add(recTagTable, 2, table).buf)
+		s.version = tc.version
+		rec, err := s.ScanNext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if zones := len(rec.(RecTable).Zones); zones != tc.zones {
+			t.Errorf("%s table has %d zones, want %d", tc.version, zones, tc.zones)
+		}
+	}
+
+	// A 5.0.3.0 char shape: border fill 3 since 5.0.2.1, then a red
+	// strikeout since 5.0.3.0
+	charShape := formattedCharShapeData(1000, 0, 0)
+	charShape = append(charShape, make([]byte, 12)...)
+	charShape = binary.LittleEndian.AppendUint16(charShape, 3)
+	charShape = binary.LittleEndian.AppendUint32(charShape, 0x0000ff)
+	for _, tc := range []struct {
+		version    Version
+		borderFill uint16
+		strikeout  uint32
+	}{{Version{5, 0, 0, 6}, 0, 0}, {Version{5, 0, 2, 1}, 3, 0}, {Version{5, 0, 3, 0}, 3, 0x0000ff}} {
+		got := decodeCharShape(charShape, tc.version)
+		if got.Size != 1000 || got.BorderFillID != tc.borderFill || got.StrikeoutColor != tc.strikeout {
+			t.Errorf("%s char shape = %+v, want border fill %d and strikeout color %#x", tc.version, got, tc.borderFill, tc.strikeout)
+		}
+	}
+
+	// Before 5.0.1.0 a cell list header ends with the cell properties; a
+	// longer list header is not a cell
+	cell := binary.LittleEndian.AppendUint32(nil, 1)
+	cell = binary.LittleEndian.AppendUint32(cell, 0)
+	cell = append(cell, make([]byte, cellPropertiesSize)...)
+	longer := append(bytes.Clone(cell), make([]byte, 13)...)
+	for _, tc := range []struct {
+		version Version
+		data    []byte
+		isCell  bool
+	}{
+		{Version{5, 0, 0, 6}, cell, true},
+		{Version{5, 0, 0, 6}, longer, false},
+		{Version{5, 0, 1, 0}, longer, true},
+		{Version{5, 0, 1, 0}, cell[:20], false},
+	} {
+		s := NewRecScanner(&(&recordStream{}).add(recTagListHeader, 3, tc.data).buf)
+		s.version = tc.version
+		rec, err := s.ScanNext()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.(RecListHeader).IsCell; got != tc.isCell {
+			t.Errorf("%s list header of %d bytes: IsCell = %v, want %v", tc.version, len(tc.data), got, tc.isCell)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package hwpv5
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// ReplaceText writes the document to w with text replaced in the
+// paragraphs of its sections, table cells, text boxes, headers, footers
+// and notes: each (old, new) pair of oldnew replaces old with new, as with
+// strings.NewReplacer, within the text of each paragraph, so that
+// placeholders split across character shapes or controls are still found.
+// The replacement takes the character shape of the text where its match
+// starts. Only the changed paragraphs' text, character shape, line segment
+// and range tag records are rewritten, and the sections holding them
+// compressed again; every other stream is copied byte for byte into a new
+// compound file. It returns the number of replacements made.
+//
+// Distribution documents cannot be edited.
+func (r *Reader) ReplaceText(w io.Writer, oldnew []string) (int, error) {
+	if err := CheckReplacements(oldnew); err != nil {
+		return 0, err
+	}
+	if r.IsDistributionDoc() {
+		return 0, errors.New("distribution documents cannot be edited")
+	}
+	file, err := readCompoundFile(r.ra, r.opts.MaxStreamSize)
+	if err != nil {
+		return 0, err
+	}
+
+	compressed := r.Header.Properties.Compressed()
+	count := 0
+	for id, path := range file.paths {
+		data, ok := file.data[id]
+		if !ok || !strings.HasPrefix(path, "BodyText/Section") {
+			continue
+		}
+		if compressed {
+			fr := flate.NewReader(bytes.NewReader(data))
+			data, err = io.ReadAll(limits.NewReader(fr, r.opts.MaxStreamSize, path))
+			fr.Close()
+			if err != nil {
+				return count, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+		}
+		edited, n, err := replaceSectionText(data, oldnew)
+		if err != nil {
+			return count, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if n == 0 {
+			continue
+		}
+		count += n
+		if compressed {
+			var buf bytes.Buffer
+			fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+			if _, err := fw.Write(edited); err != nil {
+				return count, err
+			}
+			if err := fw.Close(); err != nil {
+				return count, err
+			}
+			edited = buf.Bytes()
+		}
+		file.data[id] = edited
+	}
+	return count, file.writeTo(w)
+}
+
+// rawRecord is a record of a section stream, kept as stored unless it is
+// changed.
+type rawRecord struct {
+	tag, level uint16
+	header     []byte
+	data       []byte
+	changed    bool
+}
+
+// splitRecords splits a decoded section stream into its records.
+func splitRecords(data []byte) ([]rawRecord, error) {
+	var records []rawRecord
+	for pos := 0; pos < len(data); {
+		if len(data)-pos < 4 {
+			return nil, fmt.Errorf("record header cut short at offset %d", pos)
+		}
+		raw := binary.LittleEndian.Uint32(data[pos:])
+		headerSize, size := 4, int(raw>>20)
+		if size == 0xfff {
+			if len(data)-pos < 8 {
+				return nil, fmt.Errorf("record header cut short at offset %d", pos)
+			}
+			headerSize, size = 8, int(binary.LittleEndian.Uint32(data[pos+4:]))
+		}
+		if size < 0 || size > len(data)-pos-headerSize {
+			return nil, fmt.Errorf("record at offset %d overruns the stream", pos)
+		}
+		records = append(records, rawRecord{
+			tag:    uint16(raw & 0x3ff),
+			level:  uint16(raw >> 10 & 0x3ff),
+			header: data[pos : pos+headerSize],
+			data:   data[pos+headerSize : pos+headerSize+size],
+		})
+		pos += headerSize + size
+	}
+	return records, nil
+}
+
+// joinRecords writes records back into a section stream, with new headers
+// for the changed ones.
+func joinRecords(records []rawRecord) []byte {
+	var out []byte
+	for _, rec := range records {
+		if !rec.changed {
+			out = append(append(out, rec.header...), rec.data...)
+			continue
+		}
+		header := uint32(rec.tag) | uint32(rec.level)<<10
+		if len(rec.data) < 0xfff {
+			out = binary.LittleEndian.AppendUint32(out, header|uint32(len(rec.data))<<20)
+		} else {
+			out = binary.LittleEndian.AppendUint32(out, header|0xfff<<20)
+			out = binary.LittleEndian.AppendUint32(out, uint32(len(rec.data)))
+		}
+		out = append(out, rec.data...)
+	}
+	return out
+}
+
+// replaceSectionText applies the replacements to each paragraph of a
+// decoded section stream, returning the stream with the changed records
+// rewritten and the number of replacements.
+func replaceSectionText(data []byte, oldnew []string) ([]byte, int, error) {
+	records, err := splitRecords(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := 0
+	for i := range records {
+		if records[i].tag != recTagParaHeader {
+			continue
+		}
+		// The paragraph's text, char shapes, line segments and range tags
+		// follow its header one level down, before its controls
+		para := paragraphRecords{header: &records[i]}
+		for j := i + 1; j < len(records) && records[j].level == records[i].level+1; j++ {
+			switch records[j].tag {
+			case recTagParaText:
+				para.text = &records[j]
+			case recTagParaCharShape:
+				para.charShapes = &records[j]
+			case recTagParaLineSeg:
+				para.lineSegs = &records[j]
+			case recTagParaRangeTag:
+				para.rangeTags = &records[j]
+			}
+		}
+		count += para.replace(oldnew)
+	}
+	if count == 0 {
+		return data, 0, nil
+	}
+	return joinRecords(records), count, nil
+}
+
+// paragraphRecords are the records of a paragraph that refer to positions
+// in its text.
+type paragraphRecords struct {
+	header, text, charShapes, lineSegs, rangeTags *rawRecord
+}
+
+// replace applies the replacements to the paragraph text and moves the
+// positions in the other records along, returning the number of
+// replacements. Text is matched with the controls left out, and controls
+// within a match are kept; the replacement goes where the match starts.
+func (p *paragraphRecords) replace(oldnew []string) int {
+	if p.text == nil || len(p.header.data) < 4 {
+		return 0
+	}
+	units := make([]uint16, len(p.text.data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(p.text.data[2*i:])
+	}
+
+	// The text without controls, with the WCHAR position and width of the
+	// character each of its bytes belongs to
+	var text strings.Builder
+	var unitOf, widthOf []int
+	for i := 0; i < len(units); {
+		code := units[i]
+		if code < 32 {
+			i += controlWidth(code)
+			continue
+		}
+		r, width := rune(code), 1
+		if utf16.IsSurrogate(r) && i+1 < len(units) {
+			if pair := utf16.DecodeRune(r, rune(units[i+1])); pair != unicode.ReplacementChar {
+				r, width = pair, 2
+			}
+		}
+		start := text.Len()
+		text.WriteRune(r)
+		for range text.Len() - start {
+			unitOf, widthOf = append(unitOf, i), append(widthOf, width)
+		}
+		i += width
+	}
+	matches := FindMatches(text.String(), oldnew)
+	if len(matches) == 0 {
+		return 0
+	}
+
+	drop := make([]bool, len(units))
+	insert := make(map[int][]uint16)
+	for _, m := range matches {
+		for b := m.Start; b < m.End; b++ {
+			for k := range widthOf[b] {
+				drop[unitOf[b]+k] = true
+			}
+		}
+		insert[unitOf[m.Start]] = utf16.Encode([]rune(m.New))
+	}
+	// moved maps each position in the old text to the new text: a match's
+	// start stays before its replacement, and positions within the match
+	// move after it
+	moved := make([]uint32, len(units)+1)
+	var out []uint16
+	for i := 0; i <= len(units); i++ {
+		moved[i] = uint32(len(out))
+		out = append(out, insert[i]...)
+		if i < len(units) && !drop[i] {
+			out = append(out, units[i])
+		}
+	}
+	move := func(pos uint32) uint32 {
+		if int(pos) < len(moved) {
+			return moved[pos]
+		}
+		return pos + uint32(len(out)) - uint32(len(units))
+	}
+
+	data := make([]byte, 0, 2*len(out)+1)
+	for _, u := range out {
+		data = binary.LittleEndian.AppendUint16(data, u)
+	}
+	// An odd trailing byte is kept
+	data = append(data, p.text.data[2*len(units):]...)
+	p.text.data, p.text.changed = data, true
+
+	header := bytes.Clone(p.header.data)
+	raw := binary.LittleEndian.Uint32(header)
+	length := int64(raw&^(1<<31)) + int64(len(out)) - int64(len(units))
+	binary.LittleEndian.PutUint32(header, raw&(1<<31)|uint32(max(length, 0)))
+	p.header.data, p.header.changed = header, true
+
+	if rec := p.charShapes; rec != nil {
+		// Shapes whose start moved onto the next shape's are dropped
+		var runs []byte
+		for i := 0; i+8 <= len(rec.data); i += 8 {
+			pos := move(binary.LittleEndian.Uint32(rec.data[i:]))
+			if n := len(runs); n >= 8 && binary.LittleEndian.Uint32(runs[n-8:]) == pos {
+				runs = runs[:n-8]
+			}
+			runs = binary.LittleEndian.AppendUint32(runs, pos)
+			runs = append(runs, rec.data[i+4:i+8]...)
+		}
+		rec.data, rec.changed = runs, true
+		if len(header) >= 14 {
+			binary.LittleEndian.PutUint16(header[12:], uint16(len(runs)/8))
+		}
+	}
+	if rec := p.lineSegs; rec != nil {
+		rec.data = bytes.Clone(rec.data)
+		for i := 0; i+lineSegSize <= len(rec.data); i += lineSegSize {
+			binary.LittleEndian.PutUint32(rec.data[i:], move(binary.LittleEndian.Uint32(rec.data[i:])))
+		}
+		rec.changed = true
+	}
+	if rec := p.rangeTags; rec != nil {
+		rec.data = bytes.Clone(rec.data)
+		for i := 0; i+rangeTagSize <= len(rec.data); i += rangeTagSize {
+			binary.LittleEndian.PutUint32(rec.data[i:], move(binary.LittleEndian.Uint32(rec.data[i:])))
+			binary.LittleEndian.PutUint32(rec.data[i+4:], move(binary.LittleEndian.Uint32(rec.data[i+4:])))
+		}
+		rec.changed = true
+	}
+	return len(matches)
+}
+
+// rangeTagSize is the size of a range tag in a ParaRangeTag record.
+const rangeTagSize = 12
+
+// controlWidth returns the number of WCHARs a control takes in paragraph
+// text: char controls take one, inline and extended controls eight.
+func controlWidth(code uint16) int {
+	switch code {
+	case paraTextCodeUnusable, paraTextCodeReserved1, paraTextCodeLineBreak, paraTextCodeParaBreak,
+		paraTextCodeHyphen, paraTextCodeReserved25, paraTextCodeReserved26, paraTextCodeReserved27,
+		paraTextCodeReserved28, paraTextCodeReserved29, paraTextCodeBundleSpace, paraTextCodeFixedSpace:
+		return 1
+	}
+	return 8
+}
+
+// CheckReplacements checks (old, new) replacement pairs: their count must
+// be even and no old text empty.
+func CheckReplacements(oldnew []string) error {
+	if len(oldnew)%2 != 0 {
+		return errors.New("odd argument count in replacements")
+	}
+	for i := 0; i < len(oldnew); i += 2 {
+		if oldnew[i] == "" {
+			return errors.New("empty text to replace")
+		}
+	}
+	return nil
+}
+
+// TextMatch is an occurrence of a replaced text, by byte range, and its
+// replacement.
+type TextMatch struct {
+	Start, End int
+	New        string
+}
+
+// FindMatches returns the non-overlapping occurrences of the old texts of
+// oldnew in text, from left to right, trying the pairs in order at each
+// position.
+func FindMatches(text string, oldnew []string) []TextMatch {
+	var matches []TextMatch
+	for i := 0; i < len(text); {
+		found := false
+		for j := 0; j < len(oldnew); j += 2 {
+			if strings.HasPrefix(text[i:], oldnew[j]) {
+				matches = append(matches, TextMatch{i, i + len(oldnew[j]), oldnew[j+1]})
+				i += len(oldnew[j])
+				found = true
+				break
+			}
+		}
+		if !found {
+			i++
+		}
+	}
+	return matches
+}
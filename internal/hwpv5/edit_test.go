@@ -0,0 +1,311 @@
+package hwpv5
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+type testStream struct {
+	path string
+	data []byte
+}
+
+// compoundFileData builds a compound file holding the streams, with the
+// storages on their paths. The entries of each storage are chained as right
+// siblings.
+func compoundFileData(t *testing.T, streams ...testStream) []byte {
+	t.Helper()
+	file := &compoundFile{data: make(map[int][]byte)}
+	storages := map[string]int{"": 0}
+	last := map[int]int{}
+	add := func(name string, kind byte, parent int) int {
+		entry := make([]byte, cfbEntrySize)
+		units := utf16.Encode([]rune(name))
+		for i, u := range units {
+			binary.LittleEndian.PutUint16(entry[2*i:], u)
+		}
+		binary.LittleEndian.PutUint16(entry[64:], uint16(2*len(units)+2))
+		entry[66], entry[67] = kind, 1
+		for _, off := range []int{68, 72, 76} {
+			binary.LittleEndian.PutUint32(entry[off:], cfbNoStream)
+		}
+		id := len(file.entries)
+		file.entries = append(file.entries, entry)
+		if id == 0 {
+			return id
+		}
+		if prev, ok := last[parent]; ok {
+			binary.LittleEndian.PutUint32(file.entries[prev][72:], uint32(id))
+		} else {
+			binary.LittleEndian.PutUint32(file.entries[parent][76:], uint32(id))
+		}
+		last[parent] = id
+		return id
+	}
+	add("Root Entry", cfbObjectRoot, 0)
+	for _, s := range streams {
+		dir, name := "", s.path
+		if i := strings.LastIndex(s.path, "/"); i >= 0 {
+			dir, name = s.path[:i], s.path[i+1:]
+		}
+		if _, ok := storages[dir]; !ok {
+			storages[dir] = add(dir, 1, 0)
+		}
+		file.data[add(name, cfbObjectStream, storages[dir])] = s.data
+	}
+
+	var buf bytes.Buffer
+	if err := file.writeTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompoundFileRoundTrip(t *testing.T) {
+	pattern := func(n int) []byte {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		return data
+	}
+	streams := []testStream{
+		{"FileHeader", pattern(256)},
+		{"BodyText/Section0", pattern(5000)},
+		{"BodyText/Section1", pattern(100)},
+		{"Empty", nil},
+		// More than 109 FAT sectors take a DIFAT sector
+		{"BinData/BIN0001.png", pattern(8 << 20)},
+	}
+	data := compoundFileData(t, streams...)
+
+	// The file reads back with this package's reader and with mscfb's
+	file, err := readCompoundFile(bytes.NewReader(data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string][]byte{}
+	for id, path := range file.paths {
+		if d, ok := file.data[id]; ok {
+			got[path] = d
+		}
+	}
+	for _, s := range streams {
+		if !bytes.Equal(got[s.path], s.data) {
+			t.Errorf("%s: %d bytes read back, want %d", s.path, len(got[s.path]), len(s.data))
+		}
+		r, err := OpenRawStream(bytes.NewReader(data), s.path)
+		if err != nil {
+			t.Fatalf("%s: %v", s.path, err)
+		}
+		if d, _ := io.ReadAll(r); !bytes.Equal(d, s.data) && len(s.data) > 0 {
+			t.Errorf("%s: mscfb read %d bytes, want %d", s.path, len(d), len(s.data))
+		}
+	}
+
+	// Writing it again gives the same file
+	var again bytes.Buffer
+	if err := file.writeTo(&again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Bytes(), data) {
+		t.Error("rewritten compound file differs")
+	}
+
+	if _, err := readCompoundFile(bytes.NewReader(data), 1<<20); err == nil {
+		t.Error("stream over the size limit: no error")
+	}
+}
+
+// paraRecords builds the records of a paragraph of text in runs of char
+// shapes 0, 1, ..., with a line segment at each run and a range tag over
+// the last run.
+func paraRecords(rs *recordStream, runs ...string) {
+	var text, shapes, segs, tags []byte
+	for i, run := range runs {
+		pos := uint32(len(text) / 2)
+		shapes = binary.LittleEndian.AppendUint32(shapes, pos)
+		shapes = binary.LittleEndian.AppendUint32(shapes, uint32(i))
+		seg := make([]byte, lineSegSize)
+		binary.LittleEndian.PutUint32(seg, pos)
+		segs = append(segs, seg...)
+		text = append(text, utf16Bytes(run)...)
+		if i == len(runs)-1 {
+			tags = binary.LittleEndian.AppendUint32(tags, pos)
+			tags = binary.LittleEndian.AppendUint32(tags, uint32(len(text)/2))
+			tags = binary.LittleEndian.AppendUint32(tags, 0x01000007)
+		}
+	}
+	text = binary.LittleEndian.AppendUint16(text, paraTextCodeParaBreak)
+
+	header := make([]byte, 22)
+	binary.LittleEndian.PutUint32(header, 1<<31|uint32(len(text)/2))
+	binary.LittleEndian.PutUint16(header[12:], uint16(len(runs)))
+	binary.LittleEndian.PutUint16(header[14:], 1)
+	binary.LittleEndian.PutUint16(header[16:], uint16(len(runs)))
+	rs.add(recTagParaHeader, 0, header)
+	rs.add(recTagParaText, 1, text)
+	rs.add(recTagParaCharShape, 1, shapes)
+	rs.add(recTagParaLineSeg, 1, segs)
+	rs.add(recTagParaRangeTag, 1, tags)
+}
+
+func TestReplaceSectionText(t *testing.T) {
+	stream, unchanged := &recordStream{}, &recordStream{}
+	paraRecords(stream, "이름: {{na", "me}}, {{name}}")
+	paraRecords(unchanged, "변경 없음")
+	section := append(stream.buf.Bytes(), unchanged.buf.Bytes()...)
+
+	edited, n, err := replaceSectionText(section, []string{"{{name}}", "홍길동"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("replacements = %d, want 2", n)
+	}
+	records, err := splitRecords(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u32s := func(data []byte, stride int) []uint32 {
+		var values []uint32
+		for i := 0; i+stride <= len(data); i += stride {
+			values = append(values, binary.LittleEndian.Uint32(data[i:]))
+		}
+		return values
+	}
+	header := records[0].data
+	if got := binary.LittleEndian.Uint32(header); got != 1<<31|13 {
+		t.Errorf("text length = %#x, want %#x", got, 1<<31|13)
+	}
+	if got := binary.LittleEndian.Uint16(header[12:]); got != 2 {
+		t.Errorf("char shape count = %d, want 2", got)
+	}
+	text := records[1].data
+	units := make([]uint16, len(text)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(text[2*i:])
+	}
+	if got := string(utf16.Decode(units)); got != "이름: 홍길동, 홍길동\r" {
+		t.Errorf("text = %q", got)
+	}
+	// The split placeholder takes the first run's shape; the second run
+	// starts after the replacement
+	for _, tc := range []struct {
+		name string
+		got  []uint32
+		want []uint32
+	}{
+		{"char shapes", u32s(records[2].data, 4), []uint32{0, 0, 7, 1}},
+		{"line segments", u32s(records[3].data, lineSegSize), []uint32{0, 7}},
+		{"range tags", u32s(records[4].data, 4), []uint32{7, 12, 0x01000007}},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+	// The unchanged paragraph is copied as it was
+	if !bytes.HasSuffix(edited, unchanged.buf.Bytes()) {
+		t.Error("second paragraph changed")
+	}
+
+	if _, n, _ := replaceSectionText(section, []string{"{{date}}", "오늘"}); n != 0 {
+		t.Errorf("replacements without a match = %d, want 0", n)
+	}
+}
+
+func TestReplaceTextControls(t *testing.T) {
+	// A placeholder split by an inline control is matched, and the control
+	// is kept
+	tab := append(binary.LittleEndian.AppendUint16(nil, paraTextCodeTab), make([]byte, 14)...)
+	stream := (&recordStream{}).para(0, "{{a", tab, "}}b")
+	edited, n, err := replaceSectionText(stream.buf.Bytes(), []string{"{{a}}", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("replacements = %d, want 1", n)
+	}
+	records, _ := splitRecords(edited)
+	want := append(append(utf16Bytes("x"), tab...), utf16Bytes("b")...)
+	if !bytes.Equal(records[1].data, want) {
+		t.Errorf("text = % x, want % x", records[1].data, want)
+	}
+}
+
+// hwpFileData builds an HWP file with one section holding the paragraphs,
+// and the extra streams as given.
+func hwpFileData(t *testing.T, compressed bool, section []byte, extra ...testStream) []byte {
+	t.Helper()
+	header := make([]byte, 256)
+	copy(header, signatureText)
+	binary.LittleEndian.PutUint32(header[32:], 0x05010100)
+	docInfo := (&recordStream{}).add(recTagDocumentProperties, 0, []byte{1, 0}).buf.Bytes()
+	if compressed {
+		header[36] = 1
+		docInfo, section = deflateData(docInfo), deflateData(section)
+	}
+	return compoundFileData(t, append([]testStream{
+		{"FileHeader", header},
+		{"DocInfo", docInfo},
+		{"BodyText/Section0", section},
+		{"PrvText", utf16Bytes("미리보기")},
+	}, extra...)...)
+}
+
+// deflateData compresses data as HWP streams are.
+func deflateData(data []byte) []byte {
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	fw.Write(data)
+	fw.Close()
+	return buf.Bytes()
+}
+
+func TestReplaceText(t *testing.T) {
+	stream := &recordStream{}
+	paraRecords(stream, "이름: {{na", "me}}")
+	paraRecords(stream, "변경 없음")
+
+	for _, compressed := range []bool{false, true} {
+		data := hwpFileData(t, compressed, stream.buf.Bytes())
+		r, err := OpenReader(bytes.NewReader(data), document.DefaultScanOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		n, err := r.ReplaceText(&out, []string{"{{name}}", "홍길동"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("compressed %v: replacements = %d, want 1", compressed, n)
+		}
+
+		s, err := Open(bytes.NewReader(out.Bytes()), document.DefaultScanOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := collectTexts(t, s), []string{"이름: 홍길동", "변경 없음"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("compressed %v: texts = %q, want %q", compressed, got, want)
+		}
+		// Other streams are copied as they were
+		for _, name := range []string{"FileHeader", "DocInfo", "PrvText"} {
+			before, _ := OpenRawStream(bytes.NewReader(data), name)
+			after, _ := OpenRawStream(bytes.NewReader(out.Bytes()), name)
+			a, _ := io.ReadAll(before)
+			b, _ := io.ReadAll(after)
+			if !bytes.Equal(a, b) {
+				t.Errorf("compressed %v: %s changed", compressed, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package hwpv5
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// Markers delimiting the content of an embedded document in the output.
+const (
+	embeddedBeginText = "[EMBEDDED DOCUMENT]"
+	embeddedEndText   = "[END OF EMBEDDED DOCUMENT]"
+)
+
+// cfbSignature starts every OLE compound file.
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// openEmbedded opens the OLE object stored in BinData id as an HWP document.
+// It returns nil if the object is not an HWP document or cannot be read; the
+// object is then only represented by its placeholder.
+func (r *Reader) openEmbedded(id uint16) *ContentScanner {
+	data, err := r.readBinData(id)
+	if err != nil {
+		return nil
+	}
+
+	// OLE streams carry a 4-byte size before the compound file
+	if !bytes.HasPrefix(data, cfbSignature) && len(data) > 4 && bytes.HasPrefix(data[4:], cfbSignature) {
+		data = data[4:]
+	}
+	if !bytes.HasPrefix(data, cfbSignature) {
+		return nil
+	}
+
+	opts := r.opts
+	opts.EmbeddedDepth--
+	opts.Resume = nil
+	opts.OnCheckpoint = nil
+	scanner, err := Open(bytes.NewReader(data), opts)
+	if err != nil {
+		return nil
+	}
+	return scanner.(*ContentScanner)
+}
+
+// embeddedScanner yields the content of an embedded document between
+// begin and end marker paragraphs. Node IDs are prefixed with the ID of the
+// embedding object and a colon.
+type embeddedScanner struct {
+	id      string
+	inner   document.ContentNodeScanner
+	started bool
+	done    bool
+}
+
+func (e *embeddedScanner) Next() (document.ContentNode, error) {
+	if !e.started {
+		e.started = true
+		return &document.Paragraph{ID: e.id + ".begin", Text: embeddedBeginText}, nil
+	}
+	if e.done {
+		return nil, io.EOF
+	}
+
+	node, err := e.inner.Next()
+	if err == io.EOF {
+		e.done = true
+		return &document.Paragraph{ID: e.id + ".end", Text: embeddedEndText}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("embedded document %s: %w", e.id, err)
+	}
+
+	switch n := node.(type) {
+	case *document.Paragraph:
+		n.ID = e.id + ":" + n.ID
+	case *document.Heading:
+		n.ID = e.id + ":" + n.ID
+	case *document.Table:
+		n.ID = e.id + ":" + n.ID
+	case *document.Image:
+		n.ID = e.id + ":" + n.ID
+	case *document.Equation:
+		n.ID = e.id + ":" + n.ID
+	case *document.Media:
+		n.ID = e.id + ":" + n.ID
+	case *document.Footnote:
+		n.ID = e.id + ":" + n.ID
+		if n.Anchor != "" {
+			n.Anchor = e.id + ":" + n.Anchor
+		}
+	case *document.Chart:
+		n.ID = e.id + ":" + n.ID
+	case *document.PageBreak:
+		n.ID = e.id + ":" + n.ID
+	case *document.SectionBreak:
+		n.ID = e.id + ":" + n.ID
+	case *document.Comment:
+		n.ID = e.id + ":" + n.ID
+		if n.Anchor != "" {
+			n.Anchor = e.id + ":" + n.Anchor
+		}
+	}
+	return node, nil
+}
@@ -21,6 +21,20 @@ func (v Version) String() string {
 	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Rev)
 }
 
+// AtLeast reports whether v is the version major.minor.patch.rev or later.
+// Record layouts grew over the 5.0.x versions; fields added in a version
+// are only read from documents of that version on.
+func (v Version) AtLeast(major, minor, patch, rev byte) bool {
+	a := [4]byte{v.Major, v.Minor, v.Patch, v.Rev}
+	b := [4]byte{major, minor, patch, rev}
+	return bytes.Compare(a[:], b[:]) >= 0
+}
+
+// latestVersion is the newest format version whose record layouts are
+// known; records are read as this version when no file header says
+// otherwise.
+var latestVersion = Version{5, 1, 1, 0}
+
 // FileProperties exposes a few frequently used flags from the FileHeader stream.
 type FileProperties struct {
 	Raw uint32
@@ -29,6 +43,9 @@ type FileProperties struct {
 func (p FileProperties) Compressed() bool { return p.Raw&0x1 != 0 }
 func (p FileProperties) Encrypted() bool  { return p.Raw&0x2 != 0 }
 
+// Signed reports whether the document holds digital signature information.
+func (p FileProperties) Signed() bool { return p.Raw&0x80 != 0 }
+
 // FileHeader mirrors the 256-byte FileHeader stream.
 type FileHeader struct {
 	Signature       string
@@ -0,0 +1,67 @@
+package hwpv5
+
+import (
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// ctrlIDForm is MAKE_4CHID('f','o','r','m'), the control of a form object.
+const ctrlIDForm = 0x666f726d
+
+// Form object types of HWPTAG_FORM_OBJECT records
+const (
+	formTypePushButton  = 0x7462702b // MAKE_4CHID('t','b','p','+')
+	formTypeCheckButton = 0x7462632b // MAKE_4CHID('t','b','c','+')
+	formTypeRadioButton = 0x7462722b // MAKE_4CHID('t','b','r','+')
+	formTypeComboBox    = 0x7463622b // MAKE_4CHID('t','c','b','+')
+	formTypeEdit        = 0x74656474 // MAKE_4CHID('t','e','d','t')
+)
+
+// formField returns the field of a form object, or false for controls that
+// hold no value, such as push buttons.
+func formField(rec RecFormObject) (document.Field, bool) {
+	field := document.Field{Name: commandProperty(rec.Properties, "Name")}
+	switch rec.TypeID {
+	case formTypeCheckButton, formTypeRadioButton:
+		field.Type = document.FieldCheckBox
+		if rec.TypeID == formTypeRadioButton {
+			field.Type = document.FieldRadio
+		}
+		field.Value = "off"
+		if v := commandProperty(rec.Properties, "Value"); v == "1" || strings.EqualFold(v, "checked") {
+			field.Value = "on"
+		}
+	case formTypeComboBox:
+		field.Type, field.Value = document.FieldComboBox, commandProperty(rec.Properties, "Text")
+	case formTypeEdit:
+		field.Type, field.Value = document.FieldEdit, commandProperty(rec.Properties, "Text")
+	default:
+		return field, false
+	}
+	return field, true
+}
+
+// readFormObject consumes the children of a form control and returns the
+// field of its form object.
+func (s *ContentScanner) readFormObject(parentLevel uint16) (document.Field, bool, error) {
+	var field document.Field
+	found := false
+	for {
+		rec, err := s.scanRecord()
+		if err == io.EOF {
+			return field, found, nil
+		}
+		if err != nil {
+			return field, found, err
+		}
+		if rec.Lvl() <= parentLevel {
+			s.putBack(rec)
+			return field, found, nil
+		}
+		if obj, ok := rec.(RecFormObject); ok && !found {
+			field, found = formField(obj)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package hwpv5
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Number formats (번호 모양) of footnotes, endnotes and auto-numbers
+const (
+	NumberDigits             uint8 = 0    // 1, 2, 3
+	NumberCircledDigits      uint8 = 1    // ①, ②, ③
+	NumberUpperRoman         uint8 = 2    // I, II, III
+	NumberLowerRoman         uint8 = 3    // i, ii, iii
+	NumberUpperLatin         uint8 = 4    // A, B, C
+	NumberLowerLatin         uint8 = 5    // a, b, c
+	NumberCircledUpperLatin  uint8 = 6    // Ⓐ, Ⓑ, Ⓒ
+	NumberCircledLowerLatin  uint8 = 7    // ⓐ, ⓑ, ⓒ
+	NumberHangulSyllable     uint8 = 8    // 가, 나, 다
+	NumberCircledHangul      uint8 = 9    // ㉮, ㉯, ㉰
+	NumberHangulJamo         uint8 = 10   // ㄱ, ㄴ, ㄷ
+	NumberCircledHangulJamo  uint8 = 11   // ㉠, ㉡, ㉢
+	NumberHangulNumeral      uint8 = 12   // 일, 이, 삼
+	NumberIdeograph          uint8 = 13   // 一, 二, 三
+	NumberCircledIdeograph   uint8 = 14   // ㊀, ㊁, ㊂
+	NumberHeavenlyStemHangul uint8 = 15   // 갑, 을, 병
+	NumberHeavenlyStem       uint8 = 16   // 甲, 乙, 丙
+	NumberSymbols            uint8 = 0x80 // *, †, ‡, § in turn
+	NumberUserSymbol         uint8 = 0x81 // the user symbol
+)
+
+// numberSequences lists the symbols of number formats that count through a
+// fixed sequence.
+var numberSequences = map[uint8][]string{
+	NumberHangulSyllable:     strings.Split("가나다라마바사아자차카타파하", ""),
+	NumberCircledHangul:      runeRange('㉮', 14),
+	NumberHangulJamo:         strings.Split("ㄱㄴㄷㄹㅁㅂㅅㅇㅈㅊㅋㅌㅍㅎ", ""),
+	NumberCircledHangulJamo:  runeRange('㉠', 14),
+	NumberCircledIdeograph:   runeRange('㊀', 10),
+	NumberHeavenlyStemHangul: strings.Split("갑을병정무기경신임계", ""),
+	NumberHeavenlyStem:       strings.Split("甲乙丙丁戊己庚辛壬癸", ""),
+	NumberCircledUpperLatin:  runeRange('Ⓐ', 26),
+	NumberCircledLowerLatin:  runeRange('ⓐ', 26),
+}
+
+func runeRange(first rune, n int) []string {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = string(first + rune(i))
+	}
+	return s
+}
+
+// FormatNumber writes the positive number n in a number format. Numbers a
+// format cannot express, such as ⑳ and beyond, fall back to digits.
+func FormatNumber(format uint8, n int, user rune) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+	if seq, ok := numberSequences[format]; ok {
+		if n <= len(seq) {
+			return seq[n-1]
+		}
+		return strconv.Itoa(n)
+	}
+	switch format {
+	case NumberCircledDigits:
+		if n <= 20 {
+			return string('①' + rune(n-1))
+		}
+	case NumberUpperRoman:
+		return strings.ToUpper(roman(n))
+	case NumberLowerRoman:
+		return roman(n)
+	case NumberUpperLatin:
+		return strings.ToUpper(latin(n))
+	case NumberLowerLatin:
+		return latin(n)
+	case NumberHangulNumeral:
+		return sinoNumeral(n, []rune("영일이삼사오육칠팔구"), []rune("십백천"))
+	case NumberIdeograph:
+		return sinoNumeral(n, []rune("〇一二三四五六七八九"), []rune("十百千"))
+	case NumberSymbols:
+		return strings.Repeat(string([]rune("*†‡§")[(n-1)%4]), (n-1)/4+1)
+	case NumberUserSymbol:
+		if user != 0 {
+			return string(user)
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// roman writes n in lowercase roman numerals, or in digits from 4000 on.
+func roman(n int) string {
+	if n >= 4000 {
+		return strconv.Itoa(n)
+	}
+	var b strings.Builder
+	for _, r := range []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+		{50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+	} {
+		for ; n >= r.value; n -= r.value {
+			b.WriteString(r.symbol)
+		}
+	}
+	return b.String()
+}
+
+// latin writes n in lowercase letters: a to z, then aa, bb and so on.
+func latin(n int) string {
+	return strings.Repeat(string(rune('a'+(n-1)%26)), (n-1)/26+1)
+}
+
+// sinoNumeral writes n with Sino-Korean or Chinese numerals, given the
+// digits 0 to 9 and the place words for ten, hundred and thousand: 13 is
+// 십삼 and 205 is 이백오. Numbers from 10000 on are written in digits.
+func sinoNumeral(n int, digits, places []rune) string {
+	if n >= 10000 {
+		return strconv.Itoa(n)
+	}
+	var b strings.Builder
+	for place := 3; place >= 0; place-- {
+		d := n
+		for range place {
+			d /= 10
+		}
+		d %= 10
+		if d == 0 {
+			continue
+		}
+		if d > 1 || place == 0 {
+			b.WriteRune(digits[d])
+		}
+		if place > 0 {
+			b.WriteRune(places[place-1])
+		}
+	}
+	return b.String()
+}
+
+// ParaHead is a level of a paragraph numbering (문단 번호).
+type ParaHead struct {
+	// Format is the number format, one of the Number* constants.
+	Format uint8
+	// Text is the text of the number, in which ^1 to ^9 and ^A stand for
+	// the numbers of levels 1 to 10 in their formats, e.g. "^1.^2.".
+	Text string
+	// Start is the first number of the level.
+	Start int
+}
+
+// Numbering is a paragraph numbering (HWPTAG_NUMBERING): the number of
+// each level of a numbered list.
+type Numbering struct {
+	Levels []ParaHead
+}
+
+// Number advances a numbered list to its next paragraph at a level, from
+// 1, and returns the paragraph's number text and its number at the level.
+// numbers holds the current number of each level, zero for levels not yet
+// started, and is updated: the level is started or counted up, and the
+// levels below it restart.
+func (n Numbering) Number(numbers []int, level int) (string, int) {
+	if level < 1 || level > len(numbers) || level > len(n.Levels) {
+		return "", 0
+	}
+	if numbers[level-1] == 0 {
+		numbers[level-1] = max(n.Levels[level-1].Start, 1)
+	} else {
+		numbers[level-1]++
+	}
+	clear(numbers[level:])
+
+	text := n.Levels[level-1].Text
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		l := paraHeadLevel(text, i)
+		if l == 0 || l > len(n.Levels) {
+			b.WriteByte(text[i])
+			continue
+		}
+		head := n.Levels[l-1]
+		number := numbers[l-1]
+		if number == 0 {
+			number = max(head.Start, 1)
+		}
+		b.WriteString(FormatNumber(head.Format, number, 0))
+		i++
+	}
+	return b.String(), numbers[level-1]
+}
+
+// paraHeadLevel returns the level a ^ at text[i] stands for, or 0 if there
+// is none.
+func paraHeadLevel(text string, i int) int {
+	if text[i] != '^' || i+1 >= len(text) {
+		return 0
+	}
+	switch c := text[i+1]; {
+	case c >= '1' && c <= '9':
+		return int(c - '0')
+	case c == 'A':
+		return 10
+	}
+	return 0
+}
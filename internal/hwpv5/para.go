@@ -1,9 +1,6 @@
 package hwpv5
 
-import (
-	"encoding/binary"
-	"io"
-)
+import "unicode/utf16"
 
 const (
 	// Unusable range (0)
@@ -57,21 +54,29 @@ const (
 
 type ParaTextElement interface {
 	isParaTextElement()
+	// Position returns the position of the element in the paragraph text,
+	// in WCHARs, which is what ParaCharShape runs refer to.
+	Position() uint32
 }
 
 type paraTextBase struct {
 	Code uint16
+	Pos  uint32
 }
 
 func (p paraTextBase) isParaTextElement() {}
+func (p paraTextBase) Position() uint32   { return p.Pos }
 
 type (
 	ParaTextString struct {
 		paraTextBase
 		Value string
 	}
-	ParaTextSectionColDef   struct{ paraTextBase }
-	ParaTextFieldStart      struct{ paraTextBase }
+	ParaTextSectionColDef struct{ paraTextBase }
+	ParaTextFieldStart    struct {
+		paraTextBase
+		CtrlID uint32 // field type, e.g. '%clk' for click-here fields
+	}
 	ParaTextFieldEnd        struct{ paraTextBase }
 	ParaTextTitleMark       struct{ paraTextBase }
 	ParaTextTab             struct{ paraTextBase }
@@ -91,31 +96,41 @@ type (
 )
 
 type paraTextDecoder struct {
-	data io.Reader
+	r recordReader
 }
 
-func (d *paraTextDecoder) decodeParaTextElements() []ParaTextElement {
+// decodeParaTextElements decodes the paragraph text. Extended and inline
+// controls carry 7 WCHARs after their code; a control cut short by the end
+// of the record is an ErrTruncatedRecord.
+func (d *paraTextDecoder) decodeParaTextElements() ([]ParaTextElement, error) {
 	var elements []ParaTextElement
-	var stringBuffer []rune
+	var stringBuffer []uint16
+	var stringPos uint32
 
 	flushString := func() {
 		if len(stringBuffer) > 0 {
 			elements = append(elements, ParaTextString{
-				paraTextBase: paraTextBase{Code: 0},
-				Value:        string(stringBuffer),
+				paraTextBase: paraTextBase{Code: 0, Pos: stringPos},
+				Value:        string(utf16.Decode(stringBuffer)),
 			})
 			stringBuffer = stringBuffer[:0]
 		}
 	}
 
-	for {
-		var code uint16
-		if err := binary.Read(d.data, binary.LittleEndian, &code); err != nil {
+	for d.r.remaining() > 0 {
+		// Positions count the WCHARs read so far
+		start := uint32(d.r.pos / 2)
+		code := d.r.u16()
+		if d.r.err != nil {
 			break
 		}
 
 		if code >= 32 {
-			stringBuffer = append(stringBuffer, rune(code))
+			if len(stringBuffer) == 0 {
+				stringPos = start
+			}
+			// Characters outside the BMP take two WCHARs, a surrogate pair
+			stringBuffer = append(stringBuffer, code)
 			continue
 		}
 
@@ -128,95 +143,96 @@ func (d *paraTextDecoder) decodeParaTextElements() []ParaTextElement {
 		// === Extended Controls (8 WCHAR = 16 bytes) ===
 		case paraTextCodeSectionColDef:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextSectionColDef{paraTextBase{code}})
+			elements = append(elements, ParaTextSectionColDef{paraTextBase{code, start}})
 
 		case paraTextCodeFieldStart:
-			d.skipBytes(14)
-			elements = append(elements, ParaTextFieldStart{paraTextBase{code}})
+			id := d.r.u32()
+			d.skipBytes(10)
+			elements = append(elements, ParaTextFieldStart{paraTextBase{code, start}, id})
 
 		// === Inline Controls (8 WCHAR = 16 bytes) ===
 		case paraTextCodeFieldEnd:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextFieldEnd{paraTextBase{code}})
+			elements = append(elements, ParaTextFieldEnd{paraTextBase{code, start}})
 
 		case paraTextCodeReserved5, paraTextCodeReserved6, paraTextCodeReserved7:
 			d.skipBytes(14)
 
 		case paraTextCodeTitleMark:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextTitleMark{paraTextBase{code}})
+			elements = append(elements, ParaTextTitleMark{paraTextBase{code, start}})
 
 		case paraTextCodeTab:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextTab{paraTextBase{code}})
+			elements = append(elements, ParaTextTab{paraTextBase{code, start}})
 
 		// === Char Controls (1 WCHAR = 2 bytes) ===
 		case paraTextCodeLineBreak:
-			elements = append(elements, ParaTextLineBreak{paraTextBase{code}})
+			elements = append(elements, ParaTextLineBreak{paraTextBase{code, start}})
 
 		// === Extended Controls ===
 		case paraTextCodeGsoTable:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextGsoTable{paraTextBase{code}})
+			elements = append(elements, ParaTextGsoTable{paraTextBase{code, start}})
 
 		case paraTextCodeReserved12:
 			d.skipBytes(14)
 
 		case paraTextCodeParaBreak:
-			elements = append(elements, ParaTextParaBreak{paraTextBase{code}})
+			elements = append(elements, ParaTextParaBreak{paraTextBase{code, start}})
 
 		case paraTextCodeReserved14:
 			d.skipBytes(14)
 
 		case paraTextCodeHiddenComment:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextHiddenComment{paraTextBase{code}})
+			elements = append(elements, ParaTextHiddenComment{paraTextBase{code, start}})
 
 		case paraTextCodeHeaderFooter:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextHeaderFooter{paraTextBase{code}})
+			elements = append(elements, ParaTextHeaderFooter{paraTextBase{code, start}})
 
 		case paraTextCodeFootnoteEndnote:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextFootnoteEndnote{paraTextBase{code}})
+			elements = append(elements, ParaTextFootnoteEndnote{paraTextBase{code, start}})
 
 		case paraTextCodeAutoNumber:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextAutoNumber{paraTextBase{code}})
+			elements = append(elements, ParaTextAutoNumber{paraTextBase{code, start}})
 
 		case paraTextCodeReserved19, paraTextCodeReserved20:
 			d.skipBytes(14)
 
 		case paraTextCodePageControl:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextPageControl{paraTextBase{code}})
+			elements = append(elements, ParaTextPageControl{paraTextBase{code, start}})
 
 		case paraTextCodeBookmarkIndex:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextBookmarkIndex{paraTextBase{code}})
+			elements = append(elements, ParaTextBookmarkIndex{paraTextBase{code, start}})
 
 		case paraTextCodeAddTextOverlap:
 			d.skipBytes(14)
-			elements = append(elements, ParaTextAddTextOverlap{paraTextBase{code}})
+			elements = append(elements, ParaTextAddTextOverlap{paraTextBase{code, start}})
 
 		case paraTextCodeHyphen:
-			elements = append(elements, ParaTextHyphen{paraTextBase{code}})
+			elements = append(elements, ParaTextHyphen{paraTextBase{code, start}})
 
 		case paraTextCodeReserved25, paraTextCodeReserved26, paraTextCodeReserved27,
 			paraTextCodeReserved28, paraTextCodeReserved29:
 
 		case paraTextCodeBundleSpace:
-			elements = append(elements, ParaTextBundleSpace{paraTextBase{code}})
+			elements = append(elements, ParaTextBundleSpace{paraTextBase{code, start}})
 
 		case paraTextCodeFixedSpace:
-			elements = append(elements, ParaTextFixedSpace{paraTextBase{code}})
+			elements = append(elements, ParaTextFixedSpace{paraTextBase{code, start}})
 		}
 	}
 
 	flushString()
-	return elements
+	return elements, d.r.err
 }
 
 func (d *paraTextDecoder) skipBytes(n int) {
-	io.CopyN(io.Discard, d.data, int64(n))
+	d.r.skip(n)
 }
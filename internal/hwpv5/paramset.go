@@ -0,0 +1,146 @@
+package hwpv5
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Parameter item types used in parameter sets (CtrlData records)
+const (
+	paramTypeNull = 0
+	paramTypeBSTR = 1
+	paramTypeI1   = 2
+	paramTypeI2   = 3
+	paramTypeI4   = 4
+	paramTypeI    = 5
+	paramTypeUI1  = 6
+	paramTypeUI2  = 7
+	paramTypeUI4  = 8
+	paramTypeUI   = 9
+)
+
+// parameterSetString returns the first string item of a parameter set
+// (WORD set ID, INT16 item count, then items of WORD ID, WORD type, value).
+// Parsing stops at nested sets, arrays and binary items, which do not hold
+// the strings we look for.
+func parameterSetString(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	count := int(int16(binary.LittleEndian.Uint16(data[2:])))
+	pos := 4
+	for i := 0; i < count && pos+4 <= len(data); i++ {
+		typ := binary.LittleEndian.Uint16(data[pos+2:])
+		pos += 4
+
+		switch typ {
+		case paramTypeBSTR:
+			s, _ := readLenWString(data, pos)
+			return s
+		case paramTypeNull:
+		case paramTypeI1, paramTypeUI1:
+			pos++
+		case paramTypeI2, paramTypeUI2:
+			pos += 2
+		case paramTypeI4, paramTypeI, paramTypeUI4, paramTypeUI:
+			pos += 4
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// clickHereDirection returns the guide text of a click-here field control
+// (ctrl ID, property, extra property BYTE, then the field command as a
+// WORD-length string). The command reads like
+// "Clickhere:set:66:Direction:wstring:9:여기에 입력 HelpState:wstring:0: ".
+func clickHereDirection(data []byte) string {
+	if len(data) < 9 {
+		return ""
+	}
+	command, _ := readLenWString(data, 9)
+	return strings.TrimSpace(commandProperty(command, "Direction"))
+}
+
+// memoIndex returns the index of the memo of a memo field control, which
+// follows the field command and the instance ID (UINT32). Controls written
+// before memos were numbered end with the instance ID.
+func memoIndex(data []byte) (uint32, bool) {
+	if len(data) < 9 {
+		return 0, false
+	}
+	_, pos := readLenWString(data, 9)
+	pos += 4
+	if pos+4 > len(data) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint32(data[pos:]), true
+}
+
+// commandProperty returns the value of a property in a command string,
+// where properties read "Name:type:length:value" and length counts the
+// characters of the value.
+func commandProperty(command, name string) string {
+	for start := 0; ; {
+		i := strings.Index(command[start:], name+":")
+		if i < 0 {
+			return ""
+		}
+		i += start
+		start = i + len(name)
+		if i > 0 {
+			// Skip matches inside longer names ("SelectedValue" for "Value")
+			if r, _ := utf8.DecodeLastRuneInString(command[:i]); unicode.IsLetter(r) {
+				continue
+			}
+		}
+
+		// type:length:value
+		parts := strings.SplitN(command[start+1:], ":", 3)
+		if len(parts) < 3 {
+			return ""
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return ""
+		}
+		value := []rune(parts[2])
+		return string(value[:min(n, len(value))])
+	}
+}
+
+// hyperlinkURL returns the target of a hyperlink field control, whose
+// command follows the extra property BYTE.
+func hyperlinkURL(data []byte) string {
+	if len(data) < 9 {
+		return ""
+	}
+	command, _ := readLenWString(data, 9)
+	return LinkTarget(command)
+}
+
+// LinkTarget returns the target of a hyperlink field command, which holds
+// the target, with ':' escaped, followed by link options:
+// "https\://www.hancom.com/;1;0;0;".
+func LinkTarget(command string) string {
+	var url strings.Builder
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			url.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ';':
+			return url.String()
+		default:
+			url.WriteRune(r)
+		}
+	}
+	return url.String()
+}
@@ -7,20 +7,28 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/limits"
 	"github.com/richardlehane/mscfb"
 )
 
 // Reader wraps an open HWP document.
 type Reader struct {
-	ra           io.ReaderAt
-	Header       FileHeader
-	sectionCount int
+	ra      io.ReaderAt
+	opts    document.ScanOptions
+	Header  FileHeader
+	DocInfo *DocInfo
+	// sections holds the numbers of the section streams, in order
+	sections []int
 }
 
 // OpenReader opens an HWP 5.0 file and returns a Reader.
-func OpenReader(ra io.ReaderAt) (*Reader, error) {
-	r := &Reader{ra: ra}
+func OpenReader(ra io.ReaderAt, opts document.ScanOptions) (*Reader, error) {
+	r := &Reader{ra: ra, opts: opts}
 
 	headerStream, err := r.openStream("FileHeader")
 	if err != nil {
@@ -42,36 +50,72 @@ func OpenReader(ra io.ReaderAt) (*Reader, error) {
 
 	var currentReader io.Reader = docInfoStream
 	if r.Header.Properties.Compressed() {
-		currentReader = flate.NewReader(docInfoStream)
-		defer currentReader.(io.Closer).Close()
+		fr := flate.NewReader(docInfoStream)
+		defer fr.Close()
+		currentReader = limits.NewReader(fr, r.opts.MaxStreamSize, "DocInfo")
 	}
 
-	scanner := NewRecScanner(currentReader)
-	const HWPTAG_DOCUMENT_PROPERTIES = 0x10
-	for {
-		rec, err := scanner.ScanNext()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, fmt.Errorf("failed to scan DocInfo: %w", err)
-		}
+	r.DocInfo, err = readDocInfo(r.newRecScanner("DocInfo", currentReader))
+	if err != nil {
+		return nil, err
+	}
 
-		if rec.Tag() == HWPTAG_DOCUMENT_PROPERTIES {
-			if docProps, ok := rec.(RecUnknown); ok && len(docProps.Data) >= 2 {
-				r.sectionCount = int(binary.LittleEndian.Uint16(docProps.Data[0:2]))
-			}
-			break
+	if r.DocInfo.TrackChanges && opts.Changes != "" && opts.Changes != document.ChangesFinal {
+		opts.Warn(errors.New("tracked changes in HWP files are not decoded; reading the text as stored"))
+	}
+
+	if opts.StrictReferences {
+		if err := r.DocInfo.CheckMappings(); err != nil {
+			return nil, err
 		}
 	}
 
-	if r.sectionCount == 0 {
-		r.sectionCount = 1
+	if err := r.findSections(); err != nil {
+		return nil, err
 	}
 
 	return r, nil
 }
 
+// findSections finds the section streams in the compound file directory.
+// The section count in DocInfo is often wrong, so it is only used when the
+// directory holds no sections; a mismatch is reported as a warning.
+func (r *Reader) findSections() error {
+	streams, err := ListStreams(r.ra)
+	if err != nil {
+		return err
+	}
+	r.setSections(streams)
+	return nil
+}
+
+// setSections sets the sections from the entries of the compound file.
+func (r *Reader) setSections(streams []Stream) {
+	prefix := r.sectionPrefix()
+	for _, s := range streams {
+		if s.Storage || !strings.HasPrefix(s.Name, prefix) {
+			continue
+		}
+		if n, err := strconv.Atoi(s.Name[len(prefix):]); err == nil && n >= 0 {
+			r.sections = append(r.sections, n)
+		}
+	}
+	slices.Sort(r.sections)
+
+	declared := r.DocInfo.Properties.SectionCount
+	if len(r.sections) == 0 {
+		for i := range max(declared, 1) {
+			r.sections = append(r.sections, i)
+		}
+		return
+	}
+	if declared != len(r.sections) {
+		r.opts.Warn(fmt.Errorf("DocInfo declares %d sections, but the file holds %d", declared, len(r.sections)))
+	} else if r.sections[len(r.sections)-1] != len(r.sections)-1 {
+		r.opts.Warn(fmt.Errorf("section streams are not numbered consecutively: %v", r.sections))
+	}
+}
+
 // openStream opens a named stream from the OLE container.
 func (r *Reader) openStream(name string) (io.Reader, error) {
 	doc, err := mscfb.New(r.ra)
@@ -100,21 +144,57 @@ func (r *Reader) IsDistributionDoc() bool {
 	return r.Header.Properties.Raw&0x04 != 0
 }
 
+// sectionPrefix returns the stream name prefix of the body sections:
+// ViewText for distribution documents, BodyText otherwise.
+func (r *Reader) sectionPrefix() string {
+	if r.IsDistributionDoc() {
+		return "ViewText/Section"
+	}
+	return "BodyText/Section"
+}
+
 // SectionCount returns the number of sections in the document.
 func (r *Reader) SectionCount() int {
-	return r.sectionCount
+	return len(r.sections)
 }
 
-// OpenSection opens a section stream by index.
+// Properties returns the document properties: the section count, the start
+// numbers of pages, notes, pictures, tables and equations, and the caret
+// position.
+func (r *Reader) Properties() DocumentProperties {
+	return r.DocInfo.Properties
+}
+
+// OpenSection opens the section stream at an index in section order.
 // Returns a reader that handles decompression and decryption as needed.
 func (r *Reader) OpenSection(index int) (io.ReadCloser, error) {
-	var streamName string
-	if r.IsDistributionDoc() {
-		streamName = fmt.Sprintf("ViewText/Section%d", index)
-	} else {
-		streamName = fmt.Sprintf("BodyText/Section%d", index)
+	if index < 0 || index >= len(r.sections) {
+		return nil, fmt.Errorf("section %d out of range", index)
+	}
+	return r.openSection(r.SectionName(index))
+}
+
+// newRecScanner returns a scanner for the records of a stream of the
+// document, read with the layouts of its version.
+func (r *Reader) newRecScanner(stream string, rd io.Reader) *RecScanner {
+	s := NewRecScanner(rd)
+	s.stream = stream
+	s.maxSize = r.opts.MaxRecordSize
+	s.version = r.Header.Version
+	return s
+}
+
+// SectionName returns the stream name of the section at an index in
+// section order, e.g. "BodyText/Section0".
+func (r *Reader) SectionName(index int) string {
+	if index < 0 || index >= len(r.sections) {
+		return ""
 	}
+	return fmt.Sprintf("%s%d", r.sectionPrefix(), r.sections[index])
+}
 
+// openSection opens a section stream by name.
+func (r *Reader) openSection(streamName string) (io.ReadCloser, error) {
 	rawStream, err := r.openStream(streamName)
 	if err != nil {
 		return nil, err
@@ -131,8 +211,7 @@ func (r *Reader) OpenSection(index int) (io.ReadCloser, error) {
 		tagID := uint16(tagVal & 0x3FF)
 		size := tagVal >> 20
 
-		const HWPTAG_DISTRIBUTE_DOC_DATA = 0x1C
-		if tagID == HWPTAG_DISTRIBUTE_DOC_DATA && size == 256 {
+		if tagID == recTagDistributeDocData && size == 256 {
 			distData := make([]byte, 256)
 			if _, err := io.ReadFull(currentReader, distData); err != nil {
 				return nil, fmt.Errorf("failed to read distribute doc data: %w", err)
@@ -150,12 +229,16 @@ func (r *Reader) OpenSection(index int) (io.ReadCloser, error) {
 
 			currentReader = &cryptoReader{r: currentReader, block: block}
 		} else {
-			return nil, fmt.Errorf("invalid distribution document stream (tag=0x%x, size=%d)", tagID, size)
+			return nil, fmt.Errorf("invalid distribution document stream (%s, size=%d)", TagName(tagID), size)
 		}
 	}
 
 	if r.Header.Properties.Compressed() {
-		return flate.NewReader(currentReader), nil
+		fr := flate.NewReader(currentReader)
+		return struct {
+			io.Reader
+			io.Closer
+		}{limits.NewReader(fr, r.opts.MaxStreamSize, streamName), fr}, nil
 	}
 
 	return io.NopCloser(currentReader), nil
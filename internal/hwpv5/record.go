@@ -5,6 +5,10 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/hanpama/hwp/internal/limits"
 )
 
 const (
@@ -53,23 +57,79 @@ type Rec interface {
 	Tag() uint16
 	Lvl() uint16
 	Len() uint32
+	// String describes the record header, e.g.
+	// "HWPTAG_PARA_TEXT level=1 size=24".
+	String() string
 }
 
 func (b recHeader) Tag() uint16 { return b.TagID }
 func (b recHeader) Lvl() uint16 { return b.Level }
 func (b recHeader) Len() uint32 { return b.Size }
 
+func (b recHeader) String() string {
+	return fmt.Sprintf("%s level=%d size=%d", TagName(b.TagID), b.Level, b.Size)
+}
+
+// CharShapeRun applies the character shape ShapeID from text position Pos on.
+type CharShapeRun struct {
+	Pos     uint32
+	ShapeID uint32
+}
+
+// TableZone is a range of table cells, from the start to the end column
+// and row inclusive, drawn with the border fill BorderFillID.
+type TableZone struct {
+	StartCol, StartRow uint16
+	EndCol, EndRow     uint16
+	BorderFillID       uint16
+}
+
+// LineSeg is a line of a paragraph as laid out when the document was saved.
+// Lengths are in HWPUNITs.
+type LineSeg struct {
+	// TextStart is the text position the line starts at.
+	TextStart    uint32
+	VerticalPos  int32
+	LineHeight   int32
+	TextHeight   int32
+	Baseline     int32
+	LineSpacing  int32
+	ColumnStart  int32
+	SegmentWidth int32
+	Tag          uint32
+}
+
 // Body record concrete types (payloads are intentionally empty scaffolds).
 type (
-	RecParaHeader struct{ recHeader }
-	RecParaText   struct {
+	RecParaHeader struct {
+		recHeader
+		// TextLength is the number of WCHARs of the paragraph text
+		TextLength uint32
+		// ControlMask has bit n set when the text holds control code n
+		ControlMask uint32
+		ParaShapeID uint16
+		StyleID     uint8
+		// BreakType holds the section, multi-column, page and column break bits
+		BreakType      uint8
+		CharShapeCount uint16
+		RangeTagCount  uint16
+		LineSegCount   uint16
+		InstanceID     uint32
+	}
+	RecParaText struct {
 		recHeader
 		Els []ParaTextElement
 	}
-	RecParaCharShape struct{ recHeader }
-	RecParaLineSeg   struct{ recHeader }
-	RecParaRangeTag  struct{ recHeader }
-	RecCtrlHeader    struct {
+	RecParaCharShape struct {
+		recHeader
+		Runs []CharShapeRun
+	}
+	RecParaLineSeg struct {
+		recHeader
+		Segs []LineSeg
+	}
+	RecParaRangeTag struct{ recHeader }
+	RecCtrlHeader   struct {
 		recHeader
 		CtrlID uint32
 		Data   []byte
@@ -79,20 +139,78 @@ type (
 		IsCell    bool
 		ParaCount int16
 		Property  uint32
-		ColIndex  uint16
-		RowIndex  uint16
-		ColSpan   uint16
-		RowSpan   uint16
-	}
-	RecPageDef        struct{ recHeader }
-	RecFootnoteShape  struct{ recHeader }
-	RecPageBorderFill struct{ recHeader }
+		// The cell properties, set when IsCell is
+		ColIndex uint16
+		RowIndex uint16
+		ColSpan  uint16
+		RowSpan  uint16
+		// Width and Height are the cell size in HWPUNITs
+		Width, Height uint32
+		// The margins between the cell border and its text, in HWPUNITs
+		MarginLeft, MarginRight, MarginTop, MarginBottom uint16
+		// BorderFillID is the 1-based ID of the cell's border fill
+		BorderFillID uint16
+	}
+	RecPageDef struct{ recHeader }
+	// RecFootnoteShape is the footnote or endnote shape of a section
+	// definition, which holds the footnote shape first and the endnote
+	// shape second. Lengths are in HWPUNITs.
+	RecFootnoteShape struct {
+		recHeader
+		// Property holds the number format (bits 0-7), the placement
+		// (bits 8-9), the numbering (bits 10-11) and the superscript flag
+		// (bit 12)
+		Property    uint32
+		UserSymbol  rune
+		Prefix      rune
+		Suffix      rune
+		StartNumber uint16
+		// The separator line above the notes and its spacing
+		SeparatorLength    int32
+		SeparatorAbove     uint16
+		SeparatorBelow     uint16
+		NoteSpacing        uint16
+		SeparatorType      uint8
+		SeparatorThickness uint8
+		SeparatorColor     uint32
+	}
+	// RecPageBorderFill is a page border and background of a section
+	// definition, which holds one each for both, even and odd pages.
+	// Margins are in HWPUNITs.
+	RecPageBorderFill struct {
+		recHeader
+		// Property holds the reference of the margins (bit 0), whether the
+		// header and footer are inside the border (bits 1 and 2) and the
+		// area filled (bits 3-4)
+		Property     uint32
+		MarginLeft   int16
+		MarginRight  int16
+		MarginTop    int16
+		MarginBottom int16
+		// BorderFillID is the 1-based ID of the border fill drawn
+		BorderFillID uint16
+	}
 	RecShapeComponent struct{ recHeader }
-	RecTable          struct {
+	// RecTable is the table of a table control. Lengths are in HWPUNITs.
+	RecTable struct {
 		recHeader
-		Data     []byte
-		RowCount uint16
-		ColCount uint16
+		// Property holds the page break behavior (bits 0-1) and whether the
+		// header row repeats on each page (bit 2)
+		Property     uint32
+		RowCount     uint16
+		ColCount     uint16
+		CellSpacing  uint16
+		MarginLeft   uint16
+		MarginRight  uint16
+		MarginTop    uint16
+		MarginBottom uint16
+		// RowSizes holds the number of cells in each row
+		RowSizes []uint16
+		// BorderFillID is the 1-based ID of the table's border fill
+		BorderFillID uint16
+		// Zones are cell ranges with their own border fill (5.0.1.0 and
+		// later)
+		Zones []TableZone
 	}
 	RecShapeComponentLine      struct{ recHeader }
 	RecShapeComponentRectangle struct{ recHeader }
@@ -100,18 +218,59 @@ type (
 	RecShapeComponentArc       struct{ recHeader }
 	RecShapeComponentPolygon   struct{ recHeader }
 	RecShapeComponentCurve     struct{ recHeader }
-	RecShapeComponentOLE       struct{ recHeader }
-	RecShapeComponentPicture   struct{ recHeader }
+	RecShapeComponentOLE       struct {
+		recHeader
+		BinDataID uint16
+	}
+	// RecShapeComponentPicture is the picture of a drawing object.
+	// Lengths are in HWPUNITs.
+	RecShapeComponentPicture struct {
+		recHeader
+		BinDataID uint16
+		// ImageWidth and ImageHeight are the size of the image rectangle
+		ImageWidth  int32
+		ImageHeight int32
+		// The crop rectangle of the picture data
+		CropLeft   int32
+		CropTop    int32
+		CropRight  int32
+		CropBottom int32
+		Brightness int8
+		Contrast   int8
+		// Effect is 0 for the real picture, 1 for grayscale, 2 for black and
+		// white and 3 for a pattern
+		Effect uint8
+	}
 	RecShapeComponentContainer struct{ recHeader }
-	RecCtrlData                struct{ recHeader }
-	RecEqEdit                  struct{ recHeader }
-	RecShapeComponentTextArt   struct{ recHeader }
-	RecFormObject              struct{ recHeader }
-	RecMemoShape               struct{ recHeader }
-	RecMemoList                struct{ recHeader }
-	RecChartData               struct{ recHeader }
-	RecVideoData               struct{ recHeader }
-	RecShapeComponentUnknown   struct{ recHeader }
+	RecCtrlData                struct {
+		recHeader
+		Data []byte
+	}
+	RecEqEdit struct {
+		recHeader
+		Script string // Hangul equation script
+	}
+	RecShapeComponentTextArt struct{ recHeader }
+	RecFormObject            struct {
+		recHeader
+		TypeID     uint32 // form control type, e.g. 'tbc+' for check buttons
+		Properties string // property set command ("CheckBtnSet:set:...")
+	}
+	RecMemoShape struct{ recHeader }
+	// RecMemoList starts a memo of the section, whose paragraphs follow
+	RecMemoList struct {
+		recHeader
+		// Index is the index of the memo, which its memo field refers to
+		Index uint32
+	}
+	RecChartData struct{ recHeader }
+	RecVideoData struct {
+		recHeader
+		Web       bool   // a web video rather than a local file
+		BinDataID uint16 // local video data
+		EmbedTag  string // embed tag of a web video
+	}
+	RecShapeComponentUnknown struct{ recHeader }
 
 	// RecUnknown keeps the raw payload when no concrete type is defined.
 	RecUnknown struct {
@@ -123,17 +282,42 @@ type (
 // RecScanner consumes a stream of records and yields them sequentially.
 type RecScanner struct {
 	r io.Reader
+	// stream names the stream in errors, e.g. "BodyText/Section0"
+	stream string
+	offset int64
+	// count is the number of records read
+	count int
+	// maxSize caps the size of a record; zero means no limit
+	maxSize int64
+	// version selects the record layouts of the document's format version
+	version Version
 }
 
 func NewRecScanner(r io.Reader) *RecScanner {
-	return &RecScanner{r: r}
+	return &RecScanner{r: r, version: latestVersion}
+}
+
+// Offset returns the stream offset of the next record.
+func (s *RecScanner) Offset() int64 {
+	return s.offset
 }
 
+// ScanNext returns the next record, or io.EOF at the end of the stream.
+// Other errors are *RecordErrors locating the record.
 func (s *RecScanner) ScanNext() (Rec, error) {
+	start := s.offset
+	fail := func(tag uint16, err error) error {
+		return &RecordError{Stream: s.stream, Record: s.count, Tag: tag, Offset: start, Err: err}
+	}
+
 	var headerRaw uint32
 	if err := binary.Read(s.r, binary.LittleEndian, &headerRaw); err != nil {
-		return nil, err
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fail(0, fmt.Errorf("read record header: %w", err))
 	}
+	s.offset += 4
 
 	base := recHeader{
 		TagID: uint16(headerRaw & 0x3ff),
@@ -142,15 +326,52 @@ func (s *RecScanner) ScanNext() (Rec, error) {
 	}
 	if base.Size == 0xfff {
 		if err := binary.Read(s.r, binary.LittleEndian, &base.Size); err != nil {
-			return nil, fmt.Errorf("read extended size: %w", err)
+			return nil, fail(base.TagID, fmt.Errorf("read extended size: %w", err))
 		}
+		s.offset += 4
 	}
 
-	data := make([]byte, base.Size)
-	if _, err := io.ReadFull(s.r, data); err != nil {
-		return nil, fmt.Errorf("read record data: %w", err)
+	if err := limits.Check("record", int64(base.Size), s.maxSize, "bytes"); err != nil {
+		return nil, fail(base.TagID, err)
+	}
+	data, err := readRecordData(s.r, base.Size)
+	if err != nil {
+		return nil, fail(base.TagID, fmt.Errorf("read record data: %w", err))
 	}
+	s.offset += int64(base.Size)
+	s.count++
 
+	rec, err := s.decodeRecord(base, data)
+	if err != nil {
+		return nil, &RecordError{Stream: s.stream, Record: s.count - 1, Tag: base.TagID, Offset: start, Err: err}
+	}
+	return rec, nil
+}
+
+// largeRecord is the record size above which record data is read in
+// pieces, so that a size in a damaged or hostile header is not allocated
+// before the data turns out to be missing.
+const largeRecord = 1 << 20
+
+// readRecordData reads the size bytes of a record's data.
+func readRecordData(r io.Reader, size uint32) ([]byte, error) {
+	if size <= largeRecord {
+		data := make([]byte, size)
+		_, err := io.ReadFull(r, data)
+		return data, err
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(size)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeRecord decodes the payload of a record by its tag.
+func (s *RecScanner) decodeRecord(base recHeader, data []byte) (Rec, error) {
 	switch base.TagID {
 	case recTagParaHeader:
 		return s.decodeParaHeaderRecord(base, data)
@@ -217,21 +438,74 @@ func (s *RecScanner) ScanNext() (Rec, error) {
 	}
 }
 
-func (s *RecScanner) decodeParaHeaderRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecParaHeader{b}, nil
+// decodeParaHeaderRecord decodes a paragraph header: UINT32 text length,
+// whose top bit may be set, and control mask, UINT16 paragraph shape ID,
+// BYTE style ID and break type, UINT16 char shape, range tag and line
+// segment counts and the UINT32 instance ID. The counts and instance ID are
+// left zero in records too old to hold them.
+func (s *RecScanner) decodeParaHeaderRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecParaHeader{recHeader: b}
+	r := &recordReader{data: data}
+	rec.TextLength = r.u32() &^ (1 << 31)
+	rec.ControlMask = r.u32()
+	rec.ParaShapeID = r.u16()
+	rec.StyleID = r.u8()
+	rec.BreakType = r.u8()
+	if r.remaining() >= 6 {
+		rec.CharShapeCount = r.u16()
+		rec.RangeTagCount = r.u16()
+		rec.LineSegCount = r.u16()
+	}
+	if r.remaining() >= 4 {
+		rec.InstanceID = r.u32()
+	}
+	return rec, r.err
 }
 
 func (s *RecScanner) decodeParaTextRecord(b recHeader, data []byte) (Rec, error) {
-	d := &paraTextDecoder{data: bytes.NewReader(data)}
-	return RecParaText{recHeader: b, Els: d.decodeParaTextElements()}, nil
-}
-
-func (s *RecScanner) decodeParaCharShapeRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecParaCharShape{b}, nil
-}
-
-func (s *RecScanner) decodeParaLineSegRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecParaLineSeg{b}, nil
+	d := &paraTextDecoder{r: recordReader{data: data}}
+	els, err := d.decodeParaTextElements()
+	return RecParaText{recHeader: b, Els: els}, err
+}
+
+func (s *RecScanner) decodeParaCharShapeRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecParaCharShape{recHeader: b}
+	r := &recordReader{data: data}
+	for r.remaining() > 0 {
+		run := CharShapeRun{Pos: r.u32(), ShapeID: r.u32()}
+		if r.err != nil {
+			break
+		}
+		rec.Runs = append(rec.Runs, run)
+	}
+	return rec, r.err
+}
+
+// lineSegSize is the size of a line segment in a ParaLineSeg record.
+const lineSegSize = 36
+
+// decodeParaLineSegRecord decodes the line segments of a paragraph.
+func (s *RecScanner) decodeParaLineSegRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecParaLineSeg{recHeader: b}
+	r := &recordReader{data: data}
+	for r.remaining() > 0 {
+		seg := LineSeg{
+			TextStart:    r.u32(),
+			VerticalPos:  r.i32(),
+			LineHeight:   r.i32(),
+			TextHeight:   r.i32(),
+			Baseline:     r.i32(),
+			LineSpacing:  r.i32(),
+			ColumnStart:  r.i32(),
+			SegmentWidth: r.i32(),
+			Tag:          r.u32(),
+		}
+		if r.err != nil {
+			break
+		}
+		rec.Segs = append(rec.Segs, seg)
+	}
+	return rec, r.err
 }
 
 func (s *RecScanner) decodeParaRangeTagRecord(b recHeader, _ []byte) (Rec, error) {
@@ -239,69 +513,209 @@ func (s *RecScanner) decodeParaRangeTagRecord(b recHeader, _ []byte) (Rec, error
 }
 
 func (s *RecScanner) decodeCtrlHeaderRecord(b recHeader, data []byte) (Rec, error) {
-	rec := RecCtrlHeader{recHeader: b, Data: data}
-	if len(data) >= 4 {
-		rec.CtrlID = binary.LittleEndian.Uint32(data[:4])
-	}
-	return rec, nil
+	r := &recordReader{data: data}
+	rec := RecCtrlHeader{recHeader: b, CtrlID: r.u32(), Data: data}
+	return rec, r.err
 }
 
 func (s *RecScanner) decodeListHeaderRecord(b recHeader, data []byte) (Rec, error) {
 	rec := RecListHeader{recHeader: b}
-	if len(data) >= 6 {
-		rec.ParaCount = int16(binary.LittleEndian.Uint16(data[0:]))
-		rec.Property = binary.LittleEndian.Uint32(data[2:])
-	}
-	// Cell list = LIST_HEADER (6 bytes) + Cell properties (26 bytes) = 32 bytes total
-	// But in practice we need 33 bytes based on old hwp3 code
-	if len(data) >= 33 {
+	r := &recordReader{data: data}
+	// The paragraph count is documented as an INT16 but stored in 4 bytes
+	rec.ParaCount = int16(r.u32())
+	rec.Property = r.u32()
+	// A cell list header goes on with the 26 bytes of the cell properties;
+	// the list headers of captions and other lists are shorter. Before
+	// 5.0.1.0 nothing follows the cell properties, and longer list headers
+	// are not cells
+	if r.remaining() == cellPropertiesSize || s.version.AtLeast(5, 0, 1, 0) && r.remaining() > cellPropertiesSize {
 		rec.IsCell = true
-
-		// Cell data format (from old hwp3 implementation):
-		// Uses byte offsets at positions 7+1, 7+3, 7+5, 7+7
-		cellData := data[7:33]
-		if len(cellData) >= 8 {
-			rec.ColIndex = uint16(cellData[1])
-			rec.RowIndex = uint16(cellData[3])
-			rec.ColSpan = uint16(cellData[5])
-			rec.RowSpan = uint16(cellData[7])
-
-			if rec.ColSpan == 0 {
-				rec.ColSpan = 1
-			}
-			if rec.RowSpan == 0 {
-				rec.RowSpan = 1
-			}
-		}
+		rec.ColIndex = r.u16()
+		rec.RowIndex = r.u16()
+		rec.ColSpan = max(r.u16(), 1)
+		rec.RowSpan = max(r.u16(), 1)
+		rec.Width, rec.Height = r.u32(), r.u32()
+		rec.MarginLeft, rec.MarginRight = r.u16(), r.u16()
+		rec.MarginTop, rec.MarginBottom = r.u16(), r.u16()
+		rec.BorderFillID = r.u16()
 	}
-	return rec, nil
+	return rec, r.err
 }
 
+// cellPropertiesSize is the size of the cell properties following the list
+// header of a table cell: UINT16 column and row addresses and spans, HWPUNIT
+// width and height, HWPUNIT16 left, right, top and bottom margins and the
+// UINT16 border fill ID.
+const cellPropertiesSize = 26
+
 func (s *RecScanner) decodePageDefRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecPageDef{b}, nil
 }
 
-func (s *RecScanner) decodeFootnoteShapeRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecFootnoteShape{b}, nil
+// decodeFootnoteShapeRecord decodes a footnote shape. The format documents
+// a HWPUNIT16 separator length, but documents store a HWPUNIT, which makes
+// the record 28 bytes long instead of 26.
+func (s *RecScanner) decodeFootnoteShapeRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecFootnoteShape{recHeader: b}
+	r := &recordReader{data: data}
+	rec.Property = r.u32()
+	rec.UserSymbol = rune(r.u16())
+	rec.Prefix = rune(r.u16())
+	rec.Suffix = rune(r.u16())
+	rec.StartNumber = r.u16()
+	if len(data) >= 28 {
+		rec.SeparatorLength = r.i32()
+	} else {
+		rec.SeparatorLength = int32(r.i16())
+	}
+	rec.SeparatorAbove = r.u16()
+	rec.SeparatorBelow = r.u16()
+	rec.NoteSpacing = r.u16()
+	rec.SeparatorType = r.u8()
+	rec.SeparatorThickness = r.u8()
+	rec.SeparatorColor = r.u32()
+	return rec, r.err
+}
+
+// Footnote placements (RecFootnoteShape property bits 8-9)
+const (
+	// Footnotes
+	NotePlaceColumnBottom = 0 // at the bottom of each column
+	NotePlaceSpread       = 1 // spread across the columns
+	NotePlaceRightColumn  = 2 // at the bottom of the rightmost column
+	// Endnotes
+	NotePlaceDocumentEnd = 0 // at the end of the document
+	NotePlaceSectionEnd  = 1 // at the end of the section
+)
+
+// Footnote numberings (RecFootnoteShape property bits 10-11)
+const (
+	NoteNumberingContinuous = 0 // numbers continue through the document
+	NoteNumberingPerSection = 1 // numbers restart in each section
+	NoteNumberingPerPage    = 2 // numbers restart on each page
+)
+
+// NumberFormat returns the number format of the notes, one of the Number
+// constants.
+func (r RecFootnoteShape) NumberFormat() uint8 { return uint8(r.Property) }
+
+// Placement returns where the notes are placed, one of the NotePlace
+// constants.
+func (r RecFootnoteShape) Placement() int { return int(r.Property >> 8 & 0x3) }
+
+// Numbering returns how the notes are numbered, one of the NoteNumbering
+// constants.
+func (r RecFootnoteShape) Numbering() int { return int(r.Property >> 10 & 0x3) }
+
+// Superscript reports whether note numbers are set in superscript in the
+// body text.
+func (r RecFootnoteShape) Superscript() bool { return r.Property&(1<<12) != 0 }
+
+// Number returns the label of the nth note: n in the number format, with
+// the prefix and suffix decoration, such as "1)" or "①".
+func (r RecFootnoteShape) Number(n int) string {
+	label := FormatNumber(r.NumberFormat(), n, r.UserSymbol)
+	if r.Prefix != 0 {
+		label = string(r.Prefix) + label
+	}
+	if r.Suffix != 0 {
+		label += string(r.Suffix)
+	}
+	return label
 }
 
-func (s *RecScanner) decodePageBorderFillRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecPageBorderFill{b}, nil
+func (s *RecScanner) decodePageBorderFillRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecPageBorderFill{recHeader: b}
+	r := &recordReader{data: data}
+	rec.Property = r.u32()
+	rec.MarginLeft, rec.MarginRight = r.i16(), r.i16()
+	rec.MarginTop, rec.MarginBottom = r.i16(), r.i16()
+	rec.BorderFillID = r.u16()
+	return rec, r.err
 }
 
+// Page border fill areas (RecPageBorderFill property bits 3-4)
+const (
+	PageFillPaper  = 0 // the whole paper
+	PageFillPage   = 1 // the page within its margins
+	PageFillBorder = 2 // the area within the border
+)
+
+// FromPaperEdge reports whether the margins are measured from the edge of
+// the paper rather than from the body text.
+func (r RecPageBorderFill) FromPaperEdge() bool { return r.Property&1 != 0 }
+
+// IncludesHeader reports whether the border encloses the header.
+func (r RecPageBorderFill) IncludesHeader() bool { return r.Property&(1<<1) != 0 }
+
+// IncludesFooter reports whether the border encloses the footer.
+func (r RecPageBorderFill) IncludesFooter() bool { return r.Property&(1<<2) != 0 }
+
+// FillArea returns the area the background fills, one of the PageFill
+// constants.
+func (r RecPageBorderFill) FillArea() int { return int(r.Property >> 3 & 0x3) }
+
 func (s *RecScanner) decodeShapeComponentRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecShapeComponent{b}, nil
 }
 
 func (s *RecScanner) decodeTableRecord(b recHeader, data []byte) (Rec, error) {
-	rec := RecTable{recHeader: b, Data: data}
-	if len(data) >= 8 {
-		rec.RowCount = binary.LittleEndian.Uint16(data[4:])
-		rec.ColCount = binary.LittleEndian.Uint16(data[6:])
+	rec := RecTable{recHeader: b}
+	r := &recordReader{data: data}
+	rec.Property = r.u32()
+	rec.RowCount, rec.ColCount = r.u16(), r.u16()
+	rec.CellSpacing = r.u16()
+	rec.MarginLeft, rec.MarginRight = r.u16(), r.u16()
+	rec.MarginTop, rec.MarginBottom = r.u16(), r.u16()
+	for i := 0; i < int(rec.RowCount) && r.err == nil; i++ {
+		rec.RowSizes = append(rec.RowSizes, r.u16())
 	}
-	return rec, nil
+	rec.BorderFillID = r.u16()
+	// Zones were added in 5.0.1.0
+	if r.err == nil && s.version.AtLeast(5, 0, 1, 0) && r.remaining() >= 2 {
+		n := int(r.u16())
+		for i := 0; i < n && r.err == nil; i++ {
+			rec.Zones = append(rec.Zones, TableZone{
+				StartCol: r.u16(), StartRow: r.u16(),
+				EndCol: r.u16(), EndRow: r.u16(),
+				BorderFillID: r.u16(),
+			})
+		}
+	}
+	return rec, r.err
 }
 
+// PageBreak reports whether the paragraph starts a new page (BreakType bit
+// 2), as after a page break (쪽 나누기).
+func (r RecParaHeader) PageBreak() bool { return r.BreakType&(1<<2) != 0 }
+
+// Vertical alignments of the text of a list (RecListHeader property bits
+// 5-6)
+const (
+	ListAlignTop    = 0
+	ListAlignCenter = 1
+	ListAlignBottom = 2
+)
+
+// VertAlign returns the vertical alignment of the list's text, one of the
+// ListAlign constants.
+func (r RecListHeader) VertAlign() int { return int(r.Property >> 5 & 3) }
+
+// Table page breaks (RecTable property bits 0-1)
+const (
+	TableBreakNone = 0 // the table is not split
+	TableBreakCell = 1 // the table is split between cells
+	TableBreakKeep = 2 // the table is kept on one page
+)
+
+// PageBreak returns how the table is split across pages, one of the
+// TableBreak constants.
+func (r RecTable) PageBreak() int { return int(r.Property & 0x3) }
+
+// RepeatHeader reports whether the header row of the table repeats on each
+// page it spans.
+func (r RecTable) RepeatHeader() bool { return r.Property&(1<<2) != 0 }
+
 func (s *RecScanner) decodeShapeComponentLineRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecShapeComponentLine{b}, nil
 }
@@ -326,50 +740,110 @@ func (s *RecScanner) decodeShapeComponentCurveRecord(b recHeader, _ []byte) (Rec
 	return RecShapeComponentCurve{b}, nil
 }
 
-func (s *RecScanner) decodeShapeComponentOLERecord(b recHeader, _ []byte) (Rec, error) {
-	return RecShapeComponentOLE{b}, nil
-}
-
-func (s *RecScanner) decodeShapeComponentPictureRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecShapeComponentPicture{b}, nil
+func (s *RecScanner) decodeShapeComponentOLERecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecShapeComponentOLE{recHeader: b}
+	r := &recordReader{data: data}
+	// Attribute (4), extent width (4) and height (4) precede the BinData ID
+	r.skip(12)
+	rec.BinDataID = r.u16()
+	return rec, r.err
+}
+
+func (s *RecScanner) decodeShapeComponentPictureRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecShapeComponentPicture{recHeader: b}
+	r := &recordReader{data: data}
+	// Border (12), image corners (32), crop (16) and margins (8) precede the
+	// picture info: brightness, contrast, effect and the BinData ID
+	r.skip(12)
+	// The corners run clockwise from the top left
+	var corners [8]int32
+	for i := range corners {
+		corners[i] = r.i32()
+	}
+	rec.ImageWidth = corners[4] - corners[0]
+	rec.ImageHeight = corners[5] - corners[1]
+	rec.CropLeft, rec.CropTop, rec.CropRight, rec.CropBottom = r.i32(), r.i32(), r.i32(), r.i32()
+	r.skip(8)
+	rec.Brightness, rec.Contrast, rec.Effect = int8(r.u8()), int8(r.u8()), r.u8()
+	rec.BinDataID = r.u16()
+	return rec, r.err
 }
 
 func (s *RecScanner) decodeShapeComponentContainerRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecShapeComponentContainer{b}, nil
 }
 
-func (s *RecScanner) decodeCtrlDataRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecCtrlData{b}, nil
+func (s *RecScanner) decodeCtrlDataRecord(b recHeader, data []byte) (Rec, error) {
+	return RecCtrlData{recHeader: b, Data: data}, nil
 }
 
-func (s *RecScanner) decodeEqEditRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecEqEdit{b}, nil
+func (s *RecScanner) decodeEqEditRecord(b recHeader, data []byte) (Rec, error) {
+	// Attribute (4), then the script as a WORD-length string
+	script, _ := readLenWString(data, 4)
+	return RecEqEdit{recHeader: b, Script: script}, nil
 }
 
 func (s *RecScanner) decodeShapeComponentTextArtRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecShapeComponentTextArt{b}, nil
 }
 
-func (s *RecScanner) decodeFormObjectRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecFormObject{b}, nil
+func (s *RecScanner) decodeFormObjectRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecFormObject{recHeader: b}
+	r := &recordReader{data: data}
+	if rec.TypeID = r.u32(); r.err != nil {
+		return rec, r.err
+	}
+
+	// The property command follows as a WCHAR string after its length
+	// fields; it starts with the name of its set
+	units := make([]uint16, (len(data)-4)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[4+i*2:])
+	}
+	text := string(utf16.Decode(units))
+	if i := strings.Index(text, ":set:"); i >= 0 {
+		start := i
+		for start > 0 && isASCIILetter(text[start-1]) {
+			start--
+		}
+		rec.Properties = text[start:]
+	}
+	return rec, nil
 }
 
 func (s *RecScanner) decodeMemoShapeRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecMemoShape{b}, nil
 }
 
-func (s *RecScanner) decodeMemoListRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecMemoList{b}, nil
+func (s *RecScanner) decodeMemoListRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecMemoList{recHeader: b}
+	r := &recordReader{data: data}
+	rec.Index = r.u32()
+	return rec, r.err
 }
 
 func (s *RecScanner) decodeChartDataRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecChartData{b}, nil
 }
 
-func (s *RecScanner) decodeVideoDataRecord(b recHeader, _ []byte) (Rec, error) {
-	return RecVideoData{b}, nil
+func (s *RecScanner) decodeVideoDataRecord(b recHeader, data []byte) (Rec, error) {
+	rec := RecVideoData{recHeader: b}
+	r := &recordReader{data: data}
+	// Video type (0 local, 1 web), then the video's BinData ID or the web
+	// tag as a WORD-length string, then the thumbnail's BinData ID
+	rec.Web = r.u32() == 1
+	if rec.Web {
+		rec.EmbedTag, _ = readLenWString(data, 4)
+	} else {
+		rec.BinDataID = r.u16()
+	}
+	return rec, r.err
 }
 
 func (s *RecScanner) decodeShapeComponentUnknownRecord(b recHeader, _ []byte) (Rec, error) {
 	return RecShapeComponentUnknown{b}, nil
 }
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
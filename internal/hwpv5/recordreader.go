@@ -0,0 +1,93 @@
+package hwpv5
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTruncatedRecord is returned, wrapped in a *RecordError, for a record
+// too short to hold the fields its type requires. The record is read in
+// full, so scanning can go on with the next one.
+var ErrTruncatedRecord = errors.New("truncated record")
+
+// RecordError locates a record that could not be read or decoded, e.g.
+// "BodyText/Section1 offset 0x4a3c record 12 tag 0x43 (HWPTAG_PARA_TEXT)".
+type RecordError struct {
+	// Stream names the stream the record is in.
+	Stream string
+	// Record is the index of the record in the stream, and Offset the
+	// stream offset of its header.
+	Record int
+	Offset int64
+	// Tag is 0 when the record header itself could not be read.
+	Tag uint16
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	var b strings.Builder
+	if e.Stream != "" {
+		b.WriteString(e.Stream + " ")
+	}
+	fmt.Fprintf(&b, "offset %#x record %d", e.Offset, e.Record)
+	if e.Tag != 0 {
+		fmt.Fprintf(&b, " tag %#x (%s)", e.Tag, TagName(e.Tag))
+	}
+	return b.String() + ": " + e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error { return e.Err }
+
+// recordReader reads little-endian fields from a record payload. Reading
+// past the end sets a sticky ErrTruncatedRecord error, after which all reads
+// return zero; decoders check err once they are done.
+type recordReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+// remaining returns the number of unread bytes.
+func (r *recordReader) remaining() int { return len(r.data) - r.pos }
+
+// next returns the next n bytes, or nil if fewer are left.
+func (r *recordReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > r.remaining() {
+		r.err = fmt.Errorf("%w: %d bytes needed at byte %d, %d left", ErrTruncatedRecord, n, r.pos, r.remaining())
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *recordReader) skip(n int) { r.next(n) }
+
+func (r *recordReader) u8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *recordReader) u16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *recordReader) u32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *recordReader) i16() int16 { return int16(r.u16()) }
+func (r *recordReader) i32() int32 { return int32(r.u32()) }
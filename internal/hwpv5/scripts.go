@@ -0,0 +1,126 @@
+package hwpv5
+
+import (
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// Scripts holds the document scripts (macros) stored in the "Scripts"
+// storage: the JScript code of "Scripts/DefaultJScript" and the version in
+// "Scripts/JScriptVersion".
+type Scripts struct {
+	Version string
+	// Header declares the document objects scripts work with.
+	Header string
+	// Source holds the script functions, such as the OnDocument_Open event
+	// handler run when the document is opened.
+	Source string
+	// PreSource and PostSource run before and after Source.
+	PreSource  string
+	PostSource string
+}
+
+var (
+	scriptComment       = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+	scriptEmptyFunction = regexp.MustCompile(`function\s+\w+\s*\([^)]*\)\s*\{\s*\}`)
+)
+
+// HasMacros reports whether the scripts hold code that would run. Word
+// processors save an empty OnDocument_New and OnDocument_Open handler with
+// every document; functions with empty bodies and comments do not count.
+// The check is lexical, so a comment marker inside a string literal can
+// hide code.
+func (s Scripts) HasMacros() bool {
+	for _, code := range []string{s.PreSource, s.Source, s.PostSource} {
+		code = scriptComment.ReplaceAllString(code, "")
+		code = scriptEmptyFunction.ReplaceAllString(code, "")
+		if strings.TrimSpace(code) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Code returns the script code in the order it runs: the header, the
+// pre-source, the source and the post-source, separated by blank lines.
+func (s Scripts) Code() string {
+	var parts []string
+	for _, code := range []string{s.Header, s.PreSource, s.Source, s.PostSource} {
+		if code = strings.TrimSpace(code); code != "" {
+			parts = append(parts, code)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Scripts reads the document scripts. Documents without scripts yield an
+// empty Scripts.
+func (r *Reader) Scripts() (Scripts, error) {
+	data, err := r.readScriptStream("Scripts/DefaultJScript")
+	if err != nil {
+		return Scripts{}, nil
+	}
+	scripts, err := decodeScripts(data)
+	if err != nil {
+		return scripts, fmt.Errorf("failed to read scripts: %w", err)
+	}
+	if data, err := r.readScriptStream("Scripts/JScriptVersion"); err == nil && len(data) >= 8 {
+		scripts.Version = fmt.Sprintf("%d.%d", binary.LittleEndian.Uint32(data), binary.LittleEndian.Uint32(data[4:]))
+	}
+	return scripts, nil
+}
+
+// decodeScripts decodes the DefaultJScript stream: four DWORD-length WCHAR
+// strings, the header, source, pre-source and post-source, followed by an
+// end flag.
+func decodeScripts(data []byte) (Scripts, error) {
+	var scripts Scripts
+	var err error
+	pos := 0
+	for _, s := range []*string{&scripts.Header, &scripts.Source, &scripts.PreSource, &scripts.PostSource} {
+		if *s, pos, err = readDWordWString(data, pos); err != nil {
+			return scripts, err
+		}
+	}
+	return scripts, nil
+}
+
+// readScriptStream reads a stream of the Scripts storage, which is
+// compressed like the DocInfo stream.
+func (r *Reader) readScriptStream(name string) ([]byte, error) {
+	stream, err := r.openStream(name)
+	if err != nil {
+		return nil, err
+	}
+	if r.Header.Properties.Compressed() {
+		fr := flate.NewReader(stream)
+		defer fr.Close()
+		stream = limits.NewReader(fr, r.opts.MaxStreamSize, name)
+	}
+	return io.ReadAll(stream)
+}
+
+// readDWordWString reads a DWORD length followed by that many WCHARs,
+// returning the string and the position after it.
+func readDWordWString(data []byte, pos int) (string, int, error) {
+	if pos+4 > len(data) {
+		return "", pos, io.ErrUnexpectedEOF
+	}
+	n := int(binary.LittleEndian.Uint32(data[pos:]))
+	pos += 4
+	if n > (len(data)-pos)/2 {
+		return "", pos, io.ErrUnexpectedEOF
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[pos+i*2:])
+	}
+	return string(utf16.Decode(units)), pos + n*2, nil
+}
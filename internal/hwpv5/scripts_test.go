@@ -0,0 +1,53 @@
+package hwpv5
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// scriptsData returns a DefaultJScript stream holding the given header,
+// source, pre-source and post-source.
+func scriptsData(parts ...string) []byte {
+	var data []byte
+	for _, part := range parts {
+		text := utf16Bytes(part)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(text)/2))
+		data = append(data, text...)
+	}
+	return binary.LittleEndian.AppendUint32(data, 0xffffffff)
+}
+
+func TestScripts(t *testing.T) {
+	const header = "var Documents = XHwpDocuments;\nvar Document = Documents.Active_XHwpDocument;\n"
+	const blank = "function OnDocument_New()\n{\n\t//todo : \n}\n\nfunction OnDocument_Open()\n{\n\t/* todo */\n}\n"
+	const macro = "function OnDocument_Open()\n{\n\tShell(\"cmd\");\n}\n"
+
+	for _, tc := range []struct {
+		name   string
+		data   []byte
+		macros bool
+	}{
+		{"blank", scriptsData(header, blank, "", ""), false},
+		{"source", scriptsData(header, macro, "", ""), true},
+		{"post-source", scriptsData(header, blank, "", "Run();"), true},
+	} {
+		scripts, err := decodeScripts(tc.data)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if scripts.Header != header {
+			t.Errorf("%s: header = %q", tc.name, scripts.Header)
+		}
+		if got := scripts.HasMacros(); got != tc.macros {
+			t.Errorf("%s: HasMacros() = %v, want %v", tc.name, got, tc.macros)
+		}
+	}
+
+	scripts, _ := decodeScripts(scriptsData(header, macro, "", ""))
+	if want := header[:len(header)-1] + "\n\n" + macro[:len(macro)-1]; scripts.Code() != want {
+		t.Errorf("Code() = %q, want %q", scripts.Code(), want)
+	}
+	if _, err := decodeScripts(scriptsData(header)[:20]); err == nil {
+		t.Error("truncated scripts decoded without error")
+	}
+}
@@ -0,0 +1,111 @@
+package hwpv5
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/asn1"
+	"io"
+	"time"
+)
+
+// Signer describes the certificate of a document signer.
+type Signer struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+}
+
+// Signature describes the digital signature of a document, stored in the
+// "DocOptions/DigitalSignature" and "DocOptions/PublicKeyInfo" streams.
+type Signature struct {
+	// Signers lists the certificates found in the signature streams. It
+	// is empty when their data is in a form that is not recognized.
+	Signers []Signer
+}
+
+// pkcs7SignedData is the content type OID of PKCS #7 signed data.
+var pkcs7SignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type pkcs7SignedDataContent struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
+}
+
+// Signature reports the document's digital signature, or nil if it is not
+// signed. The signature is not verified.
+func (r *Reader) Signature() (*Signature, error) {
+	var streams [][]byte
+	for _, name := range []string{"DocOptions/DigitalSignature", "DocOptions/PublicKeyInfo"} {
+		stream, err := r.openStream(name)
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(stream)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, data)
+	}
+	if len(streams) == 0 && !r.Header.Properties.Signed() {
+		return nil, nil
+	}
+
+	sig := &Signature{}
+	seen := make(map[string]bool)
+	for _, data := range streams {
+		for _, cert := range certificates(data) {
+			if seen[string(cert.Raw)] {
+				continue
+			}
+			seen[string(cert.Raw)] = true
+			sig.Signers = append(sig.Signers, Signer{
+				Subject:      cert.Subject.String(),
+				Issuer:       cert.Issuer.String(),
+				SerialNumber: cert.SerialNumber.String(),
+				NotBefore:    cert.NotBefore,
+				NotAfter:     cert.NotAfter,
+			})
+		}
+	}
+	return sig, nil
+}
+
+// certificates returns the certificates of a signature stream: a PKCS #7
+// signed data structure or DER certificates, either of them possibly
+// compressed.
+func certificates(data []byte) []*x509.Certificate {
+	if certs := derCertificates(data); len(certs) > 0 {
+		return certs
+	}
+	inflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil
+	}
+	return derCertificates(inflated)
+}
+
+func derCertificates(data []byte) []*x509.Certificate {
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(data, &info); err == nil && info.ContentType.Equal(pkcs7SignedData) {
+		var signed pkcs7SignedDataContent
+		if _, err := asn1.Unmarshal(info.Content.Bytes, &signed); err != nil {
+			return nil
+		}
+		certs, _ := x509.ParseCertificates(signed.Certificates.Bytes)
+		return certs
+	}
+	certs, _ := x509.ParseCertificates(data)
+	return certs
+}
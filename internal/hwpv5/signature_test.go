@@ -0,0 +1,74 @@
+package hwpv5
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSignatureCertificates(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "행정안전부"},
+		NotBefore:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := asn1.Marshal(pkcs7SignedDataContent{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      asn1.RawValue{FullBytes: mustMarshal(t, struct{ Type asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: cert},
+		SignerInfos:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs7 := mustMarshal(t, pkcs7ContentInfo{
+		ContentType: pkcs7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signed},
+	})
+	var compressed bytes.Buffer
+	fw, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	fw.Write(cert)
+	fw.Close()
+
+	for name, data := range map[string][]byte{
+		"pkcs7":      pkcs7,
+		"der":        cert,
+		"compressed": compressed.Bytes(),
+	} {
+		certs := certificates(data)
+		if len(certs) != 1 || certs[0].Subject.CommonName != "행정안전부" || certs[0].SerialNumber.Int64() != 42 {
+			t.Errorf("%s: certificates = %v", name, certs)
+		}
+	}
+	if certs := certificates([]byte("not a signature")); certs != nil {
+		t.Errorf("certificates of junk = %v, want none", certs)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,140 @@
+package hwpv5
+
+import (
+	"compress/flate"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/limits"
+	"github.com/richardlehane/mscfb"
+)
+
+// StreamStat reports the stored and decoded size of an OLE stream.
+type StreamStat struct {
+	Name string
+	// Size is the number of bytes stored in the compound file.
+	Size int64
+	// DecodedSize is the number of bytes after decryption and decompression,
+	// or -1 if the stream could not be decoded.
+	DecodedSize int64
+}
+
+// StreamStats reports sizes of the DocInfo, section, BinData and Scripts
+// streams.
+func (r *Reader) StreamStats() ([]StreamStat, error) {
+	doc, err := mscfb.New(r.ra)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []StreamStat
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		name := strings.Join(append(append([]string{}, entry.Path...), entry.Name), "/")
+		stat := StreamStat{Name: name, Size: entry.Size}
+
+		switch {
+		case name == "DocInfo", strings.HasPrefix(name, "Scripts/"):
+			stat.DecodedSize, _ = r.decodedSize(entry, name, r.Header.Properties.Compressed())
+		case strings.HasPrefix(name, r.sectionPrefix()):
+			stat.DecodedSize = r.sectionDecodedSize(name)
+		case strings.HasPrefix(name, "BodyText/Section"), strings.HasPrefix(name, "ViewText/Section"):
+			// Placeholder body of a distribution document (or vice versa)
+			stat.DecodedSize, _ = r.decodedSize(entry, name, r.Header.Properties.Compressed())
+		case strings.HasPrefix(name, "BinData/"):
+			// Storage flags live in the DocInfo BinData record; a stream that
+			// does not inflate is stored uncompressed
+			size, err := r.decodedSize(entry, name, true)
+			if err != nil && !errors.Is(err, limits.ErrExceeded) {
+				size = stat.Size
+			}
+			stat.DecodedSize = size
+		default:
+			continue
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// decodedSize counts the bytes of a stream after optional decompression,
+// which stops past the maximum stream size. It returns -1 with the error
+// if the stream cannot be read or is too large.
+func (r *Reader) decodedSize(stream io.Reader, name string, compressed bool) (int64, error) {
+	if compressed {
+		fr := flate.NewReader(stream)
+		defer fr.Close()
+		stream = limits.NewReader(fr, r.opts.MaxStreamSize, name)
+	}
+	n, err := io.Copy(io.Discard, stream)
+	if err != nil {
+		return -1, err
+	}
+	return n, nil
+}
+
+// sectionDecodedSize counts the bytes of a section after decryption and decompression.
+func (r *Reader) sectionDecodedSize(name string) int64 {
+	section, err := r.openSection(name)
+	if err != nil {
+		return -1
+	}
+	defer section.Close()
+	n, err := io.Copy(io.Discard, section)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// Stream is an entry of the OLE compound file: a stream, or a storage
+// holding other entries.
+type Stream struct {
+	// Name is the path of the entry, such as "DocOptions/_LinkDoc".
+	Name string
+	// Size is the number of bytes stored; zero for storages.
+	Size    int64
+	Storage bool
+}
+
+// Streams lists every storage and stream of the compound file, in
+// directory order.
+func (r *Reader) Streams() ([]Stream, error) {
+	return ListStreams(r.ra)
+}
+
+// OpenRawStream opens a stream by its path as stored, without decryption
+// or decompression.
+func (r *Reader) OpenRawStream(name string) (io.Reader, error) {
+	return r.openStream(name)
+}
+
+// ListStreams lists every storage and stream of a compound file, in
+// directory order. Unlike OpenReader it does not parse the document, so it
+// works on files the parser rejects.
+func ListStreams(ra io.ReaderAt) ([]Stream, error) {
+	doc, err := mscfb.New(ra)
+	if err != nil {
+		return nil, err
+	}
+	var streams []Stream
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		name := strings.Join(append(append([]string{}, entry.Path...), entry.Name), "/")
+		if entry.FileInfo().IsDir() {
+			streams = append(streams, Stream{Name: name, Storage: true})
+			continue
+		}
+		streams = append(streams, Stream{Name: name, Size: entry.Size})
+	}
+	return streams, nil
+}
+
+// OpenRawStream opens a stream of a compound file by its path as stored,
+// without parsing the document.
+func OpenRawStream(ra io.ReaderAt, name string) (io.Reader, error) {
+	return (&Reader{ra: ra}).openStream(name)
+}
@@ -0,0 +1,46 @@
+package hwpv5
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestStreamStatsLimit(t *testing.T) {
+	// A BinData stream inflating far past the stream size limit
+	bomb := deflateData(make([]byte, 1<<20))
+	data := hwpFileData(t, true, (&recordStream{}).para(0, "본문").buf.Bytes(),
+		testStream{"BinData/BIN0001.png", bomb},
+		testStream{"BinData/BIN0002.png", []byte("stored")},
+	)
+	opts := document.DefaultScanOptions()
+	opts.MaxStreamSize = 64 << 10
+	r, err := OpenReader(bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := r.StreamStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{
+		"DocInfo":             4 + 2,
+		"BinData/BIN0001.png": -1,
+		"BinData/BIN0002.png": 6,
+	}
+	found := 0
+	for _, stat := range stats {
+		size, ok := want[stat.Name]
+		if !ok {
+			continue
+		}
+		found++
+		if stat.DecodedSize != size {
+			t.Errorf("%s decoded size = %d, want %d", stat.Name, stat.DecodedSize, size)
+		}
+	}
+	if found != len(want) {
+		t.Errorf("stats = %+v, want %d of the streams", stats, len(want))
+	}
+}
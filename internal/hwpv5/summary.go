@@ -0,0 +1,110 @@
+package hwpv5
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"unicode/utf16"
+)
+
+// Summary holds the document properties stored in the
+// "\x05HwpSummaryInformation" property set stream.
+type Summary struct {
+	Title      string
+	Subject    string
+	Author     string
+	Keywords   string
+	Comments   string
+	LastAuthor string
+}
+
+// Property identifiers used by the HWP summary information property set.
+const (
+	pidTitle      = 0x02
+	pidSubject    = 0x03
+	pidAuthor     = 0x04
+	pidKeywords   = 0x05
+	pidComments   = 0x06
+	pidLastAuthor = 0x08
+)
+
+// Property value types
+const (
+	vtLPWSTR = 0x1F
+)
+
+var errPropertySet = errors.New("malformed property set stream")
+
+// Summary reads the summary information stream. Documents without the stream
+// yield an empty Summary.
+func (r *Reader) Summary() (Summary, error) {
+	stream, err := r.openStream("HwpSummaryInformation")
+	if err != nil {
+		return Summary{}, nil
+	}
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	props, err := parsePropertySet(data)
+	if err != nil {
+		return Summary{}, err
+	}
+	return Summary{
+		Title:      props[pidTitle],
+		Subject:    props[pidSubject],
+		Author:     props[pidAuthor],
+		Keywords:   props[pidKeywords],
+		Comments:   props[pidComments],
+		LastAuthor: props[pidLastAuthor],
+	}, nil
+}
+
+// parsePropertySet extracts the string properties of the first property set
+// in an OLE property set stream (MS-OLEPS), keyed by property identifier.
+func parsePropertySet(data []byte) (map[uint32]string, error) {
+	// Header: byte order, version, system id, CLSID, set count, then FMTID/offset pairs
+	if len(data) < 48 || binary.LittleEndian.Uint16(data[0:]) != 0xFFFE {
+		return nil, errPropertySet
+	}
+	setOffset := int(binary.LittleEndian.Uint32(data[44:]))
+	if setOffset < 0 || setOffset+8 > len(data) {
+		return nil, errPropertySet
+	}
+	set := data[setOffset:]
+
+	count := int(binary.LittleEndian.Uint32(set[4:]))
+	if count < 0 || 8+count*8 > len(set) {
+		return nil, errPropertySet
+	}
+
+	props := make(map[uint32]string)
+	for i := 0; i < count; i++ {
+		entry := set[8+i*8:]
+		id := binary.LittleEndian.Uint32(entry[0:])
+		offset := int(binary.LittleEndian.Uint32(entry[4:]))
+		if offset < 0 || offset+8 > len(set) {
+			continue
+		}
+
+		value := set[offset:]
+		if binary.LittleEndian.Uint16(value[0:]) != vtLPWSTR {
+			continue
+		}
+		chars := int(binary.LittleEndian.Uint32(value[4:]))
+		if chars < 0 || 8+chars*2 > len(value) {
+			continue
+		}
+		units := make([]uint16, 0, chars)
+		for j := 0; j < chars; j++ {
+			u := binary.LittleEndian.Uint16(value[8+j*2:])
+			if u == 0 {
+				break
+			}
+			units = append(units, u)
+		}
+		props[id] = string(utf16.Decode(units))
+	}
+	return props, nil
+}
@@ -0,0 +1,82 @@
+package hwpv5
+
+import "fmt"
+
+// tagNames maps DocInfo and BodyText record tags to their names in the
+// HWP 5.0 specification. DocInfo and BodyText tags do not overlap.
+var tagNames = map[uint16]string{
+	recTagDocumentProperties: "HWPTAG_DOCUMENT_PROPERTIES",
+	recTagIDMappings:         "HWPTAG_ID_MAPPINGS",
+	recTagBinData:            "HWPTAG_BIN_DATA",
+	recTagFaceName:           "HWPTAG_FACE_NAME",
+	recTagBorderFill:         "HWPTAG_BORDER_FILL",
+	recTagCharShape:          "HWPTAG_CHAR_SHAPE",
+	recTagTabDef:             "HWPTAG_TAB_DEF",
+	recTagNumbering:          "HWPTAG_NUMBERING",
+	recTagBullet:             "HWPTAG_BULLET",
+	recTagParaShape:          "HWPTAG_PARA_SHAPE",
+	recTagStyle:              "HWPTAG_STYLE",
+	recTagDocData:            "HWPTAG_DOC_DATA",
+	recTagDistributeDocData:  "HWPTAG_DISTRIBUTE_DOC_DATA",
+	recTagCompatibleDocument: "HWPTAG_COMPATIBLE_DOCUMENT",
+	recTagLayoutCompat:       "HWPTAG_LAYOUT_COMPATIBILITY",
+	recTagTrackChangeInfo:    "HWPTAG_TRACKCHANGE",
+	recTagForbiddenChar:      "HWPTAG_FORBIDDEN_CHAR",
+	recTagTrackChange:        "HWPTAG_TRACK_CHANGE",
+	recTagTrackChangeAuthor:  "HWPTAG_TRACK_CHANGE_AUTHOR",
+
+	recTagParaHeader:              "HWPTAG_PARA_HEADER",
+	recTagParaText:                "HWPTAG_PARA_TEXT",
+	recTagParaCharShape:           "HWPTAG_PARA_CHAR_SHAPE",
+	recTagParaLineSeg:             "HWPTAG_PARA_LINE_SEG",
+	recTagParaRangeTag:            "HWPTAG_PARA_RANGE_TAG",
+	recTagCtrlHeader:              "HWPTAG_CTRL_HEADER",
+	recTagListHeader:              "HWPTAG_LIST_HEADER",
+	recTagPageDef:                 "HWPTAG_PAGE_DEF",
+	recTagFootnoteShape:           "HWPTAG_FOOTNOTE_SHAPE",
+	recTagPageBorderFill:          "HWPTAG_PAGE_BORDER_FILL",
+	recTagShapeComponent:          "HWPTAG_SHAPE_COMPONENT",
+	recTagTable:                   "HWPTAG_TABLE",
+	recTagShapeComponentLine:      "HWPTAG_SHAPE_COMPONENT_LINE",
+	recTagShapeComponentRectangle: "HWPTAG_SHAPE_COMPONENT_RECTANGLE",
+	recTagShapeComponentEllipse:   "HWPTAG_SHAPE_COMPONENT_ELLIPSE",
+	recTagShapeComponentArc:       "HWPTAG_SHAPE_COMPONENT_ARC",
+	recTagShapeComponentPolygon:   "HWPTAG_SHAPE_COMPONENT_POLYGON",
+	recTagShapeComponentCurve:     "HWPTAG_SHAPE_COMPONENT_CURVE",
+	recTagShapeComponentOLE:       "HWPTAG_SHAPE_COMPONENT_OLE",
+	recTagShapeComponentPicture:   "HWPTAG_SHAPE_COMPONENT_PICTURE",
+	recTagShapeComponentContainer: "HWPTAG_SHAPE_COMPONENT_CONTAINER",
+	recTagCtrlData:                "HWPTAG_CTRL_DATA",
+	recTagEqEdit:                  "HWPTAG_EQEDIT",
+	recTagShapeComponentTextArt:   "HWPTAG_SHAPE_COMPONENT_TEXTART",
+	recTagFormObject:              "HWPTAG_FORM_OBJECT",
+	recTagMemoShape:               "HWPTAG_MEMO_SHAPE",
+	recTagMemoList:                "HWPTAG_MEMO_LIST",
+	recTagChartData:               "HWPTAG_CHART_DATA",
+	recTagVideoData:               "HWPTAG_VIDEO_DATA",
+	recTagShapeComponentUnknown:   "HWPTAG_SHAPE_COMPONENT_UNKNOWN",
+}
+
+// tagIDs is the reverse of tagNames.
+var tagIDs = func() map[string]uint16 {
+	ids := make(map[string]uint16, len(tagNames))
+	for id, name := range tagNames {
+		ids[name] = id
+	}
+	return ids
+}()
+
+// TagName returns the specification name of a record tag, or
+// "HWPTAG_0x###" for tags without one.
+func TagName(tag uint16) string {
+	if name, ok := tagNames[tag]; ok {
+		return name
+	}
+	return fmt.Sprintf("HWPTAG_0x%03X", tag)
+}
+
+// TagID returns the record tag with the given specification name.
+func TagID(name string) (uint16, bool) {
+	id, ok := tagIDs[name]
+	return id, ok
+}
@@ -0,0 +1,25 @@
+package hwpv5
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTagNames(t *testing.T) {
+	for id, name := range tagNames {
+		if got, ok := TagID(name); !ok || got != id {
+			t.Errorf("TagID(%q) = %#x, %v; want %#x", name, got, ok, id)
+		}
+	}
+	if got := TagName(0x3FF); got != "HWPTAG_0x3FF" {
+		t.Errorf("TagName(0x3FF) = %q", got)
+	}
+
+	rec, err := NewRecScanner(bytes.NewReader((&recordStream{}).para(0, "가").buf.Bytes())).ScanNext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.String(); got != "HWPTAG_PARA_HEADER level=0 size=12" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,224 @@
+package hwpv5
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// Violation is a structural problem with a document: a record breaking an
+// invariant of the format, or a DocInfo inconsistency. HWPX documents
+// report elements of their section XML parts instead of records.
+type Violation struct {
+	// Section is the index of the section the record is in, or -1 for
+	// problems with DocInfo.
+	Section int
+	// Part is the section XML part of an HWPX document, empty for HWP.
+	Part string
+	// Record is the index of the record in the section stream, and Offset
+	// the stream offset of its header. For HWPX they are the index and
+	// byte offset of the element in the part.
+	Record int
+	Offset int64
+	// Tag names the record's tag, e.g. "HWPTAG_TABLE", or the element,
+	// e.g. "tbl".
+	Tag     string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Part != "" {
+		return fmt.Sprintf("%s element %d (%s at %#x): %s", v.Part, v.Record, v.Tag, v.Offset, v.Message)
+	}
+	if v.Section < 0 {
+		return "DocInfo: " + v.Message
+	}
+	return fmt.Sprintf("section %d record %d (%s at %#x): %s", v.Section, v.Record, v.Tag, v.Offset, v.Message)
+}
+
+// Validate checks the document against structural invariants of the
+// format: record levels that nest one at a time, tables whose cells cover
+// their RowCount×ColCount grid, and references that are within the bounds
+// of the DocInfo ID mappings. Undecodable records are violations too; only
+// failures to read a section at all are errors.
+func (r *Reader) Validate() ([]Violation, error) {
+	var violations []Violation
+	if err := r.DocInfo.CheckMappings(); err != nil {
+		violations = append(violations, Violation{Section: -1, Message: err.Error()})
+	}
+	for i := range r.SectionCount() {
+		section, err := r.OpenSection(i)
+		if err != nil {
+			return violations, fmt.Errorf("failed to open section %d: %w", i, err)
+		}
+		v := &validator{section: i, docInfo: r.DocInfo}
+		err = v.run(r.newRecScanner(r.SectionName(i), section))
+		section.Close()
+		violations = append(violations, v.violations...)
+		if err != nil {
+			return violations, err
+		}
+	}
+	return violations, nil
+}
+
+// validator checks the records of one section.
+type validator struct {
+	section    int
+	docInfo    *DocInfo
+	violations []Violation
+
+	// record, offset and tag locate the current record
+	record int
+	offset int64
+	tag    uint16
+	level  int
+	// tables holds the open tables, innermost last
+	tables []validatedTable
+}
+
+// validatedTable is a table whose cells are being counted.
+type validatedTable struct {
+	// at locates the Table record
+	at         Violation
+	level      uint16
+	rows, cols int
+	// area is the number of grid cells covered by the cells so far
+	area int
+}
+
+func (v *validator) run(s *RecScanner) error {
+	v.level = -1
+	for v.record = 0; ; v.record++ {
+		v.offset = s.Offset()
+		rec, err := s.ScanNext()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, limits.ErrExceeded) {
+			return err
+		}
+		var recErr *RecordError
+		if errors.As(err, &recErr) {
+			v.tag = recErr.Tag
+			v.report("%v", recErr.Err)
+			if errors.Is(err, ErrTruncatedRecord) {
+				continue
+			}
+			// The rest of the stream cannot be read
+			break
+		}
+		if err != nil {
+			return err
+		}
+		v.check(rec)
+	}
+	for len(v.tables) > 0 {
+		v.closeTable()
+	}
+	// Tables are reported when they close, after their cells
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Record < v.violations[j].Record
+	})
+	return nil
+}
+
+// report adds a violation at the current record.
+func (v *validator) report(format string, args ...any) {
+	v.violations = append(v.violations, v.at(fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) at(message string) Violation {
+	return Violation{
+		Section: v.section,
+		Record:  v.record,
+		Offset:  v.offset,
+		Tag:     TagName(v.tag),
+		Message: message,
+	}
+}
+
+// reference reports a failed DocInfo lookup.
+func (v *validator) reference(err error) {
+	if err != nil {
+		v.report("%v", err)
+	}
+}
+
+func (v *validator) check(rec Rec) {
+	v.tag = rec.Tag()
+	level := int(rec.Lvl())
+	// A record is a child of the last record one level up, so levels
+	// grow one at a time; the first record starts at level 0
+	if level > v.level+1 {
+		v.report("level %d record follows a level %d record", level, max(v.level, 0))
+	}
+	v.level = level
+	for len(v.tables) > 0 && rec.Lvl() < v.tables[len(v.tables)-1].level {
+		v.closeTable()
+	}
+
+	info := v.docInfo
+	switch r := rec.(type) {
+	case RecParaHeader:
+		_, err := info.ParaShape(r.ParaShapeID)
+		v.reference(err)
+		_, err = info.Style(r.StyleID)
+		v.reference(err)
+	case RecParaCharShape:
+		for _, run := range r.Runs {
+			_, err := info.CharShape(run.ShapeID)
+			v.reference(err)
+		}
+	case RecTable:
+		if r.RowCount == 0 || r.ColCount == 0 {
+			v.report("table has %d rows and %d columns", r.RowCount, r.ColCount)
+		}
+		if r.BorderFillID != 0 {
+			_, err := info.BorderFill(r.BorderFillID)
+			v.reference(err)
+		}
+		v.tables = append(v.tables, validatedTable{
+			at:    v.at(""),
+			level: r.Lvl(),
+			rows:  int(r.RowCount),
+			cols:  int(r.ColCount),
+		})
+	case RecListHeader:
+		if !r.IsCell || len(v.tables) == 0 || r.Lvl() != v.tables[len(v.tables)-1].level {
+			break
+		}
+		t := &v.tables[len(v.tables)-1]
+		row, col := int(r.RowIndex), int(r.ColIndex)
+		rowSpan, colSpan := int(r.RowSpan), int(r.ColSpan)
+		if row+rowSpan > t.rows || col+colSpan > t.cols {
+			v.report("cell at row %d, column %d spanning %dx%d is outside the %dx%d table",
+				row, col, rowSpan, colSpan, t.rows, t.cols)
+		}
+		t.area += rowSpan * colSpan
+		if r.BorderFillID != 0 {
+			_, err := info.BorderFill(r.BorderFillID)
+			v.reference(err)
+		}
+	case RecShapeComponentPicture:
+		_, err := info.BinDataItem(r.BinDataID)
+		v.reference(err)
+	case RecShapeComponentOLE:
+		_, err := info.BinDataItem(r.BinDataID)
+		v.reference(err)
+	}
+}
+
+// closeTable checks that the cells of the innermost open table cover its
+// grid.
+func (v *validator) closeTable() {
+	t := v.tables[len(v.tables)-1]
+	v.tables = v.tables[:len(v.tables)-1]
+	if want := t.rows * t.cols; t.area != want {
+		t.at.Message = fmt.Sprintf("cells cover %d of the %d cells of the %dx%d table", t.area, want, t.rows, t.cols)
+		v.violations = append(v.violations, t.at)
+	}
+}
@@ -0,0 +1,124 @@
+package hwpx
+
+import (
+	"strconv"
+
+	"github.com/hanpama/hwp/internal/hwpv5"
+)
+
+// NumberFormat is the number format (번호 모양) of an auto-number and the
+// characters around it.
+type NumberFormat struct {
+	Type       string `xml:"type,attr"`
+	UserChar   string `xml:"userChar,attr"`
+	PrefixChar string `xml:"prefixChar,attr"`
+	SuffixChar string `xml:"suffixChar,attr"`
+}
+
+// numberFormats maps the OWPML number types to the HWP number formats,
+// which are written the same way.
+var numberFormats = map[string]uint8{
+	"DIGIT":                   hwpv5.NumberDigits,
+	"CIRCLED_DIGIT":           hwpv5.NumberCircledDigits,
+	"ROMAN_CAPITAL":           hwpv5.NumberUpperRoman,
+	"ROMAN_SMALL":             hwpv5.NumberLowerRoman,
+	"LATIN_CAPITAL":           hwpv5.NumberUpperLatin,
+	"LATIN_SMALL":             hwpv5.NumberLowerLatin,
+	"CIRCLED_LATIN_CAPITAL":   hwpv5.NumberCircledUpperLatin,
+	"CIRCLED_LATIN_SMALL":     hwpv5.NumberCircledLowerLatin,
+	"HANGUL_SYLLABLE":         hwpv5.NumberHangulSyllable,
+	"CIRCLED_HANGUL_SYLLABLE": hwpv5.NumberCircledHangul,
+	"HANGUL_JAMO":             hwpv5.NumberHangulJamo,
+	"CIRCLED_HANGUL_JAMO":     hwpv5.NumberCircledHangulJamo,
+	"HANGUL_PHONETIC":         hwpv5.NumberHangulNumeral,
+	"IDEOGRAPH":               hwpv5.NumberIdeograph,
+	"CIRCLED_IDEOGRAPH":       hwpv5.NumberCircledIdeograph,
+	"DECAGON_CIRCLE":          hwpv5.NumberHeavenlyStemHangul,
+	"DECAGON_CIRCLE_HANJA":    hwpv5.NumberHeavenlyStem,
+	"SYMBOL":                  hwpv5.NumberSymbols,
+	"USER_CHAR":               hwpv5.NumberUserSymbol,
+}
+
+// text returns the number of the auto-number in its format, with its
+// prefix and suffix. Page numbers, which depend on the layout, become the
+// page number placeholder instead, or are dropped when it is empty.
+func (a *AutoNum) text(pageNumber string) string {
+	number := a.Num
+	if a.NumType == "PAGE" {
+		number = pageNumber
+	} else if n, err := strconv.Atoi(a.Num); err == nil {
+		var user rune
+		if r := []rune(a.Format.UserChar); len(r) > 0 {
+			user = r[0]
+		}
+		number = hwpv5.FormatNumber(numberFormats[a.Format.Type], n, user)
+	}
+	if number == "" {
+		return ""
+	}
+	return a.Format.PrefixChar + number + a.Format.SuffixChar
+}
+
+// text returns the page number placeholder with the side characters of a
+// page number position control that shows page numbers (pos other than
+// NONE), or "" otherwise.
+func (p *PageNum) text(pageNumber string) string {
+	if pageNumber == "" || p.Pos == "NONE" {
+		return ""
+	}
+	return p.SideChar + pageNumber + p.SideChar
+}
+
+// setPageNumbers sets the page number placeholder of the auto-numbers and
+// page number position controls in a paragraph and the objects, notes,
+// headers and footers in it.
+func setPageNumbers(p *ParagraphElement, pageNumber string) {
+	for i := range p.Runs {
+		run := &p.Runs[i]
+		if run.Table != nil {
+			setTablePageNumbers(run.Table, pageNumber)
+		}
+		for j := range run.Children {
+			run.Children[j].setPageNumbers(pageNumber)
+		}
+	}
+}
+
+func setTablePageNumbers(tbl *TableElement, pageNumber string) {
+	for i := range tbl.Rows {
+		for j := range tbl.Rows[i].Cells {
+			setSubListPageNumbers(&tbl.Rows[i].Cells[j].SubList, pageNumber)
+		}
+	}
+	if tbl.Caption != nil {
+		setSubListPageNumbers(&tbl.Caption.SubList, pageNumber)
+	}
+}
+
+func setSubListPageNumbers(list *SubList, pageNumber string) {
+	for i := range list.Paragraphs {
+		setPageNumbers(&list.Paragraphs[i], pageNumber)
+	}
+}
+
+func (c *RunChild) setPageNumbers(pageNumber string) {
+	c.pageNumber = pageNumber
+	for _, list := range []*SubList{c.DrawText, c.HiddenComment, c.Header, c.Footer} {
+		if list != nil {
+			setSubListPageNumbers(list, pageNumber)
+		}
+	}
+	if c.Caption != nil {
+		setSubListPageNumbers(&c.Caption.SubList, pageNumber)
+	}
+	for _, note := range []*Note{c.FootNote, c.EndNote} {
+		if note != nil {
+			for i := range note.Paragraphs {
+				setPageNumbers(&note.Paragraphs[i], pageNumber)
+			}
+		}
+	}
+	for i := range c.Shapes {
+		c.Shapes[i].setPageNumbers(pageNumber)
+	}
+}
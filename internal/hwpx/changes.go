@@ -0,0 +1,193 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// changeMark is an insertBegin, insertEnd, deleteBegin or deleteEnd mark
+// at a byte offset within the text of a t element.
+type changeMark struct {
+	offset int
+	begin  bool
+	kind   string // document.ChangeInsert or document.ChangeDelete
+	id     string // the hh:trackChange ID
+}
+
+// changeSpan is a span of changed text within the text of a t element.
+type changeSpan struct {
+	start, end int
+	kind       string
+	id         string
+}
+
+// changeMarkKinds maps the change mark elements to their change type and
+// whether they begin the change.
+var changeMarkKinds = map[string]struct {
+	kind  string
+	begin bool
+}{
+	"insertBegin": {document.ChangeInsert, true},
+	"insertEnd":   {document.ChangeInsert, false},
+	"deleteBegin": {document.ChangeDelete, true},
+	"deleteEnd":   {document.ChangeDelete, false},
+}
+
+// UnmarshalXML decodes a run child. The text of a t element is mixed
+// content; change marks within it are kept with their position, other
+// inline elements are skipped.
+func (c *RunChild) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain RunChild
+	if start.Name.Local != "t" {
+		return d.DecodeElement((*plain)(c), &start)
+	}
+
+	c.XMLName = start.Name
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			if mark, ok := changeMarkKinds[t.Name.Local]; ok {
+				c.marks = append(c.marks, changeMark{offset: text.Len(), begin: mark.begin, kind: mark.kind, id: attr(t, "TcId")})
+			}
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			c.Text = text.String()
+			return nil
+		}
+	}
+}
+
+// attr returns the value of the attribute with the given local name.
+func attr(elem xml.StartElement, name string) string {
+	for _, a := range elem.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// applyChanges removes the text that the selected view of tracked changes
+// hides from a paragraph and the objects in it. With ChangesMarkup all text
+// stays and the changed spans are kept for Paragraph.Changes.
+func (s *ContentScanner) applyChanges(p *ParagraphElement) {
+	var open *changeMark
+	for i := range p.Runs {
+		run := &p.Runs[i]
+		if run.Table != nil {
+			s.applyTableChanges(run.Table)
+		}
+		for j := range run.Children {
+			s.applyChildChanges(&run.Children[j], &open)
+		}
+	}
+}
+
+func (s *ContentScanner) applyTableChanges(tbl *TableElement) {
+	for i := range tbl.Rows {
+		for j := range tbl.Rows[i].Cells {
+			s.applySubListChanges(&tbl.Rows[i].Cells[j].SubList)
+		}
+	}
+	if tbl.Caption != nil {
+		s.applySubListChanges(&tbl.Caption.SubList)
+	}
+}
+
+func (s *ContentScanner) applySubListChanges(list *SubList) {
+	for i := range list.Paragraphs {
+		s.applyChanges(&list.Paragraphs[i])
+	}
+}
+
+// applyChildChanges applies changes to a run child. open is the change in
+// effect at the start of the child, and is updated to the one in effect
+// after it.
+func (s *ContentScanner) applyChildChanges(c *RunChild, open **changeMark) {
+	for _, list := range []*SubList{c.DrawText, c.HiddenComment} {
+		if list != nil {
+			s.applySubListChanges(list)
+		}
+	}
+	if c.Caption != nil {
+		s.applySubListChanges(&c.Caption.SubList)
+	}
+	for i := range c.Shapes {
+		var shapeOpen *changeMark
+		s.applyChildChanges(&c.Shapes[i], &shapeOpen)
+	}
+	if c.XMLName.Local != "t" || len(c.marks) == 0 && *open == nil {
+		return
+	}
+
+	view := s.opts.Changes
+	var text strings.Builder
+	pos := 0
+	segment := func(end int) {
+		seg := c.Text[pos:end]
+		pos = end
+		if seg == "" {
+			return
+		}
+		m := *open
+		if m != nil {
+			if m.kind == document.ChangeDelete && view != document.ChangesOriginal && view != document.ChangesMarkup ||
+				m.kind == document.ChangeInsert && view == document.ChangesOriginal {
+				return
+			}
+			if view == document.ChangesMarkup {
+				c.spans = append(c.spans, changeSpan{text.Len(), text.Len() + len(seg), m.kind, m.id})
+			}
+		}
+		text.WriteString(seg)
+	}
+	for i := range c.marks {
+		m := &c.marks[i]
+		segment(m.offset)
+		if m.begin {
+			*open = m
+		} else {
+			*open = nil
+		}
+	}
+	segment(len(c.Text))
+	c.Text = text.String()
+}
+
+// changes returns the tracked changes of the paragraph text, with author
+// and date from the header's changes by ID. Adjacent spans of one change
+// are merged.
+func (p *ParagraphElement) changes(byID map[string]document.Change) []document.Change {
+	var changes []document.Change
+	var ids []string
+	offset := 0
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			for _, span := range child.spans {
+				start, text := offset+span.start, child.Text[span.start:span.end]
+				if n := len(changes) - 1; n >= 0 && ids[n] == span.id && changes[n].Type == span.kind &&
+					changes[n].Offset+len(changes[n].Text) == start {
+					changes[n].Text += text
+					continue
+				}
+				change := byID[span.id]
+				change.Type, change.Offset, change.Text = span.kind, start, text
+				changes = append(changes, change)
+				ids = append(ids, span.id)
+			}
+			offset += len(child.text())
+		}
+	}
+	return changes
+}
@@ -0,0 +1,160 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// chartSpace is a chart part (Chart/chartN.xml), in the DrawingML chart
+// format of Office Open XML (c:chartSpace).
+type chartSpace struct {
+	Title    chartTitle `xml:"chart>title"`
+	PlotArea struct {
+		// Groups holds the chart groups (c:barChart, c:lineChart, ...)
+		// among the axes and layout
+		Groups []chartGroup `xml:",any"`
+	} `xml:"chart>plotArea"`
+}
+
+type chartTitle struct {
+	Paragraphs []struct {
+		Runs []string `xml:"r>t"`
+	} `xml:"tx>rich>p"`
+}
+
+func (t chartTitle) text() string {
+	var lines []string
+	for _, p := range t.Paragraphs {
+		if line := strings.TrimSpace(strings.Join(p.Runs, "")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type chartGroup struct {
+	XMLName xml.Name
+	Series  []chartSeries `xml:"ser"`
+}
+
+// chartSeries is a data series. Scatter and bubble charts give their
+// categories and values as x and y values.
+type chartSeries struct {
+	Name chartData `xml:"tx"`
+	Cat  chartData `xml:"cat"`
+	XVal chartData `xml:"xVal"`
+	Val  chartData `xml:"val"`
+	YVal chartData `xml:"yVal"`
+}
+
+// chartData is literal text or a reference to spreadsheet data, with the
+// points of the data cached in the chart.
+type chartData struct {
+	Text string     `xml:"v"`
+	Str  chartCache `xml:"strRef>strCache"`
+	Num  chartCache `xml:"numRef>numCache"`
+}
+
+type chartCache struct {
+	Count struct {
+		Value int `xml:"val,attr"`
+	} `xml:"ptCount"`
+	Points []chartPoint `xml:"pt"`
+}
+
+type chartPoint struct {
+	Index int    `xml:"idx,attr"`
+	Value string `xml:"v"`
+}
+
+// maxChartPoints bounds the number of points of a series, which a chart
+// part declares.
+const maxChartPoints = 1 << 16
+
+// points returns the values of the data by point index.
+func (d chartData) points() []string {
+	cache := d.Str
+	if len(cache.Points) == 0 {
+		cache = d.Num
+	}
+	points, count := cache.Points, cache.Count.Value
+	for _, p := range points {
+		count = max(count, p.Index+1)
+	}
+	count = min(count, maxChartPoints)
+	values := make([]string, count)
+	for _, p := range points {
+		if p.Index >= 0 && p.Index < count {
+			values[p.Index] = p.Value
+		}
+	}
+	return values
+}
+
+// label returns the text of a series name.
+func (d chartData) label() string {
+	if d.Text != "" {
+		return d.Text
+	}
+	return strings.Join(d.points(), " ")
+}
+
+// chart reads the chart part a chart object refers to, by path or manifest
+// item ID.
+func (r *Reader) chart(ref string, maxSize int64) (*document.Chart, error) {
+	name, ok := r.resolve("", ref)
+	if !ok {
+		if name = r.manifest[ref].Name; name == "" {
+			return nil, fmt.Errorf("no part for chart %q", ref)
+		}
+	}
+	file, err := r.zipReader.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var space chartSpace
+	if err := xml.NewDecoder(limits.NewReader(file, maxSize, name)).Decode(&space); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	chart := &document.Chart{Title: space.Title.text()}
+	for _, group := range space.PlotArea.Groups {
+		if len(group.Series) == 0 {
+			continue
+		}
+		if chart.Kind == "" {
+			chart.Kind = chartKind(group.XMLName.Local)
+		}
+		for _, ser := range group.Series {
+			cat, val := ser.Cat, ser.Val
+			if len(ser.YVal.Num.Points) > 0 {
+				cat, val = ser.XVal, ser.YVal
+			}
+			if chart.Categories == nil {
+				chart.Categories = cat.points()
+			}
+			series := document.ChartSeries{Name: ser.Name.label()}
+			for _, v := range val.points() {
+				// Missing points are zero
+				f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				series.Values = append(series.Values, f)
+			}
+			chart.Series = append(chart.Series, series)
+		}
+	}
+	return chart, nil
+}
+
+// chartKind returns the kind of chart of a chart group element, e.g. "bar"
+// for c:bar3DChart.
+func chartKind(element string) string {
+	kind := strings.TrimSuffix(element, "Chart")
+	return strings.TrimSuffix(kind, "3D")
+}
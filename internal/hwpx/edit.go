@@ -0,0 +1,201 @@
+package hwpx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/hwpv5"
+)
+
+// ReplaceText writes the package to w with text replaced in its sections
+// and master pages: each (old, new) pair of oldnew replaces old with new,
+// as with strings.NewReplacer, within the text of each paragraph, so that
+// placeholders split across runs of different formatting are still found.
+// The replacement takes the formatting of the run the match starts in.
+// Parts without matches are copied byte for byte, still compressed, and
+// in the others only the text of the changed t elements is rewritten. It
+// returns the number of replacements made.
+func (r *Reader) ReplaceText(w io.Writer, oldnew []string, maxSize int64) (int, error) {
+	if err := hwpv5.CheckReplacements(oldnew); err != nil {
+		return 0, err
+	}
+
+	kinds := make(map[string]string)
+	for _, p := range r.Parts() {
+		kinds[p.Name] = p.Kind
+	}
+	zw := zip.NewWriter(w)
+	count := 0
+	for _, file := range r.zipReader.File {
+		if kind := kinds[file.Name]; kind != PartSection && kind != PartMasterPage {
+			if err := zw.Copy(file); err != nil {
+				return count, fmt.Errorf("failed to copy %s: %w", file.Name, err)
+			}
+			continue
+		}
+		data, err := r.readPart(file.Name, maxSize)
+		if err != nil {
+			return count, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		edited, n, err := replaceText(data, oldnew)
+		if err != nil {
+			return count, fmt.Errorf("failed to parse %s: %w", file.Name, err)
+		}
+		count += n
+		if n == 0 {
+			if err := zw.Copy(file); err != nil {
+				return count, fmt.Errorf("failed to copy %s: %w", file.Name, err)
+			}
+			continue
+		}
+		header := file.FileHeader
+		part, err := zw.CreateHeader(&header)
+		if err != nil {
+			return count, err
+		}
+		if _, err := part.Write(edited); err != nil {
+			return count, err
+		}
+	}
+	return count, zw.Close()
+}
+
+// textSegment is a run of character data within a t element, located by
+// its byte range in the part.
+type textSegment struct {
+	start, end int64
+	text       string
+}
+
+// replaceText applies the replacements to the paragraphs of an OWPML part,
+// returning the part with the changed text segments rewritten, and the
+// number of replacements.
+func replaceText(data []byte, oldnew []string) ([]byte, int, error) {
+	// paragraphs holds the text segments of the open paragraphs, innermost
+	// last; done those of the closed ones
+	var paragraphs, done [][]textSegment
+	inText := 0
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		start := d.InputOffset()
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				// Text after a nested paragraph, as in a table within the
+				// paragraph, is matched apart from the text before it
+				if n := len(paragraphs) - 1; n >= 0 {
+					done = append(done, paragraphs[n])
+					paragraphs[n] = nil
+				}
+				paragraphs = append(paragraphs, nil)
+			case "t":
+				inText++
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p":
+				if n := len(paragraphs) - 1; n >= 0 {
+					done = append(done, paragraphs[n])
+					paragraphs = paragraphs[:n]
+				}
+			case "t":
+				inText--
+			}
+		case xml.CharData:
+			if n := len(paragraphs) - 1; n >= 0 && inText > 0 {
+				paragraphs[n] = append(paragraphs[n], textSegment{start, d.InputOffset(), string(t)})
+			}
+		}
+	}
+
+	var edits []textSegment
+	count := 0
+	for _, segments := range done {
+		changed, n := replaceSegments(segments, oldnew)
+		edits = append(edits, changed...)
+		count += n
+	}
+	if count == 0 {
+		return data, 0, nil
+	}
+
+	// Segments of nested paragraphs come out of order
+	sortSegments(edits)
+	var out bytes.Buffer
+	pos := int64(0)
+	for _, e := range edits {
+		out.Write(data[pos:e.start])
+		xml.EscapeText(&out, []byte(e.text))
+		pos = e.end
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), count, nil
+}
+
+// replaceSegments applies the replacements to the text of a paragraph made
+// of segments, returning the segments whose text changed, with their new
+// text, and the number of replacements.
+func replaceSegments(segments []textSegment, oldnew []string) ([]textSegment, int) {
+	var text strings.Builder
+	for _, s := range segments {
+		text.WriteString(s.text)
+	}
+	matches := hwpv5.FindMatches(text.String(), oldnew)
+	if len(matches) == 0 {
+		return nil, 0
+	}
+
+	var changed []textSegment
+	offset, m := 0, 0
+	for _, s := range segments {
+		segStart, segEnd := offset, offset+len(s.text)
+		offset = segEnd
+		var b strings.Builder
+		for pos := segStart; pos < segEnd; {
+			// Skip the matches that end before pos
+			for m < len(matches) && matches[m].End <= pos {
+				m++
+			}
+			if m < len(matches) && matches[m].Start <= pos {
+				// The replacement goes where its match starts; the rest
+				// of the match is removed
+				if matches[m].Start == pos {
+					b.WriteString(matches[m].New)
+				}
+				pos = min(matches[m].End, segEnd)
+				continue
+			}
+			next := segEnd
+			if m < len(matches) && matches[m].Start < segEnd {
+				next = matches[m].Start
+			}
+			b.WriteString(text.String()[pos:next])
+			pos = next
+		}
+		if b.String() != s.text {
+			s.text = b.String()
+			changed = append(changed, s)
+		}
+	}
+	return changed, len(matches)
+}
+
+func sortSegments(segments []textSegment) {
+	for i := 1; i < len(segments); i++ {
+		for j := i; j > 0 && segments[j].start < segments[j-1].start; j-- {
+			segments[j], segments[j-1] = segments[j-1], segments[j]
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// manifestPath is the ODF manifest, which declares the encryption of the
+// parts of a password-protected package.
+const manifestPath = "META-INF/manifest.xml"
+
+// ErrEncrypted is matched (via errors.Is) by *EncryptionError.
+var ErrEncrypted = errors.New("encrypted HWPX package")
+
+// EncryptionError is returned for a package whose parts are encrypted,
+// with a document password (문서 암호) or by a DRM product.
+type EncryptionError struct {
+	// Parts lists the encrypted parts, or for DRM the parts that mark the
+	// package as protected.
+	Parts []string
+	// Algorithm is the encryption algorithm declared in the manifest, if
+	// any.
+	Algorithm string
+	// DRM is set for packages protected by a DRM product.
+	DRM bool
+}
+
+func (e *EncryptionError) Error() string {
+	var b strings.Builder
+	if e.DRM {
+		b.WriteString("HWPX package is DRM-protected")
+	} else {
+		b.WriteString("HWPX package is encrypted")
+		if e.Algorithm != "" {
+			fmt.Fprintf(&b, " with %s", e.Algorithm)
+		}
+	}
+	if len(e.Parts) > 0 {
+		fmt.Fprintf(&b, " (%s", e.Parts[0])
+		if len(e.Parts) > 1 {
+			fmt.Fprintf(&b, " and %d more parts", len(e.Parts)-1)
+		}
+		b.WriteString(")")
+	}
+	if e.DRM {
+		b.WriteString("; decrypt it with the DRM client first")
+	} else {
+		b.WriteString("; save it in Hangul without a password first")
+	}
+	return b.String()
+}
+
+func (e *EncryptionError) Is(target error) bool { return target == ErrEncrypted }
+
+// checkEncryption returns an *EncryptionError for a package with DRM parts
+// under META-INF, encrypted ZIP entries or parts whose encryption the
+// manifest declares, before reading the parts fails with less helpful
+// errors.
+func (r *Reader) checkEncryption() error {
+	var drm, encrypted []string
+	for _, file := range r.zipReader.File {
+		if strings.HasPrefix(file.Name, "META-INF/") && strings.Contains(strings.ToLower(file.Name), "drm") {
+			drm = append(drm, file.Name)
+		}
+		// General purpose flag bit 0 marks an encrypted entry
+		if file.Flags&0x1 != 0 {
+			encrypted = append(encrypted, file.Name)
+		}
+	}
+	if len(drm) > 0 {
+		return &EncryptionError{Parts: drm, DRM: true}
+	}
+	if len(encrypted) > 0 {
+		return &EncryptionError{Parts: encrypted}
+	}
+
+	file, err := r.zipReader.Open(manifestPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var manifest struct {
+		Entries []struct {
+			FullPath   string `xml:"full-path,attr"`
+			Encryption *struct {
+				Algorithm struct {
+					Name string `xml:"algorithm-name,attr"`
+				} `xml:"algorithm"`
+			} `xml:"encryption-data"`
+		} `xml:"file-entry"`
+	}
+	if err := xml.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil
+	}
+	var e EncryptionError
+	for _, entry := range manifest.Entries {
+		if entry.Encryption != nil {
+			e.Parts = append(e.Parts, entry.FullPath)
+			if e.Algorithm == "" {
+				e.Algorithm = entry.Encryption.Algorithm.Name
+			}
+		}
+	}
+	if len(e.Parts) > 0 {
+		return &e
+	}
+	return nil
+}
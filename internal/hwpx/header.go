@@ -0,0 +1,377 @@
+package hwpx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/hwpv5"
+)
+
+const headerPath = "Contents/header.xml"
+
+// Header holds the document-wide definitions of Contents/header.xml that
+// section content refers to.
+type Header struct {
+	FontFaces []FontFace       `xml:"refList>fontfaces>fontface"`
+	CharPrs   []CharProperties `xml:"refList>charProperties>charPr"`
+	ParaPrs   []ParaProperties `xml:"refList>paraProperties>paraPr"`
+	Styles    []Style          `xml:"refList>styles>style"`
+	// BorderFills are the borders and backgrounds of cells and other
+	// objects
+	BorderFills []BorderFill `xml:"refList>borderFills>borderFill"`
+	// Numberings and bullets of paragraphs with paragraph numbers or
+	// bullets, referred to by paragraph shapes
+	Numberings []Numbering `xml:"refList>numberings>numbering"`
+	Bullets    []Bullet    `xml:"refList>bullets>bullet"`
+	// Tracked changes and their authors, referred to by change marks
+	TrackChanges  []TrackChange       `xml:"refList>trackChanges>trackChange"`
+	ChangeAuthors []TrackChangeAuthor `xml:"refList>trackChangeAuthors>trackChangeAuthor"`
+}
+
+// FontFace is the font list of one language (HANGUL, LATIN, ...).
+type FontFace struct {
+	Lang  string `xml:"lang,attr"`
+	Fonts []Font `xml:"font"`
+}
+
+// Font is a font declaration.
+type Font struct {
+	ID         string    `xml:"id,attr"`
+	Face       string    `xml:"face,attr"`
+	IsEmbedded bool      `xml:"isEmbedded,attr"`
+	SubstFont  SubstFont `xml:"substFont"`
+	TypeInfo   *TypeInfo `xml:"typeInfo"`
+}
+
+// SubstFont is the font used when a font is not installed.
+type SubstFont struct {
+	Face string `xml:"face,attr"`
+}
+
+// TypeInfo holds the PANOSE-like font classification.
+type TypeInfo struct {
+	Proportion int `xml:"proportion,attr"`
+}
+
+// CharProperties is a character shape (hh:charPr).
+type CharProperties struct {
+	ID      string  `xml:"id,attr"`
+	FontRef FontRef `xml:"fontRef"`
+	// Height is the font size in 1/100 points.
+	Height    int    `xml:"height,attr"`
+	TextColor string `xml:"textColor,attr"`
+	// Emphasis elements are present when set
+	Bold      *struct{}  `xml:"bold"`
+	Italic    *struct{}  `xml:"italic"`
+	Underline *LineShape `xml:"underline"`
+	Strikeout *LineShape `xml:"strikeout"`
+	Supscript *struct{}  `xml:"supscript"`
+	Subscript *struct{}  `xml:"subscript"`
+}
+
+// LineShape is the type and shape of an underline or strikeout line,
+// "NONE" when there is none.
+type LineShape struct {
+	Type  string `xml:"type,attr"`
+	Shape string `xml:"shape,attr"`
+}
+
+// drawn reports whether the line is drawn.
+func (l *LineShape) drawn() bool {
+	return l != nil && l.Type != "NONE" && l.Shape != "NONE"
+}
+
+// Run returns the formatting of the character shape.
+func (c CharProperties) Run() document.Run {
+	run := document.Run{
+		Bold:        c.Bold != nil,
+		Italic:      c.Italic != nil,
+		Underline:   c.Underline.drawn(),
+		Strikeout:   c.Strikeout.drawn(),
+		Superscript: c.Supscript != nil,
+		Subscript:   c.Subscript != nil,
+		Size:        float64(c.Height) / 100,
+	}
+	// Black is the default color
+	if color := strings.ToLower(c.TextColor); color != "#000000" && color != "none" {
+		run.Color = color
+	}
+	return run
+}
+
+// ParaProperties is a paragraph shape (hh:paraPr). Newer files give the
+// margins and line spacing in an hp:switch, whose hp:default has their
+// values in HWPUNIT.
+type ParaProperties struct {
+	ID    string `xml:"id,attr"`
+	Align struct {
+		Horizontal string `xml:"horizontal,attr"`
+	} `xml:"align"`
+	Margin      *ParaMargin  `xml:"margin"`
+	LineSpacing *LineSpacing `xml:"lineSpacing"`
+	Heading     *ParaHeading `xml:"heading"`
+}
+
+// ParaHeading is the numbering of a paragraph shape (hh:heading): OUTLINE,
+// NUMBER or BULLET, with the level from 0, or NONE.
+type ParaHeading struct {
+	Type  string `xml:"type,attr"`
+	IDRef string `xml:"idRef,attr"`
+	Level int    `xml:"level,attr"`
+}
+
+// OutlineLevel returns the level of a paragraph shape with outline
+// numbering (개요 번호), from 1, or 0 for other shapes.
+func (p ParaProperties) OutlineLevel() int {
+	h := p.Heading
+	if h == nil || h.Type != "OUTLINE" || h.Level < 0 || h.Level >= maxOutlineLevel {
+		return 0
+	}
+	return h.Level + 1
+}
+
+// ParaMargin holds the indentation and spacing of a paragraph shape.
+type ParaMargin struct {
+	Indent HWPValue `xml:"intent"`
+	Left   HWPValue `xml:"left"`
+	Right  HWPValue `xml:"right"`
+	Prev   HWPValue `xml:"prev"`
+	Next   HWPValue `xml:"next"`
+}
+
+// HWPValue is a length in HWPUNIT, 1/7200 inch.
+type HWPValue struct {
+	Value int `xml:"value,attr"`
+}
+
+func (v HWPValue) points() float64 { return float64(v.Value) / 100 }
+
+// LineSpacing is the line spacing of a paragraph shape.
+type LineSpacing struct {
+	Type  string `xml:"type,attr"`
+	Value int    `xml:"value,attr"`
+}
+
+// hwpxAligns maps hh:align horizontal values to layout alignments.
+var hwpxAligns = map[string]string{
+	"LEFT":             document.AlignLeft,
+	"RIGHT":            document.AlignRight,
+	"CENTER":           document.AlignCenter,
+	"DISTRIBUTE":       document.AlignDistribute,
+	"DISTRIBUTE_SPACE": document.AlignDivide,
+}
+
+// Layout returns the layout of the paragraph shape, or nil for a plain
+// one: aligned to both sides or the left, without margins or indent.
+func (p ParaProperties) Layout() *document.Layout {
+	layout := &document.Layout{Align: document.AlignJustify}
+	if align, ok := hwpxAligns[p.Align.Horizontal]; ok {
+		layout.Align = align
+	}
+	margin, spacing := p.Margin, p.LineSpacing
+	if margin != nil {
+		layout.MarginLeft = margin.Left.points()
+		layout.MarginRight = margin.Right.points()
+		layout.Indent = margin.Indent.points()
+		layout.SpaceBefore = margin.Prev.points()
+		layout.SpaceAfter = margin.Next.points()
+	}
+	if spacing != nil && spacing.Type == "PERCENT" {
+		layout.LineSpacing = spacing.Value
+	}
+	if (layout.Align == document.AlignJustify || layout.Align == document.AlignLeft) && !layout.Indented() {
+		return nil
+	}
+	return layout
+}
+
+// BorderFill is a border and background definition (hh:borderFill).
+type BorderFill struct {
+	ID     string     `xml:"id,attr"`
+	Left   BorderLine `xml:"leftBorder"`
+	Right  BorderLine `xml:"rightBorder"`
+	Top    BorderLine `xml:"topBorder"`
+	Bottom BorderLine `xml:"bottomBorder"`
+}
+
+// BorderLine is one side of a border fill. Type is the line type, "NONE"
+// or empty for no line.
+type BorderLine struct {
+	Type string `xml:"type,attr"`
+}
+
+func (b BorderLine) drawn() bool { return b.Type != "" && b.Type != "NONE" }
+
+// CellBorders returns which sides of the border fill have a line.
+func (b BorderFill) CellBorders() *document.CellBorders {
+	return &document.CellBorders{
+		Left:   b.Left.drawn(),
+		Right:  b.Right.drawn(),
+		Top:    b.Top.drawn(),
+		Bottom: b.Bottom.drawn(),
+	}
+}
+
+// Style is a named style (hh:style).
+type Style struct {
+	ID          string `xml:"id,attr"`
+	Type        string `xml:"type,attr"`
+	Name        string `xml:"name,attr"`
+	EngName     string `xml:"engName,attr"`
+	ParaPrIDRef string `xml:"paraPrIDRef,attr"`
+	CharPrIDRef string `xml:"charPrIDRef,attr"`
+}
+
+// maxOutlineLevel is the number of outline styles.
+const maxOutlineLevel = 7
+
+// OutlineLevel returns the level of an outline style (개요 1-7, "Outline 1"
+// in English), or 0 for other styles.
+func (s Style) OutlineLevel() int {
+	for _, name := range []string{s.Name, s.EngName} {
+		for _, prefix := range []string{"개요 ", "Outline "} {
+			n, ok := strings.CutPrefix(name, prefix)
+			if !ok || len(n) != 1 {
+				continue
+			}
+			if level := int(n[0] - '0'); level >= 1 && level <= maxOutlineLevel {
+				return level
+			}
+		}
+	}
+	return 0
+}
+
+// FontRef holds per-language font IDs.
+type FontRef struct {
+	Hangul string `xml:"hangul,attr"`
+	Latin  string `xml:"latin,attr"`
+}
+
+// Monospace reports whether the font is fixed-pitch, judged from its type
+// information when present and from well-known font names otherwise.
+func (f Font) Monospace() bool {
+	var proportion byte
+	if f.TypeInfo != nil && f.TypeInfo.Proportion >= 0 && f.TypeInfo.Proportion <= 0xff {
+		proportion = byte(f.TypeInfo.Proportion)
+	}
+	return hwpv5.MonospaceFont(f.Face, proportion)
+}
+
+// Header parses Contents/header.xml. Packages without a header yield an
+// empty Header.
+func (r *Reader) Header() (*Header, error) {
+	file, err := r.zipReader.Open(headerPath)
+	if err != nil {
+		return &Header{}, nil
+	}
+	defer file.Close()
+
+	var header Header
+	if err := newDecoder(file).Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", headerPath, err)
+	}
+	return &header, nil
+}
+
+// monospaceCharPrs returns the IDs of character shapes whose Latin font is
+// fixed-pitch.
+func (h *Header) monospaceCharPrs() map[string]bool {
+	var latin []Font
+	for _, ff := range h.FontFaces {
+		if ff.Lang == "LATIN" {
+			latin = ff.Fonts
+		}
+	}
+
+	mono := make(map[string]bool)
+	for _, cp := range h.CharPrs {
+		for _, font := range latin {
+			if font.ID == cp.FontRef.Latin && font.Monospace() {
+				mono[cp.ID] = true
+			}
+		}
+	}
+	return mono
+}
+
+// layouts returns the layouts of paragraph shapes by ID, leaving out plain
+// ones.
+func (h *Header) layouts() map[string]*document.Layout {
+	layouts := make(map[string]*document.Layout)
+	for _, pp := range h.ParaPrs {
+		if layout := pp.Layout(); layout != nil {
+			layouts[pp.ID] = layout
+		}
+	}
+	return layouts
+}
+
+// cellBorders returns the sides with a line of border fills by ID.
+func (h *Header) cellBorders() map[string]*document.CellBorders {
+	borders := make(map[string]*document.CellBorders, len(h.BorderFills))
+	for _, bf := range h.BorderFills {
+		borders[bf.ID] = bf.CellBorders()
+	}
+	return borders
+}
+
+// runStyles returns the formatting of character shapes by ID.
+func (h *Header) runStyles() map[string]document.Run {
+	styles := make(map[string]document.Run, len(h.CharPrs))
+	for _, cp := range h.CharPrs {
+		styles[cp.ID] = cp.Run()
+	}
+	return styles
+}
+
+// outlineLevels returns the levels of outline styles by ID.
+func (h *Header) outlineLevels() map[string]int {
+	levels := make(map[string]int)
+	for _, style := range h.Styles {
+		if level := style.OutlineLevel(); level > 0 {
+			levels[style.ID] = level
+		}
+	}
+	return levels
+}
+
+// shapeOutlineLevels returns the levels of paragraph shapes with outline
+// numbering by ID.
+func (h *Header) shapeOutlineLevels() map[string]int {
+	levels := make(map[string]int)
+	for _, pp := range h.ParaPrs {
+		if level := pp.OutlineLevel(); level > 0 {
+			levels[pp.ID] = level
+		}
+	}
+	return levels
+}
+
+// TrackChange is a tracked change (hh:trackChange).
+type TrackChange struct {
+	ID       string `xml:"id,attr"`
+	Type     string `xml:"type,attr"`
+	Date     string `xml:"date,attr"`
+	AuthorID string `xml:"authorID,attr"`
+}
+
+// TrackChangeAuthor is the author of tracked changes (hh:trackChangeAuthor).
+type TrackChangeAuthor struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name,attr"`
+}
+
+// changes returns the tracked changes by ID, with their author names.
+func (h *Header) changes() map[string]document.Change {
+	authors := make(map[string]string)
+	for _, a := range h.ChangeAuthors {
+		authors[a.ID] = a.Name
+	}
+	changes := make(map[string]document.Change)
+	for _, tc := range h.TrackChanges {
+		changes[tc.ID] = document.Change{Author: authors[tc.AuthorID], Date: tc.Date}
+	}
+	return changes
+}
@@ -0,0 +1,110 @@
+package hwpx
+
+import (
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/hwpv5"
+)
+
+// Numbering is a paragraph numbering (hh:numbering) of numbered lists.
+type Numbering struct {
+	ID        string     `xml:"id,attr"`
+	ParaHeads []ParaHead `xml:"paraHead"`
+}
+
+// ParaHead is a level of a numbering (hh:paraHead), from 1. Its text is the
+// text of the number, as in hwpv5.ParaHead.
+type ParaHead struct {
+	Level     int    `xml:"level,attr"`
+	Start     int    `xml:"start,attr"`
+	NumFormat string `xml:"numFormat,attr"`
+	Text      string `xml:",chardata"`
+}
+
+// Bullet is a bullet (hh:bullet) of bulleted lists.
+type Bullet struct {
+	ID   string `xml:"id,attr"`
+	Char string `xml:"char,attr"`
+}
+
+// lists numbers the paragraphs of numbered and bulleted lists.
+type lists struct {
+	// headings holds the numbering of paragraph shapes with paragraph
+	// numbers or bullets, by ID
+	headings   map[string]ParaHeading
+	numberings map[string]hwpv5.Numbering
+	bullets    map[string]string
+	// numbers holds the current numbers of the levels of each numbering
+	numbers map[string][]int
+}
+
+// lists returns the numberings and bullets of the header, and the paragraph
+// shapes that use them.
+func (h *Header) lists() *lists {
+	l := &lists{
+		headings:   make(map[string]ParaHeading),
+		numberings: make(map[string]hwpv5.Numbering),
+		bullets:    make(map[string]string),
+		numbers:    make(map[string][]int),
+	}
+	for _, pp := range h.ParaPrs {
+		if pp.Heading != nil && (pp.Heading.Type == "NUMBER" || pp.Heading.Type == "BULLET") {
+			l.headings[pp.ID] = *pp.Heading
+		}
+	}
+	for _, n := range h.Numberings {
+		var numbering hwpv5.Numbering
+		for _, head := range n.ParaHeads {
+			if head.Level < 1 || head.Level > 10 {
+				continue
+			}
+			for len(numbering.Levels) < head.Level {
+				numbering.Levels = append(numbering.Levels, hwpv5.ParaHead{Start: 1})
+			}
+			numbering.Levels[head.Level-1] = hwpv5.ParaHead{
+				Format: numberFormats[head.NumFormat],
+				Text:   head.Text,
+				Start:  head.Start,
+			}
+		}
+		l.numberings[n.ID] = numbering
+	}
+	for _, b := range h.Bullets {
+		l.bullets[b.ID] = b.Char
+	}
+	return l
+}
+
+// item returns the list item of a paragraph in a paragraph shape with
+// paragraph numbers or bullets, counting it in its numbered list, or nil
+// for other paragraphs, or without a header.
+func (l *lists) item(paraPrID string) *document.ListItem {
+	if l == nil {
+		return nil
+	}
+	heading, ok := l.headings[paraPrID]
+	if !ok {
+		return nil
+	}
+	level := heading.Level + 1
+	if heading.Type == "BULLET" {
+		char, ok := l.bullets[heading.IDRef]
+		if !ok {
+			return nil
+		}
+		return &document.ListItem{Level: level, Marker: char}
+	}
+	numbering, ok := l.numberings[heading.IDRef]
+	if !ok {
+		return nil
+	}
+	numbers, ok := l.numbers[heading.IDRef]
+	if !ok {
+		numbers = make([]int, len(numbering.Levels))
+		l.numbers[heading.IDRef] = numbers
+	}
+	marker, number := numbering.Number(numbers, level)
+	if marker == "" && number == 0 {
+		return nil
+	}
+	return &document.ListItem{Ordered: true, Level: level, Marker: marker, Number: number}
+}
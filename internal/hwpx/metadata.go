@@ -0,0 +1,55 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Metadata holds the document properties from the package file (content.hpf).
+type Metadata struct {
+	Title       string
+	Subject     string
+	Creator     string
+	Description string
+	Keywords    string
+}
+
+// Metadata reads document properties from the package file. Packages without
+// a content.hpf yield an empty Metadata.
+func (r *Reader) Metadata() (Metadata, error) {
+	file, err := r.zipReader.Open(r.packagePath)
+	if err != nil {
+		return Metadata{}, nil
+	}
+	defer file.Close()
+
+	var pkg struct {
+		Metadata struct {
+			Title string `xml:"title"`
+			Metas []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"meta"`
+		} `xml:"metadata"`
+	}
+	if err := xml.NewDecoder(file).Decode(&pkg); err != nil {
+		return Metadata{}, fmt.Errorf("failed to parse %s: %w", r.packagePath, err)
+	}
+
+	md := Metadata{Title: strings.TrimSpace(pkg.Metadata.Title)}
+	for _, meta := range pkg.Metadata.Metas {
+		value := strings.TrimSpace(meta.Value)
+		switch meta.Name {
+		case "subject":
+			md.Subject = value
+		case "creator":
+			md.Creator = value
+		case "description":
+			md.Description = value
+		case "keyword":
+			md.Keywords = value
+		}
+	}
+	return md, nil
+}
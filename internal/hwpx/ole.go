@@ -0,0 +1,93 @@
+package hwpx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// cfbSignature starts every OLE compound file.
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// nullCLSID is the class ID of storages that do not name one.
+const nullCLSID = "{00000000-0000-0000-0000-000000000000}"
+
+// oleClass returns the class of an OLE object: the ProgID its CompObj
+// stream records, or else the class ID of its root storage. It returns ""
+// for data that is not a compound file.
+func oleClass(data []byte) string {
+	// Hangul writes some objects with a 4-byte size before the compound
+	// file, as in HWP BinData streams
+	if !bytes.HasPrefix(data, cfbSignature) && len(data) > 4 && bytes.HasPrefix(data[4:], cfbSignature) {
+		data = data[4:]
+	}
+	if !bytes.HasPrefix(data, cfbSignature) {
+		return ""
+	}
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		// mscfb drops the \x01 that starts the stream name
+		if entry.Name != "CompObj" || len(entry.Path) > 0 {
+			continue
+		}
+		stream, err := io.ReadAll(io.LimitReader(entry, 1<<16))
+		if err != nil {
+			break
+		}
+		if progID := compObjProgID(stream); progID != "" {
+			return progID
+		}
+		break
+	}
+	if id := doc.ID(); id != nullCLSID {
+		return id
+	}
+	return ""
+}
+
+// compObjProgID returns the ProgID of a CompObj stream (MS-OLEDS 2.3.8): a
+// 28-byte header, the user type and clipboard format, then the ProgID.
+func compObjProgID(b []byte) string {
+	if len(b) < 28 {
+		return ""
+	}
+	b = b[28:]
+	_, b = lengthPrefixedString(b)
+	if len(b) < 4 {
+		return ""
+	}
+	// The clipboard format is a standard format number, marked by
+	// 0xFFFFFFFF or 0xFFFFFFFE, or the length of a format name
+	switch n := binary.LittleEndian.Uint32(b); {
+	case n == 0:
+		b = b[4:]
+	case n >= 0xFFFFFFFE:
+		b = b[min(8, len(b)):]
+	case uint64(n) <= uint64(len(b)-4):
+		b = b[4+n:]
+	default:
+		return ""
+	}
+	progID, _ := lengthPrefixedString(b)
+	return progID
+}
+
+// lengthPrefixedString reads a null-terminated ANSI string preceded by its
+// length, returning the string and the bytes after it.
+func lengthPrefixedString(b []byte) (string, []byte) {
+	if len(b) < 4 {
+		return "", nil
+	}
+	n := binary.LittleEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return "", nil
+	}
+	s := string(b[4 : 4+n])
+	return strings.TrimRight(s, "\x00"), b[4+n:]
+}
@@ -0,0 +1,194 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+const (
+	containerPath = "META-INF/container.xml"
+	// packageMediaType is the media type of the package file in
+	// container.xml
+	packageMediaType = "application/hwpml-package+xml"
+	// defaultPackagePath is where the package file is when container.xml
+	// does not say otherwise
+	defaultPackagePath = "Contents/content.hpf"
+)
+
+// findPackage sets the path of the package file (content.hpf) from the
+// root files listed in META-INF/container.xml.
+func (r *Reader) findPackage() {
+	r.packagePath = defaultPackagePath
+	file, err := r.zipReader.Open(containerPath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var container struct {
+		RootFiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.NewDecoder(file).Decode(&container); err != nil {
+		return
+	}
+	for _, root := range container.RootFiles {
+		if root.MediaType == packageMediaType || strings.HasSuffix(root.FullPath, ".hpf") {
+			if name, ok := r.resolve("", root.FullPath); ok {
+				r.packagePath = name
+			}
+			return
+		}
+	}
+}
+
+// ManifestItem is a part listed in the manifest of the package file.
+type ManifestItem struct {
+	// Name is the path of the part in the package, and MediaType its type,
+	// e.g. "image/png".
+	Name      string
+	MediaType string
+}
+
+// loadPackage reads the manifest and spine of the package file. Packages
+// without a readable package file have neither.
+func (r *Reader) loadPackage() {
+	file, err := r.zipReader.Open(r.packagePath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var pkg struct {
+		Items []struct {
+			ID        string `xml:"id,attr"`
+			Href      string `xml:"href,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"manifest>item"`
+		ItemRefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.NewDecoder(file).Decode(&pkg); err != nil {
+		return
+	}
+
+	r.manifest = make(map[string]ManifestItem, len(pkg.Items))
+	for _, item := range pkg.Items {
+		if name, ok := r.resolve(path.Dir(r.packagePath), item.Href); ok {
+			r.manifest[item.ID] = ManifestItem{Name: name, MediaType: item.MediaType}
+		}
+	}
+	for _, ref := range pkg.ItemRefs {
+		r.spine = append(r.spine, ref.IDRef)
+	}
+}
+
+// spineSections returns the section parts in the order of the spine. The
+// spine also lists the header, so only parts with a section root element
+// are sections.
+func (r *Reader) spineSections() []*Section {
+	var sections []*Section
+	for _, id := range r.spine {
+		item, ok := r.manifest[id]
+		if ok && r.isSection(item.Name) {
+			sections = append(sections, &Section{name: item.Name})
+		}
+	}
+	return sections
+}
+
+// resolve returns the part a package reference names. References are
+// relative to the package root in files written by Hangul, and to the
+// referring part's directory dir in other OPF packages.
+func (r *Reader) resolve(dir, href string) (string, bool) {
+	if href == "" {
+		return "", false
+	}
+	for _, name := range []string{path.Clean(href), path.Join(dir, href)} {
+		if info, err := fs.Stat(r.zipReader, name); err == nil && !info.IsDir() {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// isSection reports whether the root element of an XML part is a section.
+func (r *Reader) isSection(name string) bool {
+	file, err := r.zipReader.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	decoder := xml.NewDecoder(io.LimitReader(file, 1<<16))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if elem, ok := token.(xml.StartElement); ok {
+			return elem.Name.Local == "sec"
+		}
+	}
+}
+
+// binaryItem returns the part of a binary item, such as the picture an
+// hc:img refers to by binaryItemIDRef. Items are looked up in the manifest,
+// or by file name in BinData/ for packages without one.
+func (r *Reader) binaryItem(id string) (string, bool) {
+	if item, ok := r.manifest[id]; ok {
+		return item.Name, true
+	}
+	for _, file := range r.zipReader.File {
+		name := path.Base(file.Name)
+		if strings.HasPrefix(file.Name, "BinData/") && strings.TrimSuffix(name, path.Ext(name)) == id {
+			return file.Name, true
+		}
+	}
+	return "", false
+}
+
+// readPart returns the contents of a part, failing once more than maxSize
+// bytes have been read from it.
+func (r *Reader) readPart(name string, maxSize int64) ([]byte, error) {
+	file, err := r.zipReader.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(limits.NewReader(file, maxSize, name))
+}
+
+// masterPage returns the paragraphs of a master page part, given its
+// manifest item ID.
+func (r *Reader) masterPage(id string, maxSize int64) ([]ParagraphElement, error) {
+	name := r.manifest[id].Name
+	if name == "" {
+		var ok bool
+		if name, ok = r.resolve("", "Contents/"+id+".xml"); !ok {
+			return nil, fmt.Errorf("no part for item %q", id)
+		}
+	}
+	file, err := r.zipReader.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var page struct {
+		Paragraphs []ParagraphElement `xml:"subList>p"`
+	}
+	if err := newDecoder(limits.NewReader(file, maxSize, name)).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return page.Paragraphs, nil
+}
@@ -0,0 +1,116 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// PageDef is the page definition of a section, from the secPr and colPr
+// of its first paragraph. Lengths are in HWPUNITs (1/7200 inch).
+type PageDef struct {
+	// Width and Height are the paper size, before any turn to landscape.
+	Width, Height int32
+	Landscape     bool
+	// Margins between the paper edge and the body, header and footer, and
+	// the binding gutter
+	MarginLeft, MarginRight, MarginTop, MarginBottom int32
+	MarginHeader, MarginFooter, MarginGutter         int32
+	// Columns is the number of text columns, and ColumnGap the space
+	// between them when they share one.
+	Columns   int
+	ColumnGap int32
+}
+
+// pagePr is the page properties element of a secPr. Its landscape attribute
+// is WIDELY for portrait pages and NARROWLY for landscape ones.
+type pagePr struct {
+	Landscape string `xml:"landscape,attr"`
+	Width     int32  `xml:"width,attr"`
+	Height    int32  `xml:"height,attr"`
+	Margin    struct {
+		Left   int32 `xml:"left,attr"`
+		Right  int32 `xml:"right,attr"`
+		Top    int32 `xml:"top,attr"`
+		Bottom int32 `xml:"bottom,attr"`
+		Header int32 `xml:"header,attr"`
+		Footer int32 `xml:"footer,attr"`
+		Gutter int32 `xml:"gutter,attr"`
+	} `xml:"margin"`
+}
+
+type colPr struct {
+	ColCount int   `xml:"colCount,attr"`
+	SameGap  int32 `xml:"sameGap,attr"`
+}
+
+// PageDefs returns the page definition of each section, in section order.
+// Sections without a secPr get a PageDef of zero size.
+func (r *Reader) PageDefs(maxSize int64) ([]PageDef, error) {
+	defs := make([]PageDef, len(r.sections))
+	for i := range r.sections {
+		def, err := r.pageDef(i, maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.sections[i].name, err)
+		}
+		defs[i] = def
+	}
+	return defs, nil
+}
+
+// pageDef reads the page definition of section i. The secPr and colPr
+// belong to the first paragraph, so reading stops where it ends.
+func (r *Reader) pageDef(i int, maxSize int64) (PageDef, error) {
+	file, err := r.openSection(i, maxSize)
+	if err != nil {
+		return PageDef{}, err
+	}
+	defer file.Close()
+
+	def := PageDef{Columns: 1}
+	decoder := newDecoder(file)
+	depth, paragraphDepth := 0, 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return def, nil
+		}
+		if err != nil {
+			return def, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch t.Name.Local {
+			case "p":
+				if paragraphDepth == 0 {
+					paragraphDepth = depth
+				}
+			case "pagePr":
+				var page pagePr
+				if err := decoder.DecodeElement(&page, &t); err != nil {
+					return def, err
+				}
+				depth--
+				def.Width, def.Height = page.Width, page.Height
+				def.Landscape = page.Landscape == "NARROWLY"
+				m := page.Margin
+				def.MarginLeft, def.MarginRight, def.MarginTop, def.MarginBottom = m.Left, m.Right, m.Top, m.Bottom
+				def.MarginHeader, def.MarginFooter, def.MarginGutter = m.Header, m.Footer, m.Gutter
+			case "colPr":
+				var col colPr
+				if err := decoder.DecodeElement(&col, &t); err != nil {
+					return def, err
+				}
+				depth--
+				def.Columns, def.ColumnGap = max(col.ColCount, 1), col.SameGap
+			}
+		case xml.EndElement:
+			if depth == paragraphDepth {
+				return def, nil
+			}
+			depth--
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package hwpx
+
+import (
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Part kinds, which tell the parts of a package apart
+const (
+	PartSection    = "section"     // section XML
+	PartHeader     = "header"      // Contents/header.xml
+	PartMasterPage = "master-page" // master page (바탕쪽) XML
+	PartBinData    = "bindata"     // pictures, OLE objects and other binary items
+	PartChart      = "chart"       // chart XML
+	PartPreview    = "preview"     // preview text and image
+	PartSettings   = "settings"    // settings.xml
+	PartPackage    = "package"     // mimetype, version.xml, META-INF and the package file
+	PartOther      = "other"
+)
+
+// Part is an entry of the ZIP package. ID and MediaType come from the
+// manifest of the package file, and are empty for parts it does not list.
+type Part struct {
+	Name      string
+	ID        string
+	MediaType string
+	Kind      string
+	// Size is the uncompressed size of the part.
+	Size int64
+}
+
+// Parts lists every part of the package in ZIP order.
+func (r *Reader) Parts() []Part {
+	items := make(map[string]string, len(r.manifest))
+	for id, item := range r.manifest {
+		items[item.Name] = id
+	}
+	sections := make(map[string]bool, len(r.sections))
+	for _, s := range r.sections {
+		sections[s.name] = true
+	}
+
+	parts := make([]Part, 0, len(r.zipReader.File))
+	for _, file := range r.zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		id := items[file.Name]
+		parts = append(parts, Part{
+			Name:      file.Name,
+			ID:        id,
+			MediaType: r.manifest[id].MediaType,
+			Kind:      r.partKind(file.Name, id, sections),
+			Size:      int64(file.UncompressedSize64),
+		})
+	}
+	return parts
+}
+
+// partKind tells the kind of a part from its path, or for master pages
+// from their manifest item ID (masterpage0, ...).
+func (r *Reader) partKind(name, id string, sections map[string]bool) string {
+	dir, _, _ := strings.Cut(name, "/")
+	switch {
+	case sections[name]:
+		return PartSection
+	case name == headerPath:
+		return PartHeader
+	case strings.HasPrefix(strings.ToLower(id), "masterpage") ||
+		strings.HasPrefix(strings.ToLower(path.Base(name)), "masterpage"):
+		return PartMasterPage
+	case dir == "BinData":
+		return PartBinData
+	case dir == "Chart" || dir == "Charts":
+		return PartChart
+	case dir == "Preview":
+		return PartPreview
+	case slices.Contains(settingsPaths, name):
+		return PartSettings
+	case name == "mimetype" || name == "version.xml" || dir == "META-INF" || name == r.packagePath:
+		return PartPackage
+	}
+	return PartOther
+}
+
+// OpenPart opens a part of the package by its name, as listed by Parts.
+// Its data is uncompressed.
+func (r *Reader) OpenPart(name string) (io.ReadCloser, error) {
+	return r.zipReader.Open(name)
+}
@@ -0,0 +1,70 @@
+package hwpx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// previewPart is the part holding the plain text preview (미리 보기) that
+// Hangul writes when saving: the start of the document text, with tables
+// and objects reduced to their text.
+const previewPart = "Preview/PrvText.txt"
+
+// previewText returns the non-empty lines of the preview text. The preview
+// is UTF-8, or UTF-16LE when it starts with a byte order mark.
+func (r *Reader) previewText(maxSize int64) ([]string, error) {
+	data, err := r.readPart(previewPart, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	text := string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(data, []byte{0xff, 0xfe}) {
+		units := make([]uint16, (len(data)-2)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(data[2+2*i:])
+		}
+		text = string(utf16.Decode(units))
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// fallBack replaces the rest of the document with the lines of its preview
+// text as paragraphs when ScanOptions.PreviewFallback is set, reporting
+// the section error as a warning. It returns err when the fallback is off,
+// the preview cannot be read, or err is a resource limit, which the preview
+// must not get around.
+func (s *ContentScanner) fallBack(err error) (document.ContentNode, error) {
+	if !s.opts.PreviewFallback || s.reader == nil || errors.Is(err, limits.ErrExceeded) {
+		return nil, err
+	}
+	lines, previewErr := s.reader.previewText(s.opts.MaxStreamSize)
+	if previewErr != nil {
+		return nil, err
+	}
+	s.opts.Warn(fmt.Errorf("section %d and the rest of the document read from %s: %w", s.section, previewPart, err))
+
+	s.Close()
+	s.closer, s.previewed = nil, true
+	for i, line := range lines {
+		s.pending = append(s.pending, &document.Paragraph{ID: fmt.Sprintf("preview.p%d", i), Text: line})
+	}
+	if len(s.pending) == 0 {
+		return nil, io.EOF
+	}
+	return s.Next()
+}
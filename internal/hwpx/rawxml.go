@@ -0,0 +1,365 @@
+package hwpx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// RawXMLOptions controls WriteRawXML.
+type RawXMLOptions struct {
+	// Indent pretty-prints the XML. Text inside hp:t elements and elements
+	// marked xml:space="preserve" is kept as is.
+	Indent bool
+	// NormalizeNamespaces rewrites element and attribute prefixes to the
+	// conventional OWPML ones (hp, hs, hc, ...), declared once on the root
+	// element, whatever prefixes the producing application chose.
+	NormalizeNamespaces bool
+	// MaxStreamSize caps the decompressed size of each section part.
+	// Zero means no limit.
+	MaxStreamSize int64
+}
+
+// owpmlNamespaces maps OWPML namespace URIs to their conventional prefixes,
+// in declaration order.
+var owpmlNamespaces = []struct{ prefix, uri string }{
+	{"ha", "http://www.hancom.co.kr/hwpml/2011/app"},
+	{"hp", "http://www.hancom.co.kr/hwpml/2011/paragraph"},
+	{"hp10", "http://www.hancom.co.kr/hwpml/2016/paragraph"},
+	{"hs", "http://www.hancom.co.kr/hwpml/2011/section"},
+	{"hc", "http://www.hancom.co.kr/hwpml/2011/core"},
+	{"hh", "http://www.hancom.co.kr/hwpml/2011/head"},
+	{"hhs", "http://www.hancom.co.kr/hwpml/2011/history"},
+	{"hm", "http://www.hancom.co.kr/hwpml/2011/master-page"},
+	{"hpf", "http://www.hancom.co.kr/schema/2011/hpf"},
+	{"dc", "http://purl.org/dc/elements/1.1/"},
+	{"opf", "http://www.idpf.org/2007/opf/"},
+	{"ooxmlchart", "http://www.hancom.co.kr/hwpml/2016/ooxmlchart"},
+	{"hwpunitchar", "http://www.hancom.co.kr/hwpml/2016/HwpUnitChar"},
+	{"epub", "http://www.idpf.org/2007/ops"},
+	{"config", "urn:oasis:names:tc:opendocument:xmlns:config:1.0"},
+}
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// rawXMLRoot is the element wrapping the concatenated sections.
+const rawXMLRoot = "sections"
+
+// WriteRawXML writes the section parts of the document, in section order,
+// as one XML document whose root element wraps the sections' root elements.
+// Without options the section XML is copied byte for byte apart from its
+// XML declaration.
+func (r *Reader) WriteRawXML(w io.Writer, opts RawXMLOptions) error {
+	var root bytes.Buffer
+	root.WriteString(xml.Header + "<" + rawXMLRoot)
+	if opts.NormalizeNamespaces {
+		for _, ns := range owpmlNamespaces {
+			fmt.Fprintf(&root, ` xmlns:%s="%s"`, ns.prefix, ns.uri)
+		}
+	}
+	root.WriteString(">\n")
+	if _, err := w.Write(root.Bytes()); err != nil {
+		return err
+	}
+
+	enc := newRawXMLEncoder(w, opts)
+	for _, section := range r.sections {
+		file, err := r.zipReader.Open(section.name)
+		if err != nil {
+			return fmt.Errorf("failed to open section file: %w", err)
+		}
+		err = enc.copySection(limits.NewReader(file, opts.MaxStreamSize, section.name))
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", section.name, err)
+		}
+	}
+
+	_, err := io.WriteString(w, "</"+rawXMLRoot+">\n")
+	return err
+}
+
+// sortSections orders section parts by their number, so that section10
+// follows section9.
+func sortSections(sections []*Section) {
+	sort.SliceStable(sections, func(i, j int) bool {
+		return sectionNumber(sections[i].name) < sectionNumber(sections[j].name)
+	})
+}
+
+// sectionNumber returns the number in a "Contents/sectionN.xml" part name.
+func sectionNumber(name string) int {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "Contents/section"), ".xml")
+	n, err := strconv.Atoi(name)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// rawXMLEncoder re-serializes section XML token by token.
+type rawXMLEncoder struct {
+	w    io.Writer
+	opts RawXMLOptions
+
+	// prefixes maps namespace URIs to output prefixes when normalizing
+	prefixes map[string]string
+	// scopes holds the URIs declared on each open element
+	scopes [][]string
+
+	levels []rawXMLLevel
+	// space holds whitespace seen since the last markup, which indenting
+	// may replace
+	space string
+	// openTag is set while the last start tag still lacks its closing '>'
+	openTag bool
+}
+
+type rawXMLLevel struct {
+	preserve bool // keep content as is
+	children bool // has child elements
+	text     bool // has non-whitespace text
+}
+
+func newRawXMLEncoder(w io.Writer, opts RawXMLOptions) *rawXMLEncoder {
+	e := &rawXMLEncoder{w: w, opts: opts, prefixes: make(map[string]string)}
+	for _, ns := range owpmlNamespaces {
+		e.prefixes[ns.uri] = ns.prefix
+	}
+	return e
+}
+
+// copySection writes the root element of one section part.
+func (e *rawXMLEncoder) copySection(r io.Reader) error {
+	if !e.opts.Indent && !e.opts.NormalizeNamespaces {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if _, err := e.w.Write(stripXMLDeclaration(data)); err != nil {
+			return err
+		}
+		_, err = io.WriteString(e.w, "\n")
+		return err
+	}
+
+	var buf bytes.Buffer
+	e.levels = []rawXMLLevel{{}}
+	e.space = ""
+	dec := xml.NewDecoder(r)
+	for {
+		var tok xml.Token
+		var err error
+		if e.opts.NormalizeNamespaces {
+			tok, err = dec.Token()
+		} else {
+			tok, err = dec.RawToken()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e.startElement(&buf, t)
+		case xml.EndElement:
+			e.endElement(&buf, t)
+		case xml.CharData:
+			e.charData(&buf, string(t))
+		case xml.Comment:
+			e.markup(&buf)
+			buf.WriteString("<!--" + string(t) + "-->")
+		case xml.ProcInst:
+			if t.Target != "xml" {
+				e.markup(&buf)
+				buf.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+			}
+		}
+
+		if buf.Len() >= rawXMLFlushSize {
+			if _, err := e.w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+	buf.WriteString("\n")
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// rawXMLFlushSize is how much output is buffered before it is written.
+const rawXMLFlushSize = 32 * 1024
+
+func (e *rawXMLEncoder) top() *rawXMLLevel {
+	return &e.levels[len(e.levels)-1]
+}
+
+// closeTag completes a pending start tag.
+func (e *rawXMLEncoder) closeTag(buf *bytes.Buffer) {
+	if e.openTag {
+		buf.WriteByte('>')
+		e.openTag = false
+	}
+}
+
+// markup prepares for a child node: pending whitespace is replaced by
+// indentation, or kept where whitespace is significant.
+func (e *rawXMLEncoder) markup(buf *bytes.Buffer) {
+	e.closeTag(buf)
+	level := e.top()
+	if e.opts.Indent && !level.preserve && !level.text {
+		buf.WriteString("\n" + strings.Repeat("  ", len(e.levels)-1))
+	} else {
+		buf.WriteString(escapeXMLText(e.space))
+	}
+	e.space = ""
+}
+
+func (e *rawXMLEncoder) startElement(buf *bytes.Buffer, t xml.StartElement) {
+	parent := e.top()
+	if len(e.levels) > 1 {
+		e.markup(buf)
+	} else {
+		e.closeTag(buf)
+		e.space = ""
+	}
+	parent.children = true
+
+	level := rawXMLLevel{preserve: parent.preserve || t.Name.Local == "t"}
+	var declared []string
+	var attrs []xml.Attr
+	for _, a := range t.Attr {
+		if e.opts.NormalizeNamespaces && (a.Name.Space == "xmlns" || a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		if (a.Name.Space == "xml" || a.Name.Space == xmlNamespace) && a.Name.Local == "space" {
+			level.preserve = a.Value == "preserve"
+		}
+		attrs = append(attrs, a)
+	}
+
+	buf.WriteString("<" + e.name(t.Name, &declared))
+	var attrText bytes.Buffer
+	for _, a := range attrs {
+		attrText.WriteString(" " + e.name(a.Name, &declared) + `="` + escapeXMLAttr(a.Value) + `"`)
+	}
+	for _, uri := range declared {
+		fmt.Fprintf(buf, ` xmlns:%s="%s"`, e.prefixes[uri], escapeXMLAttr(uri))
+	}
+	buf.Write(attrText.Bytes())
+	e.openTag = true
+
+	e.scopes = append(e.scopes, declared)
+	e.levels = append(e.levels, level)
+}
+
+func (e *rawXMLEncoder) endElement(buf *bytes.Buffer, t xml.EndElement) {
+	level := e.top()
+	e.levels = e.levels[:len(e.levels)-1]
+	e.scopes = e.scopes[:len(e.scopes)-1]
+
+	if e.openTag && (e.space == "" || e.opts.Indent && !level.preserve) {
+		buf.WriteString("/>")
+		e.openTag = false
+		e.space = ""
+		return
+	}
+	e.closeTag(buf)
+	if e.opts.Indent && !level.preserve && !level.text {
+		if level.children {
+			buf.WriteString("\n" + strings.Repeat("  ", len(e.levels)-1))
+		}
+	} else {
+		buf.WriteString(escapeXMLText(e.space))
+	}
+	e.space = ""
+
+	var declared []string
+	buf.WriteString("</" + e.name(t.Name, &declared) + ">")
+}
+
+func (e *rawXMLEncoder) charData(buf *bytes.Buffer, text string) {
+	if strings.TrimSpace(text) == "" {
+		e.space += text
+		return
+	}
+	e.closeTag(buf)
+	e.top().text = true
+	buf.WriteString(escapeXMLText(e.space + text))
+	e.space = ""
+}
+
+// name formats an element or attribute name. Raw tokens carry their source
+// prefix; when normalizing, tokens carry the namespace URI, which is mapped
+// to its prefix and added to declared unless already in scope.
+func (e *rawXMLEncoder) name(n xml.Name, declared *[]string) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	if !e.opts.NormalizeNamespaces {
+		return n.Space + ":" + n.Local
+	}
+	if n.Space == xmlNamespace || n.Space == "xml" {
+		return "xml:" + n.Local
+	}
+
+	prefix, known := e.prefixes[n.Space]
+	if !known {
+		prefix = fmt.Sprintf("ns%d", len(e.prefixes)-len(owpmlNamespaces)+1)
+		e.prefixes[n.Space] = prefix
+	}
+	if !e.inScope(n.Space) {
+		*declared = append(*declared, n.Space)
+	}
+	return prefix + ":" + n.Local
+}
+
+// inScope reports whether a namespace is declared on the root element or an
+// open element.
+func (e *rawXMLEncoder) inScope(uri string) bool {
+	for _, ns := range owpmlNamespaces {
+		if ns.uri == uri {
+			return true
+		}
+	}
+	for _, scope := range e.scopes {
+		for _, declared := range scope {
+			if declared == uri {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripXMLDeclaration removes a leading byte order mark and XML declaration.
+func stripXMLDeclaration(data []byte) []byte {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) {
+		if end := bytes.Index(trimmed, []byte("?>")); end >= 0 {
+			return bytes.TrimLeft(trimmed[end+2:], " \t\r\n")
+		}
+	}
+	return data
+}
+
+var (
+	xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	xmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+		"\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+)
+
+func escapeXMLText(s string) string { return xmlTextEscaper.Replace(s) }
+
+func escapeXMLAttr(s string) string { return xmlAttrEscaper.Replace(s) }
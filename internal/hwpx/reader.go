@@ -3,11 +3,13 @@ package hwpx
 import (
 	"archive/zip"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
 	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/limits"
 )
 
 // Reader provides access to HWPX document content
@@ -15,6 +17,12 @@ type Reader struct {
 	zipReader *zip.Reader
 	version   Version
 	sections  []*Section
+	// packagePath is the path of the package file (content.hpf), whose
+	// manifest holds the parts by ID and whose spine lists part IDs in
+	// reading order
+	packagePath string
+	manifest    map[string]ManifestItem
+	spine       []string
 }
 
 // Version represents the HWPX format version
@@ -26,6 +34,50 @@ type Version struct {
 	XMLVersion  string
 }
 
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Micro, v.BuildNumber)
+}
+
+// Format versions the scanner reads: packages of another major version are
+// rejected, and those of a newer minor version read with a warning.
+const (
+	supportedMajorVersion = 5
+	latestMinorVersion    = 1
+)
+
+// ErrUnsupportedVersion is matched (via errors.Is) by *VersionError.
+var ErrUnsupportedVersion = errors.New("unsupported HWPX format version")
+
+// VersionError is returned for a package whose version.xml names a format
+// version the scanner cannot read.
+type VersionError struct {
+	Version Version
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("HWPX format version %s is not supported (only %d.x is); save the document in Hangul as .hwp or in an older HWPX format",
+		e.Version, supportedMajorVersion)
+}
+
+func (e *VersionError) Is(target error) bool { return target == ErrUnsupportedVersion }
+
+// checkVersion returns a *VersionError for a package of another major
+// version, and reports a newer minor version as a warning, since the
+// content it adds may be missing from the output. A version.xml without a
+// version is taken to be of the supported one.
+func (r *Reader) checkVersion(opts document.ScanOptions) error {
+	v := r.version
+	switch {
+	case v.Major == 0:
+	case v.Major != supportedMajorVersion:
+		return &VersionError{Version: v}
+	case v.Minor > latestMinorVersion:
+		opts.Warn(fmt.Errorf("HWPX format version %s is newer than %d.%d; content added in it may be missing",
+			v, supportedMajorVersion, latestMinorVersion))
+	}
+	return nil
+}
+
 // Section represents a section XML file in the HWPX document
 type Section struct {
 	name   string
@@ -43,6 +95,10 @@ func Open(r io.ReaderAt, size int64) (*Reader, error) {
 		zipReader: zipReader,
 	}
 
+	if err := reader.checkEncryption(); err != nil {
+		return nil, err
+	}
+
 	if err := reader.validateMimetype(); err != nil {
 		return nil, err
 	}
@@ -51,6 +107,8 @@ func Open(r io.ReaderAt, size int64) (*Reader, error) {
 		return nil, err
 	}
 
+	reader.findPackage()
+	reader.loadPackage()
 	if err := reader.loadSections(); err != nil {
 		return nil, err
 	}
@@ -58,6 +116,11 @@ func Open(r io.ReaderAt, size int64) (*Reader, error) {
 	return reader, nil
 }
 
+// Version returns the format version parsed from version.xml.
+func (r *Reader) Version() Version {
+	return r.version
+}
+
 func (r *Reader) validateMimetype() error {
 	file, err := r.zipReader.Open("mimetype")
 	if err != nil {
@@ -71,13 +134,21 @@ func (r *Reader) validateMimetype() error {
 	}
 
 	mimetype := string(data)
-	if mimetype != "application/hwp+zip" {
+	if !isHWPXMimetype(mimetype) {
 		return fmt.Errorf("invalid mimetype: expected 'application/hwp+zip', got '%s'", mimetype)
 	}
 
 	return nil
 }
 
+// isHWPXMimetype reports whether a package mimetype is that of an HWPX
+// document, application/hwp+zip, or of a variant sharing its container,
+// such as a template (.hwtx): an application/hwp... type with a +zip
+// suffix.
+func isHWPXMimetype(mimetype string) bool {
+	return strings.HasPrefix(mimetype, "application/hwp") && strings.HasSuffix(mimetype, "+zip")
+}
+
 func (r *Reader) parseVersion() error {
 	file, err := r.zipReader.Open("version.xml")
 	if err != nil {
@@ -110,8 +181,12 @@ func (r *Reader) parseVersion() error {
 	return nil
 }
 
+// loadSections lists the sections in the order of the package spine, or,
+// for packages without one, the Contents/sectionN.xml parts by number.
 func (r *Reader) loadSections() error {
-	r.sections = make([]*Section, 0)
+	if r.sections = r.spineSections(); len(r.sections) > 0 {
+		return nil
+	}
 
 	for _, file := range r.zipReader.File {
 		if strings.HasPrefix(file.Name, "Contents/section") && strings.HasSuffix(file.Name, ".xml") {
@@ -124,21 +199,55 @@ func (r *Reader) loadSections() error {
 	if len(r.sections) == 0 {
 		return fmt.Errorf("no section files found in Contents/")
 	}
+	sortSections(r.sections)
 
 	return nil
 }
 
-// NewContentScanner creates a ContentNodeScanner for the HWPX document
-func (r *Reader) NewContentScanner() (document.ContentNodeScanner, error) {
+// NewContentScanner creates a ContentNodeScanner for the HWPX document. It
+// reads the sections in order, opening each as the previous one ends.
+func (r *Reader) NewContentScanner(opts document.ScanOptions) (document.ContentNodeScanner, error) {
 	if len(r.sections) == 0 {
 		return nil, fmt.Errorf("no sections available")
 	}
+	if err := r.checkVersion(opts); err != nil {
+		return nil, err
+	}
 
-	// Open the first section file
-	file, err := r.zipReader.Open(r.sections[0].name)
+	header, err := r.Header()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open section file: %w", err)
+		return nil, err
 	}
 
-	return NewContentScanner(file)
+	scanner := &ContentScanner{
+		reader:        r,
+		opts:          opts,
+		section:       -1,
+		monospace:     header.monospaceCharPrs(),
+		changes:       header.changes(),
+		layouts:       header.layouts(),
+		outlines:      header.outlineLevels(),
+		runStyles:     header.runStyles(),
+		shapeOutlines: header.shapeOutlineLevels(),
+		lists:         header.lists(),
+		borders:       header.cellBorders(),
+	}
+	if err := scanner.advanceSection(); err != nil {
+		return nil, err
+	}
+	return scanner, nil
+}
+
+// openSection opens the XML of section i, failing once more than maxSize
+// bytes have been read from it.
+func (r *Reader) openSection(i int, maxSize int64) (io.ReadCloser, error) {
+	name := r.sections[i].name
+	file, err := r.zipReader.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{limits.NewReader(file, maxSize, name), file}, nil
 }
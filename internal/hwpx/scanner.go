@@ -4,42 +4,98 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"path"
+	"strconv"
 	"strings"
 
 	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/equation"
+	"github.com/hanpama/hwp/internal/hwpv5"
+	"github.com/hanpama/hwp/internal/limits"
 )
 
 // ContentScanner parses HWPX section XML and emits content nodes
 type ContentScanner struct {
 	decoder *xml.Decoder
 	closer  io.Closer
+	opts    document.ScanOptions
+	// reader opens the following sections; nil when scanning a single
+	// section XML
+	reader *Reader
+	// monospace holds the IDs of character shapes with a fixed-pitch font
+	monospace map[string]bool
+	// changes holds the author and date of tracked changes by ID
+	changes map[string]document.Change
+	// layouts holds the layouts of paragraph shapes, outlines the levels of
+	// outline styles, and shapeOutlines those of paragraph shapes with
+	// outline numbering, by ID
+	layouts       map[string]*document.Layout
+	outlines      map[string]int
+	shapeOutlines map[string]int
+	// lists numbers the items of numbered and bulleted lists
+	lists *lists
+	// runStyles holds the formatting of character shapes by ID
+	runStyles map[string]document.Run
+	// borders holds the sides with a line of border fills by ID
+	borders map[string]*document.CellBorders
+
+	// Section index and counts of top-level elements, used for node IDs
+	section    int
+	paraCount  int
+	tableCount int
+	// footnotes and endnotes count the notes so far, which number notes
+	// without a number of their own
+	footnotes, endnotes int
+
+	// pending holds nodes decoded along with the last returned one, such
+	// as the equations of a paragraph
+	pending []document.ContentNode
+	// previewed is set once the preview text has replaced the rest of the
+	// document, see fallBack
+	previewed bool
 }
 
-// NewContentScanner creates a new ContentScanner from a section XML reader
-func NewContentScanner(r io.ReadCloser) (*ContentScanner, error) {
-	decoder := xml.NewDecoder(r)
+// NewContentScanner creates a new ContentScanner from a single section XML
+// reader
+func NewContentScanner(r io.ReadCloser, opts document.ScanOptions) (*ContentScanner, error) {
+	decoder := newDecoder(r)
 	return &ContentScanner{
 		decoder: decoder,
 		closer:  r,
+		opts:    opts,
 	}, nil
 }
 
 // Next returns the next content node from the document
 func (s *ContentScanner) Next() (document.ContentNode, error) {
+	if len(s.pending) > 0 {
+		node := s.pending[0]
+		s.pending = s.pending[1:]
+		return node, nil
+	}
+	if s.previewed {
+		return nil, io.EOF
+	}
 	for {
 		token, err := s.decoder.Token()
 		if err == io.EOF {
-			return nil, io.EOF
+			if err := s.advanceSection(); err != nil {
+				return nil, err
+			}
+			if s.opts.Breaks {
+				return &document.SectionBreak{ID: fmt.Sprintf("s%d", s.section), Section: s.section}, nil
+			}
+			continue
 		}
 		if err != nil {
-			return nil, fmt.Errorf("XML parse error: %w", err)
+			return s.fallBack(fmt.Errorf("XML parse error: %w", err))
 		}
 
 		switch elem := token.(type) {
 		case xml.StartElement:
 			node, err := s.handleStartElement(elem)
 			if err != nil {
-				return nil, err
+				return s.fallBack(err)
 			}
 			if node != nil {
 				return node, nil
@@ -48,77 +104,350 @@ func (s *ContentScanner) Next() (document.ContentNode, error) {
 	}
 }
 
+// advanceSection closes the current section and opens the next one,
+// returning io.EOF after the last.
+func (s *ContentScanner) advanceSection() error {
+	if s.closer != nil {
+		s.closer.Close()
+		s.closer = nil
+	}
+	if s.reader == nil || s.section+1 >= len(s.reader.sections) {
+		return io.EOF
+	}
+
+	s.section++
+	file, err := s.reader.openSection(s.section, s.opts.MaxStreamSize)
+	if err != nil {
+		err = fmt.Errorf("failed to open section %d: %w", s.section, err)
+		if s.opts.Recover {
+			s.opts.Warn(fmt.Errorf("skipped section: %w", err))
+			return s.advanceSection()
+		}
+		return err
+	}
+
+	s.decoder = newDecoder(file)
+	s.closer = file
+	s.paraCount, s.tableCount = 0, 0
+	return nil
+}
+
 func (s *ContentScanner) handleStartElement(elem xml.StartElement) (document.ContentNode, error) {
 	localName := elem.Name.Local
 
 	switch localName {
 	case "p":
-		return s.parseParagraph(elem)
+		s.paraCount++
+		node, err := s.parseParagraph(elem)
+		// A page break at the start of a section goes with the section
+		if brk := attr(elem, "pageBreak"); err != nil || !s.opts.Breaks || s.paraCount == 1 || brk != "1" && brk != "true" {
+			return node, err
+		}
+		if node != nil {
+			s.pending = append([]document.ContentNode{node}, s.pending...)
+		}
+		return &document.PageBreak{ID: fmt.Sprintf("s%d.p%d.pb", s.section, s.paraCount-1)}, nil
 	case "tbl":
+		s.tableCount++
 		return s.parseTable(elem)
 	}
 
 	return nil, nil
 }
 
-// parseParagraph parses <hp:p> element into a Paragraph node or Table node
+// parseParagraph parses <hp:p> element into a Paragraph node or Table node.
+// Equations and videos in the paragraph follow it.
 func (s *ContentScanner) parseParagraph(elem xml.StartElement) (document.ContentNode, error) {
-	var para ParagraphElement
-	if err := s.decoder.DecodeElement(&para, &elem); err != nil {
+	id := fmt.Sprintf("s%d.p%d", s.section, s.paraCount-1)
+	para, table, hasTable, err := s.decodeParagraph(elem, id)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode paragraph: %w", err)
 	}
-
-	// Check if this paragraph contains a table
-	for _, run := range para.Runs {
-		if run.Table != nil {
-			return s.parseTableElement(run.Table)
-		}
+	// A paragraph holding a table stands for the table
+	if hasTable {
+		return table, nil
 	}
+	s.applyChanges(para)
+	setPageNumbers(para, s.opts.PageNumber)
 
+	var nodes []document.ContentNode
 	text := para.extractText()
-	if text == "" {
+	bookmarks := para.bookmarks()
+	fields := para.fields()
+	if text != "" || len(bookmarks) > 0 || len(fields) > 0 {
+		p := &document.Paragraph{
+			ID:           id,
+			Text:         text,
+			Preformatted: s.isMonospace(para),
+			Bookmarks:    bookmarks,
+			Fields:       fields,
+			Hyperlinks:   para.hyperlinks(),
+			Runs:         para.runs(s.runStyles),
+			Changes:      para.changes(s.changes),
+			Layout:       s.layouts[para.ParaPrIDRef],
+			List:         s.lists.item(para.ParaPrIDRef),
+		}
+		level := s.outlines[para.StyleIDRef]
+		if level == 0 {
+			level = s.shapeOutlines[para.ParaPrIDRef]
+		}
+		if level > 0 && strings.TrimSpace(text) != "" {
+			nodes = append(nodes, &document.Heading{Paragraph: *p, Level: level})
+		} else {
+			nodes = append(nodes, p)
+		}
+	}
+	nodes = append(nodes, s.objects(para, id)...)
+	nodes = append(nodes, s.notes(para, id)...)
+	nodes = append(nodes, para.comments(id)...)
+	nodes = append(nodes, s.pageTexts(para, id)...)
+	if s.opts.HiddenText {
+		nodes = append(nodes, para.hiddenComments(id)...)
+	}
+	if len(nodes) == 0 {
 		return nil, nil
 	}
+	s.pending = append(s.pending, nodes[1:]...)
+	return nodes[0], nil
+}
 
-	return &document.Paragraph{
-		Text: text,
-	}, nil
+// decodeParagraph decodes the <hp:p> element started by elem as
+// DecodeElement would, except that the first table in its runs is parsed
+// as it is read into a Table node with the given paragraph ID, rather than
+// kept in the run. hasTable reports whether there was one; the table is
+// nil if it has no rows or columns. Further tables are skipped.
+func (s *ContentScanner) decodeParagraph(elem xml.StartElement, id string) (para *ParagraphElement, table document.ContentNode, hasTable bool, err error) {
+	para = &ParagraphElement{
+		XMLName:     elem.Name,
+		ID:          attr(elem, "id"),
+		ParaPrIDRef: attr(elem, "paraPrIDRef"),
+		StyleIDRef:  attr(elem, "styleIDRef"),
+	}
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return nil, nil, false, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "run" {
+				if err := s.decoder.Skip(); err != nil {
+					return nil, nil, false, err
+				}
+				continue
+			}
+			run := Run{XMLName: t.Name, CharPrIDRef: attr(t, "charPrIDRef")}
+			if err := s.decodeRun(&run, id, &table, &hasTable); err != nil {
+				return nil, nil, false, err
+			}
+			para.Runs = append(para.Runs, run)
+		case xml.EndElement:
+			return para, table, hasTable, nil
+		}
+	}
+}
+
+// decodeRun decodes the children of a run up to its end element, parsing
+// the first table of the paragraph into *table.
+func (s *ContentScanner) decodeRun(run *Run, id string, table *document.ContentNode, hasTable *bool) error {
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "tbl" {
+				if *hasTable {
+					if err := s.decoder.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				*hasTable = true
+				if *table, err = s.streamTable(t, id+".t0"); err != nil {
+					return err
+				}
+				continue
+			}
+			var child RunChild
+			if err := s.decoder.DecodeElement(&child, &t); err != nil {
+				return err
+			}
+			run.Children = append(run.Children, child)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// chart returns the Chart node of a chart object, or nil if its chart part
+// cannot be read.
+func (s *ContentScanner) chart(c *RunChild, caption string) *document.Chart {
+	if s.reader == nil {
+		return nil
+	}
+	chart, err := s.reader.chart(c.ChartIDRef, s.opts.MaxStreamSize)
+	if err != nil {
+		s.opts.Warn(fmt.Errorf("chart shown as an image: %w", err))
+		return nil
+	}
+	chart.Caption = caption
+	return chart
+}
+
+// pictureData sets the file name and format of a picture from the binary
+// item it shows, and the reading of its bytes.
+func (s *ContentScanner) pictureData(img *document.Image, itemID string) {
+	if s.reader == nil {
+		return
+	}
+	name, ok := s.reader.binaryItem(itemID)
+	if !ok {
+		return
+	}
+	img.Filename = path.Base(name)
+	img.Format = strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	reader, maxSize := s.reader, s.opts.MaxStreamSize
+	img.SetData(func() ([]byte, error) {
+		return reader.readPart(name, maxSize)
+	})
+}
+
+// oleData sets the file name, class and bytes of an OLE object from the
+// binary item holding it, which is read for the class.
+func (s *ContentScanner) oleData(img *document.Image, itemID string) {
+	if s.reader == nil {
+		return
+	}
+	name, ok := s.reader.binaryItem(itemID)
+	if !ok {
+		return
+	}
+	img.Filename, img.Format = path.Base(name), document.OLEFormat
+	data, err := s.reader.readPart(name, s.opts.MaxStreamSize)
+	if err != nil {
+		// Unreadable data leaves a placeholder without class or bytes
+		return
+	}
+	img.Class = oleClass(data)
+	img.SetData(func() ([]byte, error) { return data, nil })
+}
+
+// isMonospace reports whether every run with text uses a fixed-pitch font.
+func (s *ContentScanner) isMonospace(p *ParagraphElement) bool {
+	found := false
+	for _, run := range p.Runs {
+		if run.extractText() == "" {
+			continue
+		}
+		if !s.monospace[run.CharPrIDRef] {
+			return false
+		}
+		found = true
+	}
+	return found
 }
 
 // parseTable parses <hp:tbl> element into a Table node
 func (s *ContentScanner) parseTable(elem xml.StartElement) (document.ContentNode, error) {
-	var tbl TableElement
-	if err := s.decoder.DecodeElement(&tbl, &elem); err != nil {
+	table, err := s.streamTable(elem, fmt.Sprintf("s%d.t%d", s.section, s.tableCount-1))
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode table: %w", err)
 	}
-
-	return s.parseTableElement(&tbl)
+	return table, nil
 }
 
-func (s *ContentScanner) parseTableElement(tbl *TableElement) (document.ContentNode, error) {
-	rowCount := tbl.RowCnt
-	colCount := tbl.ColCnt
-
+// streamTable parses the <hp:tbl> element started by elem into a Table
+// node, or nil if it has no rows or columns. Rows are read token by token
+// and only one cell is decoded at a time, so that the memory a large table
+// takes is that of its cells' text. With ScanOptions.OnTableRow, each row
+// is passed on as it is read and not kept in the node.
+func (s *ContentScanner) streamTable(elem xml.StartElement, id string) (document.ContentNode, error) {
+	rowCount, _ := strconv.Atoi(attr(elem, "rowCnt"))
+	colCount, _ := strconv.Atoi(attr(elem, "colCnt"))
 	if rowCount == 0 || colCount == 0 {
-		return nil, nil
+		return nil, s.decoder.Skip()
+	}
+	if err := limits.Check("table "+id, int64(rowCount)*int64(colCount), s.opts.MaxTableCells, "cells"); err != nil {
+		return nil, err
 	}
 
 	table := &document.Table{
+		ID:    id,
 		Rows:  rowCount,
 		Cols:  colCount,
 		Cells: make([]document.Cell, 0),
 	}
+	// RepeatHeader is set when the first row repeats on each page
+	table.HeaderRow, _ = strconv.ParseBool(attr(elem, "repeatHeader"))
 
-	for _, tr := range tbl.Rows {
-		for _, tc := range tr.Cells {
-			cell := s.parseCell(tc)
-			if cell != nil {
-				table.Cells = append(table.Cells, *cell)
+	rows := 0
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "caption":
+				var caption Caption
+				if err := s.decoder.DecodeElement(&caption, &t); err != nil {
+					return nil, err
+				}
+				s.applySubListChanges(&caption.SubList)
+				setSubListPageNumbers(&caption.SubList, s.opts.PageNumber)
+				table.Caption = caption.text()
+			case "tr":
+				cells, err := s.streamRow()
+				if err != nil {
+					return nil, err
+				}
+				if s.opts.OnTableRow != nil {
+					s.opts.OnTableRow(document.TableRow{Table: id, Row: rows, Cells: cells})
+				} else {
+					table.Cells = append(table.Cells, cells...)
+				}
+				rows++
+			default:
+				if err := s.decoder.Skip(); err != nil {
+					return nil, err
+				}
 			}
+		case xml.EndElement:
+			return table, nil
 		}
 	}
+}
 
-	return table, nil
+// streamRow parses the cells of a <hp:tr> element up to its end element.
+func (s *ContentScanner) streamRow() ([]document.Cell, error) {
+	var cells []document.Cell
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "tc" {
+				if err := s.decoder.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			var tc TableCell
+			if err := s.decoder.DecodeElement(&tc, &t); err != nil {
+				return nil, err
+			}
+			s.applySubListChanges(&tc.SubList)
+			setSubListPageNumbers(&tc.SubList, s.opts.PageNumber)
+			cells = append(cells, *s.parseCell(tc))
+		case xml.EndElement:
+			return cells, nil
+		}
+	}
 }
 
 func (s *ContentScanner) parseCell(tc TableCell) *document.Cell {
@@ -135,14 +464,34 @@ func (s *ContentScanner) parseCell(tc TableCell) *document.Cell {
 	}
 
 	var textParts []string
+	var fields []document.Field
+	var changes []document.Change
+	align, offset := "", 0
 	for _, p := range tc.SubList.Paragraphs {
 		text := p.extractText()
 		if text != "" {
+			if len(textParts) > 0 {
+				offset += len(s.opts.CellParagraphSeparator)
+			}
+			for _, change := range p.changes(s.changes) {
+				change.Offset += offset
+				changes = append(changes, change)
+			}
+			offset += len(text)
+			if len(textParts) == 0 {
+				// The cell takes the alignment of its first paragraph with
+				// text
+				if layout := s.layouts[p.ParaPrIDRef]; layout != nil &&
+					layout.Align != document.AlignJustify && layout.Align != document.AlignLeft {
+					align = layout.Align
+				}
+			}
 			textParts = append(textParts, text)
 		}
+		fields = append(fields, p.fields()...)
 	}
 
-	cellText := strings.Join(textParts, "\n")
+	cellText := strings.Join(textParts, s.opts.CellParagraphSeparator)
 
 	return &document.Cell{
 		Row:     row,
@@ -150,6 +499,13 @@ func (s *ContentScanner) parseCell(tc TableCell) *document.Cell {
 		RowSpan: rowSpan,
 		ColSpan: colSpan,
 		Text:    cellText,
+		Fields:  fields,
+		Width:   float64(tc.CellSz.Width) / 100,
+		Height:  float64(tc.CellSz.Height) / 100,
+		Align:   align,
+		VAlign:  hwpxVAligns[tc.SubList.VertAlign],
+		Borders: s.borders[tc.BorderFillIDRef],
+		Changes: changes,
 	}
 }
 
@@ -164,9 +520,11 @@ func (s *ContentScanner) Close() error {
 // XML element structures with proper namespace handling
 
 type ParagraphElement struct {
-	XMLName xml.Name `xml:"p"`
-	ID      string   `xml:"id,attr"`
-	Runs    []Run    `xml:"run"`
+	XMLName     xml.Name `xml:"p"`
+	ID          string   `xml:"id,attr"`
+	ParaPrIDRef string   `xml:"paraPrIDRef,attr"`
+	StyleIDRef  string   `xml:"styleIDRef,attr"`
+	Runs        []Run    `xml:"run"`
 }
 
 func (p *ParagraphElement) extractText() string {
@@ -180,39 +538,639 @@ func (p *ParagraphElement) extractText() string {
 	return strings.Join(parts, "")
 }
 
+// runs returns the formatting of the paragraph text as consecutive spans,
+// merging runs of the same formatting, or nil if none has emphasis.
+func (p *ParagraphElement) runs(styles map[string]document.Run) []document.Run {
+	var runs []document.Run
+	offset, emphasis := 0, false
+	for _, run := range p.Runs {
+		text := run.extractText()
+		if text == "" {
+			continue
+		}
+		r := styles[run.CharPrIDRef]
+		r.Offset, r.Text = offset, text
+		offset += len(text)
+		emphasis = emphasis || r.Emphasized()
+		if n := len(runs) - 1; n >= 0 && runs[n].SameFormat(&r) {
+			runs[n].Text += r.Text
+			continue
+		}
+		runs = append(runs, r)
+	}
+	if !emphasis {
+		return nil
+	}
+	return runs
+}
+
+// bookmarks returns the names of the bookmarks placed in the paragraph.
+func (p *ParagraphElement) bookmarks() []string {
+	var names []string
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			if child.Bookmark != nil && child.Bookmark.Name != "" {
+				names = append(names, child.Bookmark.Name)
+			}
+		}
+	}
+	return names
+}
+
+// fields returns the form fields of the paragraph: click-here fields, whose
+// value is the text between fieldBegin and fieldEnd, and form controls.
+func (p *ParagraphElement) fields() []document.Field {
+	var fields []document.Field
+	var text strings.Builder
+	type openField struct {
+		id    string
+		index int
+		start int
+	}
+	var open []openField
+
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			switch child.XMLName.Local {
+			case "t", "lineBreak":
+				text.WriteString(child.text())
+			case "ctrl":
+				text.WriteString(child.text())
+				if b := child.FieldBegin; b != nil && b.Type == "CLICK_HERE" {
+					open = append(open, openField{b.ID, len(fields), text.Len()})
+					fields = append(fields, document.Field{Name: b.name(), Type: document.FieldClickHere})
+				}
+				if e := child.FieldEnd; e != nil && len(open) > 0 {
+					// Fields nest; unmatched ends belong to other field types
+					n := len(open) - 1
+					if e.BeginIDRef != "" && e.BeginIDRef != open[n].id {
+						continue
+					}
+					fields[open[n].index].Value = text.String()[open[n].start:]
+					open = open[:n]
+				}
+			default:
+				if field, ok := child.formField(); ok {
+					fields = append(fields, field)
+				}
+			}
+		}
+	}
+	for _, f := range open {
+		fields[f.index].Value = text.String()[f.start:]
+	}
+	return fields
+}
+
+// hyperlinks returns the hyperlinks of the paragraph: the text between the
+// fieldBegin and fieldEnd of each HYPERLINK field, with its target.
+func (p *ParagraphElement) hyperlinks() []document.Hyperlink {
+	var links []document.Hyperlink
+	var text strings.Builder
+	// open holds the field ID of the link being read, and link its index
+	open, link := "", -1
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			text.WriteString(child.text())
+			if b := child.FieldBegin; b != nil && b.Type == "HYPERLINK" {
+				open, link = b.ID, len(links)
+				links = append(links, document.Hyperlink{Offset: text.Len(), URL: b.target(), Title: b.Name})
+			}
+			if e := child.FieldEnd; e != nil && link >= 0 && (e.BeginIDRef == "" || e.BeginIDRef == open) {
+				links[link].Text = text.String()[links[link].Offset:]
+				link = -1
+			}
+		}
+	}
+	// A link left open ends with the text
+	if link >= 0 {
+		links[link].Text = text.String()[links[link].Offset:]
+	}
+	return links
+}
+
+// comments returns the memos of the paragraph's memo fields as comments on
+// the text between each field's begin and end, with IDs under the
+// paragraph's.
+func (p *ParagraphElement) comments(id string) []document.ContentNode {
+	var comments []*document.Comment
+	var text strings.Builder
+	// open holds the field IDs of the memos being read, by comment index
+	open := make(map[int]string)
+	end := func(i int) {
+		comments[i].Quote = text.String()[comments[i].Offset:]
+		delete(open, i)
+	}
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			text.WriteString(child.text())
+			if b := child.FieldBegin; b != nil && b.Type == "MEMO" {
+				c := &document.Comment{
+					ID:     fmt.Sprintf("%s.a%d", id, len(comments)),
+					Author: b.param("Author"),
+					Date:   b.param("CreateDateTime"),
+					Anchor: id,
+					Offset: text.Len(),
+				}
+				if b.Memo != nil {
+					c.Text = joinParagraphs(b.Memo.Paragraphs)
+				}
+				open[len(comments)] = b.ID
+				comments = append(comments, c)
+			}
+			if e := child.FieldEnd; e != nil {
+				for i, begin := range open {
+					if e.BeginIDRef == begin {
+						end(i)
+					}
+				}
+			}
+		}
+	}
+	// Memos left open end with the text
+	for i := range open {
+		end(i)
+	}
+	nodes := make([]document.ContentNode, len(comments))
+	for i, c := range comments {
+		nodes[i] = c
+	}
+	return nodes
+}
+
+// objects returns the equations, videos, pictures, charts, OLE objects and
+// text box paragraphs of the paragraph, with IDs under the paragraph's.
+func (s *ContentScanner) objects(p *ParagraphElement, id string) []document.ContentNode {
+	var nodes []document.ContentNode
+	equations, videos, boxes, images, charts := 0, 0, 0, 0, 0
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			boxParas := child.boxParagraphs(nil, s.changes)
+			caption := ""
+			if child.Caption != nil {
+				caption = child.Caption.text()
+			}
+			// Drawings holding only text need no placeholder
+			if drawingShapes[child.XMLName.Local] && (caption != "" || len(boxParas) == 0) {
+				nodes = append(nodes, child.image(fmt.Sprintf("%s.i%d", id, images), caption))
+				images++
+			}
+			for i := range boxParas {
+				para := &boxParas[i]
+				para.ID, para.Floating = fmt.Sprintf("%s.b%d", id, boxes), true
+				nodes = append(nodes, para)
+				boxes++
+			}
+
+			switch child.XMLName.Local {
+			case "equation":
+				nodes = append(nodes, &document.Equation{
+					ID:      fmt.Sprintf("%s.e%d", id, equations),
+					Script:  child.Script,
+					LaTeX:   equation.LaTeX(child.Script),
+					Caption: caption,
+				})
+				equations++
+			case "video":
+				m := &document.Media{ID: fmt.Sprintf("%s.m%d", id, videos), Caption: caption}
+				if strings.EqualFold(child.VideoType, "Web") {
+					m.Source = document.EmbedURL(child.EmbedTag)
+				} else {
+					// The manifest item of the video file
+					m.Source, m.Embedded = child.FileIDRef, true
+				}
+				nodes = append(nodes, m)
+				videos++
+			case "pic":
+				img := child.image(fmt.Sprintf("%s.i%d", id, images), caption)
+				if child.Img != nil {
+					s.pictureData(img, child.Img.BinaryItemIDRef)
+				}
+				nodes = append(nodes, img)
+				images++
+			case "chart":
+				if chart := s.chart(&child, caption); chart != nil {
+					chart.ID = fmt.Sprintf("%s.c%d", id, charts)
+					nodes = append(nodes, chart)
+					charts++
+					break
+				}
+				nodes = append(nodes, child.image(fmt.Sprintf("%s.i%d", id, images), caption))
+				images++
+			case "ole":
+				img := child.image(fmt.Sprintf("%s.i%d", id, images), caption)
+				s.oleData(img, child.BinaryItemIDRef)
+				nodes = append(nodes, img)
+				images++
+			}
+		}
+	}
+	return nodes
+}
+
+// notes returns the footnotes and endnotes of the paragraph, with IDs under
+// the paragraph's.
+func (s *ContentScanner) notes(p *ParagraphElement, id string) []document.ContentNode {
+	var nodes []document.ContentNode
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			note, count, endnote := child.FootNote, &s.footnotes, false
+			if child.EndNote != nil {
+				note, count, endnote = child.EndNote, &s.endnotes, true
+			}
+			if note == nil {
+				continue
+			}
+			*count++
+			number := note.Number
+			if number <= 0 {
+				number = *count
+			}
+			nodes = append(nodes, &document.Footnote{
+				ID:         fmt.Sprintf("%s.n%d", id, len(nodes)),
+				Number:     number,
+				Text:       joinParagraphs(note.Paragraphs),
+				Endnote:    endnote,
+				Anchor:     id,
+				Paragraphs: s.noteParagraphs(note.Paragraphs),
+			})
+		}
+	}
+	return nodes
+}
+
+// noteParagraphs returns the non-empty paragraphs of a note with their
+// formatting and links.
+func (s *ContentScanner) noteParagraphs(paras []ParagraphElement) []document.Paragraph {
+	var out []document.Paragraph
+	for i := range paras {
+		para := &paras[i]
+		text := para.extractText()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		out = append(out, document.Paragraph{
+			Text:       text,
+			Fields:     para.fields(),
+			Hyperlinks: para.hyperlinks(),
+			Runs:       para.runs(s.runStyles),
+		})
+	}
+	return out
+}
+
+// pageTexts returns the text of the headers and footers (머리말, 꼬리말) and
+// master pages (바탕쪽) of the paragraph as Floating paragraphs, with IDs
+// under the paragraph's. Master pages are referred to by the section
+// properties in the first paragraph of a section.
+func (s *ContentScanner) pageTexts(p *ParagraphElement, id string) []document.ContentNode {
+	var nodes []document.ContentNode
+	add := func(format string, n int, paras []ParagraphElement) {
+		if text := joinParagraphs(paras); text != "" {
+			nodes = append(nodes, &document.Paragraph{ID: fmt.Sprintf(format, id, n), Text: text, Floating: true})
+		}
+	}
+	headers, masters := 0, 0
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			for _, list := range []*SubList{child.Header, child.Footer} {
+				if list != nil {
+					add("%s.hf%d", headers, list.Paragraphs)
+					headers++
+				}
+			}
+			for _, ref := range child.MasterPages {
+				if s.reader == nil {
+					break
+				}
+				paras, err := s.reader.masterPage(ref.IDRef, s.opts.MaxStreamSize)
+				if err != nil {
+					s.opts.Warn(fmt.Errorf("skipped master page %s: %w", ref.IDRef, err))
+					continue
+				}
+				for i := range paras {
+					setPageNumbers(&paras[i], s.opts.PageNumber)
+				}
+				add("%s.mp%d", masters, paras)
+				masters++
+			}
+		}
+	}
+	return nodes
+}
+
+// joinParagraphs joins the non-empty texts of paragraphs with line breaks.
+func joinParagraphs(paras []ParagraphElement) string {
+	var lines []string
+	for _, para := range paras {
+		if text := strings.TrimSpace(para.extractText()); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hiddenComments returns the hidden comments of the paragraph as Hidden
+// paragraphs, with IDs under the paragraph's.
+func (p *ParagraphElement) hiddenComments(id string) []document.ContentNode {
+	var nodes []document.ContentNode
+	for _, run := range p.Runs {
+		for _, child := range run.Children {
+			if child.HiddenComment == nil {
+				continue
+			}
+			if text := joinParagraphs(child.HiddenComment.Paragraphs); text != "" {
+				nodes = append(nodes, &document.Paragraph{ID: fmt.Sprintf("%s.h%d", id, len(nodes)), Text: text, Hidden: true})
+			}
+		}
+	}
+	return nodes
+}
+
+// image returns the Image node of a picture or drawing, with its size and
+// description.
+func (c *RunChild) image(id, caption string) *document.Image {
+	img := &document.Image{ID: id, Caption: caption, Alt: strings.TrimSpace(c.ShapeComment)}
+	if c.Size != nil {
+		img.Width, img.Height = c.Size.points()
+	}
+	if c.OrgSize != nil {
+		img.OriginalWidth, img.OriginalHeight = c.OrgSize.points()
+	}
+	if c.Pos != nil {
+		img.Anchor = c.Pos.anchor()
+	}
+	return img
+}
+
+// drawingShapes holds the run children that are drawing objects: shapes
+// and groups of them (container).
+var drawingShapes = map[string]bool{
+	"rect": true, "ellipse": true, "arc": true, "polygon": true, "curve": true,
+	"line": true, "connectLine": true, "textart": true, "container": true,
+}
+
+// boxParagraphs appends the non-empty paragraphs of the child's text box
+// or text art and of the shapes grouped within it, in document order, with
+// their text and tracked changes.
+func (c *RunChild) boxParagraphs(paras []document.Paragraph, changes map[string]document.Change) []document.Paragraph {
+	if c.XMLName.Local == "textart" && strings.TrimSpace(c.ArtText) != "" {
+		paras = append(paras, document.Paragraph{Text: c.ArtText})
+	}
+	if c.DrawText != nil {
+		for i := range c.DrawText.Paragraphs {
+			p := &c.DrawText.Paragraphs[i]
+			if text := p.extractText(); strings.TrimSpace(text) != "" {
+				paras = append(paras, document.Paragraph{Text: text, Changes: p.changes(changes)})
+			}
+		}
+	}
+	for i := range c.Shapes {
+		paras = c.Shapes[i].boxParagraphs(paras, changes)
+	}
+	return paras
+}
+
 type Run struct {
-	XMLName   xml.Name      `xml:"run"`
-	TextNodes []TextNode    `xml:"t"`
-	LineBreak *LineBreak    `xml:"lineBreak"`
-	Table     *TableElement `xml:"tbl"`
+	XMLName     xml.Name      `xml:"run"`
+	CharPrIDRef string        `xml:"charPrIDRef,attr"`
+	Table       *TableElement `xml:"tbl"`
+	// Children holds the other child elements (t, ctrl, lineBreak) in
+	// document order, so that inline controls stay in place within the text.
+	Children []RunChild `xml:",any"`
 }
 
 func (r *Run) extractText() string {
 	var parts []string
-	for _, t := range r.TextNodes {
-		parts = append(parts, t.Text)
-	}
-	if r.LineBreak != nil {
-		parts = append(parts, "\n")
+	for _, child := range r.Children {
+		parts = append(parts, child.text())
 	}
 	return strings.Join(parts, "")
 }
 
-type TextNode struct {
-	XMLName xml.Name `xml:"t"`
-	Text    string   `xml:",chardata"`
+// RunChild is a child element of a run: text (t), a control container
+// (ctrl), a line break, an equation, a video, a picture, a chart, an OLE
+// object, a drawing shape or a form control (edit, checkBtn, ...).
+type RunChild struct {
+	XMLName    xml.Name
+	Text       string      `xml:",chardata"`
+	Bookmark   *Bookmark   `xml:"bookmark"`
+	AutoNum    *AutoNum    `xml:"autoNum"`
+	PageNum    *PageNum    `xml:"pageNum"`
+	FieldBegin *FieldBegin `xml:"fieldBegin"`
+	FieldEnd   *FieldEnd   `xml:"fieldEnd"`
+
+	// Form control attributes
+	Name          string `xml:"name,attr"`
+	Value         string `xml:"value,attr"`
+	SelectedValue string `xml:"selectedValue,attr"`
+	EditText      string `xml:"text"`
+
+	// Equation script, video attributes and object caption
+	Script    string   `xml:"script"`
+	VideoType string   `xml:"videotype,attr"`
+	EmbedTag  string   `xml:"tag,attr"`
+	FileIDRef string   `xml:"fileIDRef,attr"`
+	Caption   *Caption `xml:"caption"`
+
+	// Chart part of a chart, and binary item of an OLE object (ole)
+	ChartIDRef      string `xml:"chartIDRef,attr"`
+	BinaryItemIDRef string `xml:"binaryItemIDRef,attr"`
+
+	// Picture data of a pic
+	Img *struct {
+		BinaryItemIDRef string `xml:"binaryItemIDRef,attr"`
+	} `xml:"img"`
+
+	// Text box of a drawing object, and the shapes of a group (container)
+	DrawText *SubList   `xml:"drawText>subList"`
+	Shapes   []RunChild `xml:",any"`
+	// ArtText is the text of a text art (글맵시)
+	ArtText string `xml:"text,attr"`
+
+	// Displayed and original size and description (개체 설명문) of a
+	// picture or drawing
+	Size         *ObjectSize `xml:"sz"`
+	OrgSize      *ObjectSize `xml:"orgSz"`
+	ShapeComment string      `xml:"shapeComment"`
+	// Pos is the position of a picture or drawing
+	Pos *ObjectPos `xml:"pos"`
+
+	// Hidden comment (숨은 설명) of a ctrl
+	HiddenComment *SubList `xml:"hiddenComment>subList"`
+
+	// Footnote (각주) or endnote (미주) of a ctrl
+	FootNote *Note `xml:"footNote"`
+	EndNote  *Note `xml:"endNote"`
+
+	// Header (머리말) or footer (꼬리말) of a ctrl
+	Header *SubList `xml:"header>subList"`
+	Footer *SubList `xml:"footer>subList"`
+
+	// Master pages (바탕쪽) of a secPr, by manifest item ID
+	MasterPages []struct {
+		IDRef string `xml:"idRef,attr"`
+	} `xml:"masterPage"`
+
+	// Change tracking marks within the text of a t, and the spans of
+	// changed text kept by applyChanges
+	marks []changeMark
+	spans []changeSpan
+	// pageNumber stands in for the page number of an autoNum or pageNum,
+	// as set by setPageNumbers
+	pageNumber string
+}
+
+// text returns the text the child contributes to the paragraph.
+func (c *RunChild) text() string {
+	switch c.XMLName.Local {
+	case "t":
+		return c.Text
+	case "lineBreak":
+		return "\n"
+	case "ctrl":
+		// The number of a note is shown with the note, not its text
+		if c.AutoNum != nil && c.AutoNum.NumType != "FOOTNOTE" && c.AutoNum.NumType != "ENDNOTE" {
+			return c.AutoNum.text(c.pageNumber)
+		}
+		if c.PageNum != nil {
+			return c.PageNum.text(c.pageNumber)
+		}
+	}
+	return ""
 }
 
-type LineBreak struct {
-	XMLName xml.Name `xml:"lineBreak"`
+// formField returns the field of a form control child.
+func (c *RunChild) formField() (document.Field, bool) {
+	field := document.Field{Name: c.Name}
+	switch c.XMLName.Local {
+	case "edit":
+		field.Type, field.Value = document.FieldEdit, c.EditText
+	case "checkBtn", "radioBtn":
+		field.Type = document.FieldCheckBox
+		if c.XMLName.Local == "radioBtn" {
+			field.Type = document.FieldRadio
+		}
+		field.Value = "off"
+		if c.Value == "CHECKED" {
+			field.Value = "on"
+		}
+	case "comboBox":
+		field.Type, field.Value = document.FieldComboBox, c.SelectedValue
+	default:
+		return field, false
+	}
+	return field, true
+}
+
+// FieldBegin starts a field (hyperlink, click-here, date, ...) whose
+// content runs up to the fieldEnd referring to it.
+type FieldBegin struct {
+	ID     string        `xml:"id,attr"`
+	Type   string        `xml:"type,attr"`
+	Name   string        `xml:"name,attr"`
+	Params []StringParam `xml:"parameters>stringParam"`
+	// Memo holds the paragraphs of a memo field
+	Memo *SubList `xml:"subList"`
+}
+
+// name returns the field name, or else the guide text shown in the empty
+// field.
+func (b *FieldBegin) name() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	for _, p := range b.Params {
+		if p.Name == "Direction" {
+			return strings.TrimSpace(p.Value)
+		}
+	}
+	return ""
+}
+
+// param returns the value of a string parameter of the field.
+func (b *FieldBegin) param(name string) string {
+	for _, p := range b.Params {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// target returns the target of a hyperlink field: its Path parameter, or
+// else the target in its Command parameter.
+func (b *FieldBegin) target() string {
+	var command string
+	for _, p := range b.Params {
+		switch p.Name {
+		case "Path":
+			if p.Value != "" {
+				return p.Value
+			}
+		case "Command":
+			command = p.Value
+		}
+	}
+	return hwpv5.LinkTarget(command)
+}
+
+type FieldEnd struct {
+	BeginIDRef string `xml:"beginIDRef,attr"`
+}
+
+type StringParam struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// AutoNum is an auto-number control (table and figure numbers in captions,
+// page numbers in headers and footers).
+type AutoNum struct {
+	XMLName xml.Name     `xml:"autoNum"`
+	Num     string       `xml:"num,attr"`
+	NumType string       `xml:"numType,attr"`
+	Format  NumberFormat `xml:"autoNumFormat"`
+}
+
+// PageNum is a page number position control (쪽 번호 위치), which shows
+// the page number at a fixed place on each page.
+type PageNum struct {
+	XMLName  xml.Name `xml:"pageNum"`
+	Pos      string   `xml:"pos,attr"`
+	SideChar string   `xml:"sideChar,attr"`
+}
+
+type Bookmark struct {
+	XMLName xml.Name `xml:"bookmark"`
+	Name    string   `xml:"name,attr"`
 }
 
 type TableElement struct {
-	XMLName xml.Name   `xml:"tbl"`
-	ID      string     `xml:"id,attr"`
-	RowCnt  int        `xml:"rowCnt,attr"`
-	ColCnt  int        `xml:"colCnt,attr"`
-	Rows    []TableRow `xml:"tr"`
+	XMLName xml.Name `xml:"tbl"`
+	ID      string   `xml:"id,attr"`
+	RowCnt  int      `xml:"rowCnt,attr"`
+	ColCnt  int      `xml:"colCnt,attr"`
+	// RepeatHeader is set when the first row repeats on each page
+	RepeatHeader bool       `xml:"repeatHeader,attr"`
+	Rows         []TableRow `xml:"tr"`
+	Caption      *Caption   `xml:"caption"`
+}
+
+type Caption struct {
+	XMLName xml.Name `xml:"caption"`
+	SubList SubList  `xml:"subList"`
+}
+
+// text returns the caption paragraphs, one per line.
+func (c *Caption) text() string {
+	var lines []string
+	for _, p := range c.SubList.Paragraphs {
+		if text := strings.TrimSpace(p.extractText()); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 type TableRow struct {
@@ -221,16 +1179,34 @@ type TableRow struct {
 }
 
 type TableCell struct {
-	XMLName  xml.Name `xml:"tc"`
-	Name     string   `xml:"name,attr"`
-	SubList  SubList  `xml:"subList"`
-	CellAddr CellAddr `xml:"cellAddr"`
-	CellSpan CellSpan `xml:"cellSpan"`
+	XMLName         xml.Name `xml:"tc"`
+	Name            string   `xml:"name,attr"`
+	BorderFillIDRef string   `xml:"borderFillIDRef,attr"`
+	SubList         SubList  `xml:"subList"`
+	CellAddr        CellAddr `xml:"cellAddr"`
+	CellSpan        CellSpan `xml:"cellSpan"`
+	CellSz          CellSz   `xml:"cellSz"`
 }
 
 type SubList struct {
 	XMLName    xml.Name           `xml:"subList"`
 	Paragraphs []ParagraphElement `xml:"p"`
+	// VertAlign is the vertical alignment of the text: TOP, CENTER or
+	// BOTTOM
+	VertAlign string `xml:"vertAlign,attr"`
+}
+
+// hwpxVAligns maps subList vertAlign values to cell vertical alignments.
+var hwpxVAligns = map[string]string{
+	"TOP":    document.VAlignTop,
+	"CENTER": document.VAlignMiddle,
+	"BOTTOM": document.VAlignBottom,
+}
+
+// Note is a footnote or endnote and its paragraphs.
+type Note struct {
+	Number     int                `xml:"number,attr"`
+	Paragraphs []ParagraphElement `xml:"subList>p"`
 }
 
 type CellAddr struct {
@@ -239,6 +1215,48 @@ type CellAddr struct {
 	RowAddr int      `xml:"rowAddr,attr"`
 }
 
+// ObjectSize is the size of an object in HWPUNITs.
+type ObjectSize struct {
+	Width  int `xml:"width,attr"`
+	Height int `xml:"height,attr"`
+}
+
+// points returns the size in points.
+func (s *ObjectSize) points() (width, height float64) {
+	return float64(s.Width) / 100, float64(s.Height) / 100
+}
+
+// ObjectPos is the position of an object: whether it is treated as a
+// character (글자처럼 취급), and else what its vertical offset is relative
+// to (PARA, PAGE or PAPER).
+type ObjectPos struct {
+	TreatAsChar string `xml:"treatAsChar,attr"`
+	VertRelTo   string `xml:"vertRelTo,attr"`
+}
+
+// anchor returns the Image.Anchor of the position.
+func (p *ObjectPos) anchor() string {
+	if p.TreatAsChar == "1" || p.TreatAsChar == "true" {
+		return document.AnchorInline
+	}
+	switch p.VertRelTo {
+	case "PARA":
+		return document.AnchorParagraph
+	case "PAGE":
+		return document.AnchorPage
+	case "PAPER":
+		return document.AnchorPaper
+	}
+	return ""
+}
+
+// CellSz is the size of a cell in HWPUNITs.
+type CellSz struct {
+	XMLName xml.Name `xml:"cellSz"`
+	Width   int      `xml:"width,attr"`
+	Height  int      `xml:"height,attr"`
+}
+
 type CellSpan struct {
 	XMLName xml.Name `xml:"cellSpan"`
 	ColSpan int      `xml:"colSpan,attr"`
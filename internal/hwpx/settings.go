@@ -0,0 +1,76 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// settingsPaths are where packages keep the application settings: at the
+// root in files written by Hangul, and next to the package file in others.
+var settingsPaths = []string{"settings.xml", "Contents/settings.xml"}
+
+// Settings holds the application settings saved with a document
+// (settings.xml): where the caret was and the configuration items Hangul
+// keeps, such as print options.
+type Settings struct {
+	Caret CaretPosition
+	// Config holds configuration item values by item set and item name,
+	// e.g. Config["PrintInfo"]["PrintAutoFootNote"] is "false".
+	Config map[string]map[string]string
+}
+
+// CaretPosition is where the caret was when the document was saved: a
+// paragraph of a paragraph list, and a character position within it.
+type CaretPosition struct {
+	ListID      int `xml:"listIDRef,attr"`
+	ParagraphID int `xml:"paraIDRef,attr"`
+	Pos         int `xml:"pos,attr"`
+}
+
+// Settings reads the application settings. It returns nil if the package
+// has no settings part.
+func (r *Reader) Settings() (*Settings, error) {
+	var name string
+	for _, p := range settingsPaths {
+		if n, ok := r.resolve("", p); ok {
+			name = n
+			break
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+	file, err := r.zipReader.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var doc struct {
+		Caret    CaretPosition `xml:"CaretPosition"`
+		ItemSets []struct {
+			Name  string `xml:"name,attr"`
+			Items []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"config-item"`
+		} `xml:"config-item-set"`
+	}
+	if err := xml.NewDecoder(file).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	settings := &Settings{Caret: doc.Caret, Config: make(map[string]map[string]string)}
+	for _, set := range doc.ItemSets {
+		items := settings.Config[set.Name]
+		if items == nil {
+			items = make(map[string]string, len(set.Items))
+			settings.Config[set.Name] = items
+		}
+		for _, item := range set.Items {
+			items[item.Name] = strings.TrimSpace(item.Value)
+		}
+	}
+	return settings, nil
+}
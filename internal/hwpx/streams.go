@@ -0,0 +1,24 @@
+package hwpx
+
+// StreamStat reports the stored and uncompressed size of a package entry.
+type StreamStat struct {
+	Name             string
+	Size             int64
+	UncompressedSize int64
+}
+
+// StreamStats reports sizes of every entry in the ZIP package.
+func (r *Reader) StreamStats() []StreamStat {
+	stats := make([]StreamStat, 0, len(r.zipReader.File))
+	for _, file := range r.zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		stats = append(stats, StreamStat{
+			Name:             file.Name,
+			Size:             int64(file.CompressedSize64),
+			UncompressedSize: int64(file.UncompressedSize64),
+		})
+	}
+	return stats
+}
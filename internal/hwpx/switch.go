@@ -0,0 +1,127 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// supportedNamespaces are the OWPML namespaces whose content the parser
+// reads. An hp:case that requires any other namespace, such as the
+// HwpUnitChar extension of Hangul 2018, is passed over.
+var supportedNamespaces = map[string]bool{
+	"http://www.hancom.co.kr/hwpml/2011/app":         true,
+	"http://www.hancom.co.kr/hwpml/2011/core":        true,
+	"http://www.hancom.co.kr/hwpml/2011/head":        true,
+	"http://www.hancom.co.kr/hwpml/2011/history":     true,
+	"http://www.hancom.co.kr/hwpml/2011/master-page": true,
+	"http://www.hancom.co.kr/hwpml/2011/paragraph":   true,
+	"http://www.hancom.co.kr/hwpml/2011/section":     true,
+}
+
+// newDecoder returns a decoder for an OWPML part that resolves the
+// compatibility blocks (hp:switch) in it.
+func newDecoder(r io.Reader) *xml.Decoder {
+	return xml.NewTokenDecoder(&switchResolver{source: xml.NewDecoder(r)})
+}
+
+// switchResolver replaces each hp:switch with the content of the branch a
+// reader of the supported namespaces takes: the first hp:case whose
+// required-namespace is supported, or else the hp:default. Without it both
+// branches would be read, duplicating the content they share.
+type switchResolver struct {
+	source *xml.Decoder
+	// pending holds the tokens of a taken branch, still to be resolved
+	// for nested switches
+	pending []xml.Token
+}
+
+func (s *switchResolver) Token() (xml.Token, error) {
+	token, err := s.next()
+	if err != nil {
+		return nil, err
+	}
+	if start, ok := token.(xml.StartElement); ok && start.Name.Local == "switch" {
+		branch, err := s.branch()
+		if err != nil {
+			return nil, err
+		}
+		s.pending = append(branch, s.pending...)
+		return s.Token()
+	}
+	return token, nil
+}
+
+// next returns the next token of the taken branch, or else of the source.
+func (s *switchResolver) next() (xml.Token, error) {
+	if len(s.pending) > 0 {
+		token := s.pending[0]
+		s.pending = s.pending[1:]
+		return token, nil
+	}
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	return xml.CopyToken(token), nil
+}
+
+// branch consumes the rest of a switch element and returns the content of
+// the branch taken, or nil if there is none.
+func (s *switchResolver) branch() ([]xml.Token, error) {
+	var (
+		taken, fallback, content []xml.Token
+		found                    bool
+		start                    xml.StartElement
+		depth                    int
+	)
+	for {
+		token, err := s.next()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				start, content = t, nil
+				continue
+			}
+		case xml.EndElement:
+			depth--
+			if depth < 0 {
+				// The end of the switch
+				if found {
+					return taken, nil
+				}
+				return fallback, nil
+			}
+			if depth == 0 {
+				switch start.Name.Local {
+				case "case":
+					if !found && supportedCase(start) {
+						taken, found = content, true
+					}
+				case "default":
+					fallback = content
+				}
+				continue
+			}
+		}
+		if depth > 0 {
+			content = append(content, token)
+		}
+	}
+}
+
+// supportedCase reports whether an hp:case requires a supported namespace.
+func supportedCase(c xml.StartElement) bool {
+	for _, attr := range c.Attr {
+		if attr.Name.Local == "required-namespace" {
+			return supportedNamespaces[attr.Value]
+		}
+	}
+	return false
+}
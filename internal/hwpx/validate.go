@@ -0,0 +1,283 @@
+package hwpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/hwpv5"
+	"github.com/hanpama/hwp/internal/limits"
+)
+
+// sectionNamespace is the namespace of the root element (hs:sec) of
+// section parts.
+const sectionNamespace = "http://www.hancom.co.kr/hwpml/2011/section"
+
+// elementParents lists the allowed parents of the section elements whose
+// place the schema fixes.
+var elementParents = map[string][]string{
+	"p":   {"sec", "subList"},
+	"run": {"p"},
+	"t":   {"run"},
+	"tr":  {"tbl"},
+	"tc":  {"tr"},
+}
+
+// compatibilityElements are the elements of hp:switch blocks, which stand
+// for their content in the element tree.
+var compatibilityElements = map[string]bool{"switch": true, "case": true, "default": true}
+
+// Validate checks the section XML of the package against structural rules
+// of OWPML and returns the violations found: malformed XML, a root element
+// other than hs:sec, paragraphs, runs, text, rows and cells outside their
+// parent elements, table cells that do not cover the table's rowCnt×colCnt
+// grid or lie outside it, and references to character shapes, paragraph
+// shapes, styles and binary items the package lacks. Both branches of
+// compatibility blocks are checked. Failures to read a section at all are
+// errors.
+func (r *Reader) Validate(maxSize int64) ([]hwpv5.Violation, error) {
+	header, err := r.Header()
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string]map[string]bool{
+		"charPrIDRef": {},
+		"paraPrIDRef": {},
+		"styleIDRef":  {},
+	}
+	for _, c := range header.CharPrs {
+		refs["charPrIDRef"][c.ID] = true
+	}
+	for _, p := range header.ParaPrs {
+		refs["paraPrIDRef"][p.ID] = true
+	}
+	for _, s := range header.Styles {
+		refs["styleIDRef"][s.ID] = true
+	}
+
+	var violations []hwpv5.Violation
+	for i, section := range r.sections {
+		file, err := r.openSection(i, maxSize)
+		if err != nil {
+			return violations, fmt.Errorf("failed to open section %d: %w", i, err)
+		}
+		v := &validator{reader: r, refs: refs, section: i, part: section.name}
+		err = v.run(xml.NewDecoder(file))
+		file.Close()
+		violations = append(violations, v.violations...)
+		if err != nil {
+			return violations, err
+		}
+	}
+	return violations, nil
+}
+
+// referenceKinds names what the reference attributes refer to.
+var referenceKinds = map[string]string{
+	"charPrIDRef": "char shape",
+	"paraPrIDRef": "paragraph shape",
+	"styleIDRef":  "style",
+}
+
+// validator checks the elements of one section part.
+type validator struct {
+	reader *Reader
+	// refs holds the IDs of the header items by the attribute referring
+	// to them; kinds the header has none of are not checked
+	refs       map[string]map[string]bool
+	section    int
+	part       string
+	violations []hwpv5.Violation
+
+	// element, offset and tag locate the current element
+	element int
+	offset  int64
+	tag     string
+	// parents holds the names of the open elements, innermost last
+	parents []string
+	// tables holds the open tables, innermost last
+	tables []validatedTable
+}
+
+// validatedTable is a table whose rows and cells are being counted.
+type validatedTable struct {
+	// at locates the tbl element
+	at         hwpv5.Violation
+	rows, cols int
+	// trs is the number of tr elements so far, and area the number of
+	// grid cells covered by the cells so far
+	trs, area int
+	// cell is the open cell, nil between cells
+	cell *validatedCell
+}
+
+// validatedCell is a cell whose address and span are being read.
+type validatedCell struct {
+	at                         hwpv5.Violation
+	row, col, rowSpan, colSpan int
+}
+
+func (v *validator) run(d *xml.Decoder) error {
+	for {
+		v.offset = d.InputOffset()
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, limits.ErrExceeded) {
+			return err
+		}
+		if err != nil {
+			// The rest of the part cannot be read
+			v.report("malformed XML: %v", err)
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			v.start(t)
+			v.element++
+		case xml.EndElement:
+			v.end(t)
+		}
+	}
+	for len(v.tables) > 0 {
+		v.closeTable()
+	}
+	// Tables are reported when they close, after their cells
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Record < v.violations[j].Record
+	})
+	return nil
+}
+
+// report adds a violation at the current element.
+func (v *validator) report(format string, args ...any) {
+	v.violations = append(v.violations, v.at(fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) at(message string) hwpv5.Violation {
+	return hwpv5.Violation{
+		Section: v.section,
+		Part:    v.part,
+		Record:  v.element,
+		Offset:  v.offset,
+		Tag:     v.tag,
+		Message: message,
+	}
+}
+
+func (v *validator) start(elem xml.StartElement) {
+	name := elem.Name.Local
+	v.tag = name
+	if compatibilityElements[name] {
+		return
+	}
+
+	if len(v.parents) == 0 {
+		if name != "sec" || elem.Name.Space != sectionNamespace {
+			v.report("root element is {%s}%s, not hs:sec", elem.Name.Space, name)
+		}
+	} else if allowed, ok := elementParents[name]; ok {
+		if parent := v.parents[len(v.parents)-1]; !slices.Contains(allowed, parent) {
+			v.report("%s element inside %s, not %s", name, parent, strings.Join(allowed, " or "))
+		}
+	}
+	v.parents = append(v.parents, name)
+
+	for _, a := range elem.Attr {
+		if ids := v.refs[a.Name.Local]; len(ids) > 0 && !ids[a.Value] {
+			v.report("reference to missing %s %s", referenceKinds[a.Name.Local], a.Value)
+		}
+		if a.Name.Local == "binaryItemIDRef" {
+			if _, ok := v.reader.binaryItem(a.Value); !ok {
+				v.report("reference to missing binary item %s", a.Value)
+			}
+		}
+	}
+
+	var table *validatedTable
+	if len(v.tables) > 0 {
+		table = &v.tables[len(v.tables)-1]
+	}
+	switch name {
+	case "tbl":
+		rows, _ := strconv.Atoi(attr(elem, "rowCnt"))
+		cols, _ := strconv.Atoi(attr(elem, "colCnt"))
+		if rows <= 0 || cols <= 0 {
+			v.report("table has %d rows and %d columns", rows, cols)
+		}
+		v.tables = append(v.tables, validatedTable{at: v.at(""), rows: rows, cols: cols})
+	case "tr":
+		if table != nil {
+			table.trs++
+		}
+	case "tc":
+		if table != nil {
+			table.cell = &validatedCell{at: v.at(""), rowSpan: 1, colSpan: 1}
+		}
+	case "cellAddr":
+		if table != nil && table.cell != nil {
+			table.cell.row, _ = strconv.Atoi(attr(elem, "rowAddr"))
+			table.cell.col, _ = strconv.Atoi(attr(elem, "colAddr"))
+		}
+	case "cellSpan":
+		if table != nil && table.cell != nil {
+			if n, _ := strconv.Atoi(attr(elem, "rowSpan")); n > 0 {
+				table.cell.rowSpan = n
+			}
+			if n, _ := strconv.Atoi(attr(elem, "colSpan")); n > 0 {
+				table.cell.colSpan = n
+			}
+		}
+	}
+}
+
+func (v *validator) end(elem xml.EndElement) {
+	name := elem.Name.Local
+	if compatibilityElements[name] || len(v.parents) == 0 {
+		return
+	}
+	v.parents = v.parents[:len(v.parents)-1]
+	if len(v.tables) == 0 {
+		return
+	}
+
+	switch name {
+	case "tbl":
+		v.closeTable()
+	case "tc":
+		t := &v.tables[len(v.tables)-1]
+		c := t.cell
+		if c == nil {
+			return
+		}
+		t.cell = nil
+		if c.row < 0 || c.col < 0 || c.row+c.rowSpan > t.rows || c.col+c.colSpan > t.cols {
+			c.at.Message = fmt.Sprintf("cell at row %d, column %d spanning %dx%d is outside the %dx%d table",
+				c.row, c.col, c.rowSpan, c.colSpan, t.rows, t.cols)
+			v.violations = append(v.violations, c.at)
+		}
+		t.area += c.rowSpan * c.colSpan
+	}
+}
+
+// closeTable checks that the rows and cells of the innermost open table
+// cover its grid.
+func (v *validator) closeTable() {
+	t := v.tables[len(v.tables)-1]
+	v.tables = v.tables[:len(v.tables)-1]
+	if t.trs != t.rows {
+		at := t.at
+		at.Message = fmt.Sprintf("table has %d tr elements for its %d rows", t.trs, t.rows)
+		v.violations = append(v.violations, at)
+	}
+	if want := t.rows * t.cols; t.area != want {
+		t.at.Message = fmt.Sprintf("cells cover %d of the %d cells of the %dx%d table", t.area, want, t.rows, t.cols)
+		v.violations = append(v.violations, t.at)
+	}
+}
@@ -0,0 +1,110 @@
+// Package limits guards parsing against adversarial documents.
+package limits
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrExceeded is matched by every limit violation (use errors.Is).
+var ErrExceeded = errors.New("resource limit exceeded")
+
+// Error reports which limit was exceeded.
+type Error struct {
+	What  string
+	Limit int64
+	// Unit is what the limit counts; empty means bytes.
+	Unit string
+}
+
+func (e *Error) Error() string {
+	unit := e.Unit
+	if unit == "" {
+		unit = "bytes"
+	}
+	return fmt.Sprintf("%s exceeds limit of %d %s", e.What, e.Limit, unit)
+}
+
+func (e *Error) Is(target error) bool { return target == ErrExceeded }
+
+// Check returns an *Error if n exceeds max. A max of zero or less disables
+// the limit.
+func Check(what string, n, max int64, unit string) error {
+	if max > 0 && n > max {
+		return &Error{What: what, Limit: max, Unit: unit}
+	}
+	return nil
+}
+
+// readAhead bounds how much decompressed data is buffered ahead of the parser.
+const readAhead = 64 * 1024
+
+// NewReader returns a buffered reader that fails with an *Error once more than
+// max bytes have been read from r. A max of zero or less disables the limit.
+// what names the stream in error messages.
+func NewReader(r io.Reader, max int64, what string) io.Reader {
+	if max > 0 {
+		r = &limitedReader{r: r, remaining: max, err: &Error{What: what, Limit: max}}
+	}
+	return bufio.NewReaderSize(r, readAhead)
+}
+
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	err       *Error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Probe for data beyond the limit to tell a clean end from an overflow
+		var probe [1]byte
+		n, err := io.ReadFull(l.r, probe[:])
+		if n > 0 {
+			return 0, l.err
+		}
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// NewWriter returns a writer that fails with an *Error instead of writing
+// more than max bytes to w; the bytes up to the limit are written. A max of
+// zero or less disables the limit. what names the output in error messages.
+func NewWriter(w io.Writer, max int64, what string) io.Writer {
+	if max <= 0 {
+		return w
+	}
+	return &limitedWriter{w: w, remaining: max, err: &Error{What: what, Limit: max}}
+}
+
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+	err       *Error
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) <= l.remaining {
+		n, err := l.w.Write(p)
+		l.remaining -= int64(n)
+		return n, err
+	}
+	n, err := l.w.Write(p[:l.remaining])
+	l.remaining -= int64(n)
+	if err == nil {
+		err = l.err
+	}
+	return n, err
+}
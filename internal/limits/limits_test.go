@@ -0,0 +1,57 @@
+package limits
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReaderWithinLimit(t *testing.T) {
+	data, err := io.ReadAll(NewReader(strings.NewReader("hello"), 5, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("unexpected data %q", data)
+	}
+}
+
+func TestReaderExceedsLimit(t *testing.T) {
+	_, err := io.ReadAll(NewReader(strings.NewReader("hello world"), 5, "test"))
+	if !errors.Is(err, ErrExceeded) {
+		t.Fatalf("expected ErrExceeded, got %v", err)
+	}
+}
+
+func TestReaderUnlimited(t *testing.T) {
+	data, err := io.ReadAll(NewReader(strings.NewReader("hello world"), 0, "test"))
+	if err != nil || len(data) != 11 {
+		t.Errorf("unexpected result %q, %v", data, err)
+	}
+}
+
+func TestWriterExceedsLimit(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b, 5, "test")
+	if _, err := io.WriteString(w, "hel"); err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.WriteString(w, "lo world")
+	if !errors.Is(err, ErrExceeded) || n != 2 || b.String() != "hello" {
+		t.Errorf("wrote %d (%q), %v; want the output cut at the limit", n, b.String(), err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check("table", 100, 100, "cells"); err != nil {
+		t.Errorf("Check within limit = %v", err)
+	}
+	if err := Check("table", 101, 0, "cells"); err != nil {
+		t.Errorf("Check without limit = %v", err)
+	}
+	err := Check("table", 101, 100, "cells")
+	if !errors.Is(err, ErrExceeded) || err.Error() != "table exceeds limit of 100 cells" {
+		t.Errorf("Check = %v", err)
+	}
+}
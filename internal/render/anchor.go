@@ -0,0 +1,82 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// anchorIDs assigns element ids derived from names such as bookmark names
+// and heading text. Ids depend only on the names seen so far, so they are
+// stable across runs for the same document; repeated names get -2, -3, ...
+// suffixes.
+type anchorIDs struct {
+	used map[string]bool
+	// bookmarks maps bookmark names to the id of their first anchor
+	bookmarks map[string]string
+}
+
+func newAnchorIDs() *anchorIDs {
+	return &anchorIDs{used: make(map[string]bool), bookmarks: make(map[string]string)}
+}
+
+// bookmark returns a new unique id for the anchor of a bookmark, which
+// links to the bookmark then resolve to.
+func (a *anchorIDs) bookmark(name string) string {
+	id := a.id(name)
+	if _, ok := a.bookmarks[name]; !ok {
+		a.bookmarks[name] = id
+	}
+	return id
+}
+
+// href returns the link target for a hyperlink URL. Links to a bookmark in
+// the document ("#name", "?name" or "?#name") become in-page links to its
+// anchor; bookmarks not seen yet are assumed to get the id of their name.
+// Other URLs are returned as they are.
+func (a *anchorIDs) href(url string) string {
+	name, ok := strings.CutPrefix(url, "?")
+	if after, hash := strings.CutPrefix(name, "#"); hash {
+		name, ok = after, true
+	}
+	if !ok || name == "" {
+		return url
+	}
+	if id, seen := a.bookmarks[name]; seen {
+		return "#" + id
+	}
+	return "#" + anchorSlug(name)
+}
+
+// id returns a new unique id for name.
+func (a *anchorIDs) id(name string) string {
+	base := anchorSlug(name)
+	id := base
+	for n := 2; a.used[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	a.used[id] = true
+	return id
+}
+
+// anchorSlug lowercases name and replaces runs of characters other than
+// letters and digits (Hangul included) with a single '-'.
+func anchorSlug(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(r)
+		} else {
+			dash = true
+		}
+	}
+	if sb.Len() == 0 {
+		return "anchor"
+	}
+	return sb.String()
+}
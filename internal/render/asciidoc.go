@@ -0,0 +1,162 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderAsciiDoc renders a ContentNodeScanner as an AsciiDoc document.
+// Tables use the |=== block with span specifiers (2+| for columns, .2+| for rows).
+func RenderAsciiDoc(scanner document.ContentNodeScanner, w io.Writer) error {
+	first := true
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		var block string
+		node = chartAsTable(node)
+		switch n := node.(type) {
+		case *document.Paragraph:
+			if n.Preformatted {
+				block = asciidocLiteral(n.Text)
+			} else {
+				block = asciidocParagraph(n.Text)
+			}
+		case *document.Heading:
+			if title := strings.Join(strings.Fields(n.Text), " "); title != "" {
+				// Level 0 (=) is the document title; sections go to level 5
+				block = strings.Repeat("=", min(n.Level, 5)+1) + " " + title
+			}
+		case *document.Table:
+			block = asciidocTitle(n.Caption, asciidocTable(n))
+		case *document.Image:
+			block = asciidocTitle(n.Caption, "{empty}"+imageText(n))
+		case *document.Media:
+			block = asciidocTitle(n.Caption, "{empty}"+mediaText(n))
+		case *document.Equation:
+			block = asciidocTitle(n.Caption, "[latexmath]\n++++\n"+n.LaTeX+"\n++++")
+		case *document.Footnote:
+			block = asciidocParagraph(noteText(n))
+		}
+		if block == "" {
+			continue
+		}
+
+		// Blocks are separated by a blank line
+		if !first {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		first = false
+		if _, err := fmt.Fprintln(w, block); err != nil {
+			return err
+		}
+	}
+}
+
+// asciidocBlockStart matches line prefixes AsciiDoc would parse as block
+// markup (section titles, lists, delimiters, attribute lists, comments).
+var asciidocBlockStart = regexp.MustCompile(`^(=+ |[*.\-]+ |\d+\. |\[|//|\|===|----|\.\.\.\.|____|\+\+\+\+|<<<|'''|:[^:]*: )`)
+
+// asciidocParagraph renders paragraph text, keeping line breaks as hard breaks.
+func asciidocParagraph(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if asciidocBlockStart.MatchString(line) {
+			lines[i] = "{empty}" + line
+		}
+	}
+	return strings.Join(lines, " +\n")
+}
+
+// asciidocLiteral renders monospaced text as a literal block, using a
+// delimiter longer than any line of dots in the text.
+func asciidocLiteral(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	delim := "...."
+	for strings.Contains("\n"+text+"\n", "\n"+delim+"\n") {
+		delim += "."
+	}
+	return delim + "\n" + text + "\n" + delim
+}
+
+// asciidocTitle prefixes a block with a .Title line for its caption.
+func asciidocTitle(caption, block string) string {
+	if caption == "" || block == "" {
+		return block
+	}
+	return "." + strings.Join(strings.Fields(caption), " ") + "\n" + block
+}
+
+func asciidocTable(t *document.Table) string {
+	if len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+		return ""
+	}
+	grid := newTableGrid(t)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[cols=\"%d*\"]\n|===\n", t.Cols)
+	for row := 0; row < grid.rows; row++ {
+		if row > 0 {
+			sb.WriteString("\n")
+		}
+		for col := 0; col < grid.cols; col++ {
+			if grid.covered(row, col) {
+				continue
+			}
+			cell := grid.at(row, col)
+			if cell == nil {
+				// Keep the grid aligned when the source has a hole
+				sb.WriteString("|\n")
+				continue
+			}
+			sb.WriteString(asciidocSpan(grid.colSpan(cell), grid.rowSpan(cell)))
+			sb.WriteString("|")
+			sb.WriteString(asciidocCellText(cell.TextWithTables()))
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("|===")
+	return sb.String()
+}
+
+// asciidocSpan returns the span specifier placed before a cell's | separator.
+func asciidocSpan(colSpan, rowSpan int) string {
+	switch {
+	case colSpan > 1 && rowSpan > 1:
+		return fmt.Sprintf("%d.%d+", colSpan, rowSpan)
+	case colSpan > 1:
+		return fmt.Sprintf("%d+", colSpan)
+	case rowSpan > 1:
+		return fmt.Sprintf(".%d+", rowSpan)
+	}
+	return ""
+}
+
+func asciidocCellText(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "|", "\\|")
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines, " +\n")
+}
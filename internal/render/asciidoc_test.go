@@ -0,0 +1,41 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderAsciiDoc(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "= 제목처럼 보이는 줄\n둘째 줄"},
+		&document.Table{Rows: 2, Cols: 3, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1, Text: "A"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 2, Text: "B|C"},
+			{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Text: "1"},
+			{Row: 1, Col: 2, RowSpan: 1, ColSpan: 1, Text: "2"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderAsciiDoc(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{empty}= 제목처럼 보이는 줄 +
+둘째 줄
+
+[cols="3*"]
+|===
+.2+|A
+2+|B\|C
+
+|1
+|2
+|===
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
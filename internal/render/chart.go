@@ -0,0 +1,54 @@
+package render
+
+import (
+	"strconv"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// chartAsTable returns a chart as a table of its data, which renderers
+// without a chart representation show instead: a header row of series
+// names, then a row of values for each category. Other nodes are returned
+// as they are.
+func chartAsTable(node document.ContentNode) document.ContentNode {
+	c, ok := node.(*document.Chart)
+	if !ok {
+		return node
+	}
+	rows := len(c.Categories)
+	for _, s := range c.Series {
+		rows = max(rows, len(s.Values))
+	}
+	t := &document.Table{
+		ID:        c.ID,
+		Rows:      rows + 1,
+		Cols:      len(c.Series) + 1,
+		Caption:   c.Caption,
+		HeaderRow: true,
+	}
+	if t.Caption == "" {
+		t.Caption = c.Title
+	}
+	cell := func(row, col int, text string) {
+		t.Cells = append(t.Cells, document.Cell{Row: row, Col: col, RowSpan: 1, ColSpan: 1, Text: text})
+	}
+	cell(0, 0, "")
+	for i, s := range c.Series {
+		cell(0, i+1, s.Name)
+	}
+	for row := range rows {
+		category := ""
+		if row < len(c.Categories) {
+			category = c.Categories[row]
+		}
+		cell(row+1, 0, category)
+		for i, s := range c.Series {
+			value := ""
+			if row < len(s.Values) {
+				value = strconv.FormatFloat(s.Values[row], 'f', -1, 64)
+			}
+			cell(row+1, i+1, value)
+		}
+	}
+	return t
+}
@@ -0,0 +1,26 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestChartAsTable(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Chart{Kind: "bar", Title: "매출", Categories: []string{"1분기", "2분기"}, Series: []document.ChartSeries{
+			{Name: "2023", Values: []float64{10, 12.5}},
+			{Name: "2024", Values: []float64{11}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderMarkdown(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "매출\n\n|  | 2023 | 2024 |\n| --- | --- | --- |\n| 1분기 | 10 | 11 |\n| 2분기 | 12.5 |  |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderCSV writes the tables of a document as CSV, one record per row and
+// tables separated by an empty line. Charts are written as tables of their
+// data; other content is ignored. A merged cell's text is repeated in every
+// position it covers, so each record is complete for data analysis.
+func RenderCSV(scanner document.ContentNodeScanner, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	first := true
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		t, ok := chartAsTable(node).(*document.Table)
+		if !ok || len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+			continue
+		}
+		if !first {
+			cw.Flush()
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		grid := newTableGrid(t)
+		for row := 0; row < grid.rows; row++ {
+			record := make([]string, grid.cols)
+			for col := range record {
+				if cell := grid.owner[row][col]; cell != nil {
+					record[col] = strings.TrimSpace(cell.TextWithTables())
+				}
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,32 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderCSV(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "ignored"},
+		&document.Table{Rows: 2, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1, Text: "지역"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "1,234"},
+			{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Text: "a \"b\""},
+		}},
+		&document.Table{Rows: 1, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "x"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderCSV(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "지역,\"1,234\"\n지역,\"a \"\"b\"\"\"\n\nx,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderDocBook renders a ContentNodeScanner as a DocBook 5 article.
+// Tables use the CALS model with namest/nameend and morerows for spans, and
+// images become mediaobjects. Blocks are written as they are scanned.
+func RenderDocBook(scanner document.ContentNodeScanner, w io.Writer) error {
+	const header = `<?xml version="1.0" encoding="UTF-8"?>
+<article xmlns="http://docbook.org/ns/docbook" version="5.0">
+<info><title/></info>
+`
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		var block string
+		node = chartAsTable(node)
+		switch n := node.(type) {
+		case *document.Paragraph:
+			if n.Preformatted {
+				block = docbookScreen(n.Text)
+			} else {
+				block = docbookParagraph(n.Text)
+			}
+		case *document.Heading:
+			// Section titles become bridgeheads, as the sections themselves
+			// are not delimited
+			if title := strings.Join(strings.Fields(n.Text), " "); title != "" {
+				block = fmt.Sprintf(`<bridgehead renderas="sect%d">%s</bridgehead>`, min(n.Level, 5), xmlText(title))
+			}
+		case *document.Table:
+			block = docbookTable(n)
+		case *document.Media:
+			content := xmlText(mediaText(n))
+			if url := mediaURL(n); url != "" {
+				content = mediaPlaceholder + " <uri>" + xmlText(url) + "</uri>"
+			}
+			block = "<para>" + content + "</para>"
+			if n.Caption != "" {
+				block = "<figure><title>" + xmlText(n.Caption) + "</title>" + block + "</figure>"
+			}
+		case *document.Equation:
+			math := `<alt role="tex">` + xmlText(n.LaTeX) + "</alt><mathphrase>" + xmlText(n.Script) + "</mathphrase>"
+			block = "<informalequation>" + math + "</informalequation>"
+			if n.Caption != "" {
+				block = "<equation><title>" + xmlText(n.Caption) + "</title>" + math + "</equation>"
+			}
+		case *document.Image:
+			block = "<mediaobject><textobject><phrase>" + xmlText(imageText(n)) + "</phrase></textobject></mediaobject>"
+			if n.Caption != "" {
+				block = "<figure><title>" + xmlText(n.Caption) + "</title>" + block + "</figure>"
+			}
+		case *document.Footnote:
+			block = docbookParagraph(noteText(n))
+		}
+		if block == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, block); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "</article>\n")
+	return err
+}
+
+// xmlText escapes text for use in element content and attribute values.
+func xmlText(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// docbookParagraph renders a para, or a literallayout when the paragraph
+// contains line breaks that must be preserved.
+func docbookParagraph(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	if strings.Contains(text, "\n") {
+		// EscapeText encodes newlines as &#xA;, which keeps them in the layout
+		return "<literallayout>" + xmlText(text) + "</literallayout>"
+	}
+	return "<para>" + xmlText(text) + "</para>"
+}
+
+// docbookScreen renders monospaced text as a screen, which is verbatim.
+func docbookScreen(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	return "<screen>" + xmlText(text) + "</screen>"
+}
+
+func docbookTable(t *document.Table) string {
+	if len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+		return ""
+	}
+	grid := newTableGrid(t)
+
+	// Captioned tables are formal tables with a title
+	element := "informaltable"
+	var sb strings.Builder
+	if t.Caption != "" {
+		element = "table"
+		sb.WriteString("<table>\n<title>" + xmlText(t.Caption) + "</title>\n")
+	} else {
+		sb.WriteString("<informaltable>\n")
+	}
+	fmt.Fprintf(&sb, "<tgroup cols=\"%d\">\n", grid.cols)
+	for c := 1; c <= grid.cols; c++ {
+		fmt.Fprintf(&sb, "<colspec colname=\"c%d\"/>\n", c)
+	}
+	sb.WriteString("<tbody>\n")
+
+	for row := 0; row < grid.rows; row++ {
+		sb.WriteString("<row>")
+		for col := 0; col < grid.cols; col++ {
+			if grid.covered(row, col) {
+				continue
+			}
+			cell := grid.at(row, col)
+			if cell == nil {
+				fmt.Fprintf(&sb, "<entry colname=\"c%d\"/>", col+1)
+				continue
+			}
+
+			colSpan, rowSpan := grid.colSpan(cell), grid.rowSpan(cell)
+			if colSpan > 1 {
+				fmt.Fprintf(&sb, "<entry namest=\"c%d\" nameend=\"c%d\"", col+1, col+colSpan)
+			} else {
+				fmt.Fprintf(&sb, "<entry colname=\"c%d\"", col+1)
+			}
+			if rowSpan > 1 {
+				fmt.Fprintf(&sb, " morerows=\"%d\"", rowSpan-1)
+			}
+			sb.WriteString(">")
+			for _, line := range strings.Split(strings.TrimSpace(cell.TextWithTables()), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					sb.WriteString("<para>" + xmlText(line) + "</para>")
+				}
+			}
+			sb.WriteString("</entry>")
+		}
+		sb.WriteString("</row>\n")
+	}
+
+	sb.WriteString("</tbody>\n</tgroup>\n</" + element + ">")
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderDocBook(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "A & B"},
+		&document.Table{Rows: 2, Cols: 3, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1, Text: "A"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 2, Text: "B"},
+			{Row: 1, Col: 2, RowSpan: 1, ColSpan: 1, Text: "C"},
+		}},
+		&document.Image{},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderDocBook(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	var doc struct{}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not well-formed: %v\n%s", err, out)
+	}
+
+	for _, want := range []string{
+		"<para>A &amp; B</para>",
+		`<entry colname="c1" morerows="1"><para>A</para></entry>`,
+		`<entry namest="c2" nameend="c3"><para>B</para></entry>`,
+		// Row 1 starts at column 2: the hole is explicit, column 0 is covered
+		`<row><entry colname="c2"/><entry colname="c3"><para>C</para></entry></row>`,
+		"<mediaobject>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestDocBookCaptions(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Table{Rows: 1, Cols: 1, Caption: "표 1. 예산", Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "A"},
+		}},
+		&document.Image{Caption: "그림 1"},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderDocBook(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if err := xml.Unmarshal(buf.Bytes(), new(struct{})); err != nil {
+		t.Fatalf("output is not well-formed: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"<table>\n<title>표 1. 예산</title>\n<tgroup",
+		"</tgroup>\n</table>",
+		"<figure><title>그림 1</title><mediaobject>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
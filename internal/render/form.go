@@ -0,0 +1,106 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// formField is a form field with the ID of the node holding it.
+type formField struct {
+	ID string `json:"id,omitempty"`
+	document.Field
+}
+
+// collectFormFields scans the document for form fields. Fields in table
+// cells get the cell's ID, as in XLIFF output.
+func collectFormFields(scanner document.ContentNodeScanner) ([]formField, error) {
+	fields := []formField{}
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				return fields, nil
+			}
+			return nil, fmt.Errorf("error reading content: %w", err)
+		}
+
+		switch n := node.(type) {
+		case *document.Paragraph:
+			for _, f := range n.Fields {
+				fields = append(fields, formField{n.ID, f})
+			}
+		case *document.Heading:
+			for _, f := range n.Fields {
+				fields = append(fields, formField{n.ID, f})
+			}
+		case *document.Table:
+			fields = tableFormFields(fields, n)
+		}
+	}
+}
+
+// tableFormFields appends the fields in the cells of a table, including
+// those of nested tables.
+func tableFormFields(fields []formField, t *document.Table) []formField {
+	for _, cell := range t.Cells {
+		for _, f := range cell.Fields {
+			fields = append(fields, formField{xliffChildID(t.ID, fmt.Sprintf("c%d-%d", cell.Row, cell.Col)), f})
+		}
+		for _, nested := range cell.Tables {
+			fields = tableFormFields(fields, nested)
+		}
+	}
+	return fields
+}
+
+// RenderFormJSON writes the form fields of a document (click-here fields
+// and form controls) with their values as a JSON object:
+//
+//	{"fields":[{"id":"s0.r3","name":"성명","type":"click-here","value":"홍길동"}]}
+func RenderFormJSON(scanner document.ContentNodeScanner, w io.Writer) error {
+	fields, err := collectFormFields(scanner)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(struct {
+		Fields []formField `json:"fields"`
+	}{fields})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// RenderXFDF writes the form fields of a document as XFDF, the XML form
+// data format of PDF forms. Fields without a name are named after their
+// node ID.
+func RenderXFDF(scanner document.ContentNodeScanner, w io.Writer) error {
+	fields, err := collectFormFields(scanner)
+	if err != nil {
+		return err
+	}
+
+	const header = `<?xml version="1.0" encoding="UTF-8"?>
+<xfdf xmlns="http://ns.adobe.com/xfdf/" xml:space="preserve">
+<fields>
+`
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		name := f.Name
+		if name == "" {
+			name = f.ID
+		}
+		if _, err := fmt.Fprintf(w, "<field name=\"%s\"><value>%s</value></field>\n", xmlText(name), xliffText(f.Value)); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "</fields>\n</xfdf>\n")
+	return err
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func formTestScanner() *sliceScanner {
+	return &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{ID: "s0.p0", Text: "성명: 홍길동", Fields: []document.Field{
+			{Name: "성명", Type: document.FieldClickHere, Value: "홍길동"},
+		}},
+		&document.Paragraph{ID: "s0.p1", Text: "no fields"},
+		&document.Table{ID: "s0.t0", Rows: 1, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "동의"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Fields: []document.Field{
+				{Name: "agree", Type: document.FieldCheckBox, Value: "on"},
+				{Type: document.FieldEdit, Value: "a < b"},
+			}},
+		}},
+	}}
+}
+
+func TestRenderFormJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderFormJSON(formTestScanner(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"fields":[` +
+		`{"id":"s0.p0","name":"성명","type":"click-here","value":"홍길동"},` +
+		`{"id":"s0.t0.c0-1","name":"agree","type":"checkbox","value":"on"},` +
+		`{"id":"s0.t0.c0-1","name":"","type":"edit","value":"a \u003c b"}]}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := RenderFormJSON(&sliceScanner{}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "{\"fields\":[]}\n" {
+		t.Errorf("empty document: got %q", got)
+	}
+}
+
+func TestRenderXFDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderXFDF(formTestScanner(), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Fields []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:"value"`
+		} `xml:"fields>field"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not well-formed: %v\n%s", err, buf.String())
+	}
+
+	want := [][2]string{{"성명", "홍길동"}, {"agree", "on"}, {"s0.t0.c0-1", "a < b"}}
+	if len(doc.Fields) != len(want) {
+		t.Fatalf("got %d fields, want %d:\n%s", len(doc.Fields), len(want), buf.String())
+	}
+	for i, f := range doc.Fields {
+		if f.Name != want[i][0] || f.Value != want[i][1] {
+			t.Errorf("field %d = %q: %q, want %q: %q", i, f.Name, f.Value, want[i][0], want[i][1])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import "github.com/hanpama/hwp/internal/document"
+
+// tableGrid maps each position of a document table to the cell covering it.
+// Markup formats list cells in row order and omit positions covered by spans,
+// so they need to know which positions start a cell.
+type tableGrid struct {
+	rows  int
+	cols  int
+	owner [][]*document.Cell
+}
+
+func newTableGrid(t *document.Table) *tableGrid {
+	g := &tableGrid{
+		rows:  t.Rows,
+		cols:  t.Cols,
+		owner: make([][]*document.Cell, t.Rows),
+	}
+	for i := range g.owner {
+		g.owner[i] = make([]*document.Cell, t.Cols)
+	}
+
+	for i := range t.Cells {
+		cell := &t.Cells[i]
+		for r := 0; r < cell.RowSpan && cell.Row+r < t.Rows; r++ {
+			for c := 0; c < cell.ColSpan && cell.Col+c < t.Cols; c++ {
+				if cell.Row+r >= 0 && cell.Col+c >= 0 {
+					g.owner[cell.Row+r][cell.Col+c] = cell
+				}
+			}
+		}
+	}
+	return g
+}
+
+// at returns the cell starting at the given position, or nil if the position
+// is empty or covered by a span from another position.
+func (g *tableGrid) at(row, col int) *document.Cell {
+	cell := g.owner[row][col]
+	if cell == nil || cell.Row != row || cell.Col != col {
+		return nil
+	}
+	return cell
+}
+
+// covered reports whether the position belongs to a cell starting elsewhere.
+func (g *tableGrid) covered(row, col int) bool {
+	cell := g.owner[row][col]
+	return cell != nil && (cell.Row != row || cell.Col != col)
+}
+
+// colSpan returns the number of grid columns a cell occupies, clipped to the grid.
+func (g *tableGrid) colSpan(cell *document.Cell) int {
+	return min(max(cell.ColSpan, 1), g.cols-cell.Col)
+}
+
+// rowSpan returns the number of grid rows a cell occupies, clipped to the grid.
+func (g *tableGrid) rowSpan(cell *document.Cell) int {
+	return min(max(cell.RowSpan, 1), g.rows-cell.Row)
+}
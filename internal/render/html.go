@@ -0,0 +1,369 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderHTML renders a ContentNodeScanner as a standalone HTML5 document.
+// Tables keep their spans with rowspan/colspan, and their cells' shading,
+// alignment and borders as inline styles; borderless tables get the
+// borderless class. Monospaced paragraphs
+// become pre blocks. Headings and bookmarks get stable ids, and links to
+// bookmarks become in-page links. Node IDs are kept as data-id attributes. Blocks are written as they are scanned.
+func RenderHTML(scanner document.ContentNodeScanner, w io.Writer) error {
+	const header = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title></title>
+</head>
+<body>
+`
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+
+	anchors := newAnchorIDs()
+	var lists htmlLists
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		var block string
+		node = chartAsTable(node)
+		switch n := node.(type) {
+		case *document.Paragraph:
+			block = htmlParagraph(n, "p", anchors)
+			if n.List != nil && block != "" {
+				block = lists.item(n.List) + block
+			}
+		case *document.Heading:
+			block = htmlParagraph(&n.Paragraph, fmt.Sprintf("h%d", min(n.Level, 6)), anchors)
+		case *document.Table:
+			block = htmlTable(n)
+		case *document.Media:
+			content := html.EscapeString(mediaText(n))
+			if url := mediaURL(n); url != "" {
+				content = `<a href="` + html.EscapeString(url) + `">` + mediaPlaceholder + "</a>"
+			}
+			block = `<p class="media"` + htmlDataID(n.ID) + `>` + content + "</p>"
+			if n.Caption != "" {
+				block = `<figure class="media"` + htmlDataID(n.ID) + `>` + content + "<figcaption>" + htmlLines(n.Caption) + "</figcaption></figure>"
+			}
+		case *document.Equation:
+			// Display math delimiters, as read by MathJax and KaTeX
+			block = `<p class="equation"` + htmlDataID(n.ID) + `>\[` + html.EscapeString(n.LaTeX) + `\]</p>`
+			if n.Caption != "" {
+				block = `<figure class="equation"` + htmlDataID(n.ID) + `>\[` + html.EscapeString(n.LaTeX) + `\]<figcaption>` + htmlLines(n.Caption) + "</figcaption></figure>"
+			}
+		case *document.Image:
+			block = `<p class="image"` + htmlDataID(n.ID) + ">" + html.EscapeString(imageText(n)) + "</p>"
+			if n.Caption != "" {
+				block = `<figure class="image"` + htmlDataID(n.ID) + ">" + html.EscapeString(imageText(n)) + "<figcaption>" + htmlLines(n.Caption) + "</figcaption></figure>"
+			}
+		case *document.Footnote:
+			class := "footnote"
+			if n.Endnote {
+				class = "endnote"
+			}
+			block = `<p` + htmlDataID(n.ID) + ` class="` + class + `">` + htmlLines(noteText(n)) + "</p>"
+		}
+		if block == "" {
+			continue
+		}
+		if p, ok := node.(*document.Paragraph); !ok || p.List == nil {
+			block = lists.close(0) + block
+		}
+		if _, err := fmt.Fprintln(w, block); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, lists.close(0)+"</body>\n</html>\n")
+	return err
+}
+
+// htmlLists tracks the ul and ol elements open around list items.
+type htmlLists struct {
+	// ordered holds whether each open list is an ol, innermost last. The
+	// last li of each is open too.
+	ordered []bool
+}
+
+// item returns the tags that go before a list item's content: those
+// closing the lists deeper than it or of another kind and the previous
+// item, and those opening its list and its li.
+func (l *htmlLists) item(item *document.ListItem) string {
+	level := max(item.Level, 1)
+	var b strings.Builder
+	b.WriteString(l.close(level))
+	if n := len(l.ordered); n == level {
+		if l.ordered[n-1] == item.Ordered {
+			b.WriteString("</li>\n")
+		} else {
+			b.WriteString(l.close(level - 1))
+		}
+	}
+	for len(l.ordered) < level {
+		l.ordered = append(l.ordered, item.Ordered)
+		switch {
+		case !item.Ordered:
+			b.WriteString("<ul>\n")
+		case len(l.ordered) == level && item.Number > 1:
+			fmt.Fprintf(&b, "<ol start=\"%d\">\n", item.Number)
+		default:
+			b.WriteString("<ol>\n")
+		}
+		// Levels skipped over get an item to nest in
+		if len(l.ordered) < level {
+			b.WriteString("<li>\n")
+		}
+	}
+	b.WriteString("<li>")
+	return b.String()
+}
+
+// close returns the tags closing the lists deeper than depth.
+func (l *htmlLists) close(depth int) string {
+	var b strings.Builder
+	for len(l.ordered) > depth {
+		if l.ordered[len(l.ordered)-1] {
+			b.WriteString("</li>\n</ol>\n")
+		} else {
+			b.WriteString("</li>\n</ul>\n")
+		}
+		l.ordered = l.ordered[:len(l.ordered)-1]
+	}
+	return b.String()
+}
+
+// htmlParagraph renders a p, or a heading element for a heading, with br
+// line breaks, or a pre for monospaced text.
+// Floating paragraphs get the floating class and hidden comments the
+// hidden-comment class. Alignment and indentation become inline styles.
+// Bookmarks become empty a elements at the start of the block, and
+// headings get an id from their text.
+func htmlParagraph(p *document.Paragraph, tag string, anchors *anchorIDs) string {
+	var marks strings.Builder
+	for _, name := range p.Bookmarks {
+		fmt.Fprintf(&marks, `<a id="%s"></a>`, html.EscapeString(anchors.bookmark(name)))
+	}
+
+	text := strings.TrimRight(p.Text, "\n")
+	id := htmlDataID(p.ID)
+	if tag != "p" && strings.TrimSpace(text) != "" {
+		id = ` id="` + html.EscapeString(anchors.id(text)) + `"` + id
+	}
+	if p.Floating {
+		id += ` class="floating"`
+	} else if p.Hidden {
+		id += ` class="hidden-comment"`
+	}
+	if style := htmlLayoutStyle(p.Layout); style != "" {
+		id += ` style="` + style + `"`
+	}
+	if strings.TrimSpace(text) == "" {
+		if marks.Len() == 0 {
+			return ""
+		}
+		return "<" + tag + id + ">" + marks.String() + "</" + tag + ">"
+	}
+	if p.Preformatted && tag == "p" {
+		return "<pre" + id + ">" + marks.String() + "<code>" + html.EscapeString(text) + "</code></pre>"
+	}
+	return "<" + tag + id + ">" + marks.String() + htmlLinkedLines(text, p, anchors) + "</" + tag + ">"
+}
+
+// htmlLinkedLines is htmlLines for the text of a paragraph, with its
+// hyperlinks as a elements, links to bookmarks resolved to their anchors,
+// tracked changes as ins and del elements and
+// emphasis as strong, em, u, s, sup and sub.
+func htmlLinkedLines(text string, p *document.Paragraph, anchors *anchorIDs) string {
+	var sb strings.Builder
+	for _, span := range paragraphSpans(text, p.Hyperlinks, p.Changes, p.Runs) {
+		content := htmlLines(span.text)
+		if strings.TrimSpace(span.text) != "" {
+			tags := emphasisTags(span.run)
+			for i := len(tags) - 1; i >= 0; i-- {
+				content = "<" + tags[i] + ">" + content + "</" + tags[i] + ">"
+			}
+		}
+		if span.url != "" {
+			title := ""
+			if span.title != "" {
+				title = ` title="` + html.EscapeString(span.title) + `"`
+			}
+			content = fmt.Sprintf(`<a href="%s"%s>%s</a>`, html.EscapeString(anchors.href(span.url)), title, content)
+		}
+		if c := span.change; c != nil {
+			tag := "ins"
+			if c.Type == document.ChangeDelete {
+				tag = "del"
+			}
+			var attrs string
+			if c.Date != "" {
+				attrs += ` datetime="` + html.EscapeString(c.Date) + `"`
+			}
+			if c.Author != "" {
+				attrs += ` title="` + html.EscapeString(c.Author) + `"`
+			}
+			content = "<" + tag + attrs + ">" + content + "</" + tag + ">"
+		}
+		sb.WriteString(content)
+	}
+	return sb.String()
+}
+
+// htmlLayoutStyle returns CSS declarations for a paragraph layout's
+// alignment and indentation. Spacing is left to the stylesheet.
+func htmlLayoutStyle(l *document.Layout) string {
+	if l == nil {
+		return ""
+	}
+	var decls []string
+	switch l.Align {
+	case document.AlignCenter, document.AlignRight:
+		decls = append(decls, "text-align: "+l.Align)
+	case document.AlignDistribute, document.AlignDivide:
+		decls = append(decls, "text-align: justify", "text-align-last: justify")
+	}
+	for _, d := range []struct {
+		name  string
+		value float64
+	}{{"margin-left", l.MarginLeft}, {"margin-right", l.MarginRight}, {"text-indent", l.Indent}} {
+		if d.value != 0 {
+			decls = append(decls, d.name+": "+strconv.FormatFloat(d.value, 'f', -1, 64)+"pt")
+		}
+	}
+	return strings.Join(decls, "; ")
+}
+
+// htmlCellStyle returns the inline style of a table cell: its background,
+// the alignment of its text and, in tables with borders, the sides it has
+// a border on.
+func htmlCellStyle(cell *document.Cell, borderless bool) string {
+	var decls []string
+	if cell.Background != "" {
+		decls = append(decls, "background-color: "+cell.Background)
+	}
+	switch cell.Align {
+	case document.AlignCenter, document.AlignRight:
+		decls = append(decls, "text-align: "+cell.Align)
+	case document.AlignDistribute, document.AlignDivide:
+		decls = append(decls, "text-align: justify")
+	}
+	if cell.VAlign != "" {
+		decls = append(decls, "vertical-align: "+cell.VAlign)
+	}
+	if b := cell.Borders; b != nil && !borderless {
+		if b.Left && b.Right && b.Top && b.Bottom {
+			decls = append(decls, "border: 1px solid")
+		} else {
+			for _, side := range []struct {
+				name  string
+				drawn bool
+			}{{"left", b.Left}, {"right", b.Right}, {"top", b.Top}, {"bottom", b.Bottom}} {
+				if side.drawn {
+					decls = append(decls, "border-"+side.name+": 1px solid")
+				}
+			}
+		}
+	}
+	return strings.Join(decls, "; ")
+}
+
+// htmlCellText returns the trimmed text of a cell with br line breaks and
+// its tracked changes as ins and del elements.
+func htmlCellText(cell *document.Cell) string {
+	text := strings.TrimSpace(cell.Text)
+	if len(cell.Changes) == 0 {
+		return htmlLines(text)
+	}
+	// Change offsets are into the untrimmed text
+	lead := len(cell.Text) - len(strings.TrimLeftFunc(cell.Text, unicode.IsSpace))
+	changes := make([]document.Change, len(cell.Changes))
+	for i, change := range cell.Changes {
+		change.Offset -= lead
+		changes[i] = change
+	}
+	return htmlLinkedLines(text, &document.Paragraph{Changes: changes}, nil)
+}
+
+// htmlDataID returns a data-id attribute for a node ID, if there is one.
+func htmlDataID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return ` data-id="` + html.EscapeString(id) + `"`
+}
+
+// htmlLines escapes text and turns line breaks into br elements.
+func htmlLines(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>\n")
+}
+
+func htmlTable(t *document.Table) string {
+	if len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+		return ""
+	}
+	grid := newTableGrid(t)
+
+	var sb strings.Builder
+	sb.WriteString("<table" + htmlDataID(t.ID))
+	if t.Borderless {
+		sb.WriteString(` class="borderless"`)
+	}
+	sb.WriteString(">\n")
+	if t.Caption != "" {
+		sb.WriteString("<caption>" + htmlLines(t.Caption) + "</caption>\n")
+	}
+	for row := 0; row < grid.rows; row++ {
+		sb.WriteString("<tr>")
+		for col := 0; col < grid.cols; col++ {
+			if grid.covered(row, col) {
+				continue
+			}
+			cell := grid.at(row, col)
+			if cell == nil {
+				sb.WriteString("<td></td>")
+				continue
+			}
+
+			tag := "td"
+			if t.HeaderRow && row == 0 {
+				tag = "th"
+			}
+			sb.WriteString("<" + tag)
+			if span := grid.colSpan(cell); span > 1 {
+				fmt.Fprintf(&sb, ` colspan="%d"`, span)
+			}
+			if span := grid.rowSpan(cell); span > 1 {
+				fmt.Fprintf(&sb, ` rowspan="%d"`, span)
+			}
+			if style := htmlCellStyle(cell, t.Borderless); style != "" {
+				sb.WriteString(` style="` + html.EscapeString(style) + `"`)
+			}
+			sb.WriteString(">")
+			sb.WriteString(htmlCellText(cell))
+			for _, nested := range cell.Tables {
+				sb.WriteString("\n" + htmlTable(nested) + "\n")
+			}
+			sb.WriteString("</" + tag + ">")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
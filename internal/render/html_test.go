@@ -0,0 +1,223 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderHTML(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "A & B\nC", Bookmarks: []string{"Intro Part"}},
+		&document.Paragraph{Bookmarks: []string{"intro-part"}},
+		&document.Paragraph{ID: "s0.r2", Text: "box", Floating: true},
+		&document.Paragraph{ID: "s0.r3", Text: "note", Hidden: true},
+		&document.Paragraph{Text: "see a<b>", Hyperlinks: []document.Hyperlink{
+			{Offset: 4, Text: "a<b>", URL: "https://example.com/?q=1&r=2"},
+		}},
+		&document.Paragraph{ID: "s0.r4", Text: "a  <b>", Preformatted: true},
+		&document.Paragraph{Text: "a bold b", Runs: []document.Run{
+			{Offset: 0, Text: "a "}, {Offset: 2, Text: "bold", Bold: true, Underline: true}, {Offset: 6, Text: " b"},
+		}},
+		&document.Heading{Paragraph: document.Paragraph{ID: "s0.r5", Text: "개요", Bookmarks: []string{"outline"}}, Level: 2},
+		&document.Paragraph{Text: "Title", Layout: &document.Layout{Align: document.AlignCenter}},
+		&document.Paragraph{Text: "quote", Layout: &document.Layout{Align: document.AlignJustify, MarginLeft: 20, Indent: -10.5}},
+		&document.Paragraph{Text: "old new", Changes: []document.Change{
+			{Type: document.ChangeDelete, Offset: 0, Text: "old"},
+			{Type: document.ChangeInsert, Offset: 4, Text: "new", Author: "Kim", Date: "2024-05-01T09:00:00Z"},
+		}},
+		&document.Table{Rows: 2, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1, Text: "A"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "B"},
+		}},
+		&document.Table{Rows: 2, Cols: 1, HeaderRow: true, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "Name"},
+			{Row: 1, Col: 0, RowSpan: 1, ColSpan: 1, Text: "Kim"},
+		}},
+		&document.Image{},
+		&document.Image{Alt: "logo <small>"},
+		&document.Media{Source: "https://youtu.be/x?a=1&b=2"},
+		&document.Media{Source: "BIN0002.mp4", Embedded: true},
+		&document.Footnote{ID: "s0.p1.n0", Number: 1, Text: "출처 <1>"},
+		&document.Footnote{Number: 2, Text: "부록", Endnote: true},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<p><a id="intro-part"></a>A &amp; B<br>` + "\nC</p>",
+		`<p><a id="intro-part-2"></a></p>`,
+		`<p data-id="s0.r2" class="floating">box</p>`,
+		`<p data-id="s0.r3" class="hidden-comment">note</p>`,
+		`<p>see <a href="https://example.com/?q=1&amp;r=2">a&lt;b&gt;</a></p>`,
+		`<pre data-id="s0.r4"><code>a  &lt;b&gt;</code></pre>`,
+		`<p>a <strong><u>bold</u></strong> b</p>`,
+		`<h2 id="개요" data-id="s0.r5"><a id="outline"></a>개요</h2>`,
+		`<p style="text-align: center">Title</p>`,
+		`<p style="margin-left: 20pt; text-indent: -10.5pt">quote</p>`,
+		`<p><del>old</del> <ins datetime="2024-05-01T09:00:00Z" title="Kim">new</ins></p>`,
+		`<tr><td rowspan="2">A</td><td>B</td></tr>`,
+		// Row 1 column 0 is covered by the rowspan; column 1 is a hole
+		"<tr><td></td></tr>",
+		"<tr><th>Name</th></tr>\n<tr><td>Kim</td></tr>",
+		`<p class="image">[IMAGE]</p>`,
+		`<p class="image">[IMAGE] logo &lt;small&gt;</p>`,
+		`<p class="media"><a href="https://youtu.be/x?a=1&amp;b=2">[VIDEO]</a></p>`,
+		`<p class="media">[VIDEO] BIN0002.mp4</p>`,
+		`<p data-id="s0.p1.n0" class="footnote">1) 출처 &lt;1&gt;</p>`,
+		`<p class="endnote">2) 부록</p>`,
+		"</body>\n</html>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTMLNestedTable(t *testing.T) {
+	nested := &document.Table{ID: "s0.r1.c3", Rows: 1, Cols: 1, Cells: []document.Cell{
+		{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "in"},
+	}}
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Table{Rows: 1, Cols: 1, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "out", Tables: []*document.Table{nested}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "<tr><td>out\n<table data-id=\"s0.r1.c3\">\n<tr><td>in</td></tr>\n</table>\n</td></tr>"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestAnchorSlug(t *testing.T) {
+	for name, want := range map[string]string{
+		"Chapter 1. 개요": "chapter-1-개요",
+		"  __x__  ":     "x",
+		"!!!":           "anchor",
+	} {
+		if got := anchorSlug(name); got != want {
+			t.Errorf("anchorSlug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestHTMLInternalLinks(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "앞 참조", Hyperlinks: []document.Hyperlink{{Offset: 0, Text: "앞", URL: "?Table 1"}}},
+		&document.Heading{Level: 1, Paragraph: document.Paragraph{Text: "Table 1", Bookmarks: []string{"Table 1"}}},
+		&document.Heading{Level: 2, Paragraph: document.Paragraph{Text: "Table 1"}},
+		&document.Paragraph{Text: "뒤 웹", Hyperlinks: []document.Hyperlink{
+			{Offset: 0, Text: "뒤", URL: "#Table 1"},
+			{Offset: 4, Text: "웹", URL: "https://example.com/#top"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<p><a href="#table-1">앞</a> 참조</p>`,
+		`<h1 id="table-1-2"><a id="table-1"></a>Table 1</h1>`,
+		`<h2 id="table-1-3">Table 1</h2>`,
+		`<p><a href="#table-1">뒤</a> <a href="https://example.com/#top">웹</a></p>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestHTMLTableShading(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Table{Rows: 1, Cols: 2, Borderless: true, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "a", Background: "#ffcc00"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "b"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "<table class=\"borderless\">\n<tr><td style=\"background-color: #ffcc00\">a</td><td>b</td></tr>\n</table>"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestHTMLCellStyle(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Table{Rows: 1, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "a", Align: document.AlignCenter, VAlign: document.VAlignBottom,
+				Borders: &document.CellBorders{Left: true, Right: true, Top: true, Bottom: true}},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "b", Align: document.AlignDistribute,
+				Borders: &document.CellBorders{Top: true}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "<tr><td style=\"text-align: center; vertical-align: bottom; border: 1px solid\">a</td>" +
+		"<td style=\"text-align: justify; border-top: 1px solid\">b</td></tr>"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestHTMLCellChanges(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Table{Rows: 1, Cols: 1, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: " oldnew", Changes: []document.Change{
+				{Type: document.ChangeDelete, Offset: 1, Text: "old"},
+				{Type: document.ChangeInsert, Offset: 4, Text: "new"},
+			}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderHTML(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "<td><del>old</del><ins>new</ins></td>"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestHTMLLists(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "a", List: &document.ListItem{Ordered: true, Level: 1, Marker: "3.", Number: 3}},
+		&document.Paragraph{Text: "b", List: &document.ListItem{Level: 2, Marker: "●"}},
+		&document.Paragraph{Text: "c", List: &document.ListItem{Ordered: true, Level: 1, Marker: "4.", Number: 4}},
+		&document.Paragraph{Text: "d", List: &document.ListItem{Level: 1, Marker: "●"}},
+		&document.Paragraph{Text: "e"},
+	}}
+	var buf bytes.Buffer
+	if err := RenderHTML(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "<ol start=\"3\">\n<li><p>a</p>\n" +
+		"<ul>\n<li><p>b</p>\n" +
+		"</li>\n</ul>\n</li>\n<li><p>c</p>\n" +
+		"</li>\n</ol>\n<ul>\n<li><p>d</p>\n" +
+		"</li>\n</ul>\n<p>e</p>\n"
+	got := buf.String()
+	got = got[strings.Index(got, "<body>\n")+len("<body>\n") : strings.Index(got, "</body>")]
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderJSONL writes each content node as a single JSON object per line.
+// Nodes are written as soon as they are scanned, so the whole document is
+// never buffered in memory.
+func RenderJSONL(scanner document.ContentNodeScanner, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		if err := enc.Encode(jsonNode(node)); err != nil {
+			return err
+		}
+	}
+}
+
+// jsonNode wraps a content node with a "type" discriminator field.
+func jsonNode(node document.ContentNode) any {
+	switch n := node.(type) {
+	case *document.Paragraph:
+		return struct {
+			Type string `json:"type"`
+			*document.Paragraph
+		}{"paragraph", n}
+	case *document.Heading:
+		return struct {
+			Type string `json:"type"`
+			*document.Heading
+		}{"heading", n}
+	case *document.Table:
+		return struct {
+			Type string `json:"type"`
+			*document.Table
+		}{"table", n}
+	case *document.Image:
+		return struct {
+			Type string `json:"type"`
+			*document.Image
+		}{"image", n}
+	case *document.Equation:
+		return struct {
+			Type string `json:"type"`
+			*document.Equation
+		}{"equation", n}
+	case *document.Media:
+		return struct {
+			Type string `json:"type"`
+			*document.Media
+		}{"media", n}
+	case *document.Chart:
+		return struct {
+			Type string `json:"type"`
+			*document.Chart
+		}{"chart", n}
+	case *document.Footnote:
+		return struct {
+			Type string `json:"type"`
+			*document.Footnote
+		}{"footnote", n}
+	case *document.PageBreak:
+		return struct {
+			Type string `json:"type"`
+			*document.PageBreak
+		}{"page-break", n}
+	case *document.SectionBreak:
+		return struct {
+			Type string `json:"type"`
+			*document.SectionBreak
+		}{"section-break", n}
+	case *document.Comment:
+		return struct {
+			Type string `json:"type"`
+			*document.Comment
+		}{"comment", n}
+	default:
+		return struct {
+			Type string `json:"type"`
+		}{"unknown"}
+	}
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// sliceScanner replays a fixed list of content nodes.
+type sliceScanner struct {
+	nodes []document.ContentNode
+}
+
+func (s *sliceScanner) Next() (document.ContentNode, error) {
+	if len(s.nodes) == 0 {
+		return nil, io.EOF
+	}
+	node := s.nodes[0]
+	s.nodes = s.nodes[1:]
+	return node, nil
+}
+
+func TestRenderJSONL(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Heading{Paragraph: document.Paragraph{ID: "s0.r0", Text: "1. 개요"}, Level: 1},
+		&document.Paragraph{Text: "<제목>"},
+		&document.Table{Rows: 1, Cols: 1, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "A"},
+		}},
+		&document.Image{},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderJSONL(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"heading","id":"s0.r0","text":"1. 개요","level":1}
+{"type":"paragraph","text":"<제목>"}
+{"type":"table","rows":1,"cols":1,"cells":[{"row":0,"col":0,"rowSpan":1,"colSpan":1,"text":"A"}]}
+{"type":"image"}
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 4 {
+		t.Errorf("Expected 4 lines, got %d", n)
+	}
+}
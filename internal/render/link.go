@@ -0,0 +1,157 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// textSpan is a span of paragraph text, linked to url unless it is empty.
+// Spans within a tracked change have the change set, and spans of a
+// formatted paragraph the formatting run they are in.
+type textSpan struct {
+	text string
+	// url and title are the target and title of a link
+	url    string
+	title  string
+	change *document.Change
+	run    *document.Run
+}
+
+// linkSpans splits text into linked and plain spans. Links that overlap an
+// earlier one, lie outside the text or have no target are left out.
+func linkSpans(text string, links []document.Hyperlink) []textSpan {
+	var spans []textSpan
+	pos := 0
+	for _, l := range links {
+		end := l.Offset + len(l.Text)
+		if l.URL == "" || l.Offset < pos || end > len(text) || text[l.Offset:end] != l.Text {
+			continue
+		}
+		if l.Offset > pos {
+			spans = append(spans, textSpan{text: text[pos:l.Offset]})
+		}
+		spans = append(spans, textSpan{text: l.Text, url: l.URL, title: l.Title})
+		pos = end
+	}
+	if pos < len(text) || len(spans) == 0 {
+		spans = append(spans, textSpan{text: text[pos:]})
+	}
+	return spans
+}
+
+// paragraphSpans splits the text of a paragraph into spans by hyperlink,
+// tracked change and formatting run.
+func paragraphSpans(text string, links []document.Hyperlink, changes []document.Change, runs []document.Run) []textSpan {
+	spans := changeSpans(text, linkSpans(text, links), changes)
+	return splitSpans(text, spans, len(runs), func(i int) (int, string) { return runs[i].Offset, runs[i].Text },
+		func(span *textSpan, i int) { span.run = &runs[i] })
+}
+
+// changeSpans splits spans covering text further at the bounds of tracked
+// changes.
+func changeSpans(text string, spans []textSpan, changes []document.Change) []textSpan {
+	return splitSpans(text, spans, len(changes), func(i int) (int, string) { return changes[i].Offset, changes[i].Text },
+		func(span *textSpan, i int) { span.change = &changes[i] })
+}
+
+// splitSpans splits spans covering text further at the bounds of n ranges
+// of text, given by their offset and text, and calls set for the spans
+// within range i. Ranges that overlap an earlier one or do not match the
+// text are left out.
+func splitSpans(text string, spans []textSpan, n int, bounds func(i int) (int, string), set func(span *textSpan, i int)) []textSpan {
+	type textRange struct{ start, end, index int }
+	var ranges []textRange
+	end := 0
+	for i := range n {
+		offset, rangeText := bounds(i)
+		rangeEnd := offset + len(rangeText)
+		if rangeText == "" || offset < end || rangeEnd > len(text) || text[offset:rangeEnd] != rangeText {
+			continue
+		}
+		ranges = append(ranges, textRange{offset, rangeEnd, i})
+		end = rangeEnd
+	}
+	if len(ranges) == 0 {
+		return spans
+	}
+
+	var out []textSpan
+	pos := 0
+	for _, span := range spans {
+		for span.text != "" {
+			for len(ranges) > 0 && ranges[0].end <= pos {
+				ranges = ranges[1:]
+			}
+			piece := span
+			size := len(span.text)
+			if len(ranges) > 0 {
+				if r := ranges[0]; r.start <= pos {
+					size = min(size, r.end-pos)
+					set(&piece, r.index)
+				} else {
+					size = min(size, r.start-pos)
+				}
+			}
+			piece.text = span.text[:size]
+			out = append(out, piece)
+			span.text = span.text[size:]
+			pos += size
+		}
+	}
+	return out
+}
+
+// emphasisTags returns the HTML elements, outermost first, that mark the
+// emphasis of a run.
+func emphasisTags(run *document.Run) []string {
+	if run == nil {
+		return nil
+	}
+	var tags []string
+	for _, t := range []struct {
+		set bool
+		tag string
+	}{
+		{run.Bold, "strong"}, {run.Italic, "em"}, {run.Underline, "u"},
+		{run.Strikeout, "s"}, {run.Superscript, "sup"}, {run.Subscript, "sub"},
+	} {
+		if t.set {
+			tags = append(tags, t.tag)
+		}
+	}
+	return tags
+}
+
+// imagePlaceholder marks an image in the output.
+const imagePlaceholder = "[IMAGE]"
+
+// imageText returns the placeholder of an image followed by its
+// alternative text.
+func imageText(img *document.Image) string {
+	return strings.TrimSpace(imagePlaceholder + " " + img.Alt)
+}
+
+// mediaPlaceholder marks a media object in the output.
+const mediaPlaceholder = "[VIDEO]"
+
+// mediaURL returns the source of web media, or "" for local files.
+func mediaURL(m *document.Media) string {
+	if strings.Contains(m.Source, "://") || strings.HasPrefix(m.Source, "//") {
+		return m.Source
+	}
+	return ""
+}
+
+// noteText returns the text of a note after its number, "1) ..." as
+// Hangul numbers notes by default.
+func noteText(n *document.Footnote) string {
+	return strings.TrimSpace(fmt.Sprintf("%d) %s", n.Number, n.Text))
+}
+
+// mediaText returns the placeholder of a media object followed by its
+// source.
+func mediaText(m *document.Media) string {
+	return strings.TrimSpace(mediaPlaceholder + " " + m.Source)
+}
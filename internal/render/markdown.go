@@ -0,0 +1,283 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderMarkdown renders a ContentNodeScanner as GitHub Flavored Markdown.
+// Tables become pipe tables with the first row as header; tables with merged
+// cells, which pipe tables cannot express, are written as raw HTML tables.
+// Monospaced paragraphs become fenced code blocks, and consecutive list
+// items a list.
+func RenderMarkdown(scanner document.ContentNodeScanner, w io.Writer) error {
+	first, inList := true, false
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		var block string
+		node = chartAsTable(node)
+		switch n := node.(type) {
+		case *document.Paragraph:
+			if n.Preformatted {
+				block = markdownCodeBlock(n.Text)
+			} else {
+				block = markdownLinkedParagraph(n.Text, n.Hyperlinks, n.Changes, n.Runs)
+			}
+			if n.List != nil && block != "" {
+				block = markdownListItem(n.List, block)
+			}
+		case *document.Heading:
+			block = markdownHeading(n)
+		case *document.Table:
+			block = markdownTable(n)
+		case *document.Image:
+			block = markdownInline.Replace(imageText(n))
+			if n.Caption != "" {
+				block += "\n\n" + markdownParagraph(n.Caption)
+			}
+		case *document.Media:
+			block = markdownInline.Replace(mediaText(n))
+			if url := mediaURL(n); url != "" {
+				block = "[" + markdownInline.Replace(mediaPlaceholder) + "](" + markdownURL.Replace(url) + ")"
+			}
+			if n.Caption != "" {
+				block += "\n\n" + markdownParagraph(n.Caption)
+			}
+		case *document.Equation:
+			block = "$$\n" + n.LaTeX + "\n$$"
+			if n.Caption != "" {
+				block += "\n\n" + markdownParagraph(n.Caption)
+			}
+		case *document.Footnote:
+			block = markdownParagraph(noteText(n))
+		}
+		if block == "" {
+			continue
+		}
+
+		// Blocks are separated by a blank line, except the items of a list
+		item := false
+		if p, ok := node.(*document.Paragraph); ok {
+			item = p.List != nil
+		}
+		if !first && !(inList && item) {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		first, inList = false, item
+		if _, err := fmt.Fprintln(w, block); err != nil {
+			return err
+		}
+	}
+}
+
+// markdownListItem renders a paragraph as a list item: "- " for bullets,
+// the item number for numbered lists, whose markers Markdown cannot keep,
+// indented four spaces per level.
+func markdownListItem(item *document.ListItem, block string) string {
+	indent := strings.Repeat("    ", max(item.Level-1, 0))
+	marker := "-"
+	if item.Ordered {
+		marker = fmt.Sprintf("%d.", item.Number)
+	}
+	lines := strings.Split(block, "\n")
+	for i := range lines {
+		if i == 0 {
+			lines[i] = indent + marker + " " + lines[i]
+		} else if lines[i] != "" {
+			lines[i] = indent + strings.Repeat(" ", len(marker)+1) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownInline escapes characters that start inline markup.
+var markdownInline = strings.NewReplacer(`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`)
+
+// markdownBlockStart matches line prefixes Markdown would parse as block
+// markup (headings, quotes, lists, thematic breaks, setext underlines, fences).
+var markdownBlockStart = regexp.MustCompile(`^(#{1,6}( |$)|>|[-+](\s|$)|\d+[.)](\s|$)|=+\s*$|~~~|\|)`)
+
+// markdownOrderedStart matches an ordered list item marker, whose
+// delimiter is escaped: a backslash before a digit would be literal.
+var markdownOrderedStart = regexp.MustCompile(`^(\d+)([.)](\s|$))`)
+
+// markdownLineStart escapes an escaped line that would start block markup.
+func markdownLineStart(line string) string {
+	if markdownOrderedStart.MatchString(line) {
+		return markdownOrderedStart.ReplaceAllString(line, `$1\$2`)
+	}
+	if markdownBlockStart.MatchString(line) {
+		line = `\` + line
+	}
+	return line
+}
+
+// markdownParagraph renders paragraph text, keeping line breaks as hard
+// breaks (a trailing backslash).
+func markdownParagraph(text string) string {
+	return markdownLinkedParagraph(text, nil, nil, nil)
+}
+
+// markdownTitle escapes characters that would end a link title.
+var markdownTitle = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ")
+
+// markdownURL escapes characters that would end an inline link destination.
+var markdownURL = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+// markdownLinkedParagraph is markdownParagraph with hyperlinks as inline
+// links, tracked changes as <ins> and ~~strikethrough~~ and emphasis as
+// **bold**, *italic*, ~~strikeout~~ and <u>, <sup> and <sub> elements. Line
+// breaks within link text become spaces.
+func markdownLinkedParagraph(text string, links []document.Hyperlink, changes []document.Change, runs []document.Run) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	var lines []string
+	var line strings.Builder
+	for _, span := range paragraphSpans(text, links, changes, runs) {
+		if span.url != "" {
+			label := markdownEmphasis(markdownInline.Replace(strings.ReplaceAll(span.text, "\n", " ")), span.run)
+			destination := markdownURL.Replace(span.url)
+			if span.title != "" {
+				destination += ` "` + markdownTitle.Replace(span.title) + `"`
+			}
+			line.WriteString(markdownChange("["+label+"]("+destination+")", span.change))
+			continue
+		}
+		for i, part := range strings.Split(span.text, "\n") {
+			if i > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+			}
+			line.WriteString(markdownChange(markdownEmphasis(markdownInline.Replace(part), span.run), span.change))
+		}
+	}
+	lines = append(lines, line.String())
+	for i := range lines {
+		lines[i] = markdownLineStart(strings.TrimSpace(lines[i]))
+	}
+	return strings.Join(lines, "\\\n")
+}
+
+// markdownHeading renders an ATX heading. Line breaks become spaces, and a
+// trailing # is escaped so that it is not read as a closing sequence.
+func markdownHeading(h *document.Heading) string {
+	text := strings.Join(strings.Fields(h.Text), " ")
+	if text == "" {
+		return ""
+	}
+	text = markdownInline.Replace(text)
+	if strings.HasSuffix(text, "#") {
+		text = text[:len(text)-1] + `\#`
+	}
+	return strings.Repeat("#", min(h.Level, 6)) + " " + text
+}
+
+// markdownEmphasis marks up inline Markdown with the emphasis of a run.
+// Surrounding white space stays outside the delimiters, which could not
+// close otherwise.
+func markdownEmphasis(md string, run *document.Run) string {
+	tags := emphasisTags(run)
+	trimmed := strings.TrimSpace(md)
+	if len(tags) == 0 || trimmed == "" {
+		return md
+	}
+	lead := md[:strings.Index(md, trimmed)]
+	trail := md[len(lead)+len(trimmed):]
+	for i := len(tags) - 1; i >= 0; i-- {
+		switch tags[i] {
+		case "strong":
+			trimmed = "**" + trimmed + "**"
+		case "em":
+			trimmed = "*" + trimmed + "*"
+		case "s":
+			trimmed = "~~" + trimmed + "~~"
+		default:
+			trimmed = "<" + tags[i] + ">" + trimmed + "</" + tags[i] + ">"
+		}
+	}
+	return lead + trimmed + trail
+}
+
+// markdownChange marks up inline Markdown as a tracked change, if it is in
+// one.
+func markdownChange(md string, change *document.Change) string {
+	switch {
+	case change == nil || strings.TrimSpace(md) == "":
+		return md
+	case change.Type == document.ChangeDelete:
+		return "~~" + md + "~~"
+	default:
+		return "<ins>" + md + "</ins>"
+	}
+}
+
+// markdownCodeBlock renders monospaced text as a fenced code block, using a
+// fence longer than any backtick run in the text.
+func markdownCodeBlock(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + text + "\n" + fence
+}
+
+func markdownTable(t *document.Table) string {
+	if len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+		return ""
+	}
+	for _, cell := range t.Cells {
+		if cell.RowSpan > 1 || cell.ColSpan > 1 {
+			return htmlTable(t)
+		}
+	}
+	grid := newTableGrid(t)
+
+	// Pipe tables have no caption; it precedes the table as a paragraph
+	var sb strings.Builder
+	if t.Caption != "" {
+		sb.WriteString(markdownParagraph(t.Caption) + "\n\n")
+	}
+	for row := 0; row < grid.rows; row++ {
+		sb.WriteString("|")
+		for col := 0; col < grid.cols; col++ {
+			text := ""
+			if cell := grid.at(row, col); cell != nil {
+				text = markdownCellText(cell.TextWithTables())
+			}
+			sb.WriteString(" " + text + " |")
+		}
+		sb.WriteString("\n")
+		if row == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", grid.cols) + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func markdownCellText(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(markdownInline.Replace(strings.TrimSpace(line)), "|", `\|`)
+	}
+	return strings.Join(lines, "<br>")
+}
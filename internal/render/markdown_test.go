@@ -0,0 +1,180 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "# not a *heading*\nsecond"},
+		&document.Paragraph{Text: "+--+\n|  |\n+--+", Preformatted: true},
+		&document.Table{Rows: 2, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "a|b"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "c"},
+			{Row: 1, Col: 0, RowSpan: 1, ColSpan: 1, Text: "d\ne"},
+		}},
+		&document.Table{Rows: 1, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2, Text: "<merged>"},
+		}},
+		&document.Footnote{Number: 1, Text: "*주*"},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderMarkdown(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\\# not a \\*heading\\*\\\nsecond\n" +
+		"\n" +
+		"```\n+--+\n|  |\n+--+\n```\n" +
+		"\n" +
+		"| a\\|b | c |\n| --- | --- |\n| d<br>e |  |\n" +
+		"\n" +
+		"<table>\n<tr><td colspan=\"2\">&lt;merged&gt;</td></tr>\n</table>\n" +
+		"\n" +
+		"1\\) \\*주\\*\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMarkdownCodeBlockFence(t *testing.T) {
+	got := markdownCodeBlock("x ``` y")
+	want := "````\nx ``` y\n````"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownLinks(t *testing.T) {
+	text := "# 자세한 내용은 [누리집](안내)을 참고"
+	links := []document.Hyperlink{
+		{Offset: 22, Text: "[누리집]", URL: "https://example.com/a b"},
+		{Offset: 33, Text: "(안내)", URL: ""},
+	}
+	got := markdownLinkedParagraph(text, links, nil, nil)
+	want := `\# 자세한 내용은 [\[누리집\]](https://example.com/a%20b)(안내)을 참고`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Links that do not match the text are ignored
+	got = markdownLinkedParagraph("a\nb", []document.Hyperlink{{Offset: 1, Text: "x", URL: "u"}}, nil, nil)
+	if want := "a\\\nb"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownEquation(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Equation{Script: "1 over 2", LaTeX: `\frac{1}{2}`, Caption: "식 1"},
+	}}
+	var buf bytes.Buffer
+	if err := RenderMarkdown(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "$$\n\\frac{1}{2}\n$$\n\n식 1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownNestedTable(t *testing.T) {
+	nested := &document.Table{Rows: 2, Cols: 2, Cells: []document.Cell{
+		{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "a"},
+		{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "b"},
+		{Row: 1, Col: 0, RowSpan: 1, ColSpan: 1, Text: "c"},
+	}}
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Table{Rows: 1, Cols: 1, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "out", Tables: []*document.Table{nested}},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderMarkdown(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	// Pipe tables cannot nest; nested rows become lines of the cell
+	want := "| out<br>a \\| b<br>c |\n| --- |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTrackedChanges(t *testing.T) {
+	text := "see docs now"
+	links := []document.Hyperlink{{Offset: 4, Text: "docs", URL: "u"}}
+	changes := []document.Change{
+		{Type: document.ChangeInsert, Offset: 0, Text: "see do"},
+		{Type: document.ChangeDelete, Offset: 9, Text: "now"},
+	}
+
+	if got, want := markdownLinkedParagraph(text, links, changes, nil), "<ins>see </ins><ins>[do](u)</ins>[cs](u) ~~now~~"; got != want {
+		t.Errorf("markdown = %q, want %q", got, want)
+	}
+	if got, want := textChanges(text, changes), "{+see do+}cs [-now-]"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownEmphasis(t *testing.T) {
+	text := "plain bold link x2"
+	runs := []document.Run{
+		{Offset: 0, Text: "plain"},
+		{Offset: 5, Text: " bold ", Bold: true},
+		{Offset: 11, Text: "link", Bold: true, Italic: true},
+		{Offset: 15, Text: " x"},
+		{Offset: 17, Text: "2", Superscript: true},
+	}
+	links := []document.Hyperlink{{Offset: 11, Text: "link", URL: "u"}}
+
+	if got, want := markdownLinkedParagraph(text, links, nil, runs), "plain **bold** [***link***](u) x<sup>2</sup>"; got != want {
+		t.Errorf("markdown = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownHeading(t *testing.T) {
+	for _, tt := range []struct {
+		heading *document.Heading
+		want    string
+	}{
+		{&document.Heading{Paragraph: document.Paragraph{Text: "1. 개요\n"}, Level: 1}, "# 1. 개요"},
+		{&document.Heading{Paragraph: document.Paragraph{Text: "가 *나*\n다"}, Level: 3}, `### 가 \*나\* 다`},
+		{&document.Heading{Paragraph: document.Paragraph{Text: "C#"}, Level: 7}, `###### C\#`},
+		{&document.Heading{Paragraph: document.Paragraph{Text: " "}, Level: 1}, ""},
+	} {
+		if got := markdownHeading(tt.heading); got != tt.want {
+			t.Errorf("markdownHeading(%q) = %q, want %q", tt.heading.Text, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownLists(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "목록"},
+		&document.Paragraph{Text: "첫째", List: &document.ListItem{Ordered: true, Level: 1, Marker: "1.", Number: 1}},
+		&document.Paragraph{Text: "가\n나", List: &document.ListItem{Ordered: true, Level: 2, Marker: "가)", Number: 1}},
+		&document.Paragraph{Text: "점", List: &document.ListItem{Level: 3, Marker: "●"}},
+		&document.Paragraph{Text: "둘째", List: &document.ListItem{Ordered: true, Level: 1, Marker: "2.", Number: 2}},
+		&document.Paragraph{Text: "끝"},
+	}}
+	var buf bytes.Buffer
+	if err := RenderMarkdown(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "목록\n" +
+		"\n" +
+		"1. 첫째\n" +
+		"    1. 가\\\n" +
+		"       나\n" +
+		"        - 점\n" +
+		"2. 둘째\n" +
+		"\n" +
+		"끝\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// pandocAPIVersion is the pandoc-types version of the emitted AST (pandoc 3.x).
+var pandocAPIVersion = []int{1, 23, 1}
+
+// RenderPandocJSON renders a ContentNodeScanner as a Pandoc JSON AST, suitable
+// for `pandoc -f json`. Blocks are written as they are scanned.
+func RenderPandocJSON(scanner document.ContentNodeScanner, w io.Writer) error {
+	version, _ := json.Marshal(pandocAPIVersion)
+	if _, err := fmt.Fprintf(w, `{"pandoc-api-version":%s,"meta":{},"blocks":[`, version); err != nil {
+		return err
+	}
+
+	first := true
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		var block any
+		node = chartAsTable(node)
+		switch n := node.(type) {
+		case *document.Paragraph:
+			if n.Preformatted {
+				if text := strings.TrimRight(n.Text, "\n"); strings.TrimSpace(text) != "" {
+					block = pandocElement("CodeBlock", []any{pandocAttr(), text})
+				}
+			} else if inlines := pandocInlines(n.Text); len(inlines) > 0 {
+				block = pandocElement("Para", inlines)
+			}
+		case *document.Heading:
+			if inlines := pandocInlines(strings.Join(strings.Fields(n.Text), " ")); len(inlines) > 0 {
+				block = pandocElement("Header", []any{n.Level, pandocAttr(), inlines})
+			}
+		case *document.Table:
+			block = pandocTable(n)
+		case *document.Media:
+			inlines := pandocInlines(mediaText(n))
+			if url := mediaURL(n); url != "" {
+				inlines = []any{pandocElement("Link", []any{pandocAttr(), pandocInlines(mediaPlaceholder), []any{url, ""}})}
+			}
+			block = pandocElement("Para", inlines)
+			if n.Caption != "" {
+				block = pandocElement("Figure", []any{pandocAttr(), pandocCaption(n.Caption), []any{block}})
+			}
+		case *document.Equation:
+			block = pandocElement("Para", []any{pandocElement("Math", []any{pandocElement("DisplayMath", nil), n.LaTeX})})
+			if n.Caption != "" {
+				block = pandocElement("Figure", []any{pandocAttr(), pandocCaption(n.Caption), []any{block}})
+			}
+		case *document.Image:
+			block = pandocElement("Para", pandocInlines(imageText(n)))
+			if n.Caption != "" {
+				block = pandocElement("Figure", []any{pandocAttr(), pandocCaption(n.Caption), []any{block}})
+			}
+		case *document.Footnote:
+			block = pandocElement("Para", pandocInlines(noteText(n)))
+		}
+		if block == nil {
+			continue
+		}
+
+		data, err := json.Marshal(block)
+		if err != nil {
+			return err
+		}
+		if !first {
+			data = append([]byte{','}, data...)
+		}
+		first = false
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "]}")
+	return err
+}
+
+// pandocElement builds a tagged AST element; content may be nil for
+// elements without contents such as Space.
+func pandocElement(tag string, content any) map[string]any {
+	if content == nil {
+		return map[string]any{"t": tag}
+	}
+	return map[string]any{"t": tag, "c": content}
+}
+
+// pandocAttr returns an empty Attr (identifier, classes, key-value pairs).
+func pandocAttr() []any {
+	return []any{"", []any{}, []any{}}
+}
+
+// pandocInlines converts text to Str/Space/LineBreak inlines.
+func pandocInlines(text string) []any {
+	inlines := []any{}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if i > 0 && len(inlines) > 0 {
+			inlines = append(inlines, pandocElement("LineBreak", nil))
+		}
+		for j, word := range strings.Fields(line) {
+			if j > 0 {
+				inlines = append(inlines, pandocElement("Space", nil))
+			}
+			inlines = append(inlines, pandocElement("Str", word))
+		}
+	}
+	return inlines
+}
+
+// pandocCaption builds a Caption without short caption.
+func pandocCaption(text string) []any {
+	blocks := []any{}
+	if inlines := pandocInlines(text); len(inlines) > 0 {
+		blocks = append(blocks, pandocElement("Plain", inlines))
+	}
+	return []any{nil, blocks}
+}
+
+func pandocTable(t *document.Table) any {
+	if len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+		return nil
+	}
+	grid := newTableGrid(t)
+
+	colSpecs := make([]any, grid.cols)
+	for i := range colSpecs {
+		colSpecs[i] = []any{pandocElement("AlignDefault", nil), pandocElement("ColWidthDefault", nil)}
+	}
+
+	rows := make([]any, 0, grid.rows)
+	for row := 0; row < grid.rows; row++ {
+		cells := []any{}
+		for col := 0; col < grid.cols; col++ {
+			if grid.covered(row, col) {
+				continue
+			}
+			rowSpan, colSpan := 1, 1
+			var blocks []any
+			if cell := grid.at(row, col); cell != nil {
+				rowSpan, colSpan = grid.rowSpan(cell), grid.colSpan(cell)
+				if inlines := pandocInlines(strings.TrimSpace(cell.TextWithTables())); len(inlines) > 0 {
+					blocks = append(blocks, pandocElement("Plain", inlines))
+				}
+			}
+			if blocks == nil {
+				blocks = []any{}
+			}
+			cells = append(cells, []any{pandocAttr(), pandocElement("AlignDefault", nil), rowSpan, colSpan, blocks})
+		}
+		rows = append(rows, []any{pandocAttr(), cells})
+	}
+
+	return pandocElement("Table", []any{
+		pandocAttr(),
+		pandocCaption(t.Caption),     // Caption: short caption, blocks
+		colSpecs,                     // ColSpec per column
+		[]any{pandocAttr(), []any{}}, // TableHead
+		[]any{[]any{pandocAttr(), 0, []any{}, rows}}, // TableBody: attr, row head columns, head rows, body rows
+		[]any{pandocAttr(), []any{}},                 // TableFoot
+	})
+}
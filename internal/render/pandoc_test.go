@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderPandocJSON(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "안녕 세상\n둘째"},
+		&document.Paragraph{Text: ""},
+		&document.Table{Rows: 2, Cols: 2, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1, Text: "A"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "B"},
+			{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Text: "C"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderPandocJSON(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Blocks []struct {
+			T string            `json:"t"`
+			C []json.RawMessage `json:"c"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	if len(doc.Blocks) != 2 || doc.Blocks[0].T != "Para" || doc.Blocks[1].T != "Table" {
+		t.Fatalf("unexpected blocks: %s", buf.String())
+	}
+	para, _ := json.Marshal(doc.Blocks[0].C)
+	expected := `[{"c":"안녕","t":"Str"},{"t":"Space"},{"c":"세상","t":"Str"},{"t":"LineBreak"},{"c":"둘째","t":"Str"}]`
+	if string(para) != expected {
+		t.Errorf("unexpected inlines: %s", para)
+	}
+
+	// The second row omits the cell covered by the row span
+	var body [][]json.RawMessage
+	json.Unmarshal(doc.Blocks[1].C[4], &body)
+	var rows [][]json.RawMessage
+	json.Unmarshal(body[0][3], &rows)
+	var cells []json.RawMessage
+	json.Unmarshal(rows[1][1], &cells)
+	if len(rows) != 2 || len(cells) != 1 {
+		t.Errorf("unexpected table body: %s", doc.Blocks[1].C[4])
+	}
+}
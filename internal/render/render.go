@@ -19,11 +19,16 @@ func RenderText(scanner document.ContentNodeScanner, w io.Writer) error {
 			return fmt.Errorf("error reading content: %w", err)
 		}
 
+		node = chartAsTable(node)
 		switch n := node.(type) {
 		case *document.Paragraph:
 			if err := renderParagraph(n, w); err != nil {
 				return err
 			}
+		case *document.Heading:
+			if err := renderParagraph(&n.Paragraph, w); err != nil {
+				return err
+			}
 		case *document.Table:
 			if err := renderTable(n, w); err != nil {
 				return err
@@ -33,12 +38,35 @@ func RenderText(scanner document.ContentNodeScanner, w io.Writer) error {
 			if err := renderImage(n, w); err != nil {
 				return err
 			}
+		case *document.Equation:
+			if err := renderEquation(n, w); err != nil {
+				return err
+			}
+		case *document.Media:
+			if err := renderMedia(n, w); err != nil {
+				return err
+			}
+		case *document.Footnote:
+			if _, err := fmt.Fprintln(w, noteText(n)); err != nil {
+				return err
+			}
 		}
 	}
 }
 
 func renderParagraph(para *document.Paragraph, w io.Writer) error {
 	text := strings.TrimRight(para.Text, "\n")
+	// Change offsets are into the text before tabs are expanded
+	if len(para.Changes) > 0 {
+		text = textChanges(text, para.Changes)
+	}
+	if len(para.TabStops) > 0 {
+		text = expandTabs(text, para.TabStops)
+	}
+	if para.List != nil && text != "" {
+		// The number or bullet that word processors draw before the text
+		text = para.List.Marker + " " + text
+	}
 	if text != "" {
 		_, err := fmt.Fprintln(w, text)
 		return err
@@ -51,6 +79,11 @@ func renderTable(docTable *document.Table, w io.Writer) error {
 	if len(docTable.Cells) == 0 {
 		return nil
 	}
+	if docTable.Caption != "" {
+		if _, err := fmt.Fprintln(w, docTable.Caption); err != nil {
+			return err
+		}
+	}
 
 	t := &Table{
 		Rows:  docTable.Rows,
@@ -59,13 +92,16 @@ func renderTable(docTable *document.Table, w io.Writer) error {
 	}
 
 	for _, docCell := range docTable.Cells {
-		text := strings.TrimSpace(docCell.Text)
+		text := strings.TrimSpace(docCell.TextWithTables())
 		t.Cells = append(t.Cells, &Cell{
 			Row:     docCell.Row,
 			Col:     docCell.Col,
 			Text:    text,
 			RowSpan: docCell.RowSpan,
 			ColSpan: docCell.ColSpan,
+			Align:   docCell.Align,
+			VAlign:  docCell.VAlign,
+			Width:   docCell.Width,
 		})
 	}
 
@@ -73,7 +109,54 @@ func renderTable(docTable *document.Table, w io.Writer) error {
 	return err
 }
 
-func renderImage(_ *document.Image, w io.Writer) error {
-	_, err := fmt.Fprintln(w, "[IMAGE]")
-	return err
+func renderImage(image *document.Image, w io.Writer) error {
+	if _, err := fmt.Fprintln(w, imageText(image)); err != nil {
+		return err
+	}
+	if image.Caption != "" {
+		_, err := fmt.Fprintln(w, image.Caption)
+		return err
+	}
+	return nil
+}
+
+// renderEquation writes an equation as LaTeX, which reads better in plain
+// text than the equation script.
+func renderEquation(eq *document.Equation, w io.Writer) error {
+	if _, err := fmt.Fprintln(w, eq.LaTeX); err != nil {
+		return err
+	}
+	if eq.Caption != "" {
+		_, err := fmt.Fprintln(w, eq.Caption)
+		return err
+	}
+	return nil
+}
+
+func renderMedia(media *document.Media, w io.Writer) error {
+	if _, err := fmt.Fprintln(w, mediaText(media)); err != nil {
+		return err
+	}
+	if media.Caption != "" {
+		_, err := fmt.Fprintln(w, media.Caption)
+		return err
+	}
+	return nil
+}
+
+// textChanges marks tracked changes in plain text the way wdiff does:
+// {+inserted+} and [-deleted-].
+func textChanges(text string, changes []document.Change) string {
+	var sb strings.Builder
+	for _, span := range changeSpans(text, []textSpan{{text: text}}, changes) {
+		switch {
+		case span.change == nil:
+			sb.WriteString(span.text)
+		case span.change.Type == document.ChangeDelete:
+			sb.WriteString("[-" + span.text + "-]")
+		default:
+			sb.WriteString("{+" + span.text + "+}")
+		}
+	}
+	return sb.String()
 }
@@ -0,0 +1,308 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderRST renders a ContentNodeScanner as a reStructuredText document.
+// Tables are written as grid tables, which support row and column spans.
+func RenderRST(scanner document.ContentNodeScanner, w io.Writer) error {
+	first := true
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		var block string
+		node = chartAsTable(node)
+		switch n := node.(type) {
+		case *document.Paragraph:
+			if n.Preformatted {
+				block = rstLiteral(n.Text)
+			} else {
+				block = rstParagraph(n.Text)
+			}
+		case *document.Heading:
+			block = rstHeading(n)
+		case *document.Table:
+			block = rstTable(n)
+			if block != "" && n.Caption != "" {
+				block = rstParagraph(n.Caption) + "\n\n" + block
+			}
+		case *document.Image:
+			block = rstParagraph(imageText(n))
+			if n.Caption != "" {
+				block += "\n\n" + rstParagraph(n.Caption)
+			}
+		case *document.Media:
+			block = rstParagraph(mediaText(n))
+			if n.Caption != "" {
+				block += "\n\n" + rstParagraph(n.Caption)
+			}
+		case *document.Equation:
+			block = ".. math::\n\n   " + n.LaTeX
+			if n.Caption != "" {
+				block += "\n\n" + rstParagraph(n.Caption)
+			}
+		case *document.Footnote:
+			block = rstParagraph(noteText(n))
+		}
+		if block == "" {
+			continue
+		}
+
+		// Blocks are separated by a blank line
+		if !first {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		first = false
+		if _, err := fmt.Fprintln(w, block); err != nil {
+			return err
+		}
+	}
+}
+
+// rstInline escapes characters that start inline markup.
+var rstInline = strings.NewReplacer(`\`, `\\`, `*`, `\*`, "`", "\\`", `|`, `\|`, `_`, `\_`)
+
+// rstBlockStart matches line prefixes RST would parse as block markup
+// (lists, directives, comments, section underlines, line blocks).
+var rstBlockStart = regexp.MustCompile(`^([-+•]( |$)|\d+[.)] |#\. |\.\. |::|[=\-~^"'.:#+<>]{4,}$|>>>)`)
+
+// rstParagraph renders paragraph text. Multi-line paragraphs become line
+// blocks so their line breaks survive.
+func rstParagraph(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = rstInline.Replace(strings.TrimSpace(line))
+		if rstBlockStart.MatchString(line) {
+			line = `\` + line
+		}
+		lines[i] = line
+	}
+	if len(lines) == 1 {
+		return lines[0]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("| "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rstSectionAdornments holds the underline characters of the outline
+// levels. RST infers section levels from the order adornments appear in, so
+// each outline level always uses the same character.
+const rstSectionAdornments = `=-~^"'+`
+
+// rstHeading renders a section title, underlined to its display width.
+func rstHeading(h *document.Heading) string {
+	title := rstInline.Replace(strings.Join(strings.Fields(h.Text), " "))
+	if title == "" {
+		return ""
+	}
+	if rstBlockStart.MatchString(title) {
+		title = `\` + title
+	}
+	level := min(h.Level, len(rstSectionAdornments))
+	return title + "\n" + strings.Repeat(rstSectionAdornments[level-1:level], displayWidth(title))
+}
+
+// rstLiteral renders monospaced text as an indented literal block.
+func rstLiteral(text string) string {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("   "+line, " ")
+	}
+	return "::\n\n" + strings.Join(lines, "\n")
+}
+
+// rstTable renders a grid table. Borders inside spanned cells are left open
+// and junctions use '+', '-' or '|' depending on which lines meet there.
+func rstTable(t *document.Table) string {
+	if len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+		return ""
+	}
+	grid := newTableGrid(t)
+
+	cellLines := make(map[*document.Cell][]string)
+	for i := range t.Cells {
+		cell := &t.Cells[i]
+		lines := strings.Split(strings.TrimSpace(cell.TextWithTables()), "\n")
+		for j, line := range lines {
+			lines[j] = rstInline.Replace(strings.TrimSpace(line))
+		}
+		cellLines[cell] = lines
+	}
+
+	colWidths := rstColWidths(grid, cellLines)
+
+	var sb strings.Builder
+	for row := 0; row <= grid.rows; row++ {
+		sb.WriteString(rstBorder(grid, colWidths, row))
+		sb.WriteString("\n")
+		if row == grid.rows {
+			break
+		}
+
+		height := 1
+		for col := 0; col < grid.cols; col++ {
+			if cell := grid.at(row, col); cell != nil {
+				height = max(height, len(cellLines[cell]))
+			}
+		}
+		for line := 0; line < height; line++ {
+			sb.WriteString(rstContentLine(grid, colWidths, cellLines, row, line))
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func rstColWidths(grid *tableGrid, cellLines map[*document.Cell][]string) []int {
+	widths := make([]int, grid.cols)
+	for i := range widths {
+		widths[i] = 1
+	}
+
+	maxWidth := func(lines []string) int {
+		w := 0
+		for _, line := range lines {
+			w = max(w, displayWidth(line))
+		}
+		return w
+	}
+
+	// Single-column cells establish widths; spanning cells then widen their
+	// columns evenly if needed. A span includes the separators between columns.
+	for pass := 0; pass < 2; pass++ {
+		for row := 0; row < grid.rows; row++ {
+			for col := 0; col < grid.cols; col++ {
+				cell := grid.at(row, col)
+				if cell == nil {
+					continue
+				}
+				span := grid.colSpan(cell)
+				if (pass == 0) != (span == 1) {
+					continue
+				}
+				need := maxWidth(cellLines[cell])
+				have := (span - 1) * 3
+				for c := col; c < col+span; c++ {
+					have += widths[c]
+				}
+				for i := 0; have < need; i++ {
+					widths[col+i%span]++
+					have++
+				}
+			}
+		}
+	}
+	return widths
+}
+
+// rstBorder renders the horizontal border above the given row
+// (row == grid.rows renders the bottom border).
+func rstBorder(grid *tableGrid, colWidths []int, row int) string {
+	owner := func(r, c int) *document.Cell {
+		if r < 0 || r >= grid.rows || c < 0 || c >= grid.cols {
+			return nil
+		}
+		return grid.owner[r][c]
+	}
+	// horizontal reports whether column c has a line at this border
+	horizontal := func(c int) bool {
+		if c < 0 || c >= grid.cols {
+			return false
+		}
+		if row == 0 || row == grid.rows {
+			return true
+		}
+		return owner(row-1, c) != owner(row, c) || owner(row, c) == nil
+	}
+	// vertical reports whether there is a line between columns c-1 and c in row r
+	vertical := func(r, c int) bool {
+		if r < 0 || r >= grid.rows {
+			return false
+		}
+		if c == 0 || c == grid.cols {
+			return true
+		}
+		return owner(r, c-1) != owner(r, c) || owner(r, c) == nil
+	}
+
+	var sb strings.Builder
+	for c := 0; c <= grid.cols; c++ {
+		across := horizontal(c-1) || horizontal(c)
+		down := vertical(row-1, c) || vertical(row, c)
+		switch {
+		case across && down:
+			sb.WriteString("+")
+		case across:
+			sb.WriteString("-")
+		case down:
+			sb.WriteString("|")
+		default:
+			sb.WriteString(" ")
+		}
+
+		if c < grid.cols {
+			fill := " "
+			if horizontal(c) {
+				fill = "-"
+			}
+			sb.WriteString(strings.Repeat(fill, colWidths[c]+2))
+		}
+	}
+	return sb.String()
+}
+
+func rstContentLine(grid *tableGrid, colWidths []int, cellLines map[*document.Cell][]string, row, line int) string {
+	var sb strings.Builder
+	sb.WriteString("|")
+
+	for col := 0; col < grid.cols; {
+		owner := grid.owner[row][col]
+		span := 1
+		if owner != nil && owner.Col == col {
+			span = grid.colSpan(owner)
+		}
+
+		width := (span - 1) * 3
+		for c := col; c < col+span; c++ {
+			width += colWidths[c]
+		}
+
+		// Text appears in the first row of a spanned cell only
+		text := ""
+		if owner != nil && owner.Row == row && owner.Col == col && line < len(cellLines[owner]) {
+			text = cellLines[owner][line]
+		}
+		sb.WriteString(" ")
+		sb.WriteString(text)
+		sb.WriteString(strings.Repeat(" ", max(width-displayWidth(text), 0)))
+		sb.WriteString(" |")
+
+		col += span
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderRST(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Heading{Paragraph: document.Paragraph{Text: "개요"}, Level: 1},
+		&document.Paragraph{Text: "- 목록처럼 보이는 *문장*"},
+		&document.Paragraph{Text: "첫째 줄\n둘째 줄"},
+		&document.Table{Rows: 3, Cols: 3, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1, Text: "구분"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 2, Text: "값"},
+			{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Text: "1"},
+			{Row: 1, Col: 2, RowSpan: 1, ColSpan: 1, Text: "2"},
+			{Row: 2, Col: 0, RowSpan: 1, ColSpan: 3, Text: "합계"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderRST(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `개요
+====
+
+\- 목록처럼 보이는 \*문장\*
+
+| 첫째 줄
+| 둘째 줄
+
++------+-------+
+| 구분 | 값    |
+|      +---+---+
+|      | 1 | 2 |
++------+---+---+
+| 합계         |
++--------------+
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	checkAllLinesEqualWidth(t, expected[bytes.Index([]byte(expected), []byte("+")):])
+}
@@ -1,8 +1,10 @@
 package render
 
 import (
+	"math"
 	"strings"
 
+	"github.com/hanpama/hwp/internal/document"
 	"github.com/mattn/go-runewidth"
 )
 
@@ -12,6 +14,12 @@ type Cell struct {
 	Text    string
 	RowSpan int
 	ColSpan int
+	// Align and VAlign place the text in the cell, as in document.Cell;
+	// Width is the cell width in the document, in points, which sizes
+	// the columns in proportion when every column has one
+	Align  string
+	VAlign string
+	Width  float64
 }
 
 type Table struct {
@@ -64,6 +72,7 @@ func (t *Table) buildLayout() *Layout {
 	}
 
 	layout.computeColWidths()
+	layout.applyWidthHints()
 	layout.computeRowHeights()
 
 	return layout
@@ -124,6 +133,35 @@ func (l *Layout) computeColWidths() {
 	}
 }
 
+// applyWidthHints widens columns so that their widths keep the proportions
+// of the document's column widths, taken from single-column cells. Columns
+// are never narrowed below their content, and the hints are ignored unless
+// every column has one.
+func (l *Layout) applyWidthHints() {
+	hints := make([]float64, l.table.Cols)
+	for _, cell := range l.table.Cells {
+		if cell.ColSpan == 1 && cell.Col < len(hints) && cell.Width > hints[cell.Col] {
+			hints[cell.Col] = cell.Width
+		}
+	}
+	total := 0.0
+	for _, hint := range hints {
+		if hint <= 0 {
+			return
+		}
+		total += hint
+	}
+	content := 0
+	for _, width := range l.colWidths {
+		content += width
+	}
+	for i, hint := range hints {
+		if width := int(math.Round(hint / total * float64(content))); width > l.colWidths[i] {
+			l.colWidths[i] = width
+		}
+	}
+}
+
 func (l *Layout) computeRowHeights() {
 	for row := 0; row < l.table.Rows; row++ {
 		maxLines := 1
@@ -255,8 +293,9 @@ func (l *Layout) renderContentLine(rowIdx int, displayRowIdx int) string {
 		lines := l.cellLines[owner]
 		var text string
 		if owner.Row == rowIdx {
-			if displayRowIdx < len(lines) {
-				text = lines[displayRowIdx]
+			line := displayRowIdx - l.lineOffset(owner, rowIdx)
+			if line >= 0 && line < len(lines) {
+				text = lines[line]
 			} else {
 				text = ""
 			}
@@ -271,8 +310,16 @@ func (l *Layout) renderContentLine(rowIdx int, displayRowIdx int) string {
 		if padding < 0 {
 			padding = 0
 		}
+		left := 0
+		switch owner.Align {
+		case document.AlignCenter:
+			left = padding / 2
+		case document.AlignRight:
+			left = padding
+		}
+		sb.WriteString(strings.Repeat(" ", left))
 		sb.WriteString(text)
-		sb.WriteString(strings.Repeat(" ", padding))
+		sb.WriteString(strings.Repeat(" ", padding-left))
 		sb.WriteString(" ")
 
 		nextColIdx := colIdx + colspan
@@ -288,6 +335,19 @@ func (l *Layout) renderContentLine(rowIdx int, displayRowIdx int) string {
 	return sb.String()
 }
 
+// lineOffset returns how many display rows of the table row come before
+// the first line of the cell's text, as its vertical alignment places it.
+func (l *Layout) lineOffset(cell *Cell, rowIdx int) int {
+	spare := l.rowHeights[rowIdx] - len(l.cellLines[cell])
+	switch cell.VAlign {
+	case document.VAlignMiddle:
+		return spare / 2
+	case document.VAlignBottom:
+		return spare
+	}
+	return 0
+}
+
 // displayWidth calculates the display width of a string using go-runewidth.
 // Correctly handles East Asian Width properties including:
 // - CJK characters (width 2)
@@ -118,3 +118,41 @@ func checkAllLinesEqualWidth(t *testing.T, result string) {
 		}
 	}
 }
+
+func TestCellAlignment(t *testing.T) {
+	table := &Table{
+		Rows: 1,
+		Cols: 3,
+		Cells: []*Cell{
+			{Row: 0, Col: 0, Text: "줄1\n줄2\n줄3", RowSpan: 1, ColSpan: 1},
+			{Row: 0, Col: 1, Text: "가운데", RowSpan: 1, ColSpan: 1, Align: "center", VAlign: "middle"},
+			{Row: 0, Col: 2, Text: "9\n12345", RowSpan: 1, ColSpan: 1, Align: "right", VAlign: "bottom"},
+		},
+	}
+	want := "+-----+--------+-------+\n" +
+		"| 줄1 |        |       |\n" +
+		"| 줄2 | 가운데 |     9 |\n" +
+		"| 줄3 |        | 12345 |\n" +
+		"+-----+--------+-------+\n"
+	if got := table.Render(); got != want {
+		t.Errorf("table =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestColumnWidthHints(t *testing.T) {
+	table := &Table{
+		Rows: 1,
+		Cols: 2,
+		Cells: []*Cell{
+			{Row: 0, Col: 0, Text: "a", RowSpan: 1, ColSpan: 1, Width: 60},
+			{Row: 0, Col: 1, Text: "bbbbbbbb", RowSpan: 1, ColSpan: 1, Width: 60},
+		},
+	}
+	// Equal hints widen the narrow column to half the content width
+	want := "+-------+----------+\n" +
+		"| a     | bbbbbbbb |\n" +
+		"+-------+----------+\n"
+	if got := table.Render(); got != want {
+		t.Errorf("table =\n%s\nwant\n%s", got, want)
+	}
+}
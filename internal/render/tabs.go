@@ -0,0 +1,75 @@
+package render
+
+import (
+	"math"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// Tab expansion assumes a 10pt font, whose half-width characters take one
+// column of 5pt, and the word processor's default tab interval of 40pt
+// past the last tab stop.
+const (
+	tabColumnWidth   = 5.0
+	tabDefaultColumn = 8
+)
+
+// expandTabs replaces the tabs of paragraph text with padding up to the
+// columns of the paragraph's tab stops. The text after a right or center
+// tab, up to the next tab, ends at or centers on the stop; decimal tabs are
+// taken as right tabs. Leaders fill the padding.
+func expandTabs(text string, stops []document.TabStop) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "\t") {
+			lines[i] = expandLineTabs(line, stops)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func expandLineTabs(line string, stops []document.TabStop) string {
+	parts := strings.Split(line, "\t")
+	var sb strings.Builder
+	sb.WriteString(parts[0])
+	col := displayWidth(parts[0])
+	for _, part := range parts[1:] {
+		stop, stopCol := nextTabStop(stops, col)
+		width := displayWidth(part)
+		start := stopCol
+		switch stop.Align {
+		case document.TabRight, document.TabDecimal:
+			start = stopCol - width
+		case document.TabCenter:
+			start = stopCol - width/2
+		}
+		// Text that does not fit before the stop follows a single space
+		start = max(start, col+1)
+
+		leader := stop.Leader
+		if leader == "" {
+			leader = " "
+		}
+		sb.WriteString(strings.Repeat(leader, start-col))
+		sb.WriteString(part)
+		col = start + width
+	}
+	return sb.String()
+}
+
+// nextTabStop returns the first tab stop past a column, and its column. Past
+// the last stop, tabs are left aligned at the default interval.
+func nextTabStop(stops []document.TabStop, col int) (document.TabStop, int) {
+	best, bestCol := document.TabStop{Align: document.TabLeft}, -1
+	for _, stop := range stops {
+		stopCol := int(math.Round(stop.Position / tabColumnWidth))
+		if stopCol > col && (bestCol < 0 || stopCol < bestCol) {
+			best, bestCol = stop, stopCol
+		}
+	}
+	if bestCol < 0 {
+		bestCol = (col/tabDefaultColumn + 1) * tabDefaultColumn
+	}
+	return best, bestCol
+}
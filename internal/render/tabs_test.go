@@ -0,0 +1,49 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestExpandTabs(t *testing.T) {
+	stops := []document.TabStop{
+		{Position: 50, Align: document.TabLeft},
+		{Position: 150, Align: document.TabRight, Leader: "."},
+	}
+	for _, tt := range []struct {
+		text string
+		want string
+	}{
+		// 성명 is 4 columns wide; the left stop is at column 10 and the
+		// right stop, ending 홍길동, at column 30
+		{"성명\t홍길동", "성명      홍길동"},
+		{"성명\t가\t홍길동", "성명      가............홍길동"},
+		{"a\tb\tc\td", "a         b..................c  d"},
+		{"0123456789ab\tc", "0123456789ab.................c"},
+		{"첫 줄\n둘\t째", "첫 줄\n둘        째"},
+	} {
+		if got := expandTabs(tt.text, stops); got != tt.want {
+			t.Errorf("expandTabs(%q) =\n%q, want\n%q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTabsWithChanges(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{
+			Text:     "a\tnew",
+			TabStops: []document.TabStop{{Position: 50, Align: document.TabLeft}},
+			Changes:  []document.Change{{Type: document.ChangeInsert, Offset: 2, Text: "new"}},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderText(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "a         {+new+}\n"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+}
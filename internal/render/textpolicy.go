@@ -0,0 +1,93 @@
+package render
+
+import (
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextOptions controls line-level normalization of plain text output.
+// The zero value leaves the output unchanged.
+type TextOptions struct {
+	// CRLF ends lines with "\r\n" instead of "\n".
+	CRLF bool
+	// CollapseBlankLines reduces runs of blank lines, such as consecutive
+	// empty paragraphs, to a single blank line.
+	CollapseBlankLines bool
+	// TrimTrailingSpace removes trailing spaces and tabs from every line.
+	TrimTrailingSpace bool
+	// NFC normalizes text to Unicode Normalization Form C, composing
+	// decomposed Hangul jamo and combining marks.
+	NFC bool
+}
+
+// RenderTextWithOptions renders plain text like RenderText and applies the
+// given output policy.
+func RenderTextWithOptions(scanner document.ContentNodeScanner, w io.Writer, opts TextOptions) error {
+	if opts == (TextOptions{}) {
+		return RenderText(scanner, w)
+	}
+	pw := &textPolicyWriter{w: w, opts: opts}
+	if err := RenderText(scanner, pw); err != nil {
+		return err
+	}
+	return pw.flush()
+}
+
+// textPolicyWriter applies TextOptions to each complete line written to it.
+type textPolicyWriter struct {
+	w         io.Writer
+	opts      TextOptions
+	line      []byte
+	prevBlank bool
+}
+
+func (p *textPolicyWriter) Write(b []byte) (int, error) {
+	for _, c := range b {
+		if c != '\n' {
+			p.line = append(p.line, c)
+			continue
+		}
+		if err := p.writeLine(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flush writes a final line that was not terminated by a newline.
+func (p *textPolicyWriter) flush() error {
+	if len(p.line) == 0 {
+		return nil
+	}
+	return p.writeLine(false)
+}
+
+func (p *textPolicyWriter) writeLine(newline bool) error {
+	line := strings.TrimSuffix(string(p.line), "\r")
+	p.line = p.line[:0]
+
+	if p.opts.NFC {
+		line = norm.NFC.String(line)
+	}
+	if p.opts.TrimTrailingSpace {
+		line = strings.TrimRight(line, " \t")
+	}
+	blank := strings.TrimSpace(line) == ""
+	if p.opts.CollapseBlankLines && blank && p.prevBlank {
+		return nil
+	}
+	p.prevBlank = blank
+
+	if newline {
+		if p.opts.CRLF {
+			line += "\r\n"
+		} else {
+			line += "\n"
+		}
+	}
+	_, err := io.WriteString(p.w, line)
+	return err
+}
@@ -0,0 +1,38 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderTextWithOptions(t *testing.T) {
+	nodes := func() document.ContentNodeScanner {
+		return &sliceScanner{nodes: []document.ContentNode{
+			&document.Paragraph{Text: "\u1112\u1161\u11ab  "}, // 한 as conjoining jamo
+			&document.Paragraph{},
+			&document.Paragraph{Text: " \t"},
+			&document.Paragraph{},
+			&document.Paragraph{Text: "끝"},
+		}}
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts TextOptions
+		want string
+	}{
+		{"default", TextOptions{}, "\u1112\u1161\u11ab  \n\n \t\n\n끝\n"},
+		{"all", TextOptions{CRLF: true, CollapseBlankLines: true, TrimTrailingSpace: true, NFC: true}, "한\r\n\r\n끝\r\n"},
+		{"collapse only", TextOptions{CollapseBlankLines: true}, "\u1112\u1161\u11ab  \n\n끝\n"},
+	} {
+		var buf bytes.Buffer
+		if err := RenderTextWithOptions(nodes(), &buf, tc.opts); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// xliffSourceLanguage is the source language declared in XLIFF output.
+const xliffSourceLanguage = "ko"
+
+// RenderXLIFF writes an XLIFF 2.0 document for translation tools, with one
+// unit per paragraph, table cell and caption. Unit ids are derived from node
+// IDs (cells add ".c<row>-<col>", captions ".caption"), so the same file
+// always yields the same ids; nodes without an ID get sequential ids.
+func RenderXLIFF(scanner document.ContentNodeScanner, w io.Writer) error {
+	const header = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="` + xliffSourceLanguage + `">
+<file id="f1">
+`
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+
+	x := &xliffWriter{w: w, used: make(map[string]bool)}
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+
+		node = chartAsTable(node)
+		switch n := node.(type) {
+		case *document.Paragraph:
+			err = x.unit(n.ID, n.Text)
+		case *document.Heading:
+			err = x.unit(n.ID, n.Text)
+		case *document.Table:
+			err = x.table(n)
+		case *document.Image:
+			if err = x.unit(xliffChildID(n.ID, "alt"), n.Alt); err == nil {
+				err = x.unit(xliffChildID(n.ID, "caption"), n.Caption)
+			}
+		case *document.Equation:
+			err = x.unit(xliffChildID(n.ID, "caption"), n.Caption)
+		case *document.Media:
+			err = x.unit(xliffChildID(n.ID, "caption"), n.Caption)
+		case *document.Footnote:
+			err = x.unit(n.ID, n.Text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "</file>\n</xliff>\n")
+	return err
+}
+
+// xliffChildID derives the id of a unit inside a node; nodes without an ID
+// yield "" so that a sequential id is used.
+func xliffChildID(id, child string) string {
+	if id == "" {
+		return ""
+	}
+	return id + "." + child
+}
+
+type xliffWriter struct {
+	w    io.Writer
+	used map[string]bool
+	seq  int
+}
+
+// unit writes a translation unit unless the text is blank. Ids must be
+// unique within the file, so repeats get a "-n" suffix.
+func (x *xliffWriter) unit(id, text string) error {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	x.seq++
+	if id == "" {
+		id = fmt.Sprintf("u%d", x.seq)
+	}
+	base := id
+	for n := 2; x.used[id]; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	x.used[id] = true
+
+	_, err := fmt.Fprintf(x.w, "<unit id=\"%s\"><segment><source xml:space=\"preserve\">%s</source></segment></unit>\n", xmlText(id), xliffText(text))
+	return err
+}
+
+// xliffText escapes text for element content. Unlike xmlText it keeps
+// newlines and tabs literal, which xml:space="preserve" retains.
+func xliffText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		parts := strings.Split(line, "\t")
+		for j, part := range parts {
+			parts[j] = xmlText(part)
+		}
+		lines[i] = strings.Join(parts, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// table writes units for the caption and cells of a table and the tables
+// nested in its cells.
+func (x *xliffWriter) table(t *document.Table) error {
+	if err := x.unit(xliffChildID(t.ID, "caption"), t.Caption); err != nil {
+		return err
+	}
+	for _, cell := range t.Cells {
+		if err := x.unit(xliffChildID(t.ID, fmt.Sprintf("c%d-%d", cell.Row, cell.Col)), cell.Text); err != nil {
+			return err
+		}
+		for _, nested := range cell.Tables {
+			if err := x.table(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderXLIFF(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{ID: "s0.r0", Text: "가 & 나\n다"},
+		&document.Paragraph{ID: "s0.r3", Text: "  "},
+		&document.Table{ID: "s0.r5", Rows: 1, Cols: 2, Caption: "표 1", Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "셀"},
+			{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: ""},
+		}},
+		&document.Paragraph{Text: "no id"},
+		&document.Paragraph{ID: "s0.r0", Text: "duplicate"},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderXLIFF(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	var doc struct {
+		Units []struct {
+			ID     string `xml:"id,attr"`
+			Source string `xml:"segment>source"`
+		} `xml:"file>unit"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not well-formed: %v\n%s", err, out)
+	}
+
+	var got []string
+	for _, u := range doc.Units {
+		got = append(got, u.ID+"="+u.Source)
+	}
+	want := []string{"s0.r0=가 & 나\n다", "s0.r5.caption=표 1", "s0.r5.c0-0=셀", "u4=no id", "s0.r0-2=duplicate"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("units = %q, want %q", got, want)
+	}
+}
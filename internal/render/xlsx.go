@@ -0,0 +1,191 @@
+package render
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// RenderXLSX writes the tables of a document as an Excel workbook, one sheet
+// per table, with merged cells preserved. Other content is ignored. Cells
+// holding plain numbers ("1,234", "-5.5") are stored as numbers.
+func RenderXLSX(scanner document.ContentNodeScanner, w io.Writer) error {
+	var sheets []xlsxSheet
+	names := make(map[string]bool)
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading content: %w", err)
+		}
+		if t, ok := node.(*document.Table); ok && len(t.Cells) > 0 && t.Rows > 0 && t.Cols > 0 {
+			name := xlsxSheetName(t.Caption, len(sheets)+1, names)
+			sheets = append(sheets, xlsxSheet{name: name, xml: xlsxWorksheet(t)})
+		}
+	}
+	if len(sheets) == 0 {
+		// A workbook needs at least one sheet
+		sheets = append(sheets, xlsxSheet{name: "Sheet1", xml: xlsxWorksheet(nil)})
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes(len(sheets))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(sheets))},
+	}
+	for i, sheet := range sheets {
+		files = append(files, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml})
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+type xlsxSheet struct {
+	name string
+	xml  string
+}
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+func xlsxContentTypes(sheets int) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func xlsxWorkbook(sheets []xlsxSheet) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&sb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlText(sheet.name), i+1, i+1)
+	}
+	sb.WriteString(`</sheets></workbook>`)
+	return sb.String()
+}
+
+func xlsxWorkbookRels(sheets int) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+// xlsxWorksheet renders a table as a worksheet; a nil table yields an empty sheet.
+func xlsxWorksheet(t *document.Table) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if t == nil {
+		sb.WriteString(`</sheetData></worksheet>`)
+		return sb.String()
+	}
+
+	grid := newTableGrid(t)
+	var merges []string
+	for row := 0; row < grid.rows; row++ {
+		fmt.Fprintf(&sb, `<row r="%d">`, row+1)
+		for col := 0; col < grid.cols; col++ {
+			cell := grid.at(row, col)
+			if cell == nil {
+				continue
+			}
+			ref := xlsxCellRef(row, col)
+			if colSpan, rowSpan := grid.colSpan(cell), grid.rowSpan(cell); colSpan > 1 || rowSpan > 1 {
+				merges = append(merges, ref+":"+xlsxCellRef(row+rowSpan-1, col+colSpan-1))
+			}
+
+			text := strings.TrimSpace(cell.TextWithTables())
+			if text == "" {
+				continue
+			}
+			if number, ok := xlsxNumber(text); ok {
+				fmt.Fprintf(&sb, `<c r="%s"><v>%s</v></c>`, ref, number)
+			} else {
+				fmt.Fprintf(&sb, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlText(text))
+			}
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData>`)
+
+	if len(merges) > 0 {
+		fmt.Fprintf(&sb, `<mergeCells count="%d">`, len(merges))
+		for _, ref := range merges {
+			fmt.Fprintf(&sb, `<mergeCell ref="%s"/>`, ref)
+		}
+		sb.WriteString(`</mergeCells>`)
+	}
+	sb.WriteString(`</worksheet>`)
+	return sb.String()
+}
+
+// xlsxCellRef returns the A1-style reference of a zero-based position.
+func xlsxCellRef(row, col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return fmt.Sprintf("%s%d", name, row+1)
+}
+
+// xlsxNumberPattern matches plain decimal numbers, optionally with
+// thousands separators.
+var xlsxNumberPattern = regexp.MustCompile(`^-?(\d{1,3}(,\d{3})+|\d+)(\.\d+)?$`)
+
+// xlsxNumber returns the cell value of text if it is a plain number.
+// Numbers with leading zeros ("007") are codes, not quantities, and stay text.
+func xlsxNumber(text string) (string, bool) {
+	if !xlsxNumberPattern.MatchString(text) {
+		return "", false
+	}
+	digits := strings.TrimPrefix(text, "-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return "", false
+	}
+	return strings.ReplaceAll(text, ",", ""), true
+}
+
+// xlsxInvalidName matches characters Excel does not allow in sheet names.
+var xlsxInvalidName = regexp.MustCompile(`[\[\]:*?/\\]`)
+
+// xlsxSheetName derives a unique sheet name from a table caption, falling
+// back to "Table n". Excel limits names to 31 characters.
+func xlsxSheetName(caption string, n int, used map[string]bool) string {
+	name := strings.Join(strings.Fields(xlsxInvalidName.ReplaceAllString(caption, " ")), " ")
+	name = strings.Trim(name, "'")
+	if runes := []rune(name); len(runes) > 31 {
+		name = strings.TrimSpace(string(runes[:31]))
+	}
+	for i := n; name == "" || used[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("Table %d", i)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
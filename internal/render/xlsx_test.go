@@ -0,0 +1,75 @@
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestRenderXLSX(t *testing.T) {
+	scanner := &sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "ignored"},
+		&document.Table{Rows: 2, Cols: 3, Caption: "표 1. 예산/결산", Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 2, Text: "구분 & 합계"},
+			{Row: 0, Col: 2, RowSpan: 2, ColSpan: 1, Text: "007"},
+			{Row: 1, Col: 0, RowSpan: 1, ColSpan: 1, Text: "1,234"},
+			{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Text: "-5.5"},
+		}},
+		&document.Table{Rows: 1, Cols: 1, Cells: []document.Cell{
+			{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "x"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderXLSX(scanner, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(data)
+	}
+
+	for part, wants := range map[string][]string{
+		"xl/workbook.xml": {`<sheet name="표 1. 예산 결산" sheetId="1" r:id="rId1"/>`, `<sheet name="Table 2" sheetId="2" r:id="rId2"/>`},
+		"xl/worksheets/sheet1.xml": {
+			`<c r="A1" t="inlineStr"><is><t xml:space="preserve">구분 &amp; 합계</t></is></c>`,
+			`<c r="C1" t="inlineStr"><is><t xml:space="preserve">007</t></is></c>`,
+			`<c r="A2"><v>1234</v></c><c r="B2"><v>-5.5</v></c>`,
+			`<mergeCells count="2"><mergeCell ref="A1:B1"/><mergeCell ref="C1:C2"/></mergeCells>`,
+		},
+		"xl/worksheets/sheet2.xml": {`<c r="A1" t="inlineStr">`},
+		"[Content_Types].xml":      {`/xl/worksheets/sheet2.xml`},
+	} {
+		for _, want := range wants {
+			if !strings.Contains(parts[part], want) {
+				t.Errorf("%s missing %s:\n%s", part, want, parts[part])
+			}
+		}
+	}
+}
+
+func TestXLSXCellRef(t *testing.T) {
+	for _, tc := range []struct {
+		row, col int
+		want     string
+	}{{0, 0, "A1"}, {9, 25, "Z10"}, {0, 26, "AA1"}, {0, 701, "ZZ1"}, {0, 702, "AAA1"}} {
+		if got := xlsxCellRef(tc.row, tc.col); got != tc.want {
+			t.Errorf("xlsxCellRef(%d, %d) = %s, want %s", tc.row, tc.col, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package transform
+
+import (
+	"io"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// Titles of the generated caption lists.
+const (
+	ListOfTablesTitle  = "List of Tables"
+	ListOfFiguresTitle = "List of Figures"
+)
+
+// CaptionLists holds the captions of a document in document order.
+type CaptionLists struct {
+	Tables  []string
+	Figures []string
+}
+
+// Add records the caption of a table or image node.
+func (c *CaptionLists) Add(node document.ContentNode) {
+	switch n := node.(type) {
+	case *document.Table:
+		if n.Caption != "" {
+			c.Tables = append(c.Tables, n.Caption)
+		}
+	case *document.Image:
+		if n.Caption != "" {
+			c.Figures = append(c.Figures, n.Caption)
+		}
+	}
+}
+
+// Nodes returns the "List of Tables" and "List of Figures" sections as
+// paragraphs: a title followed by one paragraph per caption. Empty lists
+// are omitted.
+func (c *CaptionLists) Nodes() []document.ContentNode {
+	var nodes []document.ContentNode
+	for _, list := range []struct {
+		title    string
+		captions []string
+	}{
+		{ListOfTablesTitle, c.Tables},
+		{ListOfFiguresTitle, c.Figures},
+	} {
+		if len(list.captions) == 0 {
+			continue
+		}
+		nodes = append(nodes, &document.Paragraph{Text: list.title})
+		for _, caption := range list.captions {
+			nodes = append(nodes, &document.Paragraph{Text: caption})
+		}
+	}
+	return nodes
+}
+
+// captionLister passes nodes through and appends the caption lists at the end.
+type captionLister struct {
+	scanner document.ContentNodeScanner
+	lists   CaptionLists
+	pending []document.ContentNode
+	done    bool
+}
+
+// AppendCaptionLists returns a scanner that appends generated "List of
+// Tables" and "List of Figures" sections, built from table and image
+// captions, after the document content. Captions already carry their
+// numbers ("표 3. ..."), as Hangul's auto-numbers are resolved while scanning.
+func AppendCaptionLists(scanner document.ContentNodeScanner) document.ContentNodeScanner {
+	return &captionLister{scanner: scanner}
+}
+
+func (c *captionLister) Next() (document.ContentNode, error) {
+	if !c.done {
+		node, err := c.scanner.Next()
+		if err != io.EOF {
+			if err == nil {
+				c.lists.Add(node)
+			}
+			return node, err
+		}
+		c.done = true
+		c.pending = c.lists.Nodes()
+	}
+
+	if len(c.pending) == 0 {
+		return nil, io.EOF
+	}
+	node := c.pending[0]
+	c.pending = c.pending[1:]
+	return node, nil
+}
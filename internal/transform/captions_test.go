@@ -0,0 +1,38 @@
+package transform
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestAppendCaptionLists(t *testing.T) {
+	scanner := AppendCaptionLists(&sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "본문"},
+		&document.Table{Rows: 1, Cols: 1, Caption: "표 1. 예산"},
+		&document.Image{Caption: "그림 1. 조직도"},
+		&document.Table{Rows: 1, Cols: 1},
+		&document.Table{Rows: 1, Cols: 1, Caption: "표 2. 인력"},
+	}})
+
+	var texts []string
+	for {
+		node, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, ok := node.(*document.Paragraph); ok {
+			texts = append(texts, p.Text)
+		}
+	}
+
+	want := []string{"본문", ListOfTablesTitle, "표 1. 예산", "표 2. 인력", ListOfFiguresTitle, "그림 1. 조직도"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("got %q, want %q", texts, want)
+	}
+}
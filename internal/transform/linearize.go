@@ -0,0 +1,193 @@
+// Package transform rewrites content node streams between scanning and rendering.
+package transform
+
+import (
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+// tableLinearizer replaces tables with paragraphs of "header: value" lines.
+type tableLinearizer struct {
+	scanner document.ContentNodeScanner
+	pending []document.ContentNode
+}
+
+// LinearizeTables returns a scanner that converts each table into readable
+// linear text: one paragraph per body row with a "header: value" line per
+// cell, using the detected header rows as labels. Tables without a header row
+// are read as form-style key/value pairs. Rows are separated by an empty
+// paragraph. This reads far better than grid output for screen readers and
+// language models.
+func LinearizeTables(scanner document.ContentNodeScanner) document.ContentNodeScanner {
+	return &tableLinearizer{scanner: scanner}
+}
+
+func (l *tableLinearizer) Next() (document.ContentNode, error) {
+	for len(l.pending) == 0 {
+		node, err := l.scanner.Next()
+		if err != nil {
+			return nil, err
+		}
+		table, ok := node.(*document.Table)
+		if !ok {
+			return node, nil
+		}
+		l.pending = linearizeTable(table)
+	}
+
+	node := l.pending[0]
+	l.pending = l.pending[1:]
+	return node, nil
+}
+
+func linearizeTable(t *document.Table) []document.ContentNode {
+	if len(t.Cells) == 0 || t.Rows == 0 || t.Cols == 0 {
+		return nil
+	}
+
+	owner := make([][]*document.Cell, t.Rows)
+	for i := range owner {
+		owner[i] = make([]*document.Cell, t.Cols)
+	}
+	for i := range t.Cells {
+		cell := &t.Cells[i]
+		for r := cell.Row; r < cell.Row+max(cell.RowSpan, 1) && r < t.Rows; r++ {
+			for c := cell.Col; c < cell.Col+max(cell.ColSpan, 1) && c < t.Cols; c++ {
+				if r >= 0 && c >= 0 {
+					owner[r][c] = cell
+				}
+			}
+		}
+	}
+
+	headerRows := detectHeaderRows(t, owner)
+
+	var rows []string
+	if headerRows == 0 {
+		for r := 0; r < t.Rows; r++ {
+			rows = append(rows, formRow(owner[r]))
+		}
+	} else {
+		labels := columnLabels(owner[:headerRows], t.Cols)
+		for r := headerRows; r < t.Rows; r++ {
+			rows = append(rows, labeledRow(owner[r], labels))
+		}
+	}
+
+	var nodes []document.ContentNode
+	if t.Caption != "" {
+		nodes = append(nodes, &document.Paragraph{Text: t.Caption})
+	}
+	for _, row := range rows {
+		if row == "" {
+			continue
+		}
+		if len(nodes) > 0 {
+			nodes = append(nodes, &document.Paragraph{})
+		}
+		nodes = append(nodes, &document.Paragraph{Text: row})
+	}
+	return nodes
+}
+
+// detectHeaderRows returns the number of leading header rows, or 0 if the
+// table does not look like it has a header. A header needs at least one body
+// row and must label every column. Spans in the first row extend the header
+// to cover grouped sub-headers.
+func detectHeaderRows(t *document.Table, owner [][]*document.Cell) int {
+	if t.Rows < 2 {
+		return 0
+	}
+
+	depth := 1
+	for c := 0; c < t.Cols; c++ {
+		cell := owner[0][c]
+		if cell == nil || cellText(cell) == "" {
+			return 0
+		}
+		if cell.RowSpan > depth {
+			depth = cell.RowSpan
+		}
+		if cell.ColSpan > 1 && depth < 2 {
+			depth = 2
+		}
+	}
+	if depth >= t.Rows {
+		return 0
+	}
+
+	for r := 1; r < depth; r++ {
+		for c := 0; c < t.Cols; c++ {
+			if owner[r][c] == nil || cellText(owner[r][c]) == "" {
+				return 0
+			}
+		}
+	}
+	return depth
+}
+
+// columnLabels joins the distinct header texts above each column.
+func columnLabels(header [][]*document.Cell, cols int) []string {
+	labels := make([]string, cols)
+	for c := 0; c < cols; c++ {
+		var parts []string
+		var prev *document.Cell
+		for _, row := range header {
+			if cell := row[c]; cell != prev {
+				parts = append(parts, cellText(cell))
+				prev = cell
+			}
+		}
+		labels[c] = strings.Join(parts, " / ")
+	}
+	return labels
+}
+
+// labeledRow renders a body row as "label: value" lines. Values of cells
+// spanning several columns are written once; row-spanned values repeat so
+// each row reads on its own.
+func labeledRow(row []*document.Cell, labels []string) string {
+	var lines []string
+	var prev *document.Cell
+	for c, cell := range row {
+		if cell == nil || cell == prev {
+			continue
+		}
+		prev = cell
+		if text := cellText(cell); text != "" {
+			lines = append(lines, labels[c]+": "+text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formRow renders a header-less row. Rows with an even number of values are
+// read as key/value pairs, as in application forms; others are joined.
+func formRow(row []*document.Cell) string {
+	var values []string
+	var prev *document.Cell
+	for _, cell := range row {
+		if cell == nil || cell == prev {
+			continue
+		}
+		prev = cell
+		if text := cellText(cell); text != "" {
+			values = append(values, text)
+		}
+	}
+
+	if len(values) < 2 || len(values)%2 != 0 {
+		return strings.Join(values, " ")
+	}
+	lines := make([]string, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		lines = append(lines, values[i]+": "+values[i+1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cellText returns a cell's text on a single line.
+func cellText(cell *document.Cell) string {
+	return strings.Join(strings.Fields(cell.TextWithTables()), " ")
+}
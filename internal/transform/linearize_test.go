@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"io"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+type sliceScanner struct {
+	nodes []document.ContentNode
+}
+
+func (s *sliceScanner) Next() (document.ContentNode, error) {
+	if len(s.nodes) == 0 {
+		return nil, io.EOF
+	}
+	node := s.nodes[0]
+	s.nodes = s.nodes[1:]
+	return node, nil
+}
+
+func linearize(t *testing.T, table *document.Table) []string {
+	t.Helper()
+	scanner := LinearizeTables(&sliceScanner{nodes: []document.ContentNode{table}})
+	var texts []string
+	for {
+		node, err := scanner.Next()
+		if err == io.EOF {
+			return texts
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, node.(*document.Paragraph).Text)
+	}
+}
+
+func TestLinearizeHeaderTable(t *testing.T) {
+	texts := linearize(t, &document.Table{Rows: 3, Cols: 2, Cells: []document.Cell{
+		{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "이름"},
+		{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "나이"},
+		{Row: 1, Col: 0, RowSpan: 1, ColSpan: 1, Text: "홍길동"},
+		{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Text: "30"},
+		{Row: 2, Col: 0, RowSpan: 1, ColSpan: 1, Text: "김철수"},
+		{Row: 2, Col: 1, RowSpan: 1, ColSpan: 1, Text: ""},
+	}})
+
+	expected := []string{"이름: 홍길동\n나이: 30", "", "이름: 김철수"}
+	if len(texts) != len(expected) {
+		t.Fatalf("unexpected paragraphs: %q", texts)
+	}
+	for i := range expected {
+		if texts[i] != expected[i] {
+			t.Errorf("paragraph %d: expected %q, got %q", i, expected[i], texts[i])
+		}
+	}
+}
+
+func TestLinearizeGroupedHeader(t *testing.T) {
+	texts := linearize(t, &document.Table{Rows: 3, Cols: 3, Cells: []document.Cell{
+		{Row: 0, Col: 0, RowSpan: 2, ColSpan: 1, Text: "구분"},
+		{Row: 0, Col: 1, RowSpan: 1, ColSpan: 2, Text: "예산"},
+		{Row: 1, Col: 1, RowSpan: 1, ColSpan: 1, Text: "2024"},
+		{Row: 1, Col: 2, RowSpan: 1, ColSpan: 1, Text: "2025"},
+		{Row: 2, Col: 0, RowSpan: 1, ColSpan: 1, Text: "인건비"},
+		{Row: 2, Col: 1, RowSpan: 1, ColSpan: 1, Text: "100"},
+		{Row: 2, Col: 2, RowSpan: 1, ColSpan: 1, Text: "120"},
+	}})
+
+	expected := "구분: 인건비\n예산 / 2024: 100\n예산 / 2025: 120"
+	if len(texts) != 1 || texts[0] != expected {
+		t.Errorf("unexpected paragraphs: %q", texts)
+	}
+}
+
+func TestLinearizeFormTable(t *testing.T) {
+	texts := linearize(t, &document.Table{Rows: 1, Cols: 4, Cells: []document.Cell{
+		{Row: 0, Col: 0, RowSpan: 1, ColSpan: 1, Text: "성명"},
+		{Row: 0, Col: 1, RowSpan: 1, ColSpan: 1, Text: "홍길동"},
+		{Row: 0, Col: 2, RowSpan: 1, ColSpan: 1, Text: "연락처"},
+		{Row: 0, Col: 3, RowSpan: 1, ColSpan: 1, Text: "010-0000-0000"},
+	}})
+
+	if len(texts) != 1 || texts[0] != "성명: 홍길동\n연락처: 010-0000-0000" {
+		t.Errorf("unexpected paragraphs: %q", texts)
+	}
+}
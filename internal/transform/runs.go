@@ -0,0 +1,29 @@
+package transform
+
+import "github.com/hanpama/hwp/internal/document"
+
+// textRunner sets the runs of paragraphs to their full text runs.
+type textRunner struct {
+	scanner document.ContentNodeScanner
+}
+
+// TextRuns returns a scanner that gives every paragraph and heading runs
+// covering all of its text, with the hyperlink targets of linked runs, as
+// Paragraph.TextRuns does, rather than runs only for emphasized paragraphs.
+func TextRuns(scanner document.ContentNodeScanner) document.ContentNodeScanner {
+	return &textRunner{scanner: scanner}
+}
+
+func (r *textRunner) Next() (document.ContentNode, error) {
+	node, err := r.scanner.Next()
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case *document.Paragraph:
+		n.Runs = n.TextRuns()
+	case *document.Heading:
+		n.Runs = n.TextRuns()
+	}
+	return node, nil
+}
@@ -0,0 +1,41 @@
+package transform
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/hanpama/hwp/internal/document"
+)
+
+func TestTextRuns(t *testing.T) {
+	scanner := TextRuns(&sliceScanner{nodes: []document.ContentNode{
+		&document.Paragraph{Text: "plain"},
+		&document.Heading{Paragraph: document.Paragraph{Text: "go to site", Hyperlinks: []document.Hyperlink{
+			{Offset: 6, Text: "site", URL: "https://example.com"},
+		}}, Level: 1},
+	}})
+	var got [][]document.Run
+	for {
+		node, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch n := node.(type) {
+		case *document.Paragraph:
+			got = append(got, n.Runs)
+		case *document.Heading:
+			got = append(got, n.Runs)
+		}
+	}
+	want := [][]document.Run{
+		{{Text: "plain"}},
+		{{Text: "go to "}, {Offset: 6, Text: "site", URL: "https://example.com"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runs = %+v, want %+v", got, want)
+	}
+}
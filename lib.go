@@ -1,7 +1,9 @@
 // Package hwp provides functionality to read and render HWP (Hangul Word Processor) documents.
 //
-// This package supports both binary HWP v5 format (.hwp) and XML-based HWPX format (.hwpx).
-// It extracts text content and renders tables with ASCII borders to plain text output.
+// This package supports both binary HWP v5 format (.hwp) and XML-based HWPX format (.hwpx),
+// and the templates of either (.hwt, .hwtx).
+// It extracts text content and renders tables with ASCII borders to plain text output,
+// or streams content nodes as JSON lines (see WithFormat).
 //
 // # Example Usage
 //
@@ -36,9 +38,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/hanpama/hwp/internal/document"
 	"github.com/hanpama/hwp/internal/hwpv5"
 	"github.com/hanpama/hwp/internal/hwpx"
-	"github.com/hanpama/hwp/internal/render"
+	"github.com/hanpama/hwp/internal/limits"
 )
 
 // ReadHWP reads a binary HWP v5 format file and renders its content as plain text.
@@ -54,18 +57,23 @@ import (
 //	file, _ := os.Open("document.hwp")
 //	defer file.Close()
 //	hwp.ReadHWP(file, os.Stdout)
-func ReadHWP(in io.Reader, out io.Writer) error {
+func ReadHWP(in io.Reader, out io.Writer, opts ...Option) error {
+	cfg := newConfig(opts)
+
 	file, ok := in.(*os.File)
 	if !ok {
 		return fmt.Errorf("input must be an *os.File for HWP format")
 	}
+	if cfg.rawXML {
+		return fmt.Errorf("raw XML output requires an HWPX file")
+	}
 
-	scanner, err := hwpv5.Open(file)
+	scanner, err := openHWPScanner(file, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to parse HWP file: %w", err)
+		return err
 	}
 
-	if err := render.RenderText(scanner, out); err != nil {
+	if err := cfg.render(scanner, out); err != nil {
 		return fmt.Errorf("failed to render HWP: %w", err)
 	}
 
@@ -86,18 +94,19 @@ func ReadHWP(in io.Reader, out io.Writer) error {
 //	defer file.Close()
 //	info, _ := file.Stat()
 //	hwp.ReadHWPX(file, info.Size(), os.Stdout)
-func ReadHWPX(in io.ReaderAt, size int64, out io.Writer) error {
-	reader, err := hwpx.Open(in, size)
-	if err != nil {
-		return fmt.Errorf("failed to parse HWPX file: %w", err)
+func ReadHWPX(in io.ReaderAt, size int64, out io.Writer, opts ...Option) error {
+	cfg := newConfig(opts)
+
+	if cfg.rawXML {
+		return writeRawXML(in, size, out, cfg)
 	}
 
-	scanner, err := reader.NewContentScanner()
+	scanner, err := openHWPXScanner(in, size, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create scanner: %w", err)
+		return err
 	}
 
-	if err := render.RenderText(scanner, out); err != nil {
+	if err := cfg.render(scanner, out); err != nil {
 		return fmt.Errorf("failed to render HWPX: %w", err)
 	}
 
@@ -107,8 +116,8 @@ func ReadHWPX(in io.ReaderAt, size int64, out io.Writer) error {
 // Read automatically detects the file format and renders the document to plain text.
 //
 // Format detection is based on the file extension:
-//   - .hwpx → calls ReadHWPX
-//   - .hwp or other → calls ReadHWP
+//   - .hwpx, or .hwtx for templates → calls ReadHWPX
+//   - .hwp, .hwt for templates, or other → calls ReadHWP
 //
 // This is the recommended function for general use as it handles both formats seamlessly.
 //
@@ -117,17 +126,79 @@ func ReadHWPX(in io.ReaderAt, size int64, out io.Writer) error {
 //	file, _ := os.Open("document.hwp")  // or document.hwpx
 //	defer file.Close()
 //	hwp.Read(file, os.Stdout)
-func Read(file *os.File, out io.Writer) error {
+func Read(file *os.File, out io.Writer, opts ...Option) error {
 	fileInfo, err := file.Stat()
 	if err != nil {
 		return fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	ext := strings.ToLower(filepath.Ext(file.Name()))
+	if isHWPX(file.Name()) {
+		return ReadHWPX(file, fileInfo.Size(), out, opts...)
+	}
+
+	return ReadHWP(file, out, opts...)
+}
+
+// isHWPX reports whether a file name denotes an HWPX package: a document
+// or a template, which shares its container.
+func isHWPX(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".hwpx" || ext == ".hwtx"
+}
+
+// openScanner opens a content scanner for either format, detected from the
+// file name like Read does.
+func openScanner(file *os.File, cfg *config) (document.ContentNodeScanner, error) {
+	if isHWPX(file.Name()) {
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+		return openHWPXScanner(file, fileInfo.Size(), cfg)
+	}
+	return openHWPScanner(file, cfg)
+}
+
+func openHWPScanner(in io.ReaderAt, cfg *config) (document.ContentNodeScanner, error) {
+	scanner, err := hwpv5.Open(in, cfg.scan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HWP file: %w", err)
+	}
+	return scanner, nil
+}
+
+func openHWPXScanner(in io.ReaderAt, size int64, cfg *config) (document.ContentNodeScanner, error) {
+	if cfg.scan.Resume != nil {
+		return nil, fmt.Errorf("resuming from a checkpoint requires an HWP file")
+	}
 
-	if ext == ".hwpx" {
-		return ReadHWPX(file, fileInfo.Size(), out)
+	reader, err := hwpx.Open(in, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HWPX file: %w", err)
 	}
 
-	return ReadHWP(file, out)
+	scanner, err := reader.NewContentScanner(cfg.scan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scanner: %w", err)
+	}
+	return scanner, nil
+}
+
+// writeRawXML copies the section XML of an HWPX file to out.
+func writeRawXML(in io.ReaderAt, size int64, out io.Writer, cfg *config) error {
+	if cfg.err != nil {
+		return cfg.err
+	}
+	reader, err := hwpx.Open(in, size)
+	if err != nil {
+		return fmt.Errorf("failed to parse HWPX file: %w", err)
+	}
+
+	opts := cfg.xml
+	opts.MaxStreamSize = cfg.scan.MaxStreamSize
+	out = limits.NewWriter(out, cfg.maxOutputSize, "output")
+	if err := reader.WriteRawXML(out, opts); err != nil {
+		return fmt.Errorf("failed to write HWPX XML: %w", err)
+	}
+	return nil
 }
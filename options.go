@@ -0,0 +1,506 @@
+package hwp
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/hwpv5"
+	"github.com/hanpama/hwp/internal/hwpx"
+	"github.com/hanpama/hwp/internal/limits"
+	"github.com/hanpama/hwp/internal/render"
+	"github.com/hanpama/hwp/internal/transform"
+)
+
+// Format selects the output representation written by Read, ReadHWP and ReadHWPX.
+type Format string
+
+const (
+	// FormatText renders plain text with ASCII tables. This is the default.
+	FormatText Format = "text"
+	// FormatJSONL writes one JSON object per content node, one node per line,
+	// as the document is scanned.
+	FormatJSONL Format = "jsonl"
+	// FormatAsciiDoc renders an AsciiDoc document with native tables.
+	FormatAsciiDoc Format = "asciidoc"
+	// FormatRST renders a reStructuredText document with grid tables.
+	FormatRST Format = "rst"
+	// FormatPandocJSON writes a Pandoc JSON AST, which `pandoc -f json` can
+	// convert to any Pandoc output format.
+	FormatPandocJSON Format = "pandoc-json"
+	// FormatDocBook renders a DocBook 5 article with CALS tables.
+	FormatDocBook Format = "docbook"
+	// FormatMarkdown renders GitHub Flavored Markdown with pipe tables.
+	FormatMarkdown Format = "markdown"
+	// FormatHTML renders a standalone HTML5 document.
+	FormatHTML Format = "html"
+	// FormatXLSX writes the document's tables as an Excel workbook, one
+	// sheet per table. Other content is left out.
+	FormatXLSX Format = "xlsx"
+	// FormatCSV writes the document's tables as CSV, separated by empty
+	// lines. Other content is left out.
+	FormatCSV Format = "csv"
+	// FormatXLIFF writes an XLIFF 2.0 file for translation tools, with one
+	// unit per paragraph, table cell and caption.
+	FormatXLIFF Format = "xliff"
+	// FormatFormJSON writes the document's form fields and their values as
+	// a JSON object. Other content is left out.
+	FormatFormJSON Format = "form-json"
+	// FormatXFDF writes the document's form fields and their values as
+	// XFDF. Other content is left out.
+	FormatXFDF Format = "xfdf"
+)
+
+// Checkpoint is a position in an HWP document from which reading can
+// resume; see WithCheckpoints. It serializes to JSON.
+type Checkpoint = document.Checkpoint
+
+// ErrLimitExceeded is matched (via errors.Is) by errors caused by a resource
+// limit such as WithMaxStreamSize.
+var ErrLimitExceeded = limits.ErrExceeded
+
+// ReferenceError is returned, with WithStrictReferences, for a reference
+// to a DocInfo item such as a char shape or style that the document lacks.
+type ReferenceError = hwpv5.ReferenceError
+
+// ErrTruncatedRecord is matched (via errors.Is) by errors for an HWP record
+// too short for the fields its type requires.
+var ErrTruncatedRecord = hwpv5.ErrTruncatedRecord
+
+// RecordError locates the HWP record an error is about by stream, offset,
+// record index and tag. Read and decode failures and, with
+// WithStrictReferences, references to missing items are RecordErrors.
+type RecordError = hwpv5.RecordError
+
+// ErrUnsupportedVersion is matched (via errors.Is) by errors for an HWPX
+// package of a format version that cannot be read.
+var ErrUnsupportedVersion = hwpx.ErrUnsupportedVersion
+
+// VersionError reports the format version of an HWPX package that cannot
+// be read. Packages of a newer minor version are read, with a warning to
+// the WithWarnings function.
+type VersionError = hwpx.VersionError
+
+// ErrEncrypted is matched (via errors.Is) by errors for an HWPX package that
+// is encrypted with a document password or protected by DRM.
+var ErrEncrypted = hwpx.ErrEncrypted
+
+// EncryptionError reports the encrypted parts of an HWPX package, and the
+// encryption algorithm or DRM protection.
+type EncryptionError = hwpx.EncryptionError
+
+// Formats returns every supported output format.
+func Formats() []Format {
+	return []Format{FormatText, FormatJSONL, FormatAsciiDoc, FormatRST, FormatPandocJSON, FormatDocBook, FormatMarkdown, FormatHTML, FormatXLSX, FormatCSV, FormatXLIFF, FormatFormJSON, FormatXFDF}
+}
+
+// Option configures how a document is read and rendered.
+type Option func(*config)
+
+type config struct {
+	format          Format
+	scan            document.ScanOptions
+	linearizeTables bool
+	captionLists    bool
+	textRuns        bool
+	text            render.TextOptions
+	rawXML          bool
+	xml             hwpx.RawXMLOptions
+	maxOutputSize   int64
+	// err records an invalid option, reported when rendering
+	err error
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		format: FormatText,
+		scan:   document.DefaultScanOptions(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithFormat selects the output format.
+//
+// Example:
+//
+//	hwp.Read(file, os.Stdout, hwp.WithFormat(hwp.FormatJSONL))
+func WithFormat(format Format) Option {
+	return func(c *config) {
+		c.format = format
+	}
+}
+
+// WithCellSeparator sets the string inserted between paragraphs inside a table
+// cell. The default is "\n", which renders multi-paragraph cells on several
+// lines; " " or " / " keep each cell on a single line for CSV or Markdown exports.
+func WithCellSeparator(sep string) Option {
+	return func(c *config) {
+		c.scan.CellParagraphSeparator = sep
+	}
+}
+
+// WithSplitOnParaBreak controls whether paragraph break characters inside a
+// single HWP v5 paragraph record start new paragraphs (true) or are merged into
+// one paragraph (false, the default). Enable it for documents whose paragraphs
+// come out glued together. It has no effect on HWPX files.
+func WithSplitOnParaBreak(split bool) Option {
+	return func(c *config) {
+		c.scan.SplitOnParaBreak = split
+	}
+}
+
+// WithMaxStreamSize limits how many bytes a single stream (an HWP section or
+// an HWPX section part) may expand to after decompression. Reading fails with
+// an error matching ErrLimitExceeded when the limit is crossed, so a
+// zip-bomb-style document cannot consume unbounded resources. Zero, the
+// default, means no limit.
+func WithMaxStreamSize(n int64) Option {
+	return func(c *config) {
+		c.scan.MaxStreamSize = n
+	}
+}
+
+// WithMaxRecordSize limits the size of a single HWP record, whose data is
+// allocated at the size its header declares. Reading fails with an error
+// matching ErrLimitExceeded on a larger record. Zero, the default, means no
+// limit.
+func WithMaxRecordSize(n int64) Option {
+	return func(c *config) {
+		c.scan.MaxRecordSize = n
+	}
+}
+
+// WithMaxTableCells limits the number of rows times columns of a table,
+// which renderers lay out as a grid; a table declaring 60000×60000 cells
+// would otherwise exhaust memory. Reading fails with an error matching
+// ErrLimitExceeded on a larger table. Zero, the default, means no limit.
+func WithMaxTableCells(n int64) Option {
+	return func(c *config) {
+		c.scan.MaxTableCells = n
+	}
+}
+
+// WithMaxOutputSize limits how many bytes are written to the output.
+// Writing stops at the limit with an error matching ErrLimitExceeded. Zero,
+// the default, means no limit.
+func WithMaxOutputSize(n int64) Option {
+	return func(c *config) {
+		c.maxOutputSize = n
+	}
+}
+
+// WithHiddenText controls whether hidden comments (숨은 설명), text attached
+// to a paragraph that word processors neither display nor print, are
+// extracted. They follow the paragraph they are attached to and are flagged
+// as hidden in JSONL and HTML output. The default, false, leaves them out.
+func WithHiddenText(include bool) Option {
+	return func(c *config) {
+		c.scan.HiddenText = include
+	}
+}
+
+// WithBreaks controls whether page breaks (쪽 나누기) and the starts of
+// sections (구역) are reported, as page-break and section-break nodes in
+// JSONL output, so that consumers can split the text by page or section.
+// Other formats leave them out. The default, false, reports neither.
+func WithBreaks(include bool) Option {
+	return func(c *config) {
+		c.scan.Breaks = include
+	}
+}
+
+// WithLayoutLineBreaks breaks paragraphs into lines where they broke when
+// the document was last laid out, as recorded in its line segments, so the
+// text keeps the original line breaking instead of flowing each paragraph
+// onto one line. HWP only; HWPX paragraphs are not affected.
+func WithLayoutLineBreaks(enable bool) Option {
+	return func(c *config) {
+		c.scan.LayoutLineBreaks = enable
+	}
+}
+
+// WithStrictReferences makes reading fail when an HWP document's DocInfo
+// does not hold the items its ID mappings declare, or when the body refers
+// to a missing char shape, paragraph shape, style, tab definition, border
+// fill or binary data item (a *ReferenceError). By default such documents
+// are read with the formatting of the broken references left out.
+func WithStrictReferences(strict bool) Option {
+	return func(c *config) {
+		c.scan.StrictReferences = strict
+	}
+}
+
+// WithRecovery makes reading of a damaged HWP document go on past the
+// damage: records that fail to decode are skipped, and so is the rest of a
+// section stream that cannot be read. Each skip is reported to the
+// WithWarnings function. By default the first such failure ends reading.
+func WithRecovery(enable bool) Option {
+	return func(c *config) {
+		c.scan.Recover = enable
+	}
+}
+
+// WithPreviewFallback makes reading of an HWPX document whose section XML
+// fails to parse go on with the plain text preview that Hangul stores in
+// the package, one paragraph per line, reporting the failure to the
+// WithWarnings function. The preview holds only the start of the document
+// text, without formatting or tables, and may repeat text read before the
+// failure. By default the failure ends reading. It has no effect on HWP
+// files.
+func WithPreviewFallback(fallback bool) Option {
+	return func(c *config) {
+		c.scan.PreviewFallback = fallback
+	}
+}
+
+// WithPageNumbers sets the text that stands in for page numbers: page
+// number fields in the text and page numbers placed with a page number
+// position control, which appear where the control is. Page numbers depend
+// on the page layout, which is not computed. The default, "", drops them;
+// "[PAGE]" keeps a visible placeholder.
+func WithPageNumbers(placeholder string) Option {
+	return func(c *config) {
+		c.scan.PageNumber = placeholder
+	}
+}
+
+// ChangeView selects how tracked changes (변경 추적) appear in the output.
+type ChangeView string
+
+const (
+	// ChangesFinal shows the text with all changes accepted. This is the
+	// default.
+	ChangesFinal ChangeView = document.ChangesFinal
+	// ChangesOriginal shows the text with all changes rejected.
+	ChangesOriginal ChangeView = document.ChangesOriginal
+	// ChangesMarkup keeps both inserted and deleted text. Paragraphs and
+	// table cells report the changes in JSONL; HTML marks them with ins and
+	// del elements, Markdown with <ins> and ~~strikethrough~~ and plain text
+	// with {+...+} and [-...-].
+	ChangesMarkup ChangeView = document.ChangesMarkup
+)
+
+// WithTrackChanges selects how tracked changes appear, in paragraphs, table
+// cells and text boxes. Only HWPX change tracking is decoded; HWP files are
+// read as stored, with a warning if they hold changes and another view is
+// selected. Reading fails if the view is unknown.
+func WithTrackChanges(view ChangeView) Option {
+	return func(c *config) {
+		switch view {
+		case ChangesFinal, ChangesOriginal, ChangesMarkup:
+			c.scan.Changes = string(view)
+		default:
+			c.err = fmt.Errorf("unknown track changes view %q", view)
+		}
+	}
+}
+
+// maxEmbeddedDepth bounds how deeply WithEmbeddedDocuments follows documents
+// embedded in embedded documents.
+const maxEmbeddedDepth = 8
+
+// WithEmbeddedDocuments extracts the content of HWP documents embedded as OLE
+// objects inline, after the object's placeholder and between
+// "[EMBEDDED DOCUMENT]" and "[END OF EMBEDDED DOCUMENT]" paragraphs.
+// Documents nested inside embedded documents are extracted as well. Node IDs
+// of embedded content are prefixed with the embedding object's ID and a
+// colon. Official document packages often attach documents this way. It has
+// no effect on HWPX files.
+func WithEmbeddedDocuments(extract bool) Option {
+	return func(c *config) {
+		c.scan.EmbeddedDepth = 0
+		if extract {
+			c.scan.EmbeddedDepth = maxEmbeddedDepth
+		}
+	}
+}
+
+// WithCheckpoints calls fn with the position after the content rendered so
+// far, whenever that position lies between two top-level paragraphs or
+// tables. A long-running job can store the latest checkpoint and, after an
+// interruption, pass it to WithResume to continue without reprocessing
+// earlier sections. Output formats that wrap the whole document (HTML,
+// DocBook, Pandoc JSON, XLSX) and caption lists span the resumed run only;
+// text, JSONL and CSV output can be appended. HWP v5 only.
+func WithCheckpoints(fn func(Checkpoint)) Option {
+	return func(c *config) {
+		c.scan.OnCheckpoint = fn
+	}
+}
+
+// TableRow is a row of table cells passed to the WithTableRows function.
+type TableRow = document.TableRow
+
+// WithTableRows calls fn with each row of a table as it is read, instead of
+// keeping the rows in the table: the table is still rendered, with its
+// caption but without cells. This bounds the memory that tables with
+// thousands of rows take, for callers that process rows themselves. HWPX
+// only.
+func WithTableRows(fn func(TableRow)) Option {
+	return func(c *config) {
+		c.scan.OnTableRow = fn
+	}
+}
+
+// WithWarnings calls fn for each problem the reader works around instead of
+// failing, such as a DocInfo section count that disagrees with the section
+// streams of an HWP file.
+func WithWarnings(fn func(error)) Option {
+	return func(c *config) {
+		c.scan.OnWarning = fn
+	}
+}
+
+// WithResume continues reading the same file from a checkpoint reported by
+// WithCheckpoints. Content before the checkpoint is not rendered, and node
+// IDs are the same as in an uninterrupted run. HWP v5 only.
+func WithResume(cp Checkpoint) Option {
+	return func(c *config) {
+		c.scan.Resume = &cp
+	}
+}
+
+// WithTableLinearization renders tables as linear text instead of grids:
+// each body row becomes a paragraph with one "header: value" line per cell,
+// using the detected header row as labels. Header-less tables are read as
+// form-style key/value pairs. This works far better for screen readers and
+// language model ingestion. It applies to every output format.
+func WithTableLinearization(linearize bool) Option {
+	return func(c *config) {
+		c.linearizeTables = linearize
+	}
+}
+
+// WithCaptionLists appends generated "List of Tables" and "List of Figures"
+// sections, built from table and image captions, to the output. Hangul
+// generates the same lists (표 차례, 그림 차례) for reports.
+func WithCaptionLists(lists bool) Option {
+	return func(c *config) {
+		c.captionLists = lists
+	}
+}
+
+// WithCRLF ends plain text lines with "\r\n" instead of "\n".
+// It applies to FormatText.
+func WithCRLF(crlf bool) Option {
+	return func(c *config) {
+		c.text.CRLF = crlf
+	}
+}
+
+// WithCollapseBlankLines reduces runs of blank lines in plain text output,
+// such as those produced by consecutive empty paragraphs, to a single blank
+// line. It applies to FormatText.
+func WithCollapseBlankLines(collapse bool) Option {
+	return func(c *config) {
+		c.text.CollapseBlankLines = collapse
+	}
+}
+
+// WithTrimTrailingSpace removes trailing spaces and tabs from each line of
+// plain text output. It applies to FormatText.
+func WithTrimTrailingSpace(trim bool) Option {
+	return func(c *config) {
+		c.text.TrimTrailingSpace = trim
+	}
+}
+
+// WithNFC normalizes plain text output to Unicode NFC, so that text stored
+// as decomposed jamo compares equal to precomposed Hangul in diff and NLP
+// tools. It applies to FormatText.
+func WithNFC(nfc bool) Option {
+	return func(c *config) {
+		c.text.NFC = nfc
+	}
+}
+
+// WithRawXML makes ReadHWPX emit the section XML of the document instead of
+// parsed content, for running XSLT or other XML tooling over OWPML. The
+// sections are wrapped, in order, in a single <sections> root element; see
+// WithXMLIndent and WithXMLNormalizedNamespaces. Output format options do not
+// apply, and reading an HWP v5 file fails.
+func WithRawXML(raw bool) Option {
+	return func(c *config) {
+		c.rawXML = raw
+	}
+}
+
+// WithXMLIndent pretty-prints the output of WithRawXML. Text content is left
+// untouched.
+func WithXMLIndent(indent bool) Option {
+	return func(c *config) {
+		c.xml.Indent = indent
+	}
+}
+
+// WithXMLNormalizedNamespaces rewrites the output of WithRawXML to use the
+// conventional OWPML prefixes (hp, hs, hc, ...), declared once on the root
+// element, so stylesheets can rely on them whichever application wrote the
+// file.
+func WithXMLNormalizedNamespaces(normalize bool) Option {
+	return func(c *config) {
+		c.xml.NormalizeNamespaces = normalize
+	}
+}
+
+// WithTextRuns gives every paragraph runs that cover all of its text, with
+// the hyperlink target of linked runs as url, so that JSONL consumers can
+// rebuild rich text from the runs alone. By default runs are only given for
+// paragraphs with emphasis, and links are listed apart from them.
+func WithTextRuns(runs bool) Option {
+	return func(c *config) {
+		c.textRuns = runs
+	}
+}
+
+// render writes the scanned content to out in the configured format.
+func (c *config) render(scanner document.ContentNodeScanner, out io.Writer) error {
+	if c.err != nil {
+		return c.err
+	}
+	// Caption lists must see tables before linearization replaces them
+	if c.captionLists {
+		scanner = transform.AppendCaptionLists(scanner)
+	}
+	if c.linearizeTables {
+		scanner = transform.LinearizeTables(scanner)
+	}
+	if c.textRuns {
+		scanner = transform.TextRuns(scanner)
+	}
+	out = limits.NewWriter(out, c.maxOutputSize, "output")
+
+	switch c.format {
+	case FormatText, "":
+		return render.RenderTextWithOptions(scanner, out, c.text)
+	case FormatJSONL:
+		return render.RenderJSONL(scanner, out)
+	case FormatAsciiDoc:
+		return render.RenderAsciiDoc(scanner, out)
+	case FormatRST:
+		return render.RenderRST(scanner, out)
+	case FormatPandocJSON:
+		return render.RenderPandocJSON(scanner, out)
+	case FormatDocBook:
+		return render.RenderDocBook(scanner, out)
+	case FormatMarkdown:
+		return render.RenderMarkdown(scanner, out)
+	case FormatHTML:
+		return render.RenderHTML(scanner, out)
+	case FormatXLSX:
+		return render.RenderXLSX(scanner, out)
+	case FormatCSV:
+		return render.RenderCSV(scanner, out)
+	case FormatXLIFF:
+		return render.RenderXLIFF(scanner, out)
+	case FormatFormJSON:
+		return render.RenderFormJSON(scanner, out)
+	case FormatXFDF:
+		return render.RenderXFDF(scanner, out)
+	default:
+		return fmt.Errorf("unsupported output format %q", c.format)
+	}
+}
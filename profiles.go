@@ -0,0 +1,81 @@
+package hwp
+
+import "fmt"
+
+// Profile names a preset of options for a common use case.
+type Profile string
+
+const (
+	// ProfileFaithful keeps the output as close to the document as plain
+	// text allows: grid tables, multi-line cells and no normalization.
+	ProfileFaithful Profile = "faithful"
+	// ProfileSearchIndex produces compact, normalized text for full-text
+	// indexing: linearized tables, single-line cells, NFC, no blank runs.
+	ProfileSearchIndex Profile = "search-index"
+	// ProfileLLMCorpus produces Markdown with single-line table cells, which
+	// keeps document structure readable for language models.
+	ProfileLLMCorpus Profile = "llm-corpus"
+	// ProfileCSVData extracts only the tables as CSV.
+	ProfileCSVData Profile = "csv-data"
+)
+
+// profiles maps each profile to its options. Every profile sets the same
+// options, so applying one fully replaces the effect of another.
+var profiles = map[Profile][]Option{
+	ProfileFaithful: {
+		WithFormat(FormatText),
+		WithCellSeparator("\n"),
+		WithTableLinearization(false),
+		WithCollapseBlankLines(false),
+		WithTrimTrailingSpace(false),
+		WithNFC(false),
+	},
+	ProfileSearchIndex: {
+		WithFormat(FormatText),
+		WithCellSeparator(" "),
+		WithTableLinearization(true),
+		WithCollapseBlankLines(true),
+		WithTrimTrailingSpace(true),
+		WithNFC(true),
+	},
+	ProfileLLMCorpus: {
+		WithFormat(FormatMarkdown),
+		WithCellSeparator(" "),
+		WithTableLinearization(false),
+		WithCollapseBlankLines(false),
+		WithTrimTrailingSpace(false),
+		WithNFC(false),
+	},
+	ProfileCSVData: {
+		WithFormat(FormatCSV),
+		WithCellSeparator(" "),
+		WithTableLinearization(false),
+		WithCollapseBlankLines(false),
+		WithTrimTrailingSpace(false),
+		WithNFC(false),
+	},
+}
+
+// Profiles returns every available profile.
+func Profiles() []Profile {
+	return []Profile{ProfileFaithful, ProfileSearchIndex, ProfileLLMCorpus, ProfileCSVData}
+}
+
+// WithProfile applies the options of a profile. Options given after it
+// override the profile's settings:
+//
+//	hwp.Read(file, os.Stdout, hwp.WithProfile(hwp.ProfileSearchIndex), hwp.WithCRLF(true))
+//
+// Reading fails if the profile is unknown.
+func WithProfile(p Profile) Option {
+	return func(c *config) {
+		opts, ok := profiles[p]
+		if !ok {
+			c.err = fmt.Errorf("unknown profile %q", p)
+			return
+		}
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package hwp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithProfile(t *testing.T) {
+	cfg := newConfig([]Option{WithProfile(ProfileSearchIndex), WithFormat(FormatMarkdown)})
+	if cfg.err != nil {
+		t.Fatal(cfg.err)
+	}
+	if cfg.format != FormatMarkdown {
+		t.Errorf("format = %q, want the later option to override the profile", cfg.format)
+	}
+	if !cfg.linearizeTables || !cfg.text.NFC || cfg.scan.CellParagraphSeparator != " " {
+		t.Errorf("search-index settings not applied: %+v", cfg)
+	}
+
+	// A later profile replaces every setting of an earlier one
+	cfg = newConfig([]Option{WithProfile(ProfileSearchIndex), WithProfile(ProfileFaithful)})
+	if !reflect.DeepEqual(cfg, newConfig(nil)) {
+		t.Errorf("faithful after search-index = %+v, want the defaults", cfg)
+	}
+
+	if cfg := newConfig([]Option{WithProfile("nope")}); cfg.err == nil {
+		t.Error("unknown profile accepted")
+	}
+}
+
+func TestProfilesDefined(t *testing.T) {
+	for _, p := range Profiles() {
+		if _, ok := profiles[p]; !ok {
+			t.Errorf("profile %q has no options", p)
+		}
+	}
+}
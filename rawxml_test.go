@@ -0,0 +1,66 @@
+package hwp
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func rawXMLFixture(t *testing.T) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []struct{ name, data string }{
+		{"mimetype", "application/hwp+zip"},
+		{"version.xml", `<HCFVersion major="5" minor="1" micro="0" buildNumber="1"/>`},
+		{"Contents/section1.xml", `<?xml version="1.0"?><sec xmlns="http://www.hancom.co.kr/hwpml/2011/section"/>`},
+		{"Contents/section0.xml", `<?xml version="1.0" encoding="UTF-8"?>` +
+			`<s:sec xmlns:s="http://www.hancom.co.kr/hwpml/2011/section" xmlns:p="http://www.hancom.co.kr/hwpml/2011/paragraph">` +
+			`<p:p id="1"><p:run><p:t> a &amp; b </p:t></p:run></p:p></s:sec>`},
+	}
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(part.data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestRawXML(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"raw", nil, []string{
+			`<sections>` + "\n" + `<s:sec xmlns:s=`,
+			`<p:t> a &amp; b </p:t></p:run></p:p></s:sec>` + "\n" + `<sec xmlns=`,
+		}},
+		{"indent", []Option{WithXMLIndent(true)}, []string{
+			"\n  <p:p id=\"1\">\n    <p:run>\n      <p:t> a &amp; b </p:t>\n    </p:run>\n  </p:p>\n</s:sec>",
+			`<sec xmlns="http://www.hancom.co.kr/hwpml/2011/section"/>`,
+		}},
+		{"normalized", []Option{WithXMLNormalizedNamespaces(true)}, []string{
+			`<sections xmlns:ha=`,
+			`<hs:sec><hp:p id="1"><hp:run><hp:t> a &amp; b </hp:t></hp:run></hp:p></hs:sec>` + "\n" + `<hs:sec/>`,
+		}},
+	} {
+		in := rawXMLFixture(t)
+		var out bytes.Buffer
+		opts := append([]Option{WithRawXML(true)}, tc.opts...)
+		if err := ReadHWPX(in, in.Size(), &out, opts...); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s: output lacks %q:\n%s", tc.name, want, out.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package hwp
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/hwpv5"
+)
+
+// Scripts holds the script (macro) code of an HWP document. HasMacros
+// reports whether any of it would run, and Code returns all of it.
+type Scripts = hwpv5.Scripts
+
+// ReadScripts reads the scripts of a document, such as the handlers run
+// when it is opened, for security scanning of inbound documents. Documents
+// without scripts yield an empty Scripts.
+//
+// Only HWP v5 documents are supported; HWPX documents yield no scripts.
+func ReadScripts(file *os.File) (Scripts, error) {
+	if isHWPX(file.Name()) {
+		return Scripts{}, nil
+	}
+	reader, err := hwpv5.OpenReader(file, document.DefaultScanOptions())
+	if err != nil {
+		return Scripts{}, fmt.Errorf("failed to parse HWP file: %w", err)
+	}
+	return reader.Scripts()
+}
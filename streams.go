@@ -0,0 +1,99 @@
+package hwp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hanpama/hwp/internal/hwpv5"
+	"github.com/hanpama/hwp/internal/hwpx"
+)
+
+// Stream is an entry of an HWP file's OLE compound file: a stream, or a
+// storage holding other entries. Name is its path, such as
+// "DocOptions/_LinkDoc", and Size the number of bytes stored.
+type Stream = hwpv5.Stream
+
+var errRawStreamsHWPX = errors.New("raw streams require an HWP file")
+
+// ListStreams lists every storage and stream of an HWP file, including
+// those the parser does not read, such as DocOptions and XMLTemplate. The
+// document is not parsed, so damaged and password-protected files can be
+// inspected too. HWPX files are ZIP packages and are not supported.
+func ListStreams(file *os.File) ([]Stream, error) {
+	if isHWPX(file.Name()) {
+		return nil, errRawStreamsHWPX
+	}
+	return hwpv5.ListStreams(file)
+}
+
+// OpenRawStream opens a stream of an HWP file by its path, as listed by
+// ListStreams. The data is returned as stored: DocInfo, sections and most
+// other streams are compressed, and the sections of distribution documents
+// are encrypted. HWPX files are not supported.
+func OpenRawStream(file *os.File, name string) (io.Reader, error) {
+	if isHWPX(file.Name()) {
+		return nil, errRawStreamsHWPX
+	}
+	return hwpv5.OpenRawStream(file, name)
+}
+
+// Part is an entry of an HWPX package. Name is its path, such as
+// "BinData/image1.png"; ID and MediaType come from the package manifest and
+// are empty for parts it does not list. Kind tells what the part holds:
+// PartSection, PartBinData, PartChart, PartPreview and so on. Size is the
+// uncompressed size.
+type Part = hwpx.Part
+
+// Kinds of HWPX package parts
+const (
+	PartSection    = hwpx.PartSection
+	PartHeader     = hwpx.PartHeader
+	PartMasterPage = hwpx.PartMasterPage
+	PartBinData    = hwpx.PartBinData
+	PartChart      = hwpx.PartChart
+	PartPreview    = hwpx.PartPreview
+	PartSettings   = hwpx.PartSettings
+	PartPackage    = hwpx.PartPackage
+	PartOther      = hwpx.PartOther
+)
+
+var errPartsHWP = errors.New("package parts require an HWPX file")
+
+// ListParts lists every part of an HWPX package in ZIP order, with its
+// manifest ID and media type, so that resources such as pictures and charts
+// can be picked out without guessing paths. HWP files are not supported; see
+// ListStreams.
+func ListParts(file *os.File) ([]Part, error) {
+	reader, err := openHWPXPackage(file)
+	if err != nil {
+		return nil, err
+	}
+	return reader.Parts(), nil
+}
+
+// OpenPart opens a part of an HWPX package by its path, as listed by
+// ListParts. The data is uncompressed. HWP files are not supported.
+func OpenPart(file *os.File, name string) (io.ReadCloser, error) {
+	reader, err := openHWPXPackage(file)
+	if err != nil {
+		return nil, err
+	}
+	return reader.OpenPart(name)
+}
+
+func openHWPXPackage(file *os.File) (*hwpx.Reader, error) {
+	if !isHWPX(file.Name()) {
+		return nil, errPartsHWP
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	reader, err := hwpx.Open(file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HWPX file: %w", err)
+	}
+	return reader, nil
+}
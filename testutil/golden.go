@@ -0,0 +1,148 @@
+// Package testutil provides golden-output regression testing for document
+// extraction.
+//
+// A fixture document is rendered with every output format and each result is
+// compared with a golden file stored next to it, named
+// <fixture>.<format>.golden. Downstream users can lock in extraction behavior
+// against their own corpora, and contributors adding record decoders can see
+// exactly which outputs change.
+//
+// Example:
+//
+//	func TestReport(t *testing.T) {
+//		testutil.Golden(t, "testdata/report.hwp")
+//	}
+//
+// Run the tests with HWP_UPDATE_GOLDEN=1 to (re)write golden files.
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp"
+)
+
+// UpdateEnv names the environment variable that, when non-empty, makes Golden
+// write golden files instead of comparing against them.
+const UpdateEnv = "HWP_UPDATE_GOLDEN"
+
+// maxDiffLines bounds the number of differing lines reported per format.
+const maxDiffLines = 10
+
+// GoldenPath returns the golden file path for a fixture and format.
+func GoldenPath(fixture string, format hwp.Format) string {
+	return fixture + "." + string(format) + ".golden"
+}
+
+// Render reads the fixture and renders it in the given format.
+func Render(fixture string, format hwp.Format, opts ...hwp.Option) (string, error) {
+	file, err := os.Open(fixture)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	opts = append(append([]hwp.Option{}, opts...), hwp.WithFormat(format))
+	if err := hwp.Read(file, &buf, opts...); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Golden renders the fixture with every output format and compares each
+// result with its golden file, reporting a line diff on mismatch.
+//
+// Formats without a golden file are skipped, so adding a renderer does not
+// break existing suites; at least one golden file must exist. When UpdateEnv
+// is set, golden files for all formats are written instead.
+func Golden(t *testing.T, fixture string, opts ...hwp.Option) {
+	t.Helper()
+
+	update := os.Getenv(UpdateEnv) != ""
+	compared := 0
+
+	for _, format := range hwp.Formats() {
+		path := GoldenPath(fixture, format)
+
+		got, err := Render(fixture, format, opts...)
+		if err != nil {
+			t.Errorf("%s: render %s: %v", fixture, format, err)
+			continue
+		}
+
+		if update {
+			if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+				t.Errorf("%s: %v", path, err)
+			}
+			continue
+		}
+
+		want, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			t.Logf("%s: no golden file, skipping %s", path, format)
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+
+		compared++
+		if diff := Diff(string(want), got); diff != "" {
+			t.Errorf("%s: %s output differs from golden file (-want +got):\n%s", fixture, format, diff)
+		}
+	}
+
+	if !update && compared == 0 {
+		t.Errorf("%s: no golden files found; run with %s=1 to create them", fixture, UpdateEnv)
+	}
+}
+
+// Diff returns a line-oriented description of the differences between want
+// and got, or "" if they are equal. Only the first few differing lines are
+// reported.
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var sb strings.Builder
+	reported := 0
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		w, wok := line(wantLines, i)
+		g, gok := line(gotLines, i)
+		if wok == gok && w == g {
+			continue
+		}
+
+		if reported == maxDiffLines {
+			sb.WriteString("...\n")
+			break
+		}
+		reported++
+
+		fmt.Fprintf(&sb, "line %d:\n", i+1)
+		if wok {
+			fmt.Fprintf(&sb, "- %s\n", w)
+		}
+		if gok {
+			fmt.Fprintf(&sb, "+ %s\n", g)
+		}
+	}
+	return sb.String()
+}
+
+func line(lines []string, i int) (string, bool) {
+	if i < len(lines) {
+		return lines[i], true
+	}
+	return "", false
+}
@@ -0,0 +1,70 @@
+package testutil
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hanpama/hwp"
+)
+
+// writeFixture creates a minimal single-section HWPX package.
+func writeFixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.hwpx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	parts := map[string]string{
+		"mimetype":    "application/hwp+zip",
+		"version.xml": `<HCFVersion major="5" minor="1" micro="0" buildNumber="1"/>`,
+		"Contents/section0.xml": `<sec><p><run><t>골든 테스트</t></run></p>` +
+			`<p><run><tbl rowCnt="1" colCnt="2"><tr>` +
+			`<tc><subList><p><run><t>A</t></run></p></subList><cellAddr colAddr="0" rowAddr="0"/><cellSpan colSpan="1" rowSpan="1"/></tc>` +
+			`<tc><subList><p><run><t>B</t></run></p></subList><cellAddr colAddr="1" rowAddr="0"/><cellSpan colSpan="1" rowSpan="1"/></tc>` +
+			`</tr></tbl></run></p></sec>`,
+	}
+	for _, name := range []string{"mimetype", "version.xml", "Contents/section0.xml"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(parts[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGoldenRoundTrip(t *testing.T) {
+	fixture := writeFixture(t)
+
+	t.Setenv(UpdateEnv, "1")
+	Golden(t, fixture)
+	for _, format := range hwp.Formats() {
+		if _, err := os.Stat(GoldenPath(fixture, format)); err != nil {
+			t.Errorf("golden file for %s not written: %v", format, err)
+		}
+	}
+
+	t.Setenv(UpdateEnv, "")
+	Golden(t, fixture)
+}
+
+func TestDiff(t *testing.T) {
+	if d := Diff("a\nb\n", "a\nb\n"); d != "" {
+		t.Errorf("expected no diff, got %q", d)
+	}
+
+	d := Diff("a\nb\n", "a\nc\n")
+	if !strings.Contains(d, "line 2:\n- b\n+ c\n") {
+		t.Errorf("unexpected diff:\n%s", d)
+	}
+}
@@ -0,0 +1,116 @@
+package hwp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hanpama/hwp/internal/document"
+	"github.com/hanpama/hwp/internal/hwpv5"
+	"github.com/hanpama/hwp/internal/hwpx"
+)
+
+// InferTitle returns a best-effort title for a document, using the first
+// source that yields a non-empty value:
+//
+//  1. the title stored in the document metadata
+//  2. the first line of the first non-empty paragraph
+//  3. the file name without its extension
+//
+// Most HWP files in the wild have empty summary metadata, so the fallbacks
+// matter in practice. Options affect how paragraphs are scanned.
+func InferTitle(file *os.File, opts ...Option) (string, error) {
+	cfg := newConfig(opts)
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	var title string
+	var scanner document.ContentNodeScanner
+	if isHWPX(file.Name()) {
+		title, err = hwpxMetadataTitle(file, fileInfo.Size())
+		if err == nil && title == "" {
+			scanner, err = openHWPXScanner(file, fileInfo.Size(), cfg)
+		}
+	} else {
+		title, err = hwpMetadataTitle(file)
+		if err == nil && title == "" {
+			scanner, err = openHWPScanner(file, cfg)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if title == "" {
+		title, err = firstParagraphLine(scanner)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if title == "" {
+		base := filepath.Base(file.Name())
+		title = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	return title, nil
+}
+
+func hwpMetadataTitle(in io.ReaderAt) (string, error) {
+	reader, err := hwpv5.OpenReader(in, document.DefaultScanOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HWP file: %w", err)
+	}
+	summary, err := reader.Summary()
+	if err != nil {
+		// Damaged metadata should not prevent the fallbacks
+		return "", nil
+	}
+	return strings.TrimSpace(summary.Title), nil
+}
+
+func hwpxMetadataTitle(in io.ReaderAt, size int64) (string, error) {
+	reader, err := hwpx.Open(in, size)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HWPX file: %w", err)
+	}
+	md, err := reader.Metadata()
+	if err != nil {
+		return "", nil
+	}
+	return md.Title, nil
+}
+
+// firstParagraphLine returns the first non-blank line of the first non-empty
+// top-level paragraph.
+func firstParagraphLine(scanner document.ContentNodeScanner) (string, error) {
+	for {
+		node, err := scanner.Next()
+		if err != nil {
+			if err == io.EOF {
+				return "", nil
+			}
+			return "", fmt.Errorf("error reading content: %w", err)
+		}
+
+		var para *document.Paragraph
+		switch n := node.(type) {
+		case *document.Paragraph:
+			para = n
+		case *document.Heading:
+			para = &n.Paragraph
+		default:
+			continue
+		}
+		for _, line := range strings.Split(para.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				return line, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package hwp
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hanpama/hwp/internal/hwpv5"
+	"github.com/hanpama/hwp/internal/hwpx"
+)
+
+// Violation is a structural problem with a document found by Validate,
+// located by section, record index and stream offset, or for HWPX by
+// section part, element index and byte offset.
+type Violation = hwpv5.Violation
+
+// Validate checks a document against structural invariants of the format
+// and returns the violations found: record levels that skip a level, table
+// cells that do not cover the table's rows and columns, undecodable records
+// and references beyond the DocInfo ID mappings. It is meant for checking
+// documents produced by third-party writers; a document with violations may
+// still read fine. WithMaxStreamSize applies.
+//
+// HWPX section XML is checked against structural rules of OWPML instead:
+// well-formedness, the placement of paragraphs, runs, text, table rows and
+// cells, table grids and references to header items and binary items.
+func Validate(file *os.File, opts ...Option) ([]Violation, error) {
+	cfg := newConfig(opts)
+	if isHWPX(file.Name()) {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %w", err)
+		}
+		reader, err := hwpx.Open(file, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HWPX file: %w", err)
+		}
+		return reader.Validate(cfg.scan.MaxStreamSize)
+	}
+	reader, err := hwpv5.OpenReader(file, cfg.scan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HWP file: %w", err)
+	}
+	return reader.Validate()
+}